@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/traego/scaled-mcp/pkg/proto/scaledmcp"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/protomcp"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	contextPackage   = protogen.GoImportPath("context")
+	protocolPackage  = protogen.GoImportPath("github.com/traego/scaled-mcp/pkg/protocol")
+	resourcesPackage = protogen.GoImportPath("github.com/traego/scaled-mcp/pkg/resources")
+	protomcpPackage  = protogen.GoImportPath("github.com/traego/scaled-mcp/pkg/protomcp")
+	protoPackage     = protogen.GoImportPath("google.golang.org/protobuf/proto")
+)
+
+// tool is an RPC generated as a tool
+type tool struct {
+	method *protogen.Method
+	tool   protocol.Tool
+}
+
+// generateFile generates the tools of a file's services, if it has any
+func generateFile(plugin *protogen.Plugin, file *protogen.File) error {
+	if len(file.Services) == 0 {
+		return nil
+	}
+
+	g := plugin.NewGeneratedFile(file.GeneratedFilenamePrefix+"_mcp.pb.go", file.GoImportPath)
+	g.P("// Code generated by protoc-gen-scaledmcp. DO NOT EDIT.")
+	g.P("// versions:")
+	g.P("// \tprotoc-gen-scaledmcp ", version)
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("package ", file.GoPackageName)
+
+	for _, service := range file.Services {
+		tools, err := serviceTools(service)
+		if err != nil {
+			return err
+		}
+		generateService(g, file, service, tools)
+	}
+	return nil
+}
+
+// serviceTools returns the tools of a service's RPCs, leaving out excluded and client streaming RPCs
+func serviceTools(service *protogen.Service) ([]tool, error) {
+	var tools []tool
+	names := make(map[string]string)
+	for _, method := range service.Methods {
+		if method.Desc.IsStreamingClient() {
+			continue
+		}
+		opts, _ := proto.GetExtension(method.Desc.Options(), scaledmcp.E_Tool).(*scaledmcp.ToolOptions)
+		if opts.GetExclude() {
+			continue
+		}
+
+		t := protocol.Tool{
+			Name:        protomcp.ToolName(method.Desc),
+			Description: protomcp.Description(method.Desc),
+			InputSchema: protomcp.InputSchema(method.Input.Desc),
+		}
+		if opts.GetName() != "" {
+			t.Name = opts.GetName()
+		}
+		if opts.GetDescription() != "" {
+			t.Description = opts.GetDescription()
+		}
+		if a := opts.GetAnnotations(); a != nil {
+			t.Annotations = &protocol.ToolAnnotations{Title: a.GetTitle()}
+			if a.ReadOnlyHint != nil {
+				t.Annotations.ReadOnlyHint = proto.Bool(a.GetReadOnlyHint())
+			}
+			if a.DestructiveHint != nil {
+				t.Annotations.DestructiveHint = proto.Bool(a.GetDestructiveHint())
+			}
+			if a.IdempotentHint != nil {
+				t.Annotations.IdempotentHint = proto.Bool(a.GetIdempotentHint())
+			}
+			if a.OpenWorldHint != nil {
+				t.Annotations.OpenWorldHint = proto.Bool(a.GetOpenWorldHint())
+			}
+		}
+
+		if other, ok := names[t.Name]; ok {
+			return nil, fmt.Errorf("RPCs %s and %s are both named %s", other, method.Desc.FullName(), t.Name)
+		}
+		names[t.Name] = string(method.Desc.FullName())
+		tools = append(tools, tool{method: method, tool: t})
+	}
+	return tools, nil
+}
+
+// generateService generates the tool variables of a service and the function registering them
+func generateService(g *protogen.GeneratedFile, file *protogen.File, service *protogen.Service, tools []tool) {
+	for _, t := range tools {
+		g.P()
+		g.P("// ", toolVar(t.method), " is the tool of the ", t.method.Desc.FullName(), " RPC")
+		g.P("var ", toolVar(t.method), " = ", protocolPackage.Ident("Tool"), "{")
+		g.P("Name: ", strconv.Quote(t.tool.Name), ",")
+		g.P("Description: ", strconv.Quote(t.tool.Description), ",")
+		generateInputSchema(g, t.tool.InputSchema)
+		if a := t.tool.Annotations; a != nil {
+			g.P("Annotations: &", protocolPackage.Ident("ToolAnnotations"), "{")
+			if a.Title != "" {
+				g.P("Title: ", strconv.Quote(a.Title), ",")
+			}
+			for _, hint := range []struct {
+				name  string
+				value *bool
+			}{
+				{"ReadOnlyHint", a.ReadOnlyHint},
+				{"DestructiveHint", a.DestructiveHint},
+				{"IdempotentHint", a.IdempotentHint},
+				{"OpenWorldHint", a.OpenWorldHint},
+			} {
+				if hint.value != nil {
+					g.P(hint.name, ": ", protoPackage.Ident("Bool"), "(", *hint.value, "),")
+				}
+			}
+			g.P("},")
+		}
+		g.P("}")
+	}
+
+	server := g.QualifiedGoIdent(file.GoImportPath.Ident(service.GoName + "Server"))
+	g.P()
+	g.P("// Register", service.GoName, "Tools registers the tools of the RPCs of the ", service.Desc.FullName(),
+		" service on registry, implemented by srv")
+	g.P("func Register", service.GoName, "Tools(registry *", resourcesPackage.Ident("StaticToolRegistry"), ", srv ", server, ") error {")
+	for _, t := range tools {
+		g.P("if err := registry.RegisterTool(", toolVar(t.method), ", func(ctx ", contextPackage.Ident("Context"),
+			", params map[string]interface{}) (interface{}, error) {")
+		g.P("req := new(", t.method.Input.GoIdent, ")")
+		g.P("if err := ", protomcpPackage.Ident("UnmarshalArguments"), "(params, req); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		if t.method.Desc.IsStreamingServer() {
+			g.P("stream := ", protomcpPackage.Ident("NewServerStream"), "[", t.method.Output.GoIdent, "](ctx)")
+			g.P("return stream.Result(srv.", t.method.GoName, "(req, stream))")
+		} else {
+			g.P("return ", protomcpPackage.Ident("Result"), "(srv.", t.method.GoName, "(ctx, req))")
+		}
+		g.P("}); err != nil {")
+		g.P("return err")
+		g.P("}")
+	}
+	g.P("return nil")
+	g.P("}")
+}
+
+// generateInputSchema generates the InputSchema field of a tool
+func generateInputSchema(g *protogen.GeneratedFile, schema protocol.InputSchema) {
+	g.P("InputSchema: ", protocolPackage.Ident("InputSchema"), "{")
+	g.P("Type: ", strconv.Quote(schema.Type), ",")
+	g.P("Properties: map[string]", protocolPackage.Ident("SchemaProperty"), "{")
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property := schema.Properties[name]
+		fields := []string{"Type: " + strconv.Quote(property.Type)}
+		if property.Description != "" {
+			fields = append(fields, "Description: "+strconv.Quote(property.Description))
+		}
+		if property.Default != nil {
+			fields = append(fields, fmt.Sprintf("Default: %#v", property.Default))
+		}
+		g.P(strconv.Quote(name), ": {", strings.Join(fields, ", "), "},")
+	}
+	g.P("},")
+	if len(schema.Required) > 0 {
+		quoted := make([]string, len(schema.Required))
+		for i, name := range schema.Required {
+			quoted[i] = strconv.Quote(name)
+		}
+		g.P("Required: []string{", strings.Join(quoted, ", "), "},")
+	}
+	g.P("},")
+}
+
+// toolVar returns the name of the variable of an RPC's tool, such as Greeter_SayHello_Tool
+func toolVar(method *protogen.Method) string {
+	return method.Parent.GoName + "_" + method.GoName + "_Tool"
+}
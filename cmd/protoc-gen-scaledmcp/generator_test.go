@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// run runs the plugin on a descriptor set of testdata, returning the generated files by name. The descriptor sets
+// are built with their imports and source info, as in:
+//
+//	protoc -I testdata -I ../.. --include_imports --include_source_info -o testdata/greeter.binpb greeter.proto
+func run(t *testing.T, descriptorSet string, files ...string) map[string]string {
+	data, err := os.ReadFile(filepath.Join("testdata", descriptorSet))
+	require.NoError(t, err)
+	var set descriptorpb.FileDescriptorSet
+	require.NoError(t, proto.Unmarshal(data, &set))
+
+	plugin, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      proto.String("paths=source_relative"),
+		ProtoFile:      set.File,
+	})
+	require.NoError(t, err)
+	require.NoError(t, generate(plugin))
+
+	resp := plugin.Response()
+	require.Nil(t, resp.Error)
+	generated := make(map[string]string)
+	for _, file := range resp.File {
+		generated[file.GetName()] = file.GetContent()
+	}
+	return generated
+}
+
+func TestGenerate_Golden(t *testing.T) {
+	generated := run(t, "greeter.binpb", "greeter.proto")
+	require.Contains(t, generated, "greeter_mcp.pb.go")
+
+	golden := filepath.Join("testdata", "greeter_mcp.pb.go.golden")
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(generated["greeter_mcp.pb.go"]), 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), generated["greeter_mcp.pb.go"])
+}
+
+func TestGenerate_NoServices(t *testing.T) {
+	// Files without services get no tools, even when asked for
+	generated := run(t, "greeter.binpb", "google/protobuf/timestamp.proto")
+	assert.Empty(t, generated)
+}
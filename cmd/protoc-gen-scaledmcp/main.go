@@ -0,0 +1,42 @@
+// Command protoc-gen-scaledmcp is a protoc and buf plugin generating MCP tools of gRPC services. For each
+// service of a file it generates, in <file>_mcp.pb.go, a tool variable per unary and server streaming RPC and a
+// Register<Service>Tools function registering them on a resources.StaticToolRegistry, implemented by the
+// <Service>Server interface protoc-gen-go-grpc generates. The scaledmcp.tool method option, of
+// proto/scaledmcp/options.proto, renames, describes, annotates or excludes the tool of an RPC.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// version is the version of the plugin, written in the header of generated files
+const version = "v0.1.0"
+
+func main() {
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("protoc-gen-scaledmcp %s\n", version)
+		os.Exit(0)
+	}
+
+	protogen.Options{}.Run(generate)
+}
+
+// generate generates the tools of the services of the files to generate
+func generate(plugin *protogen.Plugin) error {
+	plugin.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	for _, file := range plugin.Files {
+		if file.Generate {
+			if err := generateFile(plugin, file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"github.com/traego/scaled-mcp/internal/channels"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tochemey/goakt/v3/actor"
 	"github.com/tochemey/goakt/v3/goaktpb"
 
 	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/metrics"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/utils"
@@ -36,11 +38,13 @@ type ClientConnectionActor struct {
 	connectionId         string
 	defaultSseConnection bool
 	basePath             string
+	metrics              metrics.Recorder
 }
 
 // NewClientConnectionActor creates a new actor for handling client connections
-// It supports both one-way (SSE) and two-way communication with clients
-func NewClientConnectionActor(cfg *config.ServerConfig, sessionId string, params *protocol.InitializeParams, channel channels.OneWayChannel, sendEndpoint bool, defaultSseConnection bool, basePath string) actor.Actor {
+// It supports both one-way (SSE) and two-way communication with clients. Open connections are reported to
+// recorder, which may be nil.
+func NewClientConnectionActor(cfg *config.ServerConfig, sessionId string, params *protocol.InitializeParams, channel channels.OneWayChannel, sendEndpoint bool, defaultSseConnection bool, basePath string, recorder metrics.Recorder) actor.Actor {
 	// I think here we actually need to do the negotiation, so that we can either start with one way or two way comms
 
 	// TODO(arsene): this is a bit of a hack, we need to pass a logger in the constructor
@@ -53,6 +57,7 @@ func NewClientConnectionActor(cfg *config.ServerConfig, sessionId string, params
 		sendEndpoint:         sendEndpoint,
 		defaultSseConnection: defaultSseConnection,
 		basePath:             basePath,
+		metrics:              metrics.OrNop(recorder),
 	}
 }
 
@@ -64,6 +69,7 @@ func (c *ClientConnectionActor) PreStart(ctx context.Context) error {
 		c.connectionId = fmt.Sprintf("%s-conn-", cId)
 	}
 	slog.Debug(fmt.Sprintf("Starting client connection %s actor for session %s", c.connectionId, c.sessionId))
+	c.metrics.ConnectionOpened(metrics.TransportSSE)
 	return nil
 }
 
@@ -141,6 +147,28 @@ func (c *ClientConnectionActor) Receive(ctx *actor.ReceiveContext) {
 			ctx.Err(err)
 			return
 		}
+	case *mcppb.JsonRpcRequest:
+		// Server-initiated notifications, such as log messages
+		jm, err := protocol.ConvertProtoToJSONRequest(msg)
+		if err != nil {
+			ctx.Logger().Error("problem converting proto to json request", "err", err)
+			ctx.Err(err)
+			return
+		}
+
+		if err = c.channel.Send("message", jm); err != nil {
+			ctx.Logger().Error("problem pushing json rpc notification down channels channel", "err", err)
+			ctx.Err(err)
+			return
+		}
+	case *mcppb.CloseConnection:
+		// The server is draining: tell the client when to reconnect, which ends up on another node
+		if err := c.channel.SendRetry(time.Duration(msg.GetRetryAfterMs()) * time.Millisecond); err != nil {
+			ctx.Logger().Error("problem sending reconnection time down channels channel", "err", err)
+		}
+		ctx.Logger().Info("closing client connection for drain", "sessionId", c.sessionId)
+		c.channel.Close()
+		ctx.Shutdown()
 	case *goaktpb.Terminated:
 		// If the session actor terminated, we should terminate as well
 		if msg.GetActorId() == utils.GetSessionActorName(c.sessionId) {
@@ -154,6 +182,7 @@ func (c *ClientConnectionActor) Receive(ctx *actor.ReceiveContext) {
 
 func (c *ClientConnectionActor) PostStop(ctx context.Context) error {
 	slog.Debug(fmt.Sprintf("Stopping client connection %s actor for session %s", c.connectionId, c.sessionId))
+	c.metrics.ConnectionClosed(metrics.TransportSSE)
 	return nil
 }
 
@@ -14,6 +14,7 @@ import (
 	"github.com/traego/scaled-mcp/internal/logger"
 	"github.com/traego/scaled-mcp/pkg/config"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/utils"
 )
 
@@ -22,6 +23,7 @@ type InMemoryChannel struct {
 	mu           sync.Mutex
 	messages     []Message
 	endpoints    []string
+	retries      []time.Duration
 	closed       bool
 	sendFunc     func(string, interface{}) error
 	endpointFunc func(string) error
@@ -82,6 +84,27 @@ func (c *InMemoryChannel) SendEndpoint(endpoint string) error {
 	return nil
 }
 
+// SendRetry records a reconnection time sent through the channel
+func (c *InMemoryChannel) SendRetry(retry time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return assert.AnError
+	}
+
+	c.retries = append(c.retries, retry)
+	return nil
+}
+
+// GetRetries returns all reconnection times sent through the channel
+func (c *InMemoryChannel) GetRetries() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.retries
+}
+
 // Close marks the channel as closed
 func (c *InMemoryChannel) Close() {
 	c.mu.Lock()
@@ -212,6 +235,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			true, // defaultSseConnection = true
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -246,6 +270,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			false, // defaultSseConnection = false
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -279,6 +304,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -320,6 +346,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -368,6 +395,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -407,6 +435,7 @@ func TestClientConnectionActor(t *testing.T) {
 			false, // Don't send endpoint
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -449,6 +478,71 @@ func TestClientConnectionActor(t *testing.T) {
 		time.Sleep(100 * time.Millisecond)
 	})
 
+	t.Run("should handle JSON-RPC notifications", func(t *testing.T) {
+		channel := NewInMemoryChannel()
+
+		mockSession := NewMockSessionActor(nil)
+		sessionId := "test-session-notification"
+		sessionPID, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionId), mockSession)
+		require.NoError(t, err)
+
+		cca := NewClientConnectionActor(config.DefaultConfig(), sessionId, nil, channel, false, true, "", nil)
+		ccaPID, err := actorSystem.Spawn(ctx, "test-client-conn-notification", cca)
+		require.NoError(t, err)
+
+		time.Sleep(500 * time.Millisecond)
+
+		notification := &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_NullId{NullId: true},
+			Method:     "notifications/message",
+			ParamsJson: `{"level":"info","data":"hello"}`,
+		}
+		err = actor.Tell(ctx, ccaPID, notification)
+		require.NoError(t, err)
+
+		time.Sleep(500 * time.Millisecond)
+
+		messages := channel.GetMessages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, "message", messages[0].EventType)
+		jm, ok := messages[0].Data.(protocol.JSONRPCMessage)
+		require.True(t, ok)
+		assert.Equal(t, "notifications/message", jm.Method)
+		assert.Nil(t, jm.ID)
+
+		_ = actor.Tell(ctx, sessionPID, &goaktpb.PoisonPill{})
+		_ = actor.Tell(ctx, ccaPID, &goaktpb.PoisonPill{})
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	t.Run("should send a reconnection time and close when drained", func(t *testing.T) {
+		channel := NewInMemoryChannel()
+
+		mockSession := NewMockSessionActor(nil)
+		sessionId := "test-session-close"
+		sessionPID, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionId), mockSession)
+		require.NoError(t, err)
+
+		cca := NewClientConnectionActor(config.DefaultConfig(), sessionId, nil, channel, false, true, "", nil)
+		ccaPID, err := actorSystem.Spawn(ctx, "test-client-conn-close", cca)
+		require.NoError(t, err)
+
+		time.Sleep(500 * time.Millisecond)
+
+		err = actor.Tell(ctx, ccaPID, &mcppb.CloseConnection{RetryAfterMs: 1000})
+		require.NoError(t, err)
+
+		time.Sleep(500 * time.Millisecond)
+
+		assert.Equal(t, []time.Duration{time.Second}, channel.GetRetries())
+		assert.True(t, channel.IsClosed())
+		assert.False(t, ccaPID.IsRunning())
+
+		_ = actor.Tell(ctx, sessionPID, &goaktpb.PoisonPill{})
+		time.Sleep(100 * time.Millisecond)
+	})
+
 	t.Run("should handle terminated messages", func(t *testing.T) {
 		// Create a channel
 		channel := NewInMemoryChannel()
@@ -470,6 +564,7 @@ func TestClientConnectionActor(t *testing.T) {
 			false,
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -514,6 +609,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -570,6 +666,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -620,6 +717,7 @@ func TestClientConnectionActor(t *testing.T) {
 			true,
 			true,
 			"",
+			nil,
 		)
 
 		// Spawn the actor
@@ -0,0 +1,56 @@
+package actors
+
+import (
+	"github.com/tochemey/goakt/v3/actor"
+
+	"github.com/traego/scaled-mcp/pkg/metrics"
+)
+
+// instrumentedMailbox is an unbounded mailbox that reports how many messages it holds
+type instrumentedMailbox struct {
+	actor.Mailbox
+	recorder metrics.Recorder
+	kind     string
+}
+
+// WithInstrumentedMailbox returns a spawn option giving the actor a mailbox whose depth is reported to
+// recorder under the given kind of actor. A nil recorder leaves the default mailbox in place.
+func WithInstrumentedMailbox(recorder metrics.Recorder, kind string) actor.SpawnOption {
+	if recorder == nil {
+		return actor.WithMailbox(actor.NewUnboundedMailbox())
+	}
+	return actor.WithMailbox(&instrumentedMailbox{
+		Mailbox:  actor.NewUnboundedMailbox(),
+		recorder: recorder,
+		kind:     kind,
+	})
+}
+
+// Enqueue implements actor.Mailbox
+func (m *instrumentedMailbox) Enqueue(msg *actor.ReceiveContext) error {
+	if err := m.Mailbox.Enqueue(msg); err != nil {
+		return err
+	}
+	m.recorder.MailboxDepthChanged(m.kind, 1)
+	return nil
+}
+
+// Dequeue implements actor.Mailbox
+func (m *instrumentedMailbox) Dequeue() *actor.ReceiveContext {
+	msg := m.Mailbox.Dequeue()
+	if msg != nil {
+		m.recorder.MailboxDepthChanged(m.kind, -1)
+	}
+	return msg
+}
+
+// Dispose implements actor.Mailbox. Messages still queued are dropped, so they no longer count.
+func (m *instrumentedMailbox) Dispose() {
+	remaining := m.Mailbox.Len()
+	m.Mailbox.Dispose()
+	if remaining > 0 {
+		m.recorder.MailboxDepthChanged(m.kind, -int(remaining))
+	}
+}
+
+var _ actor.Mailbox = (*instrumentedMailbox)(nil)
@@ -0,0 +1,50 @@
+package actors
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/goakt/v3/actor"
+
+	"github.com/traego/scaled-mcp/pkg/metrics"
+)
+
+// depthRecorder tracks mailbox depth per kind of actor
+type depthRecorder struct {
+	metrics.NopRecorder
+	mu    sync.Mutex
+	depth map[string]int
+}
+
+func (r *depthRecorder) MailboxDepthChanged(actorKind string, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.depth[actorKind] += delta
+}
+
+func TestInstrumentedMailbox(t *testing.T) {
+	recorder := &depthRecorder{depth: make(map[string]int)}
+	mailbox := &instrumentedMailbox{
+		Mailbox:  actor.NewUnboundedMailbox(),
+		recorder: recorder,
+		kind:     metrics.ActorSession,
+	}
+
+	require.NoError(t, mailbox.Enqueue(&actor.ReceiveContext{}))
+	require.NoError(t, mailbox.Enqueue(&actor.ReceiveContext{}))
+	require.NoError(t, mailbox.Enqueue(&actor.ReceiveContext{}))
+	assert.Equal(t, 3, recorder.depth[metrics.ActorSession])
+
+	assert.NotNil(t, mailbox.Dequeue())
+	assert.Equal(t, 2, recorder.depth[metrics.ActorSession])
+
+	mailbox.Dispose()
+	assert.Equal(t, 0, recorder.depth[metrics.ActorSession])
+
+	// An empty mailbox leaves the depth alone
+	empty := &instrumentedMailbox{Mailbox: actor.NewUnboundedMailbox(), recorder: recorder, kind: metrics.ActorSession}
+	assert.Nil(t, empty.Dequeue())
+	assert.Equal(t, 0, recorder.depth[metrics.ActorSession])
+}
@@ -0,0 +1,130 @@
+package actors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tochemey/goakt/v3/actor"
+	"github.com/tochemey/goakt/v3/goaktpb"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// McpSessionActor is the actor of a session, running the session's state machine. When its node leaves the
+// cluster the actor system relocates it by spawning a zero McpSessionActor under the same name on another
+// node; it then resumes the session from the session store, or stops if the session was not saved there.
+type McpSessionActor struct {
+	fsm atomic.Pointer[utils.StateMachineActor]
+}
+
+var _ actor.Actor = (*McpSessionActor)(nil)
+
+// relocationServers holds the server of each actor system, which relocated session actors resume with
+var relocationServers sync.Map // actor.ActorSystem -> config.McpServerInfo
+
+// RegisterRelocationServer lets session actors relocated to actorSystem resume their session with serverInfo.
+// The returned function unregisters it.
+func RegisterRelocationServer(actorSystem actor.ActorSystem, serverInfo config.McpServerInfo) func() {
+	relocationServers.Store(actorSystem, serverInfo)
+	return func() {
+		relocationServers.CompareAndDelete(actorSystem, serverInfo)
+	}
+}
+
+func newMcpSessionActor(fsm *utils.StateMachineActor) *McpSessionActor {
+	a := &McpSessionActor{}
+	a.fsm.Store(fsm)
+	return a
+}
+
+// PreStart starts the state machine. A relocated actor has none yet: it is restored on PostStart, once the
+// actor knows its name and actor system.
+func (a *McpSessionActor) PreStart(ctx context.Context) error {
+	if fsm := a.fsm.Load(); fsm != nil {
+		return fsm.PreStart(ctx)
+	}
+	return nil
+}
+
+// Receive passes messages on to the state machine
+func (a *McpSessionActor) Receive(ctx *actor.ReceiveContext) {
+	fsm := a.fsm.Load()
+	if fsm == nil {
+		if _, ok := ctx.Message().(*goaktpb.PostStart); !ok {
+			ctx.Unhandled()
+			return
+		}
+
+		restored, err := restoreRelocatedSession(ctx)
+		if err != nil {
+			ctx.Logger().Info("relocated session actor not resumed, stopping", "actor", ctx.Self().Name(), "reason", err.Error())
+			utils.Shutdown(ctx)
+			return
+		}
+		if err := restored.PreStart(ctx.Context()); err != nil {
+			ctx.Err(err)
+			return
+		}
+		a.fsm.Store(restored)
+		fsm = restored
+	}
+	fsm.Receive(ctx)
+}
+
+// PostStop stops the state machine, if the actor has one
+func (a *McpSessionActor) PostStop(ctx context.Context) error {
+	if fsm := a.fsm.Load(); fsm != nil {
+		return fsm.PostStop(ctx)
+	}
+	return nil
+}
+
+// GetCurrentState returns the current state of the session, or an empty state before a relocated actor resumed it
+func (a *McpSessionActor) GetCurrentState() utils.StateID {
+	if fsm := a.fsm.Load(); fsm != nil {
+		return fsm.GetCurrentState()
+	}
+	return ""
+}
+
+// GetData returns the *SessionData of the session, or nil before a relocated actor resumed it
+func (a *McpSessionActor) GetData() utils.Data {
+	if fsm := a.fsm.Load(); fsm != nil {
+		return fsm.GetData()
+	}
+	return nil
+}
+
+// restoreRelocatedSession builds the state machine of a relocated session actor from the snapshot the session
+// saved in the session store. Taking the snapshot keeps a client resuming the session elsewhere at the same
+// time from restoring it twice.
+func restoreRelocatedSession(ctx *actor.ReceiveContext) (*utils.StateMachineActor, error) {
+	sessionId, ok := utils.SessionIdFromActorName(ctx.Self().Name())
+	if !ok {
+		return nil, errors.New("not the name of a session actor")
+	}
+
+	value, ok := relocationServers.Load(ctx.ActorSystem())
+	if !ok {
+		return nil, errors.New("no server registered for the actor system")
+	}
+	serverInfo := value.(config.McpServerInfo)
+
+	store := serverInfo.GetSessionStore()
+	if store == nil {
+		return nil, errors.New("no session store")
+	}
+	snapshot, err := store.Take(ctx.Context(), sessionId)
+	if errors.Is(err, sessionstore.ErrNotFound) {
+		return nil, errors.New("session not saved")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to take session: %w", err)
+	}
+	return sessionStateMachineFromSnapshot(serverInfo, snapshot), nil
+}
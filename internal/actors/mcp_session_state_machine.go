@@ -7,15 +7,26 @@ import (
 	"fmt"
 	"github.com/traego/scaled-mcp/pkg/auth"
 	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/tochemey/goakt/v3/actor"
 	"github.com/tochemey/goakt/v3/goaktpb"
 
+	"github.com/traego/scaled-mcp/pkg/audit"
 	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/logging"
+	"github.com/traego/scaled-mcp/pkg/metrics"
+	"github.com/traego/scaled-mcp/pkg/progress"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/redact"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/sessionstate"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"github.com/traego/scaled-mcp/pkg/tracing"
 	"github.com/traego/scaled-mcp/pkg/utils"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Session states
@@ -33,9 +44,13 @@ type SessionData struct {
 	// Server configuration
 	ServerInfo config.McpServerInfo
 
+	// Principal that initialized the session, if the request was authenticated
+	PrincipalId string
+
 	// MCP protocol state
-	ProtocolVersion protocol.ProtocolVersion
-	ClientInfo      protocol.ClientInfo
+	ProtocolVersion    protocol.ProtocolVersion
+	ClientInfo         protocol.ClientInfo
+	ClientCapabilities protocol.ClientCapabilities
 
 	// Last activity time
 	LastActivity time.Time
@@ -51,10 +66,67 @@ type SessionData struct {
 
 	// Flag to track if the session is initialized
 	ClientNotificationsInitialized bool
+
+	// Why the session ended, set when it is terminated
+	EndReason string
+
+	// Logging level chosen by the client, and the logger forwarding records to it
+	Logging *logging.Session
+
+	// Key/value state kept by tools across the session's requests
+	State *sessionstate.State
+
+	// Whether the session has been saved to the session store, so that it can be resumed if this node is lost
+	persisted bool
+
+	// Logging level carried over from the node the session was handed off from, applied once the actor starts
+	restoredLogLevel protocol.LoggingLevel
+
+	// Auth info of the client, serialized by the server's auth handler, which session hooks are run with
+	authInfo []byte
 }
 
-// NewMcpSessionStateMachine creates a new MCP session state machine actor
-func NewMcpSessionStateMachine(serverInfo config.McpServerInfo, sessionID string) actor.Actor {
+// logger returns the server's logger
+func (s *SessionData) logger() *slog.Logger {
+	return config.Logger(s.ServerInfo)
+}
+
+// NewMcpSessionStateMachine creates a new MCP session state machine actor. authInfo is the serialized auth
+// info of the client creating the session, if any.
+func NewMcpSessionStateMachine(serverInfo config.McpServerInfo, sessionID string, authInfo []byte) actor.Actor {
+	data := newSessionData(serverInfo, sessionID)
+	data.authInfo = authInfo
+	return newMcpSessionActor(newSessionStateMachine(StateUninitialized, data))
+}
+
+// NewMcpSessionStateMachineFromSnapshot creates a session actor resuming a session handed off by another
+// node. It starts initialized, so the client carries on without initializing again.
+func NewMcpSessionStateMachineFromSnapshot(serverInfo config.McpServerInfo, snapshot *sessionstore.Snapshot) actor.Actor {
+	return newMcpSessionActor(sessionStateMachineFromSnapshot(serverInfo, snapshot))
+}
+
+// sessionStateMachineFromSnapshot creates the state machine of a session resumed from its snapshot
+func sessionStateMachineFromSnapshot(serverInfo config.McpServerInfo, snapshot *sessionstore.Snapshot) *utils.StateMachineActor {
+	data := newSessionData(serverInfo, snapshot.SessionId)
+	data.PrincipalId = snapshot.PrincipalId
+	data.ProtocolVersion = snapshot.ProtocolVersion
+	data.ClientInfo = snapshot.ClientInfo
+	data.ClientCapabilities = snapshot.ClientCapabilities
+	data.authInfo = snapshot.AuthInfo
+	data.ClientNotificationsInitialized = snapshot.ClientNotificationsInitialized
+	data.restoredLogLevel = snapshot.LogLevel
+	data.State = sessionstate.New(serverInfo.GetServerConfig().Session.MaxStateBytes, snapshot.State)
+	return newSessionStateMachine(StateInitialized, data)
+}
+
+// SessionSpawnOptions returns the options session actors are spawned with. When their node leaves the cluster
+// they are relocated, resuming from the session store (see McpSessionActor).
+func SessionSpawnOptions(recorder metrics.Recorder) []actor.SpawnOption {
+	return []actor.SpawnOption{WithInstrumentedMailbox(recorder, metrics.ActorSession)}
+}
+
+// newSessionData creates the state of a new session, with timeouts from the server config
+func newSessionData(serverInfo config.McpServerInfo, sessionID string) *SessionData {
 	// Initialize session data
 	sessionTimeout := 5 * time.Minute
 	if serverInfo.GetServerConfig().Session.TTL > 0 {
@@ -66,7 +138,7 @@ func NewMcpSessionStateMachine(serverInfo config.McpServerInfo, sessionID string
 		initializeTimeout = serverInfo.GetServerConfig().Session.InitializeTimeout
 	}
 
-	data := &SessionData{
+	return &SessionData{
 		SessionID:                      sessionID,
 		ServerInfo:                     serverInfo,
 		LastActivity:                   time.Now(),
@@ -74,16 +146,20 @@ func NewMcpSessionStateMachine(serverInfo config.McpServerInfo, sessionID string
 		SessionTimeout:                 sessionTimeout,
 		ClientConnectionActors:         make(map[string]*actor.PID),
 		ClientNotificationsInitialized: false,
+		State:                          sessionstate.New(serverInfo.GetServerConfig().Session.MaxStateBytes, nil),
 	}
+}
 
-	// Create state machine starting in uninitialized state
-	fsm := utils.NewStateMachineActor(sessionID, StateUninitialized, data)
+// newSessionStateMachine creates the state machine of a session, starting in initialState
+func newSessionStateMachine(initialState utils.StateID, data *SessionData) *utils.StateMachineActor {
+	fsm := utils.NewStateMachineActor(data.SessionID, initialState, data)
 
 	// Configure state handlers
 	fsm.When(StateUninitialized, handleUninitializedState).
 		When(StateInitialized, handleInitializedState).
 		When(StateShutdown, handleShutdownState).
-		WhenUnhandled(handleUnhandledMessage)
+		WhenUnhandled(handleUnhandledMessage).
+		WhenStopped(handleSessionStopped)
 
 	return fsm
 }
@@ -104,9 +180,17 @@ func handleUninitializedState(ctx *actor.ReceiveContext, data utils.Data) (utils
 		return handleTryCleanupIfUninitialized(ctx, sessionData)
 	case *mcppb.CheckSessionTTL:
 		return handleCheckSessionTTL(ctx, sessionData)
+	case *mcppb.SessionNotification:
+		return handleSessionNotification(ctx, sessionData, msg)
+	case *mcppb.DrainSession:
+		return handleDrainSession(ctx, sessionData, msg, false)
+	case *mcppb.GetSessionInfo:
+		return handleGetSessionInfo(ctx, sessionData, StateUninitialized)
+	case *mcppb.TerminateSession:
+		return handleTerminateSession(ctx, sessionData, msg)
 	default:
 		// Log unhandled message
-		slog.WarnContext(ctx.Context(), "Uninitialized state: Received unknown message type",
+		sessionData.logger().WarnContext(ctx.Context(), "Uninitialized state: Received unknown message type",
 			"session_id", sessionData.SessionID,
 			"message_type", fmt.Sprintf("%T", msg))
 		ctx.Unhandled()
@@ -120,6 +204,8 @@ func handleInitializedState(ctx *actor.ReceiveContext, data utils.Data) (utils.M
 
 	message := ctx.Message()
 	switch msg := message.(type) {
+	case *goaktpb.PostStart:
+		return handlePostStartRestored(ctx, sessionData)
 	case *mcppb.RegisterConnection:
 		return handleRegisterConnection(ctx, sessionData, msg)
 	case *mcppb.WrappedRequest:
@@ -128,9 +214,17 @@ func handleInitializedState(ctx *actor.ReceiveContext, data utils.Data) (utils.M
 		return handleCheckSessionTTL(ctx, sessionData)
 	case *mcppb.TryCleanupIfUninitialized:
 		return handleTryCleanupInitialized(ctx, sessionData)
+	case *mcppb.SessionNotification:
+		return handleSessionNotification(ctx, sessionData, msg)
+	case *mcppb.DrainSession:
+		return handleDrainSession(ctx, sessionData, msg, true)
+	case *mcppb.GetSessionInfo:
+		return handleGetSessionInfo(ctx, sessionData, StateInitialized)
+	case *mcppb.TerminateSession:
+		return handleTerminateSession(ctx, sessionData, msg)
 	default:
 		// Log unhandled message
-		slog.WarnContext(ctx.Context(), "Initialized state: Received unknown message type",
+		sessionData.logger().WarnContext(ctx.Context(), "Initialized state: Received unknown message type",
 			"session_id", sessionData.SessionID,
 			"message_type", fmt.Sprintf("%T", msg))
 		ctx.Unhandled()
@@ -149,9 +243,15 @@ func handleShutdownState(ctx *actor.ReceiveContext, data utils.Data) (utils.Mess
 		// Always shutdown when in shutdown state
 		utils.Shutdown(ctx)
 		return utils.Stay(sessionData)
+	case *mcppb.GetSessionInfo:
+		return handleGetSessionInfo(ctx, sessionData, StateShutdown)
+	case *mcppb.TerminateSession:
+		// Already ending
+		ctx.Response(&mcppb.TerminateSessionResponse{Success: true})
+		return utils.Stay(sessionData)
 	default:
 		// Log unhandled message
-		slog.WarnContext(ctx.Context(), "Shutdown state: Received message, ignoring",
+		sessionData.logger().WarnContext(ctx.Context(), "Shutdown state: Received message, ignoring",
 			"session_id", sessionData.SessionID,
 			"message_type", fmt.Sprintf("%T", msg))
 		return utils.Stay(sessionData)
@@ -161,14 +261,48 @@ func handleShutdownState(ctx *actor.ReceiveContext, data utils.Data) (utils.Mess
 // handleUnhandledMessage handles messages that are not handled by any state
 func handleUnhandledMessage(ctx *actor.ReceiveContext, data utils.Data, message interface{}) utils.Data {
 	sessionData := data.(*SessionData)
-	slog.WarnContext(ctx.Context(), "Received unhandled message",
+	sessionData.logger().WarnContext(ctx.Context(), "Received unhandled message",
 		"session_id", sessionData.SessionID,
 		"message_type", fmt.Sprintf("%T", message))
 	return sessionData
 }
 
+// handleSessionStopped records the end of the session once its actor has stopped
+func handleSessionStopped(ctx context.Context, data utils.Data) {
+	sessionData := data.(*SessionData)
+	reason := sessionData.EndReason
+	if reason == "" {
+		reason = metrics.SessionEndStopped
+	}
+	metrics.OrNop(sessionData.ServerInfo.GetMetrics()).SessionEnded(reason)
+	releaseResourceSubscriptions(ctx, sessionData)
+	runSessionClosedHook(sessionHookContext(ctx, sessionData), sessionData, reason)
+}
+
+// releaseResourceSubscriptions unsubscribes the session from the resources it subscribed to on this node
+func releaseResourceSubscriptions(ctx context.Context, sessionData *SessionData) {
+	subs := sessionData.ServerInfo.GetResourceSubscriptions()
+	if subs == nil {
+		return
+	}
+	uris := subs.RemoveSession(sessionData.SessionID)
+	registry := sessionData.ServerInfo.GetFeatureRegistry().ResourceRegistry
+	if registry == nil {
+		return
+	}
+	subscriberCtx := context.WithValue(ctx, resources.SubscriberIDKey, sessionData.SessionID)
+	for _, uri := range uris {
+		if err := registry.UnsubscribeResource(subscriberCtx, uri); err != nil && !errors.Is(err, resources.ErrResourceNotFound) {
+			sessionData.logger().WarnContext(ctx, "failed to unsubscribe ended session from resource", "session_id", sessionData.SessionID, "uri", uri, "err", err)
+		}
+	}
+}
+
 // handlePostStartUninitialized handles the PostStart message
 func handlePostStartUninitialized(ctx *actor.ReceiveContext, sessionData *SessionData) (utils.MessageHandlingResult, error) {
+	metrics.OrNop(sessionData.ServerInfo.GetMetrics()).SessionStarted()
+	sessionData.Logging = newLoggingSession(ctx, sessionData)
+	runSessionCreatedHook(sessionHookContext(ctx.Context(), sessionData), sessionData)
 	ctx.Logger().Info("mcp session actor finished starting, sending cleanup message", "session_id", sessionData.SessionID)
 	err := ctx.ActorSystem().ScheduleOnce(ctx.Context(), &mcppb.TryCleanupIfUninitialized{}, ctx.Self(), sessionData.InitializeTimeout)
 	if err != nil {
@@ -178,6 +312,214 @@ func handlePostStartUninitialized(ctx *actor.ReceiveContext, sessionData *Sessio
 	return utils.Stay(sessionData)
 }
 
+// handlePostStartRestored handles the PostStart message of a session resumed from a snapshot
+func handlePostStartRestored(ctx *actor.ReceiveContext, sessionData *SessionData) (utils.MessageHandlingResult, error) {
+	metrics.OrNop(sessionData.ServerInfo.GetMetrics()).SessionStarted()
+	sessionData.Logging = newLoggingSession(ctx, sessionData)
+	if sessionData.restoredLogLevel != "" {
+		if err := sessionData.Logging.SetLevel(sessionData.restoredLogLevel); err != nil {
+			sessionData.logger().WarnContext(ctx.Context(), "failed to restore session logging level", "session_id", sessionData.SessionID, "err", err)
+		}
+	}
+	sessionData.logger().InfoContext(ctx.Context(), "mcp session resumed from snapshot", "session_id", sessionData.SessionID)
+
+	hookCtx := sessionHookContext(ctx.Context(), sessionData)
+	runSessionCreatedHook(hookCtx, sessionData)
+	if err := runInitializedHook(hookCtx, sessionData); err != nil {
+		sessionData.logger().WarnContext(ctx.Context(), "resumed session rejected by hook", "session_id", sessionData.SessionID, "err", redact.String(err.Error()))
+		terminateSession(ctx.Context(), sessionData, metrics.SessionEndRejected)
+		utils.Shutdown(ctx)
+		return utils.Goto(StateShutdown, sessionData)
+	}
+
+	// The snapshot was taken out of the store, so the state is saved again in case this node is lost
+	if sessionData.State.Size() > 0 {
+		persistSession(ctx.Context(), sessionData)
+	}
+	return handleTryCleanupInitialized(ctx, sessionData)
+}
+
+// newLoggingSession creates the session's logging state. Log messages for the client are sent back to the
+// session actor, which forwards them to one of the client's connections.
+func newLoggingSession(ctx *actor.ReceiveContext, sessionData *SessionData) *logging.Session {
+	self := ctx.Self()
+	server := sessionData.logger().With("session_id", sessionData.SessionID)
+	return logging.NewSession(server, func(params protocol.LoggingMessageParams) {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			server.Error("failed to marshal log message for client", "err", err)
+			return
+		}
+		notification := &mcppb.SessionNotification{
+			Notification: &mcppb.JsonRpcRequest{
+				Jsonrpc:    "2.0",
+				Id:         &mcppb.JsonRpcRequest_NullId{NullId: true},
+				Method:     "notifications/message",
+				ParamsJson: string(paramsJSON),
+			},
+		}
+		if err := actor.Tell(context.Background(), self, notification); err != nil {
+			server.Debug("failed to queue log message for client", "err", err)
+		}
+	})
+}
+
+// handleSessionNotification forwards a server-initiated notification to the client, preferring the
+// session's default SSE connection. Notifications are dropped if the client has no open stream.
+func handleSessionNotification(ctx *actor.ReceiveContext, sessionData *SessionData, msg *mcppb.SessionNotification) (utils.MessageHandlingResult, error) {
+	conn := notificationConnection(sessionData, "")
+	if conn == nil {
+		sessionData.logger().DebugContext(ctx.Context(), "no open connection for notification, dropping it", "session_id", sessionData.SessionID, "method", msg.GetNotification().GetMethod())
+		return utils.Stay(sessionData)
+	}
+
+	ctx.Tell(conn, msg.GetNotification())
+	return utils.Stay(sessionData)
+}
+
+// notificationConnection returns the connection notifications are sent to the client on: the preferred one if
+// it is open, else the session's default SSE connection, else any open connection. It returns nil if the client
+// has no open stream.
+func notificationConnection(sessionData *SessionData, preferredId string) *actor.PID {
+	for _, id := range []string{preferredId, utils.GetDefaultSSEConnectionName(sessionData.SessionID)} {
+		if conn, ok := sessionData.ClientConnectionActors[id]; ok && conn.IsRunning() {
+			return conn
+		}
+	}
+	for _, conn := range sessionData.ClientConnectionActors {
+		if conn.IsRunning() {
+			return conn
+		}
+	}
+	return nil
+}
+
+// withRequestProgress lets the request's handler report progress if the client asked for it. Progress is sent
+// straight to a connection of the client, as the session actor is busy with the request until it is done.
+func withRequestProgress(ctx context.Context, sessionData *SessionData, msg *mcppb.WrappedRequest) context.Context {
+	token := progress.TokenFromParams(msg.Request.ParamsJson)
+	if token == nil {
+		return ctx
+	}
+	preferredId := ""
+	if !msg.IsAsk {
+		preferredId = msg.RespondToConnectionId
+	}
+	conn := notificationConnection(sessionData, preferredId)
+	if conn == nil {
+		sessionData.logger().DebugContext(ctx, "no open connection for progress, dropping it", "session_id", sessionData.SessionID, "method", msg.Request.Method)
+		return ctx
+	}
+
+	logger := sessionData.logger()
+	return progress.WithReporter(ctx, token, func(params protocol.ProgressNotificationParams) {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			logger.Error("failed to marshal progress for client", "session_id", sessionData.SessionID, "err", err)
+			return
+		}
+		notification := &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_NullId{NullId: true},
+			Method:     "notifications/progress",
+			ParamsJson: string(paramsJSON),
+		}
+		if err := actor.Tell(context.Background(), conn, notification); err != nil {
+			logger.Debug("failed to send progress to client", "session_id", sessionData.SessionID, "err", err)
+		}
+	})
+}
+
+// handleDrainSession hands an initialized session off to the session store, if one is configured, tells the
+// client's connections to close so that it reconnects to another node, and stops the session
+func handleDrainSession(ctx *actor.ReceiveContext, sessionData *SessionData, msg *mcppb.DrainSession, initialized bool) (utils.MessageHandlingResult, error) {
+	response := &mcppb.DrainSessionResponse{}
+	if store := sessionData.ServerInfo.GetSessionStore(); store != nil && initialized {
+		if err := store.Save(ctx.Context(), snapshotSession(sessionData), sessionData.SessionTimeout); err != nil {
+			sessionData.logger().ErrorContext(ctx.Context(), "failed to hand off session", "session_id", sessionData.SessionID, "err", err)
+			response.Error = redact.String(err.Error())
+		} else {
+			response.HandedOff = true
+		}
+	}
+
+	for _, conn := range sessionData.ClientConnectionActors {
+		if conn.IsRunning() {
+			ctx.Tell(conn, &mcppb.CloseConnection{RetryAfterMs: msg.GetRetryAfterMs()})
+		}
+	}
+
+	reason := metrics.SessionEndDrained
+	if response.HandedOff {
+		reason = metrics.SessionEndHandedOff
+	}
+	terminateSession(ctx.Context(), sessionData, reason)
+	ctx.Response(response)
+	utils.Shutdown(ctx)
+
+	return utils.Goto(StateShutdown, sessionData)
+}
+
+// handleGetSessionInfo describes the session for the admin API
+func handleGetSessionInfo(ctx *actor.ReceiveContext, sessionData *SessionData, state utils.StateID) (utils.MessageHandlingResult, error) {
+	connectionIds := make([]string, 0, len(sessionData.ClientConnectionActors))
+	for id, conn := range sessionData.ClientConnectionActors {
+		if conn.IsRunning() {
+			connectionIds = append(connectionIds, id)
+		}
+	}
+	sort.Strings(connectionIds)
+
+	ctx.Response(&mcppb.SessionInfo{
+		SessionId:          sessionData.SessionID,
+		State:              string(state),
+		ProtocolVersion:    string(sessionData.ProtocolVersion),
+		ClientName:         sessionData.ClientInfo.Name,
+		ClientVersion:      sessionData.ClientInfo.Version,
+		PrincipalId:        sessionData.PrincipalId,
+		LastActivityUnixMs: sessionData.LastActivity.UnixMilli(),
+		ConnectionIds:      connectionIds,
+	})
+	return utils.Stay(sessionData)
+}
+
+// handleTerminateSession ends the session at the request of an operator, or of the client deleting it. The
+// client's connections stop with the session, and the client has to initialize a new one.
+func handleTerminateSession(ctx *actor.ReceiveContext, sessionData *SessionData, msg *mcppb.TerminateSession) (utils.MessageHandlingResult, error) {
+	if msg.GetByClient() {
+		sessionData.logger().InfoContext(ctx.Context(), "session deleted by client", "session_id", sessionData.SessionID)
+		terminateSession(ctx.Context(), sessionData, metrics.SessionEndDeleted)
+	} else {
+		sessionData.logger().InfoContext(ctx.Context(), "session terminated by operator", "session_id", sessionData.SessionID, "reason", msg.GetReason())
+		terminateSessionWithDetail(ctx.Context(), sessionData, metrics.SessionEndTerminated, msg.GetReason())
+	}
+	ctx.Response(&mcppb.TerminateSessionResponse{Success: true})
+	utils.Shutdown(ctx)
+
+	return utils.Goto(StateShutdown, sessionData)
+}
+
+// snapshotSession captures the state another node needs to resume the session
+func snapshotSession(sessionData *SessionData) *sessionstore.Snapshot {
+	snapshot := &sessionstore.Snapshot{
+		SessionId:                      sessionData.SessionID,
+		ProtocolVersion:                sessionData.ProtocolVersion,
+		ClientInfo:                     sessionData.ClientInfo,
+		ClientCapabilities:             sessionData.ClientCapabilities,
+		AuthInfo:                       sessionData.authInfo,
+		PrincipalId:                    sessionData.PrincipalId,
+		ClientNotificationsInitialized: sessionData.ClientNotificationsInitialized,
+		LastActivity:                   sessionData.LastActivity,
+		State:                          sessionData.State.Values(),
+	}
+	if sessionData.Logging != nil {
+		if level, ok := sessionData.Logging.Level(); ok {
+			snapshot.LogLevel = level
+		}
+	}
+	return snapshot
+}
+
 // handleRegisterConnection handles the RegisterConnection message
 func handleRegisterConnection(ctx *actor.ReceiveContext, sessionData *SessionData, msg *mcppb.RegisterConnection) (utils.MessageHandlingResult, error) {
 	sender := ctx.Sender()
@@ -189,13 +531,39 @@ func handleRegisterConnection(ctx *actor.ReceiveContext, sessionData *SessionDat
 
 // handleWrappedRequestUninitialized handles wrapped requests in the uninitialized state
 func handleWrappedRequestUninitialized(rctx *actor.ReceiveContext, sessionData *SessionData, msg *mcppb.WrappedRequest) (utils.MessageHandlingResult, error) {
-	ctx := context.WithValue(context.Background(), utils.SessionIdCtx, sessionData.SessionID)
+	ctx, span := startRequestSpan(sessionData, msg)
+	defer span.End()
+
 	// In uninitialized state, we only accept initialize requests
 	switch msg.Request.Method {
 	case "initialize":
+		start := time.Now()
+		if authCtx, err := withRequestAuth(ctx, sessionData, msg); err == nil {
+			ctx = authCtx
+			if ai := auth.GetAuthInfo(ctx); ai != nil {
+				sessionData.PrincipalId = ai.GetPrincipalId()
+				sessionData.authInfo = msg.AuthInfo
+			}
+		} else {
+			sessionData.logger().WarnContext(ctx, "failed to deserialize auth info for initialize", "session_id", sessionData.SessionID, "err", err)
+		}
+
 		response := handleInitialize(ctx, sessionData, msg.Request)
+		if response.GetError() == nil {
+			if err := runInitializedHook(ctx, sessionData); err != nil {
+				// The application turned the client away, so the session ends here
+				response = errorResponse(ctx, sessionData, msg.Request, err, "initialization rejected by hook")
+				sendResponse(rctx, ctx, sessionData, msg, response)
+				auditRequest(ctx, sessionData, audit.EventInitialize, msg, response, start)
+				terminateSession(ctx, sessionData, metrics.SessionEndRejected)
+				utils.Shutdown(rctx)
+				return utils.Goto(StateShutdown, sessionData)
+			}
+		}
 		sendResponse(rctx, ctx, sessionData, msg, response)
+		auditRequest(ctx, sessionData, audit.EventInitialize, msg, response, start)
 		sessionData.LastActivity = time.Now()
+		persistSessionState(ctx, sessionData)
 
 		// Transition to initialized state
 		nextState := StateInitialized
@@ -217,19 +585,13 @@ func handleWrappedRequestUninitialized(rctx *actor.ReceiveContext, sessionData *
 // handleWrappedRequestInitialized handles wrapped requests in the initialized state
 func handleWrappedRequestInitialized(rctx *actor.ReceiveContext, sessionData *SessionData, msg *mcppb.WrappedRequest) (utils.MessageHandlingResult, error) {
 	// TODO Set a timeout here, need to think through just a bit what timeout to use
-	ctx := context.WithValue(context.Background(), utils.SessionIdCtx, sessionData.SessionID)
+	ctx, span := startRequestSpan(sessionData, msg)
+	defer span.End()
 
-	if len(msg.AuthInfo) > 0 && sessionData.ServerInfo.GetAuthHandler() != nil {
-		authInfoRaw := msg.AuthInfo
-		authInfo, err := sessionData.ServerInfo.GetAuthHandler().Deserialize(authInfoRaw)
-		if err != nil {
-			return utils.MessageHandlingResult{}, fmt.Errorf("failed to deserialize auth info: %w", err)
-		}
-		ctx = auth.SetAuthInfo(ctx, authInfo)
-	}
-
-	if len(msg.TraceId) > 0 && sessionData.ServerInfo.GetTraceHandler() != nil {
-		ctx = sessionData.ServerInfo.GetTraceHandler().SetTraceId(ctx, msg.TraceId)
+	ctx, err := withRequestAuth(ctx, sessionData, msg)
+	if err != nil {
+		tracing.SetError(span, err)
+		return utils.MessageHandlingResult{}, err
 	}
 
 	// Handle the request based on the method
@@ -237,6 +599,7 @@ func handleWrappedRequestInitialized(rctx *actor.ReceiveContext, sessionData *Se
 	case "shutdown":
 		response := handleShutdown(msg.Request)
 		sendResponse(rctx, ctx, sessionData, msg, response)
+		terminateSession(ctx, sessionData, metrics.SessionEndShutdown)
 
 		// Transition to shutdown state
 		nextState := StateShutdown
@@ -246,40 +609,146 @@ func handleWrappedRequestInitialized(rctx *actor.ReceiveContext, sessionData *Se
 		}, nil
 
 	case "notifications/initialized":
-		slog.InfoContext(ctx, "Handling notifications/initialized request", "session_id", sessionData.SessionID)
+		sessionData.logger().InfoContext(ctx, "Handling notifications/initialized request", "session_id", sessionData.SessionID)
 		// This is a notification that initialization is complete
 		sessionData.LastActivity = time.Now()
 		sessionData.ClientNotificationsInitialized = true
 		sessionData.LastActivity = time.Now()
 		return utils.Stay(sessionData)
 	default:
+		start := time.Now()
+
 		// Handle non-lifecycle messages
+		ctx = withRequestProgress(ctx, sessionData, msg)
 		response, err := handleNonLifecycleRequest(ctx, sessionData, msg.Request.Id, msg.Request)
 		if err != nil {
-			var retErr *mcppb.JsonRpcResponse
-
-			var jsonRpcError *protocol.JsonRpcError
-			if errors.As(err, &jsonRpcError) {
-				retErr = utils.CreateErrorResponseFromJsonRpcError(msg.Request, jsonRpcError)
-			} else {
-				hndlErr := protocol.NewInternalError("problem handling message", msg.Request.Id)
-				retErr = utils.CreateErrorResponseFromJsonRpcError(msg.Request, hndlErr)
-			}
-
+			retErr := errorResponse(ctx, sessionData, msg.Request, err, "problem handling non-lifecycle message")
 			sendResponse(rctx, ctx, sessionData, msg, retErr)
-			slog.ErrorContext(ctx, "problem handling non-lifecycle message", "session_id", sessionData.SessionID, "err", err)
+			auditRequest(ctx, sessionData, audit.EventRequest, msg, retErr, start)
 			return utils.Stay(sessionData)
 		}
 
 		sendResponse(rctx, ctx, sessionData, msg, response)
+		auditRequest(ctx, sessionData, audit.EventRequest, msg, response, start)
 		sessionData.LastActivity = time.Now()
+		persistSessionState(ctx, sessionData)
 		return utils.Stay(sessionData)
 	}
 }
 
+// errorResponse logs err with logMessage and converts it to the response to req. JSON-RPC errors reach the
+// client with secrets redacted; anything else is reported as an internal error, opaque in production.
+func errorResponse(ctx context.Context, sessionData *SessionData, req *mcppb.JsonRpcRequest, err error, logMessage string) *mcppb.JsonRpcResponse {
+	errorId := redact.NewErrorId()
+	sessionData.logger().ErrorContext(ctx, logMessage, "session_id", sessionData.SessionID, "error_id", errorId, "err", redact.String(err.Error()))
+
+	var jsonRpcError *protocol.JsonRpcError
+	if errors.As(err, &jsonRpcError) {
+		jsonRpcError.Message = redact.String(jsonRpcError.Message)
+		return utils.CreateErrorResponseFromJsonRpcError(req, jsonRpcError)
+	}
+
+	hndlErr := protocol.NewInternalError("problem handling message", req.Id)
+	if sessionData.ServerInfo.GetServerConfig().Errors.Production {
+		hndlErr = protocol.NewOpaqueInternalError(errorId, req.Id)
+	}
+	return utils.CreateErrorResponseFromJsonRpcError(req, hndlErr)
+}
+
+// startRequestSpan creates the context a request is handled with, carrying the session id, its logging
+// state and the caller's trace context, and starts the span covering the request's processing in the session
+func startRequestSpan(sessionData *SessionData, msg *mcppb.WrappedRequest) (context.Context, trace.Span) {
+	ctx := context.WithValue(context.Background(), utils.SessionIdCtx, sessionData.SessionID)
+	ctx = tracing.Extract(ctx, msg.TraceContext)
+	if sessionData.Logging != nil {
+		ctx = logging.WithSession(ctx, sessionData.Logging)
+	}
+	ctx = sessionstate.WithState(ctx, sessionData.State)
+
+	if traceId := utils.GetTraceId(ctx); traceId != "" && sessionData.ServerInfo.GetTraceHandler() != nil {
+		ctx = sessionData.ServerInfo.GetTraceHandler().SetTraceId(ctx, traceId)
+	}
+
+	return tracing.Tracer(sessionData.ServerInfo.GetTracerProvider()).Start(ctx, "mcp.session "+msg.Request.Method,
+		trace.WithAttributes(
+			tracing.AttrMethod.String(msg.Request.Method),
+			tracing.AttrSessionId.String(sessionData.SessionID),
+		),
+	)
+}
+
+// withRequestAuth adds the request's deserialized auth info to the context
+func withRequestAuth(ctx context.Context, sessionData *SessionData, msg *mcppb.WrappedRequest) (context.Context, error) {
+	if len(msg.AuthInfo) == 0 || sessionData.ServerInfo.GetAuthHandler() == nil {
+		return ctx, nil
+	}
+
+	authInfo, err := sessionData.ServerInfo.GetAuthHandler().Deserialize(msg.AuthInfo)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to deserialize auth info: %w", err)
+	}
+	return auth.SetAuthInfo(ctx, authInfo), nil
+}
+
+// terminateSession records why a session is ending and audits it. A session that ends for good is removed
+// from the session store, so that no node resumes it.
+func terminateSession(ctx context.Context, sessionData *SessionData, reason string) {
+	terminateSessionWithDetail(ctx, sessionData, reason, "")
+}
+
+// terminateSessionWithDetail is terminateSession with detail, such as the reason an operator gave, added to
+// the audited reason
+func terminateSessionWithDetail(ctx context.Context, sessionData *SessionData, reason, detail string) {
+	sessionData.EndReason = reason
+	auditReason := reason
+	if detail != "" {
+		auditReason = fmt.Sprintf("%s: %s", reason, detail)
+	}
+	auditSessionTerminated(ctx, sessionData, auditReason)
+	if reason != metrics.SessionEndDrained && reason != metrics.SessionEndHandedOff {
+		discardPersistedSession(ctx, sessionData)
+	}
+}
+
+// persistSessionState saves the session to the session store if its state changed
+func persistSessionState(ctx context.Context, sessionData *SessionData) {
+	if sessionData.State.TakeChanged() {
+		persistSession(ctx, sessionData)
+	}
+}
+
+// persistSession saves the session to the session store, if one is configured, so that another node resumes
+// it with its state if this node is lost, whether the client reconnects there or the actor system relocates
+// the session's actor.
+func persistSession(ctx context.Context, sessionData *SessionData) {
+	store := sessionData.ServerInfo.GetSessionStore()
+	if store == nil {
+		return
+	}
+	if err := store.Save(ctx, snapshotSession(sessionData), sessionData.SessionTimeout); err != nil {
+		sessionData.logger().ErrorContext(ctx, "failed to persist session", "session_id", sessionData.SessionID, "err", err)
+		return
+	}
+	sessionData.persisted = true
+}
+
+// discardPersistedSession removes the session from the session store, if it was saved there
+func discardPersistedSession(ctx context.Context, sessionData *SessionData) {
+	store := sessionData.ServerInfo.GetSessionStore()
+	if store == nil || !sessionData.persisted {
+		return
+	}
+	if _, err := store.Take(ctx, sessionData.SessionID); err != nil && !errors.Is(err, sessionstore.ErrNotFound) {
+		sessionData.logger().ErrorContext(ctx, "failed to remove persisted session", "session_id", sessionData.SessionID, "err", err)
+		return
+	}
+	sessionData.persisted = false
+}
+
 // handleTryCleanupIfUninitialized handles the TryCleanupIfUninitialized message
 func handleTryCleanupIfUninitialized(ctx *actor.ReceiveContext, sessionData *SessionData) (utils.MessageHandlingResult, error) {
-	slog.InfoContext(ctx.Context(), "handling cleanup request - session is uninitialized, shutting down", "session_id", sessionData.SessionID)
+	sessionData.logger().InfoContext(ctx.Context(), "handling cleanup request - session is uninitialized, shutting down", "session_id", sessionData.SessionID)
+	terminateSession(ctx.Context(), sessionData, metrics.SessionEndInitializeTimeout)
 	err := ctx.Self().Shutdown(ctx.Context())
 	if err != nil {
 		ctx.Logger().Error("failed to shut down session actor", "session_id", sessionData.SessionID, "error", err)
@@ -290,7 +759,7 @@ func handleTryCleanupIfUninitialized(ctx *actor.ReceiveContext, sessionData *Ses
 
 // handleTryCleanupIfUninitialized handles the TryCleanupIfUninitialized message
 func handleTryCleanupInitialized(ctx *actor.ReceiveContext, sessionData *SessionData) (utils.MessageHandlingResult, error) {
-	slog.InfoContext(ctx.Context(), "handling cleanup request - session is initialized, scheduling periodic session check", "session_id", sessionData.SessionID)
+	sessionData.logger().InfoContext(ctx.Context(), "handling cleanup request - session is initialized, scheduling periodic session check", "session_id", sessionData.SessionID)
 
 	sessionData.LastActivity = time.Now()
 
@@ -305,23 +774,28 @@ func handleTryCleanupInitialized(ctx *actor.ReceiveContext, sessionData *Session
 // handleCheckSessionTTL handles the CheckSessionTTL message
 func handleCheckSessionTTL(ctx *actor.ReceiveContext, sessionData *SessionData) (utils.MessageHandlingResult, error) {
 	timeoutAt := sessionData.LastActivity.Add(sessionData.SessionTimeout)
-	slog.InfoContext(ctx.Context(), "checking if session is alive", "session_id", sessionData.SessionID, "timeout_at", timeoutAt)
+	sessionData.logger().InfoContext(ctx.Context(), "checking if session is alive", "session_id", sessionData.SessionID, "timeout_at", timeoutAt)
 	if timeoutAt.Before(time.Now()) {
-		slog.InfoContext(ctx.Context(), fmt.Sprintf("session has had no activity since %s, shutting down", sessionData.LastActivity.String()), "session_id", sessionData.SessionID)
+		sessionData.logger().InfoContext(ctx.Context(), fmt.Sprintf("session has had no activity since %s, shutting down", sessionData.LastActivity.String()), "session_id", sessionData.SessionID)
 		ctx.Logger().Info("mcp session actor timeout", "session_id", sessionData.SessionID)
+		terminateSession(ctx.Context(), sessionData, metrics.SessionEndTTLExpired)
 		utils.Shutdown(ctx)
+	} else if sessionData.persisted {
+		// Keep the saved session from expiring while the session is in use
+		persistSession(ctx.Context(), sessionData)
 	}
 	return utils.Stay(sessionData)
 }
 
 // sendResponse sends a response to the client
 func sendResponse(rctx *actor.ReceiveContext, ctx context.Context, sessionData *SessionData, wrappedMsg *mcppb.WrappedRequest, response *mcppb.JsonRpcResponse) {
+	tracing.SetResponseStatus(trace.SpanFromContext(ctx), response)
 	if wrappedMsg.IsAsk {
 		rctx.Response(response)
 	} else {
 		rc, ok := sessionData.ClientConnectionActors[wrappedMsg.RespondToConnectionId]
 		if !ok {
-			slog.ErrorContext(ctx, "could not find actor to respond for connection to", "connectionId", wrappedMsg.RespondToConnectionId)
+			sessionData.logger().ErrorContext(ctx, "could not find actor to respond for connection to", "connectionId", wrappedMsg.RespondToConnectionId)
 			return
 		}
 		rctx.Tell(rc, response)
@@ -330,7 +804,7 @@ func sendResponse(rctx *actor.ReceiveContext, ctx context.Context, sessionData *
 
 // handleInitialize processes an initialize request
 func handleInitialize(ctx context.Context, sessionData *SessionData, req *mcppb.JsonRpcRequest) *mcppb.JsonRpcResponse {
-	slog.InfoContext(ctx, "Handling initialize request", "session_id", sessionData.SessionID)
+	sessionData.logger().InfoContext(ctx, "Handling initialize request", "session_id", sessionData.SessionID)
 
 	// Create base response
 	response := &mcppb.JsonRpcResponse{
@@ -376,6 +850,7 @@ func handleInitialize(ctx context.Context, sessionData *SessionData, req *mcppb.
 	// Store client info and capabilities
 	sessionData.ProtocolVersion = params.ProtocolVersion
 	sessionData.ClientInfo = params.ClientInfo
+	sessionData.ClientCapabilities = params.Capabilities
 	sessionData.LastActivity = time.Now()
 
 	// Create the result
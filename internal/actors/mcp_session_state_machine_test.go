@@ -3,6 +3,9 @@ package actors
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,11 +14,23 @@ import (
 	"github.com/tochemey/goakt/v3/actor"
 
 	"github.com/traego/scaled-mcp/internal/logger"
+	"github.com/traego/scaled-mcp/pkg/audit"
 	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/logging"
+	"github.com/traego/scaled-mcp/pkg/metrics"
+	"github.com/traego/scaled-mcp/pkg/progress"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/sessionstate"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"github.com/traego/scaled-mcp/pkg/tracing"
 	"github.com/traego/scaled-mcp/pkg/utils"
+	"github.com/traego/scaled-mcp/test/serverinfo"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TestExecutor is a real implementation of config.MethodHandler for testing
@@ -64,10 +79,19 @@ func (e *TestExecutor) HandleMethod(ctx context.Context, method string, req *mcp
 
 // TestServerInfo is a real implementation of config.McpServerInfo for testing
 type TestServerInfo struct {
+	serverinfo.Stub
+
 	serverCaps   protocol.ServerCapabilities
 	serverConfig *config.ServerConfig
 	executors    config.MethodHandler
 	registry     resources.FeatureRegistry
+	auditSink    audit.Sink
+	metrics      metrics.Recorder
+
+	tracerProvider trace.TracerProvider
+	logger         *slog.Logger
+	sessionStore   sessionstore.Store
+	sessionHooks   *config.SessionHooks
 }
 
 func NewTestServerInfo(executors config.MethodHandler) config.McpServerInfo {
@@ -107,12 +131,28 @@ func (s *TestServerInfo) GetFeatureRegistry() resources.FeatureRegistry {
 	return s.registry
 }
 
-func (s *TestServerInfo) GetAuthHandler() config.AuthHandler {
-	return nil
+func (s *TestServerInfo) GetAuditSink() audit.Sink {
+	return s.auditSink
 }
 
-func (s *TestServerInfo) GetTraceHandler() config.TraceHandler {
-	return nil
+func (s *TestServerInfo) GetMetrics() metrics.Recorder {
+	return s.metrics
+}
+
+func (s *TestServerInfo) GetTracerProvider() trace.TracerProvider {
+	return s.tracerProvider
+}
+
+func (s *TestServerInfo) GetLogger() *slog.Logger {
+	return s.logger
+}
+
+func (s *TestServerInfo) GetSessionStore() sessionstore.Store {
+	return s.sessionStore
+}
+
+func (s *TestServerInfo) GetSessionHooks() *config.SessionHooks {
+	return s.sessionHooks
 }
 
 // TestConnectionActor is a real implementation of a client connection actor for testing
@@ -259,7 +299,7 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-1"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Spawn the actor
 		pid, err := actorSystem.Spawn(ctx, "test-session-1", sessionActor)
@@ -287,7 +327,7 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-2"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Spawn the actor
 		pid, err := actorSystem.Spawn(ctx, "test-session-2", sessionActor)
@@ -316,10 +356,10 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-3"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Get the state machine to verify state transitions
-		stateMachine, ok := sessionActor.(*utils.StateMachineActor)
+		stateMachine, ok := sessionActor.(*McpSessionActor)
 		require.True(t, ok)
 
 		// Spawn the actor
@@ -350,7 +390,7 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-4"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Spawn the actor
 		pid, err := actorSystem.Spawn(ctx, "test-session-4", sessionActor)
@@ -403,10 +443,10 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-5"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Get the state machine to verify state transitions
-		stateMachine, ok := sessionActor.(*utils.StateMachineActor)
+		stateMachine, ok := sessionActor.(*McpSessionActor)
 		require.True(t, ok)
 
 		// Spawn the actor
@@ -460,10 +500,10 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-4"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Get the state machine to verify state transitions
-		stateMachine, ok := sessionActor.(*utils.StateMachineActor)
+		stateMachine, ok := sessionActor.(*McpSessionActor)
 		require.True(t, ok)
 
 		// Spawn the actor
@@ -518,6 +558,572 @@ func TestMcpSessionStateMachine(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("should audit initialize, requests and termination", func(t *testing.T) {
+		executor := NewTestExecutor()
+		executor.methodHandlers["tools/call"] = executor.methodHandlers["test/method"]
+
+		tools := resources.NewStaticToolRegistry()
+		require.NoError(t, tools.RegisterTool(
+			resources.NewTool("login").WithString("password").Sensitive().Add().WithString("user").Add().Build(),
+			func(ctx context.Context, params map[string]interface{}) (interface{}, error) { return nil, nil },
+		))
+
+		var mu sync.Mutex
+		var records []*audit.Record
+		serverInfo := NewTestServerInfo(executor).(*TestServerInfo)
+		serverInfo.registry = resources.FeatureRegistry{ToolRegistry: tools}
+		serverInfo.auditSink = audit.SinkFunc(func(ctx context.Context, r *audit.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, r)
+			return nil
+		})
+
+		sessionID := "test-session-audit"
+		pid, err := actorSystem.Spawn(ctx, sessionID, NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, "test-conn-audit")
+		require.NoError(t, err)
+
+		_, err = actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+			Request: &mcppb.JsonRpcRequest{
+				Jsonrpc:    "2.0",
+				Id:         &mcppb.JsonRpcRequest_StringId{StringId: "audit-1"},
+				Method:     "tools/call",
+				ParamsJson: `{"name":"login","arguments":{"user":"alice","password":"hunter2"}}`,
+			},
+			IsAsk:        true,
+			TraceContext: map[string]string{tracing.TraceIdKey: "trace-audit"},
+		}, 100*time.Millisecond)
+		require.NoError(t, err)
+
+		_, err = actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+			Request: &mcppb.JsonRpcRequest{
+				Jsonrpc: "2.0",
+				Id:      &mcppb.JsonRpcRequest_StringId{StringId: "audit-2"},
+				Method:  "shutdown",
+			},
+			IsAsk: true,
+		}, 100*time.Millisecond)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, records, 3)
+
+		assert.Equal(t, audit.EventInitialize, records[0].Event)
+		assert.Equal(t, sessionID, records[0].SessionId)
+		assert.Equal(t, audit.OutcomeSuccess, records[0].Outcome)
+
+		call := records[1]
+		assert.Equal(t, audit.EventRequest, call.Event)
+		assert.Equal(t, "tools/call", call.Method)
+		assert.Equal(t, "login", call.Target)
+		assert.Equal(t, "trace-audit", call.TraceId)
+		assert.Equal(t, audit.OutcomeSuccess, call.Outcome)
+		assert.NotContains(t, string(call.Params), "hunter2")
+		assert.Contains(t, string(call.Params), protocol.RedactedPlaceholder)
+		assert.Contains(t, string(call.Params), "alice")
+		assert.JSONEq(t, `{"success": true}`, string(call.Result))
+
+		assert.Equal(t, audit.EventSessionTerminated, records[2].Event)
+		assert.Equal(t, "shutdown", records[2].Reason)
+
+		err = pid.Shutdown(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("should record session start and end", func(t *testing.T) {
+		recorder := &sessionRecorder{}
+		serverInfo := NewTestServerInfo(NewTestExecutor()).(*TestServerInfo)
+		serverInfo.metrics = recorder
+
+		sessionID := "test-session-metrics"
+		pid, err := actorSystem.Spawn(ctx, sessionID, NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, "test-conn-metrics")
+		require.NoError(t, err)
+
+		_, err = actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+			Request: &mcppb.JsonRpcRequest{
+				Jsonrpc: "2.0",
+				Id:      &mcppb.JsonRpcRequest_StringId{StringId: "metrics-1"},
+				Method:  "shutdown",
+			},
+			IsAsk: true,
+		}, 100*time.Millisecond)
+		require.NoError(t, err)
+
+		err = pid.Shutdown(ctx)
+		require.NoError(t, err)
+
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		assert.Equal(t, 1, recorder.started)
+		assert.Equal(t, []string{metrics.SessionEndShutdown}, recorder.ended)
+	})
+
+	t.Run("should continue the caller's trace", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		serverInfo := NewTestServerInfo(NewTestExecutor()).(*TestServerInfo)
+		serverInfo.tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		sessionID := "test-session-tracing"
+		pid, err := actorSystem.Spawn(ctx, sessionID, NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, "test-conn-tracing")
+		require.NoError(t, err)
+
+		traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		_, err = actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+			Request: &mcppb.JsonRpcRequest{
+				Jsonrpc: "2.0",
+				Id:      &mcppb.JsonRpcRequest_StringId{StringId: "tracing-1"},
+				Method:  "unknown/method",
+			},
+			IsAsk:        true,
+			TraceContext: map[string]string{"traceparent": traceparent},
+		}, 100*time.Millisecond)
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 3)
+		assert.Equal(t, "mcp.session initialize", spans[0].Name)
+		assert.Equal(t, "mcp.session notifications/initialized", spans[1].Name)
+
+		span := spans[2]
+		assert.Equal(t, "mcp.session unknown/method", span.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+		assert.Contains(t, span.Attributes, tracing.AttrMethod.String("unknown/method"))
+		assert.Contains(t, span.Attributes, tracing.AttrSessionId.String(sessionID))
+		assert.Equal(t, codes.Error, span.Status.Code, "an error response marks the span as failed")
+
+		err = pid.Shutdown(ctx)
+		require.NoError(t, err)
+	})
+
+	t.Run("should forward log messages to the client once it sets a level", func(t *testing.T) {
+		emptyResult := func(req *mcppb.JsonRpcRequest) *mcppb.JsonRpcResponse {
+			return &mcppb.JsonRpcResponse{
+				Jsonrpc:  "2.0",
+				Id:       &mcppb.JsonRpcResponse_StringId{StringId: req.GetStringId()},
+				Response: &mcppb.JsonRpcResponse_ResultJson{ResultJson: "{}"},
+			}
+		}
+		executor := NewTestExecutor()
+		executor.methodHandlers["logging/setLevel"] = func(ctx context.Context, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+			session := logging.SessionFromContext(ctx)
+			require.NotNil(t, session)
+			return emptyResult(req), session.SetLevel(protocol.LoggingLevelWarning)
+		}
+		executor.methodHandlers["test/log"] = func(ctx context.Context, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+			logging.FromContext(ctx).InfoContext(ctx, "below the client's level")
+			logging.FromContext(ctx).WarnContext(ctx, "tool warning", "step", 2)
+			return emptyResult(req), nil
+		}
+		serverInfo := NewTestServerInfo(executor).(*TestServerInfo)
+		serverInfo.logger = slog.New(slog.DiscardHandler)
+
+		sessionID := "test-session-logging"
+		pid, err := actorSystem.Spawn(ctx, sessionID, NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		connectionId := utils.GetDefaultSSEConnectionName(sessionID)
+		connActor := NewTestConnectionActor(t)
+		connPID, err := actorSystem.Spawn(ctx, connectionId, connActor)
+		require.NoError(t, err)
+		_, err = connPID.Ask(ctx, pid, &mcppb.RegisterConnection{ConnectionId: connectionId}, time.Second)
+		require.NoError(t, err)
+
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, connectionId)
+		require.NoError(t, err)
+
+		call := func(id, method string) {
+			_, err := actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+				Request: &mcppb.JsonRpcRequest{Jsonrpc: "2.0", Id: &mcppb.JsonRpcRequest_StringId{StringId: id}, Method: method},
+				IsAsk:   true,
+			}, time.Second)
+			require.NoError(t, err)
+		}
+
+		// Nothing is forwarded before the client chooses a level
+		call("log-1", "test/log")
+		call("set-level", "logging/setLevel")
+		call("log-2", "test/log")
+		time.Sleep(100 * time.Millisecond)
+
+		var notifications []*mcppb.JsonRpcRequest
+		for _, msg := range connActor.GetReceivedMessages() {
+			if n, ok := msg.(*mcppb.JsonRpcRequest); ok {
+				notifications = append(notifications, n)
+			}
+		}
+		require.Len(t, notifications, 1)
+		assert.Equal(t, "notifications/message", notifications[0].Method)
+		assert.JSONEq(t, `{"level":"warning","data":{"message":"tool warning","step":2}}`, notifications[0].ParamsJson)
+
+		require.NoError(t, pid.Shutdown(ctx))
+		require.NoError(t, connPID.Shutdown(ctx))
+	})
+
+	t.Run("should send the progress of a request to the client that asked for it", func(t *testing.T) {
+		executor := NewTestExecutor()
+		executor.methodHandlers["test/slow"] = func(ctx context.Context, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+			progress.Report(ctx, 1, 2, "halfway")
+			progress.Report(ctx, 2, 2, "done")
+			return &mcppb.JsonRpcResponse{
+				Jsonrpc:  "2.0",
+				Id:       &mcppb.JsonRpcResponse_StringId{StringId: req.GetStringId()},
+				Response: &mcppb.JsonRpcResponse_ResultJson{ResultJson: "{}"},
+			}, nil
+		}
+		serverInfo := NewTestServerInfo(executor).(*TestServerInfo)
+		serverInfo.logger = slog.New(slog.DiscardHandler)
+
+		sessionID := "test-session-progress"
+		pid, err := actorSystem.Spawn(ctx, sessionID, NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		connectionId := utils.GetDefaultSSEConnectionName(sessionID)
+		connActor := NewTestConnectionActor(t)
+		connPID, err := actorSystem.Spawn(ctx, connectionId, connActor)
+		require.NoError(t, err)
+		_, err = connPID.Ask(ctx, pid, &mcppb.RegisterConnection{ConnectionId: connectionId}, time.Second)
+		require.NoError(t, err)
+
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, connectionId)
+		require.NoError(t, err)
+
+		// Only the request with a progress token gets progress
+		for _, params := range []string{`{}`, `{"_meta":{"progressToken":"slow-1"}}`} {
+			_, err = actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+				Request: &mcppb.JsonRpcRequest{Jsonrpc: "2.0", Id: &mcppb.JsonRpcRequest_StringId{StringId: "slow"}, Method: "test/slow", ParamsJson: params},
+				IsAsk:   true,
+			}, time.Second)
+			require.NoError(t, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		var notifications []*mcppb.JsonRpcRequest
+		for _, msg := range connActor.GetReceivedMessages() {
+			if n, ok := msg.(*mcppb.JsonRpcRequest); ok {
+				notifications = append(notifications, n)
+			}
+		}
+		require.Len(t, notifications, 2)
+		assert.Equal(t, "notifications/progress", notifications[0].Method)
+		assert.JSONEq(t, `{"progressToken":"slow-1","progress":1,"total":2,"message":"halfway"}`, notifications[0].ParamsJson)
+		assert.JSONEq(t, `{"progressToken":"slow-1","progress":2,"total":2,"message":"done"}`, notifications[1].ParamsJson)
+
+		require.NoError(t, pid.Shutdown(ctx))
+		require.NoError(t, connPID.Shutdown(ctx))
+	})
+
+	t.Run("should hand off its state when drained and resume from it", func(t *testing.T) {
+		executor := NewTestExecutor()
+		executor.methodHandlers["logging/setLevel"] = func(ctx context.Context, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+			return &mcppb.JsonRpcResponse{
+				Jsonrpc:  "2.0",
+				Id:       &mcppb.JsonRpcResponse_StringId{StringId: req.GetStringId()},
+				Response: &mcppb.JsonRpcResponse_ResultJson{ResultJson: "{}"},
+			}, logging.SessionFromContext(ctx).SetLevel(protocol.LoggingLevelError)
+		}
+		store := sessionstore.NewMemoryStore()
+		recorder := &sessionRecorder{}
+		serverInfo := NewTestServerInfo(executor).(*TestServerInfo)
+		serverInfo.sessionStore = store
+		serverInfo.metrics = recorder
+
+		sessionID := "test-session-drain"
+		pid, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		connectionId := utils.GetDefaultSSEConnectionName(sessionID)
+		connActor := NewTestConnectionActor(t)
+		connPID, err := actorSystem.Spawn(ctx, connectionId, connActor)
+		require.NoError(t, err)
+		_, err = connPID.Ask(ctx, pid, &mcppb.RegisterConnection{ConnectionId: connectionId}, time.Second)
+		require.NoError(t, err)
+
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, connectionId)
+		require.NoError(t, err)
+		_, err = actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+			Request: &mcppb.JsonRpcRequest{Jsonrpc: "2.0", Id: &mcppb.JsonRpcRequest_StringId{StringId: "set-level"}, Method: "logging/setLevel"},
+			IsAsk:   true,
+		}, time.Second)
+		require.NoError(t, err)
+
+		resp, err := actor.Ask(ctx, pid, &mcppb.DrainSession{RetryAfterMs: 500}, time.Second)
+		require.NoError(t, err)
+		assert.True(t, resp.(*mcppb.DrainSessionResponse).GetHandedOff())
+
+		time.Sleep(100 * time.Millisecond)
+		assert.False(t, pid.IsRunning(), "a drained session should stop")
+		assert.Contains(t, connActor.GetReceivedMessages(), &mcppb.CloseConnection{RetryAfterMs: 500})
+
+		// Another node resumes the session, already initialized
+		snapshot, err := store.Take(ctx, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.ProtocolVersion20250326, snapshot.ProtocolVersion)
+		assert.Equal(t, "test-client", snapshot.ClientInfo.Name)
+		assert.True(t, snapshot.ClientNotificationsInitialized)
+		assert.Equal(t, protocol.LoggingLevelError, snapshot.LogLevel)
+
+		pid, err = actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), NewMcpSessionStateMachineFromSnapshot(serverInfo, snapshot))
+		require.NoError(t, err)
+
+		resp, err = actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+			Request: &mcppb.JsonRpcRequest{Jsonrpc: "2.0", Id: &mcppb.JsonRpcRequest_StringId{StringId: "after"}, Method: "test/method"},
+			IsAsk:   true,
+		}, time.Second)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"success": true}`, resp.(*mcppb.JsonRpcResponse).GetResultJson())
+
+		// Draining again hands off the state the session was resumed with
+		resp, err = actor.Ask(ctx, pid, &mcppb.DrainSession{}, time.Second)
+		require.NoError(t, err)
+		assert.True(t, resp.(*mcppb.DrainSessionResponse).GetHandedOff())
+		snapshot, err = store.Take(ctx, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.LoggingLevelError, snapshot.LogLevel)
+		assert.Equal(t, "test-client", snapshot.ClientInfo.Name)
+
+		time.Sleep(100 * time.Millisecond)
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		assert.Equal(t, 2, recorder.started)
+		assert.Equal(t, []string{metrics.SessionEndHandedOff, metrics.SessionEndHandedOff}, recorder.ended)
+		require.NoError(t, connPID.Shutdown(ctx))
+	})
+
+	t.Run("should not hand off uninitialized sessions", func(t *testing.T) {
+		store := sessionstore.NewMemoryStore()
+		serverInfo := NewTestServerInfo(NewTestExecutor()).(*TestServerInfo)
+		serverInfo.sessionStore = store
+
+		sessionID := "test-session-drain-uninitialized"
+		pid, err := actorSystem.Spawn(ctx, sessionID, NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		resp, err := actor.Ask(ctx, pid, &mcppb.DrainSession{}, time.Second)
+		require.NoError(t, err)
+		assert.False(t, resp.(*mcppb.DrainSessionResponse).GetHandedOff())
+
+		_, err = store.Take(ctx, sessionID)
+		assert.ErrorIs(t, err, sessionstore.ErrNotFound)
+	})
+
+	t.Run("should describe itself and end when terminated", func(t *testing.T) {
+		recorder := &sessionRecorder{}
+		serverInfo := NewTestServerInfo(NewTestExecutor()).(*TestServerInfo)
+		serverInfo.metrics = recorder
+
+		sessionID := "test-session-admin"
+		pid, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+
+		resp, err := actor.Ask(ctx, pid, &mcppb.GetSessionInfo{}, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, string(StateUninitialized), resp.(*mcppb.SessionInfo).GetState())
+
+		connectionId := utils.GetDefaultSSEConnectionName(sessionID)
+		connPID, err := actorSystem.Spawn(ctx, connectionId, NewTestConnectionActor(t))
+		require.NoError(t, err)
+		_, err = connPID.Ask(ctx, pid, &mcppb.RegisterConnection{ConnectionId: connectionId}, time.Second)
+		require.NoError(t, err)
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, connectionId)
+		require.NoError(t, err)
+
+		resp, err = actor.Ask(ctx, pid, &mcppb.GetSessionInfo{}, time.Second)
+		require.NoError(t, err)
+		info := resp.(*mcppb.SessionInfo)
+		assert.Equal(t, sessionID, info.GetSessionId())
+		assert.Equal(t, string(StateInitialized), info.GetState())
+		assert.Equal(t, string(protocol.ProtocolVersion20250326), info.GetProtocolVersion())
+		assert.Equal(t, "test-client", info.GetClientName())
+		assert.Equal(t, []string{connectionId}, info.GetConnectionIds())
+		assert.NotZero(t, info.GetLastActivityUnixMs())
+
+		resp, err = actor.Ask(ctx, pid, &mcppb.TerminateSession{Reason: "abuse"}, time.Second)
+		require.NoError(t, err)
+		assert.True(t, resp.(*mcppb.TerminateSessionResponse).GetSuccess())
+
+		time.Sleep(100 * time.Millisecond)
+		assert.False(t, pid.IsRunning(), "a terminated session should stop")
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		assert.Equal(t, []string{metrics.SessionEndTerminated}, recorder.ended)
+		_ = connPID.Shutdown(ctx)
+	})
+
+	t.Run("should discard its saved state when terminated by an operator", func(t *testing.T) {
+		var records []*audit.Record
+		var mu sync.Mutex
+		store := sessionstore.NewMemoryStore()
+		serverInfo := NewTestServerInfo(NewTestExecutor()).(*TestServerInfo)
+		serverInfo.sessionStore = store
+		serverInfo.auditSink = audit.SinkFunc(func(ctx context.Context, r *audit.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, r)
+			return nil
+		})
+
+		sessionID := "test-session-operator-terminated"
+		snapshot := &sessionstore.Snapshot{
+			SessionId:       sessionID,
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: "test-client"},
+			State:           map[string]json.RawMessage{"cart": json.RawMessage(`["apple"]`)},
+		}
+		pid, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), NewMcpSessionStateMachineFromSnapshot(serverInfo, snapshot))
+		require.NoError(t, err)
+		// The resumed session is saved again as it starts, before it handles any message
+		_, err = actor.Ask(ctx, pid, &mcppb.GetSessionInfo{}, time.Second)
+		require.NoError(t, err)
+
+		_, err = actor.Ask(ctx, pid, &mcppb.TerminateSession{Reason: "abuse"}, time.Second)
+		require.NoError(t, err)
+
+		_, err = store.Take(ctx, sessionID)
+		assert.ErrorIs(t, err, sessionstore.ErrNotFound, "a terminated session must not be resumed")
+		mu.Lock()
+		defer mu.Unlock()
+		require.NotEmpty(t, records)
+		last := records[len(records)-1]
+		assert.Equal(t, audit.EventSessionTerminated, last.Event)
+		assert.Equal(t, metrics.SessionEndTerminated+": abuse", last.Reason)
+	})
+
+	t.Run("should resume from the session store when relocated", func(t *testing.T) {
+		store := sessionstore.NewMemoryStore()
+		serverInfo := NewTestServerInfo(NewTestExecutor()).(*TestServerInfo)
+		serverInfo.sessionStore = store
+		defer RegisterRelocationServer(actorSystem, serverInfo)()
+
+		sessionID := "test-session-relocated"
+		require.NoError(t, store.Save(ctx, &sessionstore.Snapshot{
+			SessionId:                      sessionID,
+			ProtocolVersion:                protocol.ProtocolVersion20250326,
+			ClientInfo:                     protocol.ClientInfo{Name: "test-client"},
+			ClientNotificationsInitialized: true,
+		}, time.Minute))
+
+		// The actor system relocates an actor by spawning a zero value of its type under the same name
+		relocated := &McpSessionActor{}
+		pid, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), relocated)
+		require.NoError(t, err)
+
+		resp, err := actor.Ask(ctx, pid, &mcppb.GetSessionInfo{}, time.Second)
+		require.NoError(t, err)
+		info := resp.(*mcppb.SessionInfo)
+		assert.Equal(t, sessionID, info.GetSessionId())
+		assert.Equal(t, string(StateInitialized), info.GetState())
+		assert.Equal(t, "test-client", info.GetClientName())
+		assert.True(t, relocated.GetData().(*SessionData).ClientNotificationsInitialized)
+
+		_, err = store.Take(ctx, sessionID)
+		assert.ErrorIs(t, err, sessionstore.ErrNotFound, "the snapshot should be taken by the relocated actor")
+		require.NoError(t, pid.Shutdown(ctx))
+
+		// A session that was never saved cannot be resumed, so its relocated actor stops
+		pid, err = actorSystem.Spawn(ctx, utils.GetSessionActorName("test-session-unsaved"), &McpSessionActor{})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return !pid.IsRunning() }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should run session hooks when resumed from a snapshot", func(t *testing.T) {
+		var events []string
+		serverInfo := NewTestServerInfo(NewTestExecutor()).(*TestServerInfo)
+		serverInfo.sessionHooks = &config.SessionHooks{
+			OnSessionCreated: func(ctx context.Context) {
+				events = append(events, "created "+utils.GetSessionId(ctx))
+			},
+			OnInitialized: func(ctx context.Context, clientInfo protocol.ClientInfo, capabilities protocol.ClientCapabilities) error {
+				events = append(events, "initialized "+clientInfo.Name)
+				assert.NotNil(t, capabilities.Roots)
+				return nil
+			},
+			OnSessionClosed: func(ctx context.Context, reason string) {
+				events = append(events, "closed "+reason)
+			},
+		}
+
+		sessionID := "test-session-hooks-resumed"
+		pid, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), NewMcpSessionStateMachineFromSnapshot(serverInfo, &sessionstore.Snapshot{
+			SessionId:          sessionID,
+			ProtocolVersion:    protocol.ProtocolVersion20250326,
+			ClientInfo:         protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+			ClientCapabilities: protocol.ClientCapabilities{Roots: &protocol.RootsClientCapability{}},
+		}))
+		require.NoError(t, err)
+
+		_, err = actor.Ask(ctx, pid, &mcppb.TerminateSession{ByClient: true}, time.Second)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return !pid.IsRunning() }, time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, []string{"created " + sessionID, "initialized test-client", "closed " + metrics.SessionEndDeleted}, events)
+	})
+
+	t.Run("should keep state across requests and persist it", func(t *testing.T) {
+		executor := NewTestExecutor()
+		executor.methodHandlers["counter/increment"] = func(ctx context.Context, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+			count, _, err := sessionstate.Get[int](ctx, "count")
+			if err != nil {
+				return nil, err
+			}
+			if err := sessionstate.Set(ctx, "count", count+1); err != nil {
+				return nil, err
+			}
+			return &mcppb.JsonRpcResponse{
+				Jsonrpc:  "2.0",
+				Id:       &mcppb.JsonRpcResponse_StringId{StringId: req.GetStringId()},
+				Response: &mcppb.JsonRpcResponse_ResultJson{ResultJson: fmt.Sprintf(`{"count": %d}`, count+1)},
+			}, nil
+		}
+		store := sessionstore.NewMemoryStore()
+		serverInfo := NewTestServerInfo(executor).(*TestServerInfo)
+		serverInfo.sessionStore = store
+
+		increment := func(pid *actor.PID) string {
+			resp, err := actor.Ask(ctx, pid, &mcppb.WrappedRequest{
+				Request: &mcppb.JsonRpcRequest{Jsonrpc: "2.0", Id: &mcppb.JsonRpcRequest_StringId{StringId: "inc"}, Method: "counter/increment"},
+				IsAsk:   true,
+			}, time.Second)
+			require.NoError(t, err)
+			return resp.(*mcppb.JsonRpcResponse).GetResultJson()
+		}
+
+		sessionID := "test-session-state"
+		pid, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), NewMcpSessionStateMachine(serverInfo, sessionID, nil))
+		require.NoError(t, err)
+		_, err = initializeSession(ctx, t, pid, protocol.ProtocolVersion20250326, "")
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"count": 1}`, increment(pid))
+		assert.JSONEq(t, `{"count": 2}`, increment(pid))
+
+		// The state is saved as it changes, so another node resumes the session with it if this one is lost
+		snapshot, err := store.Take(ctx, sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]json.RawMessage{"count": json.RawMessage("2")}, snapshot.State)
+		require.NoError(t, pid.Shutdown(ctx))
+
+		pid, err = actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionID), NewMcpSessionStateMachineFromSnapshot(serverInfo, snapshot))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"count": 3}`, increment(pid))
+
+		// A session that ends for good is not resumed
+		_, err = actor.Ask(ctx, pid, &mcppb.TerminateSession{ByClient: true}, time.Second)
+		require.NoError(t, err)
+		_, err = store.Take(ctx, sessionID)
+		assert.ErrorIs(t, err, sessionstore.ErrNotFound)
+	})
+
 	t.Run("should handle TryCleanupPreInitialized message for uninitialized session", func(t *testing.T) {
 		// Create server info with test executor
 		executor := NewTestExecutor()
@@ -525,7 +1131,7 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-7"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Spawn the actor
 		pid, err := actorSystem.Spawn(ctx, "test-session-7", sessionActor)
@@ -574,7 +1180,7 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-8"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Spawn the actor
 		pid, err := actorSystem.Spawn(ctx, "test-session-8", sessionActor)
@@ -621,7 +1227,7 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-9"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Spawn the actor
 		pid, err := actorSystem.Spawn(ctx, "test-session-9", sessionActor)
@@ -657,10 +1263,10 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-10"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Get the state machine to verify state transitions
-		stateMachine, ok := sessionActor.(*utils.StateMachineActor)
+		stateMachine, ok := sessionActor.(*McpSessionActor)
 		require.True(t, ok)
 
 		// Spawn the actor
@@ -771,10 +1377,10 @@ func TestMcpSessionStateMachine(t *testing.T) {
 
 		// Create session actor
 		sessionID := "test-session-ttl"
-		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID)
+		sessionActor := NewMcpSessionStateMachine(serverInfo, sessionID, nil)
 
 		// Get the state machine to verify state transitions
-		stateMachine, ok := sessionActor.(*utils.StateMachineActor)
+		stateMachine, ok := sessionActor.(*McpSessionActor)
 		require.True(t, ok)
 
 		// Spawn the actor
@@ -820,3 +1426,23 @@ func TestMcpSessionStateMachine(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+// sessionRecorder counts session starts and ends
+type sessionRecorder struct {
+	metrics.NopRecorder
+	mu      sync.Mutex
+	started int
+	ended   []string
+}
+
+func (r *sessionRecorder) SessionStarted() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started++
+}
+
+func (r *sessionRecorder) SessionEnded(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ended = append(r.ended, reason)
+}
@@ -0,0 +1,135 @@
+package actors
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/audit"
+	"github.com/traego/scaled-mcp/pkg/auth"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+)
+
+// newAuditRecord creates a record pre-filled with the session and caller
+func newAuditRecord(ctx context.Context, sessionData *SessionData, event string, msg *mcppb.WrappedRequest) *audit.Record {
+	record := &audit.Record{
+		Event:       event,
+		SessionId:   sessionData.SessionID,
+		PrincipalId: sessionData.PrincipalId,
+		Outcome:     audit.OutcomeSuccess,
+	}
+
+	if ai := auth.GetAuthInfo(ctx); ai != nil {
+		record.PrincipalId = ai.GetPrincipalId()
+	}
+
+	record.TraceId = tracing.TraceId(ctx)
+	if msg != nil {
+		if msg.Request != nil {
+			record.Method = msg.Request.Method
+		}
+	}
+
+	return record
+}
+
+// auditRequest records a request handled in a session along with the response sent for it
+func auditRequest(ctx context.Context, sessionData *SessionData, event string, msg *mcppb.WrappedRequest, response *mcppb.JsonRpcResponse, start time.Time) {
+	sink := sessionData.ServerInfo.GetAuditSink()
+	if sink == nil {
+		return
+	}
+
+	record := newAuditRecord(ctx, sessionData, event, msg)
+	record.SetDuration(start)
+
+	var params map[string]interface{}
+	if msg.Request.ParamsJson != "" {
+		_ = json.Unmarshal([]byte(msg.Request.ParamsJson), &params)
+	}
+	record.Target = auditTarget(msg.Request.Method, params)
+	record.SetParams(redactParams(ctx, sessionData, msg.Request.Method, params))
+
+	if response != nil {
+		if rpcErr := response.GetError(); rpcErr != nil {
+			record.Outcome = audit.OutcomeError
+			if rpcErr.Code == protocol.ErrRateLimited {
+				record.Outcome = audit.OutcomeDenied
+			}
+			record.ErrorCode = int(rpcErr.Code)
+			record.Error = rpcErr.Message
+		} else {
+			result := response.GetResultJson()
+			record.SetResult(result)
+			if protocol.IsToolErrorResult(result) {
+				record.Outcome = audit.OutcomeError
+			}
+		}
+	}
+
+	audit.Emit(ctx, sink, record)
+}
+
+// auditSessionTerminated records the end of a session
+func auditSessionTerminated(ctx context.Context, sessionData *SessionData, reason string) {
+	sink := sessionData.ServerInfo.GetAuditSink()
+	if sink == nil {
+		return
+	}
+
+	record := newAuditRecord(ctx, sessionData, audit.EventSessionTerminated, nil)
+	record.Reason = reason
+	audit.Emit(ctx, sink, record)
+}
+
+// auditTarget returns the name of the tool or prompt, or the uri of the resource, a request acts on
+func auditTarget(method string, params map[string]interface{}) string {
+	var key string
+	switch method {
+	case "tools/call", "tools/get", "prompts/get":
+		key = "name"
+	case "resources/read", "resources/subscribe", "resources/unsubscribe":
+		key = "uri"
+	default:
+		return ""
+	}
+
+	target, _ := params[key].(string)
+	return target
+}
+
+// redactParams applies the tool's redaction rules to tools/call arguments
+func redactParams(ctx context.Context, sessionData *SessionData, method string, params map[string]interface{}) map[string]interface{} {
+	if method != "tools/call" || params == nil {
+		return params
+	}
+
+	args, ok := params["arguments"].(map[string]interface{})
+	if !ok {
+		return params
+	}
+
+	registry := sessionData.ServerInfo.GetFeatureRegistry().ToolRegistry
+	name, _ := params["name"].(string)
+	if registry == nil || name == "" {
+		return params
+	}
+
+	tool, err := registry.GetTool(ctx, name)
+	if err != nil {
+		return params
+	}
+
+	redacted := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		redacted[k] = v
+	}
+	var hashKey []byte
+	if cfg := sessionData.ServerInfo.GetServerConfig(); cfg != nil {
+		hashKey = []byte(cfg.Audit.HashSecret)
+	}
+	redacted["arguments"] = tool.InputSchema.RedactArguments(args, hashKey)
+	return redacted
+}
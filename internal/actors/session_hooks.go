@@ -0,0 +1,58 @@
+package actors
+
+import (
+	"context"
+
+	"github.com/traego/scaled-mcp/pkg/auth"
+	"github.com/traego/scaled-mcp/pkg/logging"
+	"github.com/traego/scaled-mcp/pkg/sessionstate"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// sessionHookContext returns the context session hooks run with outside of a request, carrying the session id
+// and the client's auth info
+func sessionHookContext(ctx context.Context, sessionData *SessionData) context.Context {
+	ctx = context.WithValue(ctx, utils.SessionIdCtx, sessionData.SessionID)
+	if sessionData.Logging != nil {
+		ctx = logging.WithSession(ctx, sessionData.Logging)
+	}
+	ctx = sessionstate.WithState(ctx, sessionData.State)
+
+	authHandler := sessionData.ServerInfo.GetAuthHandler()
+	if len(sessionData.authInfo) == 0 || authHandler == nil {
+		return ctx
+	}
+	authInfo, err := authHandler.Deserialize(sessionData.authInfo)
+	if err != nil {
+		sessionData.logger().WarnContext(ctx, "failed to deserialize auth info for session hook", "session_id", sessionData.SessionID, "err", err)
+		return ctx
+	}
+	return auth.SetAuthInfo(ctx, authInfo)
+}
+
+// runSessionCreatedHook runs the application's OnSessionCreated hook, if any
+func runSessionCreatedHook(ctx context.Context, sessionData *SessionData) {
+	hooks := sessionData.ServerInfo.GetSessionHooks()
+	if hooks == nil || hooks.OnSessionCreated == nil {
+		return
+	}
+	hooks.OnSessionCreated(ctx)
+}
+
+// runInitializedHook runs the application's OnInitialized hook, if any, returning its rejection
+func runInitializedHook(ctx context.Context, sessionData *SessionData) error {
+	hooks := sessionData.ServerInfo.GetSessionHooks()
+	if hooks == nil || hooks.OnInitialized == nil {
+		return nil
+	}
+	return hooks.OnInitialized(ctx, sessionData.ClientInfo, sessionData.ClientCapabilities)
+}
+
+// runSessionClosedHook runs the application's OnSessionClosed hook, if any
+func runSessionClosedHook(ctx context.Context, sessionData *SessionData, reason string) {
+	hooks := sessionData.ServerInfo.GetSessionHooks()
+	if hooks == nil || hooks.OnSessionClosed == nil {
+		return
+	}
+	hooks.OnSessionClosed(ctx, reason)
+}
@@ -1,7 +1,11 @@
 package channels
 
+import "time"
+
 type OneWayChannel interface {
 	Send(eventType string, data interface{}) error
 	SendEndpoint(endpoint string) error
+	// SendRetry tells the client how long to wait before reconnecting once the connection is closed
+	SendRetry(retry time.Duration) error
 	Close()
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // SSEChannel represents an SSE channel for sending events to clients
@@ -90,6 +91,20 @@ func (c *SSEChannel) SendEndpoint(endpoint string) error {
 	return c.Send("endpoint", endpoint)
 }
 
+// SendRetry sets the client's reconnection time with an SSE retry field
+func (c *SSEChannel) SendRetry(retry time.Duration) error {
+	if _, err := fmt.Fprintf(c.w, "retry: %d\n\n", retry.Milliseconds()); err != nil {
+		return fmt.Errorf("error writing retry: %w", err)
+	}
+
+	if flusher, ok := c.w.(http.Flusher); ok {
+		flusher.Flush()
+		return nil
+	}
+
+	return fmt.Errorf("response writer does not support flushing")
+}
+
 func (c *SSEChannel) Close() {
 	close(c.Done)
 }
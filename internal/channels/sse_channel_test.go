@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -154,6 +155,18 @@ func TestSSEChannel_SendEndpoint(t *testing.T) {
 	assert.Contains(t, body, "\n\n") // Make sure there's a blank line at the end
 }
 
+// TestSSEChannel_SendRetry tests sending a reconnection time
+func TestSSEChannel_SendRetry(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest("GET", "/events", nil)
+	require.NoError(t, err)
+
+	channel := NewSSEChannel(w, r, "test-session")
+	require.NoError(t, channel.SendRetry(1500*time.Millisecond))
+
+	assert.Equal(t, "retry: 1500\n\n", w.Body.String())
+}
+
 // TestSSEChannel_Send_MarshalError tests handling a marshal error
 func TestSSEChannel_Send_MarshalError(t *testing.T) {
 	// Create a test HTTP response recorder and request
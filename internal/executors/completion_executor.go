@@ -0,0 +1,114 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/uritemplate"
+)
+
+// CompletionExecutor handles argument completion for prompts and resource templates
+type CompletionExecutor struct {
+	serverInfo config.McpServerInfo
+}
+
+// NewCompletionExecutor creates a new completion executor
+func NewCompletionExecutor(serverInfo config.McpServerInfo) *CompletionExecutor {
+	return &CompletionExecutor{serverInfo: serverInfo}
+}
+
+// CanHandleMethod checks if the method is related to completion
+func (c *CompletionExecutor) CanHandleMethod(method string) bool {
+	return method == "completion/complete"
+}
+
+// HandleMethod handles a completion/complete request
+func (c *CompletionExecutor) HandleMethod(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+	registry := c.serverInfo.GetFeatureRegistry()
+	if err := CheckFeature(registry.PromptRegistry != nil || registry.ResourceRegistry != nil, method, req.Id); err != nil {
+		return nil, err
+	}
+	response := PrepareResponse(req)
+
+	var params protocol.CompleteParams
+	if err := json.Unmarshal([]byte(req.ParamsJson), &params); err != nil {
+		return nil, protocol.NewInvalidParamsError("Invalid parameters: "+err.Error(), req.Id)
+	}
+
+	provider, err := c.findProvider(ctx, params, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := protocol.NewCompleteResult(nil, false)
+	if provider != nil {
+		var arguments map[string]string
+		if params.Context != nil {
+			arguments = params.Context.Arguments
+		}
+		values, hasMore, err := provider(ctx, params.Argument.Value, arguments)
+		if err != nil {
+			return nil, fmt.Errorf("error handling %s: %w", method, err)
+		}
+		result = protocol.NewCompleteResult(values, hasMore)
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	response.Response = &mcppb.JsonRpcResponse_ResultJson{
+		ResultJson: string(resultJSON),
+	}
+
+	return response, nil
+}
+
+// findProvider returns the completion provider of the referenced prompt argument or template variable, or nil
+// if it has none
+func (c *CompletionExecutor) findProvider(ctx context.Context, params protocol.CompleteParams, reqID interface{}) (resources.CompletionProvider, error) {
+	registry := c.serverInfo.GetFeatureRegistry()
+
+	switch params.Ref.Type {
+	case protocol.CompletionRefPrompt:
+		if registry.PromptRegistry == nil {
+			return nil, protocol.NewInvalidParamsError("prompts are not supported", reqID)
+		}
+		prompt, ok := registry.PromptRegistry.GetPrompt(ctx, params.Ref.Name)
+		if !ok {
+			return nil, protocol.NewInvalidParamsError(fmt.Sprintf("unknown prompt %q", params.Ref.Name), reqID)
+		}
+		for _, arg := range prompt.Arguments {
+			if arg.Name == params.Argument.Name {
+				return arg.Complete, nil
+			}
+		}
+		return nil, nil
+
+	case protocol.CompletionRefResource:
+		if registry.ResourceRegistry == nil {
+			return nil, protocol.NewInvalidParamsError("resources are not supported", reqID)
+		}
+		template, ok := resources.FindResourceTemplate(ctx, registry.ResourceRegistry, params.Ref.URI)
+		if !ok {
+			return nil, protocol.NewInvalidParamsError(fmt.Sprintf("unknown resource template %q", params.Ref.URI), reqID)
+		}
+		parsed, err := uritemplate.Parse(template.URITemplate)
+		if err != nil || !slices.Contains(parsed.Names(), params.Argument.Name) {
+			return nil, protocol.NewInvalidParamsError(fmt.Sprintf("resource template %q has no variable %q", params.Ref.URI, params.Argument.Name), reqID)
+		}
+		return template.Completions[params.Argument.Name], nil
+
+	default:
+		return nil, protocol.NewInvalidParamsError(fmt.Sprintf("unknown reference type %q", params.Ref.Type), reqID)
+	}
+}
+
+var _ config.MethodHandler = (*CompletionExecutor)(nil)
@@ -0,0 +1,137 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+// completeFrom suggests the candidates starting with the value typed so far
+func completeFrom(candidates ...string) resources.CompletionProvider {
+	return func(ctx context.Context, value string, arguments map[string]string) ([]string, bool, error) {
+		var values []string
+		for _, candidate := range candidates {
+			if strings.HasPrefix(candidate, value) {
+				values = append(values, candidate)
+			}
+		}
+		return values, false, nil
+	}
+}
+
+func newCompletionTestServerInfo(t *testing.T) *TestServerInfo {
+	prompts := resources.NewStaticPromptRegistry()
+	require.NoError(t, prompts.RegisterPrompt(resources.NewPrompt("review").
+		WithArgument("language").Complete(completeFrom("go", "golang", "python")).Add().
+		WithArgument("framework").Complete(func(ctx context.Context, value string, arguments map[string]string) ([]string, bool, error) {
+		if arguments["language"] == "go" {
+			return []string{"chi", "gin"}, false, nil
+		}
+		return nil, false, nil
+	}).Add().
+		WithArgument("notes").Add().
+		Build()))
+
+	templates := resources.NewStaticResourceRegistry()
+	require.NoError(t, templates.RegisterResourceTemplate(resources.NewResourceTemplate("repo://{owner}/{repo}", "Repository").
+		WithCompletion("owner", func(ctx context.Context, value string, arguments map[string]string) ([]string, bool, error) {
+			var values []string
+			for i := 0; i < 150; i++ {
+				values = append(values, fmt.Sprintf("%s%03d", value, i))
+			}
+			return values, false, nil
+		}).
+		Build()))
+
+	serverInfo := NewTestServerInfo()
+	serverInfo.FeatureRegistry = resources.FeatureRegistry{PromptRegistry: prompts, ResourceRegistry: templates}
+	return serverInfo
+}
+
+func complete(t *testing.T, executor *CompletionExecutor, params string) (protocol.Completion, error) {
+	t.Helper()
+	resp, err := executor.HandleMethod(context.Background(), "completion/complete", &mcppb.JsonRpcRequest{
+		Jsonrpc:    "2.0",
+		Id:         &mcppb.JsonRpcRequest_StringId{StringId: "1"},
+		Method:     "completion/complete",
+		ParamsJson: params,
+	})
+	if err != nil {
+		return protocol.Completion{}, err
+	}
+	var result protocol.CompleteResult
+	require.NoError(t, json.Unmarshal([]byte(resp.GetResultJson()), &result))
+	return result.Completion, nil
+}
+
+func TestCompletionExecutor_HandleMethod_Prompt(t *testing.T) {
+	executor := NewCompletionExecutor(newCompletionTestServerInfo(t))
+
+	completion, err := complete(t, executor, `{"ref":{"type":"ref/prompt","name":"review"},"argument":{"name":"language","value":"go"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "golang"}, completion.Values)
+	assert.False(t, completion.HasMore)
+
+	completion, err = complete(t, executor, `{"ref":{"type":"ref/prompt","name":"review"},"argument":{"name":"framework","value":""},"context":{"arguments":{"language":"go"}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chi", "gin"}, completion.Values, "providers see the other arguments")
+
+	completion, err = complete(t, executor, `{"ref":{"type":"ref/prompt","name":"review"},"argument":{"name":"notes","value":"x"}}`)
+	require.NoError(t, err)
+	assert.Empty(t, completion.Values, "arguments without a provider complete to nothing")
+
+	_, err = complete(t, executor, `{"ref":{"type":"ref/prompt","name":"missing"},"argument":{"name":"language","value":"go"}}`)
+	var rpcErr *protocol.JsonRpcError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+}
+
+func TestCompletionExecutor_HandleMethod_ResourceTemplate(t *testing.T) {
+	executor := NewCompletionExecutor(newCompletionTestServerInfo(t))
+
+	completion, err := complete(t, executor, `{"ref":{"type":"ref/resource","uri":"repo://{owner}/{repo}"},"argument":{"name":"owner","value":"u"}}`)
+	require.NoError(t, err)
+	assert.Len(t, completion.Values, protocol.MaxCompletionValues)
+	assert.Equal(t, "u000", completion.Values[0])
+	assert.Equal(t, 150, completion.Total)
+	assert.True(t, completion.HasMore, "values beyond the limit are left out")
+
+	completion, err = complete(t, executor, `{"ref":{"type":"ref/resource","uri":"repo://{owner}/{repo}"},"argument":{"name":"repo","value":""}}`)
+	require.NoError(t, err)
+	assert.Empty(t, completion.Values)
+
+	var rpcErr *protocol.JsonRpcError
+	_, err = complete(t, executor, `{"ref":{"type":"ref/resource","uri":"repo://{owner}/{repo}"},"argument":{"name":"branch","value":""}}`)
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code, "only the template's variables can be completed")
+
+	_, err = complete(t, executor, `{"ref":{"type":"ref/resource","uri":"file://{path}"},"argument":{"name":"path","value":""}}`)
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+
+	_, err = complete(t, executor, `{"ref":{"type":"ref/tool","name":"x"},"argument":{"name":"y","value":""}}`)
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+}
+
+func TestExecutors_HandleMethod_Completion(t *testing.T) {
+	e := DefaultExecutors(newCompletionTestServerInfo(t), nil)
+	require.True(t, e.CanHandleMethod("completion/complete"))
+
+	resp, err := e.HandleMethod(context.Background(), "completion/complete", &mcppb.JsonRpcRequest{
+		Jsonrpc:    "2.0",
+		Id:         &mcppb.JsonRpcRequest_StringId{StringId: "1"},
+		Method:     "completion/complete",
+		ParamsJson: `{"ref":{"type":"ref/prompt","name":"review"},"argument":{"name":"language","value":"py"}}`,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"completion":{"values":["python"]}}`, resp.GetResultJson())
+}
@@ -2,20 +2,39 @@ package executors
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
 	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/metrics"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
-	"strings"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// TODO This actually wants to be pluggable, this is where we'd plug in new fancy stuff
-
+// Executors route the requests of a session to the handler for their method, through the interceptors
 type Executors struct {
 	Tools        config.MethodHandler
 	Prompts      config.MethodHandler
 	Resources    config.MethodHandler
 	Utilities    config.MethodHandler
+	Completions  config.MethodHandler
 	Experimental config.MethodHandler
+
+	// Methods handles the custom methods of the method registry, after the methods of the spec
+	Methods config.MethodHandler
+
+	// Metrics records every handled request, nil disables recording
+	Metrics metrics.Recorder
+
+	// Tracer creates a span for every handled request, nil disables spans
+	Tracer trace.Tracer
+
+	// Interceptors wrap the handling of every request, the first one outermost. They run inside the request's
+	// span and are included in its metrics.
+	Interceptors []config.MethodInterceptor
 }
 
 func DefaultExecutors(serverInfo config.McpServerInfo, experimental config.MethodHandler) *Executors {
@@ -24,7 +43,11 @@ func DefaultExecutors(serverInfo config.McpServerInfo, experimental config.Metho
 		Prompts:      NewPromptExecutor(serverInfo),
 		Resources:    NewResourceExecutor(serverInfo),
 		Utilities:    NewUtilitiesExecutor(serverInfo),
+		Completions:  NewCompletionExecutor(serverInfo),
 		Experimental: experimental,
+		Methods:      NewMethodExecutor(serverInfo),
+		Metrics:      serverInfo.GetMetrics(),
+		Tracer:       tracing.Tracer(serverInfo.GetTracerProvider()),
 	}
 }
 
@@ -37,28 +60,101 @@ func (e *Executors) CanHandleMethod(method string) bool {
 		return true
 	} else if e.Utilities != nil && e.Utilities.CanHandleMethod(method) {
 		return true
+	} else if e.Completions != nil && e.Completions.CanHandleMethod(method) {
+		return true
 	} else if e.Experimental != nil && e.Experimental.CanHandleMethod(method) {
 		return true
+	} else if e.Methods != nil && e.Methods.CanHandleMethod(method) {
+		return true
 	}
 	return false
 }
 
 func (e *Executors) HandleMethod(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+	if e.Tracer != nil {
+		var span trace.Span
+		ctx, span = e.Tracer.Start(ctx, "mcp.execute "+method, trace.WithAttributes(tracing.AttrMethod.String(method)))
+		defer span.End()
+	}
+
+	start := time.Now()
+	resp, err := e.intercept(ctx, method, req)
+	duration := time.Since(start)
+
+	if e.Tracer != nil {
+		span := trace.SpanFromContext(ctx)
+		if err != nil {
+			tracing.SetError(span, err)
+		} else {
+			tracing.SetResponseStatus(span, resp)
+		}
+	}
+
+	if e.Metrics != nil {
+		e.recordMetrics(method, req, resp, err, duration)
+	}
+
+	return resp, err
+}
+
+// recordMetrics records a handled request, and the tool call it made if any
+func (e *Executors) recordMetrics(method string, req *mcppb.JsonRpcRequest, resp *mcppb.JsonRpcResponse, err error, duration time.Duration) {
+	outcome := metrics.OutcomeSuccess
+	if err != nil || resp.GetError() != nil {
+		outcome = metrics.OutcomeError
+	}
+	e.Metrics.RequestHandled(method, outcome, duration)
+
+	if method == "tools/call" {
+		if err == nil && protocol.IsToolErrorResult(resp.GetResultJson()) {
+			outcome = metrics.OutcomeError
+		}
+		e.Metrics.ToolCalled(toolName(req.ParamsJson), outcome, duration)
+	}
+}
+
+// toolName returns the name of the tool a tools/call request invokes
+func toolName(paramsJson string) string {
+	var params struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal([]byte(paramsJson), &params)
+	return params.Name
+}
+
+// intercept handles a request through the interceptors, which dispatch it last
+func (e *Executors) intercept(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+	next := e.dispatch
+	for i := len(e.Interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := e.Interceptors[i], next
+		next = func(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+			return interceptor(ctx, method, req, inner)
+		}
+	}
+	return next(ctx, method, req)
+}
+
+// dispatch routes a request to the executor for its method
+func (e *Executors) dispatch(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
 	ms := strings.Split(method, "/")
 	if len(ms) >= 2 {
 		switch ms[0] {
 		case "tools":
-			if e.Tools.CanHandleMethod(method) {
+			if e.Tools != nil && e.Tools.CanHandleMethod(method) {
 				return e.Tools.HandleMethod(ctx, method, req)
 			}
 		case "resources":
-			if e.Resources.CanHandleMethod(method) {
+			if e.Resources != nil && e.Resources.CanHandleMethod(method) {
 				return e.Resources.HandleMethod(ctx, method, req)
 			}
 		case "prompts":
-			if e.Prompts.CanHandleMethod(method) {
+			if e.Prompts != nil && e.Prompts.CanHandleMethod(method) {
 				return e.Prompts.HandleMethod(ctx, method, req)
 			}
+		case "completion":
+			if e.Completions != nil && e.Completions.CanHandleMethod(method) {
+				return e.Completions.HandleMethod(ctx, method, req)
+			}
 		}
 	}
 
@@ -67,9 +163,13 @@ func (e *Executors) HandleMethod(ctx context.Context, method string, req *mcppb.
 		return e.Utilities.HandleMethod(ctx, method, req)
 	}
 
-	if e.Experimental.CanHandleMethod(method) {
+	if e.Experimental != nil && e.Experimental.CanHandleMethod(method) {
 		return e.Experimental.HandleMethod(ctx, method, req)
 	}
 
+	if e.Methods != nil && e.Methods.CanHandleMethod(method) {
+		return e.Methods.HandleMethod(ctx, method, req)
+	}
+
 	return nil, protocol.NewMethodNotFoundError(method, req.Id)
 }
@@ -0,0 +1,178 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/metrics"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// measurement is a request or tool call seen by a testRecorder
+type measurement struct {
+	name    string
+	outcome string
+}
+
+// testRecorder keeps the requests and tool calls it is told about
+type testRecorder struct {
+	metrics.NopRecorder
+	mu       sync.Mutex
+	requests []measurement
+	tools    []measurement
+}
+
+func (r *testRecorder) RequestHandled(method, outcome string, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, measurement{method, outcome})
+}
+
+func (r *testRecorder) ToolCalled(tool, outcome string, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools = append(r.tools, measurement{tool, outcome})
+}
+
+func TestExecutors_HandleMethod_Metrics(t *testing.T) {
+	registry := &failingToolRegistry{
+		TestToolRegistry: NewTestToolRegistry(),
+		err:              errors.New("boom"),
+	}
+	registry.Tools["broken"] = protocol.Tool{Name: "broken", InputSchema: protocol.InputSchema{Type: "object"}}
+
+	serverInfo := NewTestServerInfo()
+	serverInfo.FeatureRegistry = resources.FeatureRegistry{ToolRegistry: registry}
+
+	recorder := &testRecorder{}
+	e := DefaultExecutors(serverInfo, nil)
+	e.Metrics = recorder
+
+	ctx := context.Background()
+	_, err := e.HandleMethod(ctx, "tools/list", &mcppb.JsonRpcRequest{
+		Jsonrpc: "2.0",
+		Id:      &mcppb.JsonRpcRequest_StringId{StringId: "1"},
+		Method:  "tools/list",
+	})
+	require.NoError(t, err)
+
+	_, err = e.HandleMethod(ctx, "tools/call", &mcppb.JsonRpcRequest{
+		Jsonrpc:    "2.0",
+		Id:         &mcppb.JsonRpcRequest_StringId{StringId: "2"},
+		Method:     "tools/call",
+		ParamsJson: `{"name":"broken","arguments":{}}`,
+	})
+	require.NoError(t, err)
+
+	_, err = e.HandleMethod(ctx, "unknown/method", &mcppb.JsonRpcRequest{
+		Jsonrpc: "2.0",
+		Id:      &mcppb.JsonRpcRequest_StringId{StringId: "3"},
+		Method:  "unknown/method",
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, []measurement{
+		{"tools/list", metrics.OutcomeSuccess},
+		{"tools/call", metrics.OutcomeSuccess},
+		{"unknown/method", metrics.OutcomeError},
+	}, recorder.requests)
+	assert.Equal(t, []measurement{{"broken", metrics.OutcomeError}}, recorder.tools, "a tool error result counts as a failed call")
+}
+
+func TestExecutors_HandleMethod_Spans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	serverInfo := NewTestServerInfo()
+	serverInfo.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	registry := &failingToolRegistry{
+		TestToolRegistry: NewTestToolRegistry(),
+		err:              errors.New("dial postgres://app:pa55word@db/app failed"),
+	}
+	registry.Tools["broken"] = protocol.Tool{Name: "broken", InputSchema: protocol.InputSchema{Type: "object"}}
+	serverInfo.FeatureRegistry = resources.FeatureRegistry{ToolRegistry: registry}
+
+	e := DefaultExecutors(serverInfo, nil)
+	_, err := e.HandleMethod(context.Background(), "tools/call", &mcppb.JsonRpcRequest{
+		Jsonrpc:    "2.0",
+		Id:         &mcppb.JsonRpcRequest_StringId{StringId: "1"},
+		Method:     "tools/call",
+		ParamsJson: `{"name":"broken","arguments":{}}`,
+	})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	tool, execute := spans[0], spans[1]
+	assert.Equal(t, "mcp.tool broken", tool.Name)
+	assert.Equal(t, "mcp.execute tools/call", execute.Name)
+	assert.Equal(t, execute.SpanContext.SpanID(), tool.Parent.SpanID(), "the tool span is a child of the executor span")
+
+	assert.Contains(t, tool.Attributes, tracing.AttrToolName.String("broken"))
+	assert.Equal(t, codes.Error, tool.Status.Code)
+	assert.NotContains(t, tool.Status.Description, "pa55word")
+
+	assert.Contains(t, execute.Attributes, tracing.AttrMethod.String("tools/call"))
+}
+
+func TestExecutors_HandleMethod_Interceptors(t *testing.T) {
+	recorder := &testRecorder{}
+	e := DefaultExecutors(NewTestServerInfo(), nil)
+	e.Metrics = recorder
+
+	var order []string
+	e.Interceptors = []config.MethodInterceptor{
+		func(ctx context.Context, method string, req *mcppb.JsonRpcRequest, next config.MethodInvoker) (*mcppb.JsonRpcResponse, error) {
+			order = append(order, "outer "+method)
+			resp, err := next(ctx, method, req)
+			order = append(order, "outer done")
+			return resp, err
+		},
+		func(ctx context.Context, method string, req *mcppb.JsonRpcRequest, next config.MethodInvoker) (*mcppb.JsonRpcResponse, error) {
+			order = append(order, "inner "+method)
+			if method == "tools/call" {
+				return nil, protocol.NewError(-32001, "tool calls are disabled", nil, nil)
+			}
+			return next(ctx, method, req)
+		},
+	}
+
+	ctx := context.Background()
+	resp, err := e.HandleMethod(ctx, "tools/list", &mcppb.JsonRpcRequest{
+		Jsonrpc: "2.0",
+		Id:      &mcppb.JsonRpcRequest_StringId{StringId: "1"},
+		Method:  "tools/list",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetResultJson())
+
+	_, err = e.HandleMethod(ctx, "tools/call", &mcppb.JsonRpcRequest{
+		Jsonrpc:    "2.0",
+		Id:         &mcppb.JsonRpcRequest_StringId{StringId: "2"},
+		Method:     "tools/call",
+		ParamsJson: `{"name":"anything","arguments":{}}`,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tool calls are disabled")
+
+	assert.Equal(t, []string{
+		"outer tools/list", "inner tools/list", "outer done",
+		"outer tools/call", "inner tools/call", "outer done",
+	}, order)
+	assert.Equal(t, []measurement{
+		{"tools/list", metrics.OutcomeSuccess},
+		{"tools/call", metrics.OutcomeError},
+	}, recorder.requests, "intercepted requests are measured")
+}
@@ -0,0 +1,73 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// MethodExecutor handles the custom methods of the server's method registry
+type MethodExecutor struct {
+	serverInfo config.McpServerInfo
+}
+
+// NewMethodExecutor creates a new custom method executor
+func NewMethodExecutor(serverInfo config.McpServerInfo) *MethodExecutor {
+	return &MethodExecutor{serverInfo: serverInfo}
+}
+
+// CanHandleMethod checks if a handler is registered for the method
+func (m *MethodExecutor) CanHandleMethod(method string) bool {
+	registry := m.serverInfo.GetFeatureRegistry().MethodRegistry
+	if registry == nil {
+		return false
+	}
+	_, ok := registry.GetMethod(method)
+	return ok
+}
+
+// HandleMethod calls the handler registered for the method
+func (m *MethodExecutor) HandleMethod(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
+	registry := m.serverInfo.GetFeatureRegistry().MethodRegistry
+	if registry == nil {
+		return nil, protocol.NewMethodNotFoundError(method, req.Id)
+	}
+	handler, ok := registry.GetMethod(method)
+	if !ok {
+		return nil, protocol.NewMethodNotFoundError(method, req.Id)
+	}
+
+	var params json.RawMessage
+	if req.ParamsJson != "" {
+		if !json.Valid([]byte(req.ParamsJson)) {
+			return nil, protocol.NewInvalidParamsError("Invalid parameters", req.Id)
+		}
+		params = json.RawMessage(req.ParamsJson)
+	}
+
+	result, err := handler(ctx, method, params)
+	if err != nil {
+		return nil, fmt.Errorf("error handling %s: %w", method, err)
+	}
+
+	// A result is required, so a handler with nothing to return answers with an empty object
+	resultJSON := []byte("{}")
+	if result != nil {
+		resultJSON, err = json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal result: %w", err)
+		}
+	}
+
+	response := PrepareResponse(req)
+	response.Response = &mcppb.JsonRpcResponse_ResultJson{
+		ResultJson: string(resultJSON),
+	}
+	return response, nil
+}
+
+var _ config.MethodHandler = (*MethodExecutor)(nil)
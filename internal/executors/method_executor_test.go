@@ -0,0 +1,80 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+func newMethodTestServerInfo(t *testing.T) *TestServerInfo {
+	methods := resources.NewStaticMethodRegistry()
+	require.NoError(t, methods.RegisterMethod("acme/echo", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"method": method, "params": params}, nil
+	}))
+	require.NoError(t, methods.RegisterMethod("acme/noop", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return nil, nil
+	}))
+	require.NoError(t, methods.RegisterPrefix("acme/jobs/", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return nil, protocol.NewError(-32010, "job queue is paused", nil, nil)
+	}))
+	require.NoError(t, methods.RegisterMethod("tools/list", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return nil, errors.New("the spec's methods are handled first")
+	}))
+
+	serverInfo := NewTestServerInfo()
+	serverInfo.FeatureRegistry.MethodRegistry = methods
+	return serverInfo
+}
+
+func TestMethodExecutor_CanHandleMethod(t *testing.T) {
+	executor := NewMethodExecutor(newMethodTestServerInfo(t))
+	assert.True(t, executor.CanHandleMethod("acme/echo"))
+	assert.True(t, executor.CanHandleMethod("acme/jobs/start"))
+	assert.False(t, executor.CanHandleMethod("acme/unknown"))
+
+	assert.False(t, NewMethodExecutor(NewTestServerInfo()).CanHandleMethod("acme/echo"), "no registry handles no methods")
+}
+
+func TestExecutors_HandleMethod_CustomMethods(t *testing.T) {
+	e := DefaultExecutors(newMethodTestServerInfo(t), nil)
+	ctx := context.Background()
+
+	call := func(method, params string) (*mcppb.JsonRpcResponse, error) {
+		return e.HandleMethod(ctx, method, &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_IntId{IntId: 7},
+			Method:     method,
+			ParamsJson: params,
+		})
+	}
+
+	resp, err := call("acme/echo", `{"q":"x"}`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), resp.GetIntId())
+	assert.JSONEq(t, `{"method":"acme/echo","params":{"q":"x"}}`, resp.GetResultJson())
+
+	resp, err = call("acme/noop", "")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", resp.GetResultJson(), "a result is always returned")
+
+	_, err = call("acme/jobs/start", "")
+	var rpcErr *protocol.JsonRpcError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32010, rpcErr.Code)
+
+	resp, err = call("tools/list", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetResultJson())
+
+	assert.False(t, e.CanHandleMethod("vendor/unknown"))
+	_, err = call("vendor/unknown", "")
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrMethodNotFound, rpcErr.Code)
+}
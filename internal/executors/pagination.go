@@ -0,0 +1,57 @@
+package executors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+// paginationConfig returns the server's pagination config, or the default one
+func paginationConfig(serverInfo config.McpServerInfo) config.PaginationConfig {
+	if cfg := serverInfo.GetServerConfig(); cfg != nil {
+		return cfg.Pagination
+	}
+	return config.DefaultConfig().Pagination
+}
+
+// cursorCodec returns the codec of the cursors the server hands out
+func cursorCodec(serverInfo config.McpServerInfo) *resources.CursorCodec {
+	cfg := paginationConfig(serverInfo)
+	return resources.NewCursorCodec([]byte(cfg.CursorSecret), cfg.CursorTTL)
+}
+
+// listPosition verifies the cursor of a list request and returns the registry position it holds, which is
+// empty for the first page
+func listPosition(serverInfo config.McpServerInfo, params map[string]interface{}) (string, error) {
+	cursorVal, ok := params["cursor"]
+	if !ok || cursorVal == nil {
+		return "", nil
+	}
+	cursor, ok := cursorVal.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: cursor must be a string", resources.ErrInvalidCursor)
+	}
+	if cursor == "" {
+		return "", nil
+	}
+	return cursorCodec(serverInfo).Decode(cursor)
+}
+
+// nextCursor returns the cursor handed to the client for the registry position of the next page
+func nextCursor(serverInfo config.McpServerInfo, position string) string {
+	if position == "" {
+		return ""
+	}
+	return cursorCodec(serverInfo).Encode(position)
+}
+
+// cursorError turns an invalid cursor into an invalid params error, leaving other errors as they are
+func cursorError(err error, reqID interface{}) error {
+	if errors.Is(err, resources.ErrInvalidCursor) {
+		return protocol.NewInvalidParamsError("Invalid cursor", reqID)
+	}
+	return err
+}
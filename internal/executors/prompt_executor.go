@@ -4,12 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log/slog"
 
 	"github.com/traego/scaled-mcp/pkg/config"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PromptExecutor handles prompt-related methods in the MCP protocol
@@ -53,7 +54,7 @@ func (p *PromptExecutor) HandleMethod(ctx context.Context, method string, req *m
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("error handling %s: %w", method, err)
+		return nil, fmt.Errorf("error handling %s: %w", method, cursorError(err, req.Id))
 	}
 
 	// Marshal the result
@@ -71,22 +72,20 @@ func (p *PromptExecutor) HandleMethod(ctx context.Context, method string, req *m
 
 // handleListPrompts handles a request to list prompts
 func (p *PromptExecutor) handleListPrompts(ctx context.Context, params map[string]interface{}) (interface{}, error) {
-	var cursor string
-
-	// Extract cursor
-	if cursorVal, ok := params["cursor"]; ok {
-		if cursorStr, ok := cursorVal.(string); ok {
-			cursor = cursorStr
-		}
+	position, err := listPosition(p.serverInfo, params)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create options
 	opts := resources.PromptListOptions{
-		Cursor: cursor,
+		Cursor:   position,
+		PageSize: paginationConfig(p.serverInfo).PageSize,
 	}
 
 	// Call the registry
 	result := p.serverInfo.GetFeatureRegistry().PromptRegistry.ListPrompts(ctx, opts)
+	result.NextCursor = nextCursor(p.serverInfo, result.NextCursor)
 
 	return result, nil
 }
@@ -113,22 +112,29 @@ func (p *PromptExecutor) handleGetPrompt(ctx context.Context, params map[string]
 				if strVal, ok := v.(string); ok {
 					arguments[k] = strVal
 				} else {
-					slog.Warn("Non-string argument value ignored", "key", k, "value", v)
+					config.Logger(p.serverInfo).WarnContext(ctx, "Non-string argument value ignored", "key", k, "value", v)
 				}
 			}
 		}
 	}
 
+	ctx, span := tracing.Tracer(p.serverInfo.GetTracerProvider()).Start(ctx, "mcp.prompt "+name,
+		trace.WithAttributes(tracing.AttrPromptName.String(name)))
+	defer span.End()
+
 	// Get the prompt
 	prompt, found := p.serverInfo.GetFeatureRegistry().PromptRegistry.GetPrompt(ctx, name)
 	if !found {
-		return nil, fmt.Errorf("%w: prompt '%s' not found", resources.ErrPromptNotFound, name)
+		err := fmt.Errorf("%w: prompt '%s' not found", resources.ErrPromptNotFound, name)
+		tracing.SetError(span, err)
+		return nil, err
 	}
 
 	// If arguments were provided, process the prompt template
 	if len(arguments) > 0 {
 		messages, err := p.serverInfo.GetFeatureRegistry().PromptRegistry.ProcessPrompt(ctx, name, arguments)
 		if err != nil {
+			tracing.SetError(span, err)
 			return nil, fmt.Errorf("error processing prompt template: %w", err)
 		}
 
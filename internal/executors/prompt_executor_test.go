@@ -11,10 +11,13 @@ import (
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/test/serverinfo"
 )
 
 // TestPromptServerInfo is an in-memory implementation of config.McpServerInfo for testing prompts
 type TestPromptServerInfo struct {
+	serverinfo.Stub
+
 	FeatureRegistry resources.FeatureRegistry
 	ServerCaps      protocol.ServerCapabilities
 	ServerConfig    *config.ServerConfig
@@ -75,18 +78,6 @@ func (s *TestPromptServerInfo) GetServerConfig() *config.ServerConfig {
 	return s.ServerConfig
 }
 
-func (s *TestPromptServerInfo) GetExecutors() config.MethodHandler {
-	return nil // Not needed for these tests
-}
-
-func (s *TestPromptServerInfo) GetAuthHandler() config.AuthHandler {
-	return nil
-}
-
-func (s *TestPromptServerInfo) GetTraceHandler() config.TraceHandler {
-	return nil
-}
-
 // MockPromptRegistry is a mock implementation of the PromptRegistry interface
 type MockPromptRegistry struct {
 	prompts map[string]resources.Prompt
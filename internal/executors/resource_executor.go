@@ -8,6 +8,9 @@ import (
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+	"github.com/traego/scaled-mcp/pkg/utils"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ResourceExecutor handles resource-related methods in the MCP protocol
@@ -57,7 +60,7 @@ func (r *ResourceExecutor) HandleMethod(ctx context.Context, method string, req
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("error handling %s: %w", req.Method, err)
+		return nil, fmt.Errorf("error handling %s: %w", req.Method, cursorError(err, req.Id))
 	}
 
 	// Marshal the result
@@ -75,22 +78,21 @@ func (r *ResourceExecutor) HandleMethod(ctx context.Context, method string, req
 
 // handleListResources handles a request to list resources
 func (r *ResourceExecutor) handleListResources(ctx context.Context, params map[string]interface{}) (interface{}, error) {
-	var cursor string
-
-	// Extract cursor
-	if cursorVal, ok := params["cursor"]; ok {
-		if cursorStr, ok := cursorVal.(string); ok {
-			cursor = cursorStr
-		}
+	position, err := listPosition(r.serverInfo, params)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create options
 	opts := resources.ResourceListOptions{
-		Cursor: cursor,
+		Cursor:   position,
+		PageSize: paginationConfig(r.serverInfo).PageSize,
 	}
 
 	// Call the registry
-	return r.serverInfo.GetFeatureRegistry().ResourceRegistry.ListResources(ctx, opts), nil
+	result := r.serverInfo.GetFeatureRegistry().ResourceRegistry.ListResources(ctx, opts)
+	result.NextCursor = nextCursor(r.serverInfo, result.NextCursor)
+	return result, nil
 }
 
 // handleReadResource handles a request to read a specific resource
@@ -106,9 +108,14 @@ func (r *ResourceExecutor) handleReadResource(ctx context.Context, params map[st
 		return nil, fmt.Errorf("%w: resource URI must be a non-empty string", resources.ErrInvalidParams)
 	}
 
+	ctx, span := tracing.Tracer(r.serverInfo.GetTracerProvider()).Start(ctx, "mcp.resource read",
+		trace.WithAttributes(tracing.AttrResourceURI.String(uri)))
+	defer span.End()
+
 	// Read the resource
 	contents, err := r.serverInfo.GetFeatureRegistry().ResourceRegistry.ReadResource(ctx, uri)
 	if err != nil {
+		tracing.SetError(span, err)
 		return nil, fmt.Errorf("error reading resource: %w", err)
 	}
 
@@ -128,14 +135,18 @@ func (r *ResourceExecutor) handleSubscribeResource(ctx context.Context, params m
 		return nil, fmt.Errorf("%w: resource URI must be a non-empty string", resources.ErrInvalidParams)
 	}
 
-	// Subscribe to the resource
-	err := r.serverInfo.GetFeatureRegistry().ResourceRegistry.SubscribeResource(ctx, uri)
+	// Subscribe the session to the resource
+	sessionId := utils.GetSessionId(ctx)
+	err := r.serverInfo.GetFeatureRegistry().ResourceRegistry.SubscribeResource(subscriberContext(ctx, sessionId), uri)
 	if err != nil {
 		if err == resources.ErrResourceNotFound {
 			return nil, fmt.Errorf("resource not found: %s", uri)
 		}
 		return nil, fmt.Errorf("error subscribing to resource: %w", err)
 	}
+	if subs := r.serverInfo.GetResourceSubscriptions(); subs != nil && sessionId != "" {
+		subs.Subscribe(sessionId, uri)
+	}
 
 	// Return success
 	return map[string]interface{}{
@@ -156,8 +167,12 @@ func (r *ResourceExecutor) handleUnsubscribeResource(ctx context.Context, params
 		return nil, fmt.Errorf("%w: resource URI must be a non-empty string", resources.ErrInvalidParams)
 	}
 
-	// Unsubscribe from the resource
-	err := r.serverInfo.GetFeatureRegistry().ResourceRegistry.UnsubscribeResource(ctx, uri)
+	// Unsubscribe the session from the resource
+	sessionId := utils.GetSessionId(ctx)
+	if subs := r.serverInfo.GetResourceSubscriptions(); subs != nil && sessionId != "" {
+		subs.Unsubscribe(sessionId, uri)
+	}
+	err := r.serverInfo.GetFeatureRegistry().ResourceRegistry.UnsubscribeResource(subscriberContext(ctx, sessionId), uri)
 	if err != nil {
 		if err == resources.ErrResourceNotFound {
 			return nil, fmt.Errorf("resource not found: %s", uri)
@@ -173,23 +188,31 @@ func (r *ResourceExecutor) handleUnsubscribeResource(ctx context.Context, params
 
 // handleListResourceTemplates handles a request to list resource templates
 func (r *ResourceExecutor) handleListResourceTemplates(ctx context.Context, params map[string]interface{}) (interface{}, error) {
-	var cursor string
-
-	// Extract cursor
-	if cursorVal, ok := params["cursor"]; ok {
-		if cursorStr, ok := cursorVal.(string); ok {
-			cursor = cursorStr
-		}
+	position, err := listPosition(r.serverInfo, params)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create options
 	opts := resources.ResourceTemplateListOptions{
-		Cursor: cursor,
+		Cursor:   position,
+		PageSize: paginationConfig(r.serverInfo).PageSize,
 	}
 
 	// Call the registry
-	return r.serverInfo.GetFeatureRegistry().ResourceRegistry.ListResourceTemplates(ctx, opts), nil
+	result := r.serverInfo.GetFeatureRegistry().ResourceRegistry.ListResourceTemplates(ctx, opts)
+	result.NextCursor = nextCursor(r.serverInfo, result.NextCursor)
+	return result, nil
 }
 
 // Ensure ResourceExecutor implements config.MethodHandler
 var _ config.MethodHandler = (*ResourceExecutor)(nil)
+
+// subscriberContext identifies the session as the subscriber to the registry, which otherwise sees a single
+// default subscriber
+func subscriberContext(ctx context.Context, sessionId string) context.Context {
+	if sessionId == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, resources.SubscriberIDKey, sessionId)
+}
@@ -11,13 +11,18 @@ import (
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/utils"
+	"github.com/traego/scaled-mcp/test/serverinfo"
 )
 
 // TestResourceServerInfo is an in-memory implementation of config.McpServerInfo for testing resources
 type TestResourceServerInfo struct {
+	serverinfo.Stub
+
 	FeatureRegistry resources.FeatureRegistry
 	ServerCaps      protocol.ServerCapabilities
 	ServerConfig    *config.ServerConfig
+	Subscriptions   *resources.Subscriptions
 }
 
 func NewTestResourceServerInfo() *TestResourceServerInfo {
@@ -65,6 +70,7 @@ func NewTestResourceServerInfo() *TestResourceServerInfo {
 		ServerConfig: &config.ServerConfig{
 			ProtocolVersion: protocol.ProtocolVersion20250326,
 		},
+		Subscriptions: resources.NewSubscriptions(),
 	}
 }
 
@@ -80,16 +86,8 @@ func (s *TestResourceServerInfo) GetServerConfig() *config.ServerConfig {
 	return s.ServerConfig
 }
 
-func (s *TestResourceServerInfo) GetExecutors() config.MethodHandler {
-	return nil // Not needed for these tests
-}
-
-func (s *TestResourceServerInfo) GetAuthHandler() config.AuthHandler {
-	return nil
-}
-
-func (s *TestResourceServerInfo) GetTraceHandler() config.TraceHandler {
-	return nil
+func (s *TestResourceServerInfo) GetResourceSubscriptions() *resources.Subscriptions {
+	return s.Subscriptions
 }
 
 // MockResourceRegistry is a mock implementation of the ResourceRegistry interface
@@ -98,6 +96,7 @@ type MockResourceRegistry struct {
 	resourceList      []resources.Resource
 	resourceTemplates []resources.ResourceTemplate
 	subscriptions     map[string]bool
+	subscriberIds     []string
 }
 
 func (m *MockResourceRegistry) ListResources(ctx context.Context, opts resources.ResourceListOptions) resources.ResourceListResult {
@@ -124,6 +123,9 @@ func (m *MockResourceRegistry) SubscribeResource(ctx context.Context, uri string
 		return resources.ErrResourceNotFound
 	}
 	m.subscriptions[uri] = true
+	if id, ok := ctx.Value(resources.SubscriberIDKey).(string); ok {
+		m.subscriberIds = append(m.subscriberIds, id)
+	}
 	return nil
 }
 
@@ -198,9 +200,9 @@ func TestResourceExecutor_HandleMethod_List(t *testing.T) {
 	require.True(t, ok, "Result should contain a 'resources' array")
 	assert.Equal(t, 1, len(resources), "Should have 1 resource")
 
-	// Test with cursor
+	// Test with a cursor the server handed out
 	paramsJSON, _ := json.Marshal(map[string]interface{}{
-		"cursor": "some-cursor",
+		"cursor": cursorCodec(serverInfo).Encode("some-position"),
 	})
 
 	req = &mcppb.JsonRpcRequest{
@@ -215,6 +217,13 @@ func TestResourceExecutor_HandleMethod_List(t *testing.T) {
 	resp, err = executor.HandleMethod(ctx, "resources/list", req)
 	require.NoError(t, err)
 	assert.Equal(t, "2", resp.GetStringId())
+
+	// A cursor the server did not hand out is invalid params
+	req.ParamsJson = `{"cursor":"some-cursor"}`
+	_, err = executor.HandleMethod(ctx, "resources/list", req)
+	var rpcErr *protocol.JsonRpcError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
 }
 
 func TestResourceExecutor_HandleMethod_Read(t *testing.T) {
@@ -399,6 +408,32 @@ func TestResourceExecutor_HandleMethod_Subscribe(t *testing.T) {
 	})
 }
 
+func TestResourceExecutor_SubscriptionsPerSession(t *testing.T) {
+	serverInfo := NewTestResourceServerInfo()
+	executor := NewResourceExecutor(serverInfo)
+	mockRegistry := serverInfo.GetFeatureRegistry().ResourceRegistry.(*MockResourceRegistry)
+
+	call := func(sessionId, method string) {
+		ctx := context.WithValue(context.Background(), utils.SessionIdCtx, sessionId)
+		req := &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_IntId{IntId: 1},
+			Method:     method,
+			ParamsJson: `{"uri":"test-resource"}`,
+		}
+		_, err := executor.HandleMethod(ctx, method, req)
+		require.NoError(t, err)
+	}
+
+	call("session-1", "resources/subscribe")
+	call("session-2", "resources/subscribe")
+	assert.Equal(t, []string{"session-1", "session-2"}, serverInfo.Subscriptions.Subscribers("test-resource"))
+	assert.Equal(t, []string{"session-1", "session-2"}, mockRegistry.subscriberIds, "the registry should see the session as the subscriber")
+
+	call("session-1", "resources/unsubscribe")
+	assert.Equal(t, []string{"session-2"}, serverInfo.Subscriptions.Subscribers("test-resource"))
+}
+
 func TestResourceExecutor_HandleMethod_Unsubscribe(t *testing.T) {
 	// Create a test server info
 	serverInfo := NewTestResourceServerInfo()
@@ -520,9 +555,9 @@ func TestResourceExecutor_HandleMethod_ListTemplates(t *testing.T) {
 	require.True(t, ok, "Result should contain a 'resourceTemplates' array")
 	assert.Equal(t, 1, len(templates), "Should have 1 resource template")
 
-	// Test with cursor
+	// Test with a cursor the server handed out
 	paramsJSON, _ := json.Marshal(map[string]interface{}{
-		"cursor": "some-cursor",
+		"cursor": cursorCodec(serverInfo).Encode("some-position"),
 	})
 
 	req = &mcppb.JsonRpcRequest{
@@ -537,6 +572,13 @@ func TestResourceExecutor_HandleMethod_ListTemplates(t *testing.T) {
 	resp, err = executor.HandleMethod(ctx, "resources/templates/list", req)
 	require.NoError(t, err)
 	assert.Equal(t, "2", resp.GetStringId())
+
+	// A cursor the server did not hand out is invalid params
+	req.ParamsJson = `{"cursor":"some-cursor"}`
+	_, err = executor.HandleMethod(ctx, "resources/templates/list", req)
+	var rpcErr *protocol.JsonRpcError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
 }
 
 func TestResourceExecutor_HandleMethod_InvalidMethod(t *testing.T) {
@@ -3,11 +3,17 @@ package executors
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+
 	"github.com/traego/scaled-mcp/pkg/config"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/redact"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ToolExecutor struct {
@@ -51,7 +57,7 @@ func (t *ToolExecutor) HandleMethod(ctx context.Context, method string, req *mcp
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("error handling %s: %w", req.Method, err)
+		return nil, fmt.Errorf("error handling %s: %w", req.Method, cursorError(err, req.Id))
 	}
 
 	// Marshal the result
@@ -69,18 +75,15 @@ func (t *ToolExecutor) HandleMethod(ctx context.Context, method string, req *mcp
 
 // handleListTools handles a request to list tools
 func (t *ToolExecutor) handleListTools(ctx context.Context, params map[string]interface{}) (protocol.ToolListResult, error) {
-	var cursor string
-
-	// Extract cursor
-	if cursorVal, ok := params["cursor"]; ok {
-		if cursorStr, ok := cursorVal.(string); ok {
-			cursor = cursorStr
-		}
+	position, err := listPosition(t.serverInfo, params)
+	if err != nil {
+		return protocol.ToolListResult{}, err
 	}
 
 	// Create options
 	opts := protocol.ToolListOptions{
-		Cursor: cursor,
+		Cursor:   position,
+		PageSize: paginationConfig(t.serverInfo).PageSize,
 	}
 
 	results, err := t.serverInfo.GetFeatureRegistry().ToolRegistry.ListTools(ctx, opts)
@@ -88,6 +91,7 @@ func (t *ToolExecutor) handleListTools(ctx context.Context, params map[string]in
 		return protocol.ToolListResult{}, fmt.Errorf("error listing tools: %w", err)
 	}
 
+	results.NextCursor = nextCursor(t.serverInfo, results.NextCursor)
 	return results, nil
 }
 
@@ -139,17 +143,22 @@ func (t *ToolExecutor) handleCallTool(ctx context.Context, params map[string]int
 		toolArgs = make(map[string]interface{})
 	}
 
+	ctx, span := tracing.Tracer(t.serverInfo.GetTracerProvider()).Start(ctx, "mcp.tool "+name,
+		trace.WithAttributes(tracing.AttrToolName.String(name)))
+	defer span.End()
+
 	// Invoke the tool
-	results, err := t.serverInfo.GetFeatureRegistry().ToolRegistry.CallTool(ctx, name, toolArgs)
+	results, err := t.callTool(ctx, name, toolArgs)
 	if err != nil {
-		// Create an error result
-		errorContent := protocol.NewTextContent(fmt.Sprintf("Error calling %s: %v", name, err))
-		return protocol.NewToolCallResult([]protocol.ToolCallContent{errorContent}, true), nil
+		return t.toolErrorResult(ctx, name, toolArgs, err), nil
 	}
 
 	// Convert the results to a ToolCallResult
 	// If results is already a ToolCallResult, return it directly
 	if toolCallResult, ok := results.(protocol.ToolCallResult); ok {
+		if toolCallResult.IsError {
+			span.SetStatus(codes.Error, "tool returned an error result")
+		}
 		return toolCallResult, nil
 	}
 
@@ -170,4 +179,53 @@ func (t *ToolExecutor) handleCallTool(ctx context.Context, params map[string]int
 	return protocol.NewToolCallResult([]protocol.ToolCallContent{textContent}, false), nil
 }
 
+// callTool invokes the tool through the server's tool interceptors, the first one outermost
+func (t *ToolExecutor) callTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	next := config.ToolInvoker(t.serverInfo.GetFeatureRegistry().ToolRegistry.CallTool)
+	interceptors := t.serverInfo.GetToolInterceptors()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+			return interceptor(ctx, name, args, inner)
+		}
+	}
+	return next(ctx, name, args)
+}
+
+// toolErrorResult reports a failed tool call. Secrets, including the values of arguments marked sensitive in
+// the tool's schema, are redacted from the error; in production mode only an error id is returned.
+func (t *ToolExecutor) toolErrorResult(ctx context.Context, name string, args map[string]interface{}, err error) protocol.ToolCallResult {
+	errorId := redact.NewErrorId()
+	message := redact.Values(redact.String(err.Error()), t.sensitiveValues(ctx, name, args)...)
+	config.Logger(t.serverInfo).ErrorContext(ctx, "tool call failed", "tool", name, "error_id", errorId, "err", message)
+	tracing.SetError(trace.SpanFromContext(ctx), errors.New(message))
+
+	text := fmt.Sprintf("Error calling %s: %s", name, message)
+	if t.serverInfo.GetServerConfig().Errors.Production {
+		text = fmt.Sprintf("Error calling %s (error id: %s)", name, errorId)
+	}
+
+	errorContent := protocol.NewTextContent(text)
+	return protocol.NewToolCallResult([]protocol.ToolCallContent{errorContent}, true)
+}
+
+// sensitiveValues returns the string arguments the tool's schema marks for redaction
+func (t *ToolExecutor) sensitiveValues(ctx context.Context, name string, args map[string]interface{}) []string {
+	tool, err := t.serverInfo.GetFeatureRegistry().ToolRegistry.GetTool(ctx, name)
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+	for arg, prop := range tool.InputSchema.Properties {
+		if prop.Redaction == protocol.RedactNone {
+			continue
+		}
+		if v, ok := args[arg].(string); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 var _ config.MethodHandler = (*ToolExecutor)(nil)
@@ -3,7 +3,12 @@ package executors
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,6 +16,8 @@ import (
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/test/serverinfo"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TestToolRegistry is an in-memory implementation of resources.ToolRegistry for testing
@@ -61,9 +68,14 @@ func (r *TestToolRegistry) CallTool(ctx context.Context, name string, params map
 
 // TestServerInfo is an in-memory implementation of config.McpServerInfo for testing
 type TestServerInfo struct {
-	FeatureRegistry resources.FeatureRegistry
-	ServerCaps      protocol.ServerCapabilities
-	ServerConfig    *config.ServerConfig
+	serverinfo.Stub
+
+	FeatureRegistry  resources.FeatureRegistry
+	ServerCaps       protocol.ServerCapabilities
+	ServerConfig     *config.ServerConfig
+	TracerProvider   trace.TracerProvider
+	Logger           *slog.Logger
+	ToolInterceptors []config.ToolInterceptor
 }
 
 func NewTestServerInfo() *TestServerInfo {
@@ -99,16 +111,16 @@ func (s *TestServerInfo) GetServerConfig() *config.ServerConfig {
 	return s.ServerConfig
 }
 
-func (s *TestServerInfo) GetExecutors() config.MethodHandler {
-	return nil // Not needed for these tests
+func (s *TestServerInfo) GetTracerProvider() trace.TracerProvider {
+	return s.TracerProvider
 }
 
-func (s *TestServerInfo) GetAuthHandler() config.AuthHandler {
-	return nil
+func (s *TestServerInfo) GetLogger() *slog.Logger {
+	return s.Logger
 }
 
-func (s *TestServerInfo) GetTraceHandler() config.TraceHandler {
-	return nil
+func (s *TestServerInfo) GetToolInterceptors() []config.ToolInterceptor {
+	return s.ToolInterceptors
 }
 
 func TestToolExecutor_CanHandleMethod(t *testing.T) {
@@ -543,3 +555,209 @@ func TestToolExecutor_HandleMethod_NilToolRegistry(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 }
+
+// failingToolRegistry is a TestToolRegistry whose tools always fail with a fixed error
+type failingToolRegistry struct {
+	*TestToolRegistry
+	err error
+}
+
+func (r *failingToolRegistry) CallTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	return nil, r.err
+}
+
+func TestToolExecutor_HandleMethod_CallErrorRedaction(t *testing.T) {
+	registry := &failingToolRegistry{
+		TestToolRegistry: NewTestToolRegistry(),
+		err:              errors.New("connect postgres://app:s3cr3t-pw@db:5432/app failed for token=tok-9f8e7d, account acct-7731"),
+	}
+	registry.Tools["lookup"] = protocol.Tool{
+		Name: "lookup",
+		InputSchema: protocol.InputSchema{
+			Type: "object",
+			Properties: map[string]protocol.SchemaProperty{
+				"account": {Type: "string", Redaction: protocol.RedactMask},
+			},
+		},
+	}
+
+	call := func(t *testing.T, production bool) string {
+		serverInfo := NewTestServerInfo()
+		serverInfo.FeatureRegistry = resources.FeatureRegistry{ToolRegistry: registry}
+		serverInfo.ServerConfig.Errors.Production = production
+
+		req := &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_StringId{StringId: "1"},
+			Method:     "tools/call",
+			ParamsJson: `{"name":"lookup","arguments":{"account":"acct-7731"}}`,
+		}
+
+		resp, err := NewToolExecutor(serverInfo).HandleMethod(context.Background(), "tools/call", req)
+		require.NoError(t, err)
+
+		var result struct {
+			Content []protocol.TextContent `json:"content"`
+			IsError bool                   `json:"isError"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.GetResultJson()), &result))
+		require.True(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		return result.Content[0].Text
+	}
+
+	t.Run("development mode redacts secrets", func(t *testing.T) {
+		text := call(t, false)
+		assert.Contains(t, text, "Error calling lookup")
+		assert.Contains(t, text, "postgres://app:[REDACTED]@db:5432/app")
+		assert.NotContains(t, text, "s3cr3t-pw")
+		assert.NotContains(t, text, "tok-9f8e7d")
+		assert.NotContains(t, text, "acct-7731", "sensitive argument values should be removed")
+	})
+
+	t.Run("production mode returns an error id", func(t *testing.T) {
+		text := call(t, true)
+		assert.Regexp(t, `^Error calling lookup \(error id: [0-9a-f]{16}\)$`, text)
+	})
+}
+
+// echoToolProvider is a resources.ToolProvider whose tools return their arguments
+type echoToolProvider struct{}
+
+func (echoToolProvider) GetTool(ctx context.Context, name string) (protocol.Tool, error) {
+	return protocol.Tool{Name: name, InputSchema: protocol.InputSchema{Type: "object"}}, nil
+}
+
+func (echoToolProvider) ListTools(ctx context.Context, cursor string) (protocol.ToolListResult, error) {
+	return protocol.ToolListResult{}, nil
+}
+
+func (echoToolProvider) HandleToolInvocation(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	return params, nil
+}
+
+func TestToolExecutor_HandleMethod_CallInterceptors(t *testing.T) {
+	staticRegistry := resources.NewStaticToolRegistry()
+	for _, name := range []string{"echo", "drop_table"} {
+		require.NoError(t, staticRegistry.RegisterTool(resources.NewTool(name).Build(),
+			func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+				return params, nil
+			}))
+	}
+
+	registries := map[string]resources.ToolRegistry{
+		"static":  staticRegistry,
+		"dynamic": resources.NewDynamicToolRegistry(echoToolProvider{}),
+	}
+
+	for kind, registry := range registries {
+		t.Run(kind, func(t *testing.T) {
+			var calls []string
+			serverInfo := NewTestServerInfo()
+			serverInfo.FeatureRegistry = resources.FeatureRegistry{ToolRegistry: registry}
+			serverInfo.ToolInterceptors = []config.ToolInterceptor{
+				func(ctx context.Context, name string, args map[string]interface{}, next config.ToolInvoker) (interface{}, error) {
+					calls = append(calls, "audit "+name)
+					return next(ctx, name, args)
+				},
+				func(ctx context.Context, name string, args map[string]interface{}, next config.ToolInvoker) (interface{}, error) {
+					if name == "drop_table" {
+						return nil, errors.New("drop_table is not allowed")
+					}
+					args["tenant"] = "acme"
+					return next(ctx, name, args)
+				},
+			}
+
+			call := func(name string) (string, bool) {
+				resp, err := NewToolExecutor(serverInfo).HandleMethod(context.Background(), "tools/call", &mcppb.JsonRpcRequest{
+					Jsonrpc:    "2.0",
+					Id:         &mcppb.JsonRpcRequest_StringId{StringId: "1"},
+					Method:     "tools/call",
+					ParamsJson: `{"name":"` + name + `","arguments":{"q":"x"}}`,
+				})
+				require.NoError(t, err)
+
+				var result struct {
+					Content []protocol.TextContent `json:"content"`
+					IsError bool                   `json:"isError"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(resp.GetResultJson()), &result))
+				require.Len(t, result.Content, 1)
+				return result.Content[0].Text, result.IsError
+			}
+
+			text, isError := call("echo")
+			assert.False(t, isError)
+			assert.JSONEq(t, `{"q":"x","tenant":"acme"}`, text, "interceptors can rewrite arguments")
+
+			text, isError = call("drop_table")
+			assert.True(t, isError)
+			assert.Contains(t, text, "drop_table is not allowed")
+
+			assert.Equal(t, []string{"audit echo", "audit drop_table"}, calls)
+		})
+	}
+}
+
+func TestToolExecutor_HandleMethod_ListPagination(t *testing.T) {
+	registry := resources.NewStaticToolRegistry()
+	for i := 0; i < 2500; i++ {
+		require.NoError(t, registry.RegisterTool(protocol.Tool{Name: fmt.Sprintf("tool-%04d", i)}, nil))
+	}
+
+	serverInfo := NewTestServerInfo()
+	serverInfo.FeatureRegistry.ToolRegistry = registry
+	serverInfo.ServerConfig.Pagination = config.PaginationConfig{PageSize: 1000, CursorSecret: "secret", CursorTTL: time.Hour}
+	executor := NewToolExecutor(serverInfo)
+	ctx := context.Background()
+
+	list := func(cursor string) (protocol.ToolListResult, error) {
+		params := "{}"
+		if cursor != "" {
+			params = fmt.Sprintf(`{"cursor":%q}`, cursor)
+		}
+		resp, err := executor.HandleMethod(ctx, "tools/list", &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_IntId{IntId: 1},
+			Method:     "tools/list",
+			ParamsJson: params,
+		})
+		if err != nil {
+			return protocol.ToolListResult{}, err
+		}
+		var result protocol.ToolListResult
+		require.NoError(t, json.Unmarshal([]byte(resp.GetResultJson()), &result))
+		return result, nil
+	}
+
+	var names []string
+	cursor := ""
+	pages := 0
+	for {
+		result, err := list(cursor)
+		require.NoError(t, err)
+		pages++
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		assert.NotContains(t, result.NextCursor, "tool-", "cursors should be opaque")
+		cursor = result.NextCursor
+	}
+	assert.Equal(t, 3, pages)
+	require.Len(t, names, 2500)
+	assert.True(t, sort.StringsAreSorted(names))
+
+	for _, invalid := range []string{
+		"tool-0999",
+		resources.NewCursorCodec([]byte("other secret"), time.Hour).Encode("tool-0999"),
+	} {
+		_, err := list(invalid)
+		var rpcErr *protocol.JsonRpcError
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/logging"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 )
@@ -22,7 +23,7 @@ func NewUtilitiesExecutor(serverInfo config.McpServerInfo) *UtilitiesExecutor {
 // CanHandleMethod checks if the method is related to utilities
 func (u *UtilitiesExecutor) CanHandleMethod(method string) bool {
 	switch method {
-	case "ping":
+	case "ping", "logging/setLevel":
 		return true
 	default:
 		return false
@@ -31,8 +32,12 @@ func (u *UtilitiesExecutor) CanHandleMethod(method string) bool {
 
 // HandleMethod handles utility-related methods
 func (u *UtilitiesExecutor) HandleMethod(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error) {
-	// Use the utility function to process the request - utilities don't require any specific registry
-	response, _, err := ProcessRequest(method, req, true)
+	// Utilities don't require any specific registry, but logging is only available if advertised
+	featureAvailable := true
+	if method == "logging/setLevel" {
+		featureAvailable = u.serverInfo.GetServerCapabilities().Logging != nil
+	}
+	response, params, err := ProcessRequest(method, req, featureAvailable)
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +48,8 @@ func (u *UtilitiesExecutor) HandleMethod(ctx context.Context, method string, req
 	switch method {
 	case "ping":
 		result, err = u.handlePing(ctx)
+	case "logging/setLevel":
+		result, err = u.handleSetLevel(ctx, params, req.Id)
 	default:
 		return nil, protocol.NewMethodNotFoundError(method, req.Id)
 	}
@@ -70,5 +77,29 @@ func (u *UtilitiesExecutor) handlePing(ctx context.Context) (interface{}, error)
 	return map[string]interface{}{}, nil
 }
 
+// handleSetLevel handles a logging/setLevel request, setting the minimum level of the log messages sent
+// to the session's client
+func (u *UtilitiesExecutor) handleSetLevel(ctx context.Context, params map[string]interface{}, reqID interface{}) (interface{}, error) {
+	levelVal, _ := params["level"].(string)
+	if levelVal == "" {
+		return nil, protocol.NewInvalidParamsError("level is required", reqID)
+	}
+
+	level := protocol.LoggingLevel(levelVal)
+	if _, ok := level.SlogLevel(); !ok {
+		return nil, protocol.NewInvalidParamsError(fmt.Sprintf("unknown logging level %q", levelVal), reqID)
+	}
+
+	session := logging.SessionFromContext(ctx)
+	if session == nil {
+		return nil, fmt.Errorf("no logging session for request")
+	}
+	if err := session.SetLevel(level); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{}, nil
+}
+
 // Ensure UtilitiesExecutor implements config.MethodHandler
 var _ config.MethodHandler = (*UtilitiesExecutor)(nil)
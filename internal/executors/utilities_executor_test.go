@@ -3,18 +3,23 @@ package executors
 import (
 	"context"
 	"encoding/json"
+	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/logging"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/test/serverinfo"
 )
 
 // TestUtilitiesServerInfo is an in-memory implementation of config.McpServerInfo for testing utilities
 type TestUtilitiesServerInfo struct {
+	serverinfo.Stub
+
 	FeatureRegistry resources.FeatureRegistry
 	ServerCaps      protocol.ServerCapabilities
 	ServerConfig    *config.ServerConfig
@@ -47,18 +52,6 @@ func (s *TestUtilitiesServerInfo) GetServerConfig() *config.ServerConfig {
 	return s.ServerConfig
 }
 
-func (s *TestUtilitiesServerInfo) GetExecutors() config.MethodHandler {
-	return nil // Not needed for these tests
-}
-
-func (s *TestUtilitiesServerInfo) GetAuthHandler() config.AuthHandler {
-	return nil // Not needed for these tests
-}
-
-func (s *TestUtilitiesServerInfo) GetTraceHandler() config.TraceHandler {
-	return nil
-}
-
 func TestUtilitiesExecutor_CanHandleMethod(t *testing.T) {
 	// Create a test server info
 	serverInfo := NewTestUtilitiesServerInfo()
@@ -157,3 +150,60 @@ func TestUtilitiesExecutor_HandleMethod_IntId(t *testing.T) {
 	// The ping response should be an empty object according to the implementation
 	assert.Empty(t, result)
 }
+
+func TestUtilitiesExecutor_HandleMethod_SetLevel(t *testing.T) {
+	serverInfo := NewTestUtilitiesServerInfo()
+	executor := NewUtilitiesExecutor(serverInfo)
+	assert.True(t, executor.CanHandleMethod("logging/setLevel"))
+
+	var sent []protocol.LoggingMessageParams
+	session := logging.NewSession(slog.New(slog.DiscardHandler), func(params protocol.LoggingMessageParams) {
+		sent = append(sent, params)
+	})
+	ctx := logging.WithSession(context.Background(), session)
+
+	setLevel := func(ctx context.Context, paramsJson string) (*mcppb.JsonRpcResponse, error) {
+		return executor.HandleMethod(ctx, "logging/setLevel", &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_IntId{IntId: 7},
+			Method:     "logging/setLevel",
+			ParamsJson: paramsJson,
+		})
+	}
+
+	t.Run("valid level", func(t *testing.T) {
+		resp, err := setLevel(ctx, `{"level":"warning"}`)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), resp.GetIntId())
+		assert.JSONEq(t, `{}`, resp.GetResultJson())
+
+		level, ok := session.Level()
+		require.True(t, ok)
+		assert.Equal(t, protocol.LoggingLevelWarning, level)
+
+		logging.FromContext(ctx).Info("dropped")
+		logging.FromContext(ctx).Error("forwarded")
+		require.Len(t, sent, 1)
+		assert.Equal(t, protocol.LoggingLevelError, sent[0].Level)
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		for _, params := range []string{`{"level":"verbose"}`, `{}`} {
+			_, err := setLevel(ctx, params)
+			var rpcErr *protocol.JsonRpcError
+			require.ErrorAs(t, err, &rpcErr, params)
+			assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+		}
+	})
+
+	t.Run("logging not advertised", func(t *testing.T) {
+		noLogging := NewTestUtilitiesServerInfo()
+		noLogging.ServerCaps.Logging = nil
+		_, err := NewUtilitiesExecutor(noLogging).HandleMethod(ctx, "logging/setLevel", &mcppb.JsonRpcRequest{
+			Jsonrpc: "2.0", Id: &mcppb.JsonRpcRequest_IntId{IntId: 8}, Method: "logging/setLevel", ParamsJson: `{"level":"info"}`,
+		})
+		var rpcErr *protocol.JsonRpcError
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, protocol.ErrMethodNotFound, rpcErr.Code)
+	})
+}
@@ -0,0 +1,90 @@
+package httphandlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/traego/scaled-mcp/internal/actors"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// DrainRetryAfter is how long clients turned away by a draining node are told to wait before retrying
+const DrainRetryAfter = time.Second
+
+// SetDraining sets whether the handler turns away new sessions, as the node shuts down. Existing sessions are
+// served until they are drained.
+func (h *MCPHandler) SetDraining(draining bool) {
+	h.draining.Store(draining)
+}
+
+// Draining reports whether the handler turns away new sessions
+func (h *MCPHandler) Draining() bool {
+	return h.draining.Load()
+}
+
+// InFlight returns the number of posted messages being handled
+func (h *MCPHandler) InFlight() int64 {
+	return h.inFlight.Load()
+}
+
+// WaitInFlight waits until no posted message is being handled, or until ctx is done
+func (h *MCPHandler) WaitInFlight(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for h.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// trackInFlight counts a posted message as in flight until the returned function is called
+func (h *MCPHandler) trackInFlight() func() {
+	h.inFlight.Add(1)
+	return func() {
+		h.inFlight.Add(-1)
+	}
+}
+
+// writeDraining turns a request away while the node is draining, asking the client to retry, which load
+// balancers route to another node
+func writeDraining(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(DrainRetryAfter.Seconds())))
+	http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+}
+
+// resumeSession resumes a session handed off by a node that shut down, if it is not running anywhere and the
+// session store has a snapshot of it. A draining node never resumes sessions.
+func (h *MCPHandler) resumeSession(ctx context.Context, sessionId string) error {
+	store := h.serverInfo.GetSessionStore()
+	if store == nil || h.Draining() {
+		return nil
+	}
+
+	san := utils.GetSessionActorName(sessionId)
+	if _, _, err := h.actorSystem.ActorOf(ctx, san); err == nil || !utils.IsActorNotFound(err) {
+		return nil
+	}
+
+	snapshot, err := store.Take(ctx, sessionId)
+	if errors.Is(err, sessionstore.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sa := actors.NewMcpSessionStateMachineFromSnapshot(h.serverInfo, snapshot)
+	if _, err := h.actorSystem.Spawn(ctx, san, sa, actors.SessionSpawnOptions(h.serverInfo.GetMetrics())...); err != nil {
+		return err
+	}
+	h.logger().InfoContext(ctx, "resumed handed off session", "session_id", sessionId)
+	return nil
+}
@@ -0,0 +1,97 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/goakt/v3/actor"
+
+	"github.com/traego/scaled-mcp/internal/actors"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+func TestDraining(t *testing.T) {
+	actorSystem, err := actor.NewActorSystem("test-system")
+	require.NoError(t, err)
+	require.NoError(t, actorSystem.Start(context.Background()))
+	defer func() {
+		_ = actorSystem.Stop(context.Background())
+	}()
+	_, err = actorSystem.Spawn(context.Background(), "root", actors.NewRootActor())
+	require.NoError(t, err)
+
+	handler := NewMCPHandler(config.DefaultConfig(), actorSystem, &mockServerInfo{})
+	handler.SetDraining(true)
+	assert.True(t, handler.Draining())
+
+	post := func(sessionId string, message protocol.JSONRPCMessage) *httptest.ResponseRecorder {
+		body, err := json.Marshal(message)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+		if sessionId != "" {
+			req.Header.Set("Mcp-Session-Id", sessionId)
+		}
+		w := httptest.NewRecorder()
+		handler.HandleMCPPost(w, req)
+		return w
+	}
+
+	t.Run("new sessions are turned away", func(t *testing.T) {
+		w := post("", protocol.JSONRPCMessage{JSONRPC: "2.0", Method: "initialize", ID: 1, Params: map[string]interface{}{}})
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("drained sessions are told to retry", func(t *testing.T) {
+		w := post("drained-session", protocol.JSONRPCMessage{JSONRPC: "2.0", Method: "tools/list", ID: 2})
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("SSE streams are turned away", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleSSEGet(w, httptest.NewRequest(http.MethodGet, "/sse", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+		w = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+		req.Header.Set("Mcp-Session-Id", "drained-session")
+		handler.HandleMCPGet(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("unknown sessions are not reported as draining otherwise", func(t *testing.T) {
+		handler.SetDraining(false)
+		defer handler.SetDraining(true)
+
+		w := post("unknown-session", protocol.JSONRPCMessage{JSONRPC: "2.0", Method: "tools/list", ID: 3})
+		assert.NotEqual(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestWaitInFlight(t *testing.T) {
+	handler := NewMCPHandler(config.DefaultConfig(), nil, &mockServerInfo{})
+	require.NoError(t, handler.WaitInFlight(context.Background()), "nothing is in flight")
+
+	done := handler.trackInFlight()
+	assert.Equal(t, int64(1), handler.InFlight())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, handler.WaitInFlight(ctx), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		done()
+	}()
+	require.NoError(t, handler.WaitInFlight(context.Background()))
+	assert.Equal(t, int64(0), handler.InFlight())
+}
@@ -0,0 +1,51 @@
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+/*
+DELETE /mcp
+Client explicitly ends its session
+*/
+
+// HandleMCPDelete ends the session named by the Mcp-Session-Id header at the client's request
+func (h *MCPHandler) HandleMCPDelete(w http.ResponseWriter, r *http.Request) {
+	defer h.trackInFlight()()
+	ctx := r.Context()
+
+	sessionId := r.Header.Get("Mcp-Session-Id")
+	if sessionId == "" {
+		http.Error(w, "missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	}
+
+	// A session handed off by another node is resumed first, so that it ends like any other
+	if err := h.resumeSession(ctx, sessionId); err != nil {
+		h.handleError(w, err, "")
+		return
+	}
+
+	_, rid, err := h.actorSystem.ActorOf(ctx, "root")
+	if err != nil {
+		h.handleError(w, err, "")
+		return
+	}
+
+	_, err = rid.SendSync(ctx, utils.GetSessionActorName(sessionId), &mcppb.TerminateSession{ByClient: true}, h.config.RequestTimeout)
+	if err != nil {
+		if h.Draining() && utils.IsActorNotFound(err) {
+			writeDraining(w)
+		} else if utils.IsActorNotFound(err) {
+			http.Error(w, "session not found", http.StatusNotFound)
+		} else {
+			h.handleError(w, err, "")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
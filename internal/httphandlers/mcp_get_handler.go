@@ -4,7 +4,7 @@ import (
 	"fmt"
 	actors2 "github.com/traego/scaled-mcp/internal/actors"
 	"github.com/traego/scaled-mcp/internal/channels"
-	"log/slog"
+	"github.com/traego/scaled-mcp/pkg/metrics"
 	"net/http"
 )
 
@@ -26,21 +26,31 @@ func (h *MCPHandler) HandleMCPGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.Draining() {
+		writeDraining(w)
+		return
+	}
+
+	if err := h.resumeSession(ctx, sessionId); err != nil {
+		h.handleError(w, err, "")
+		return
+	}
+
 	// Create an SSE channel for communication
 	channel := channels.NewSSEChannel(w, r, sessionId)
 
-	cca := actors2.NewClientConnectionActor(h.config, sessionId, nil, channel, true, false, "")
+	cca := actors2.NewClientConnectionActor(h.config, sessionId, nil, channel, true, false, "", h.serverInfo.GetMetrics())
 	clientActorName := fmt.Sprintf("%s-client", sessionId)
-	clientActor, err := h.actorSystem.Spawn(ctx, clientActorName, cca)
+	clientActor, err := h.actorSystem.Spawn(ctx, clientActorName, cca, actors2.WithInstrumentedMailbox(h.serverInfo.GetMetrics(), metrics.ActorClientConnection))
 	if err != nil {
 		respErr := fmt.Errorf("error spawning mcp session: %w", err)
-		handleError(w, respErr, "")
+		h.handleError(w, respErr, "")
 	}
 
 	_, dc, err := actors2.SpawnDeathWatcher(ctx, h.actorSystem, clientActor)
 	if err != nil {
 		respErr := fmt.Errorf("error spawning connection watcher: %w", err)
-		handleError(w, respErr, "")
+		h.handleError(w, respErr, "")
 	}
 
 	select {
@@ -48,5 +58,5 @@ func (h *MCPHandler) HandleMCPGet(w http.ResponseWriter, r *http.Request) {
 	case <-channel.Done:
 	}
 
-	slog.DebugContext(ctx, "Shutting down MCP Long Lived Session")
+	h.logger().DebugContext(ctx, "Shutting down MCP Long Lived Session")
 }
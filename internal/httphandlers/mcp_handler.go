@@ -1,16 +1,20 @@
 package httphandlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/tochemey/goakt/v3/actor"
+	"github.com/traego/scaled-mcp/pkg/auth"
 	"github.com/traego/scaled-mcp/pkg/config"
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/redact"
 )
 
 // MCPHandler handles MCP protocol requests
@@ -18,6 +22,10 @@ type MCPHandler struct {
 	config      *config.ServerConfig
 	actorSystem actor.ActorSystem
 	serverInfo  config.McpServerInfo
+
+	// Set while the node shuts down, and the number of posted messages being handled
+	draining atomic.Bool
+	inFlight atomic.Int64
 }
 
 // NewMCPHandler creates a new MCP handler
@@ -64,6 +72,10 @@ func parseMessageRequest(r *http.Request) (McpRequest, error) {
 }
 
 func writeMessage(w http.ResponseWriter, msg protocol.JSONRPCMessage, sessionId *string) error {
+	return writeMessageWithStatus(w, msg, sessionId, http.StatusOK)
+}
+
+func writeMessageWithStatus(w http.ResponseWriter, msg protocol.JSONRPCMessage, sessionId *string, status int) error {
 	responseJSON, err := json.Marshal(msg)
 	if err != nil {
 		handleError(w, err, msg.ID)
@@ -75,14 +87,39 @@ func writeMessage(w http.ResponseWriter, msg protocol.JSONRPCMessage, sessionId
 		w.Header().Set("Mcp-Session-Id", *sessionId)
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	_, _ = w.Write(responseJSON)
 	return nil
 }
 
+// logger returns the server's logger
+func (h *MCPHandler) logger() *slog.Logger {
+	return config.Logger(h.serverInfo)
+}
+
+// serializeAuth serializes the request's auth info for the session actor, returning nil if there is none
+func (h *MCPHandler) serializeAuth(ctx context.Context) ([]byte, error) {
+	ai := auth.GetAuthInfo(ctx)
+	if ai == nil || h.serverInfo.GetAuthHandler() == nil {
+		return nil, nil
+	}
+	return h.serverInfo.GetAuthHandler().Serialize(ai)
+}
+
+// handleError processes errors from request handling, following the server's error reporting mode
+func (h *MCPHandler) handleError(w http.ResponseWriter, err error, id interface{}) {
+	writeError(w, err, id, h.config.Errors.Production, h.logger())
+}
+
 // handleError processes errors from request handling
 // It distinguishes between JSON-RPC errors and other errors
 func handleError(w http.ResponseWriter, err error, id interface{}) {
+	writeError(w, err, id, false, slog.Default())
+}
+
+// writeError writes err as a JSON-RPC error response. Known secrets are redacted from the message, and in
+// production mode errors that are not JSON-RPC errors are replaced by an opaque error id.
+func writeError(w http.ResponseWriter, err error, id interface{}, production bool, logger *slog.Logger) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Check if it's a JSON-RPC error
@@ -93,12 +130,13 @@ func handleError(w http.ResponseWriter, err error, id interface{}) {
 		if jsonRpcError.ID == nil {
 			jsonRpcError.ID = id
 		}
+		jsonRpcError.Message = redact.String(jsonRpcError.Message)
 
 		response := jsonRpcError.ToResponse()
 		responseJSON, marshalErr := json.Marshal(response)
 		if marshalErr != nil {
 			// If we can't marshal the error response, fall back to a generic JSON-RPC server error
-			slog.Error("Failed to marshal JSON-RPC error response", "error", marshalErr)
+			logger.Error("Failed to marshal JSON-RPC error response", "error", marshalErr)
 			fallbackError := protocol.NewServerError(protocol.ErrServer, "Internal server error", nil, id)
 			fallbackJSON, _ := json.Marshal(fallbackError.ToResponse())
 			w.WriteHeader(http.StatusOK) // JSON-RPC errors use 200 OK with error in body
@@ -109,22 +147,26 @@ func handleError(w http.ResponseWriter, err error, id interface{}) {
 		w.WriteHeader(http.StatusOK) // JSON-RPC errors use 200 OK with error in body
 		_, writeErr := w.Write(responseJSON)
 		if writeErr != nil {
-			slog.Error("Failed to write JSON-RPC error response", "error", writeErr)
+			logger.Error("Failed to write JSON-RPC error response", "error", writeErr)
 		}
 		return
 	}
 
 	// It's not a JSON-RPC error, so return a generic 500 error
-	slog.Error("Internal server error", "error", err)
+	errorId := redact.NewErrorId()
+	logger.Error("Internal server error", "error_id", errorId, "error", redact.String(err.Error()))
 
 	// Create a standard JSON-RPC internal error
-	internalError := protocol.NewInternalError(err.Error(), id)
+	internalError := protocol.NewInternalError(redact.String(err.Error()), id)
+	if production {
+		internalError = protocol.NewOpaqueInternalError(errorId, id)
+	}
 	response := internalError.ToResponse()
 
 	responseJSON, marshalErr := json.Marshal(response)
 	if marshalErr != nil {
 		// If we can't marshal the error response, fall back to a generic JSON-RPC server error
-		slog.Error("Failed to marshal internal error response", "error", marshalErr)
+		logger.Error("Failed to marshal internal error response", "error", marshalErr)
 		fallbackError := protocol.NewServerError(protocol.ErrServer, "Internal server error", nil, id)
 		fallbackJSON, _ := json.Marshal(fallbackError.ToResponse())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -135,6 +177,6 @@ func handleError(w http.ResponseWriter, err error, id interface{}) {
 	w.WriteHeader(http.StatusInternalServerError)
 	_, writeErr := w.Write(responseJSON)
 	if writeErr != nil {
-		slog.Error("Failed to write error response", "error", writeErr)
+		logger.Error("Failed to write error response", "error", writeErr)
 	}
 }
@@ -15,7 +15,10 @@ import (
 	"github.com/traego/scaled-mcp/pkg/auth"
 	"github.com/traego/scaled-mcp/pkg/config"
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/ratelimit"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"github.com/traego/scaled-mcp/test/serverinfo"
 )
 
 func TestNewMCPHandler(t *testing.T) {
@@ -236,7 +239,63 @@ func TestHandleError(t *testing.T) {
 	})
 }
 
-type mockServerInfo struct{}
+func TestHandleErrorRedaction(t *testing.T) {
+	secretErr := errors.New("dial postgres://app:pa55word@db:5432/app: connection refused")
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+		var responseMessage protocol.JSONRPCMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &responseMessage))
+		errorMap, ok := responseMessage.Error.(map[string]interface{})
+		require.True(t, ok, "Error should be a map")
+		return errorMap
+	}
+
+	t.Run("development mode redacts secrets", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h := &MCPHandler{config: config.DefaultConfig()}
+		h.handleError(w, secretErr, 1)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		errorMap := decode(t, w)
+		assert.Equal(t, "Internal error: dial postgres://app:[REDACTED]@db:5432/app: connection refused", errorMap["message"])
+	})
+
+	t.Run("production mode returns an opaque error id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		cfg := config.DefaultConfig()
+		cfg.Errors.Production = true
+		h := &MCPHandler{config: cfg}
+		h.handleError(w, secretErr, 1)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		errorMap := decode(t, w)
+		assert.Equal(t, float64(protocol.ErrInternal), errorMap["code"])
+		assert.Equal(t, "Internal error", errorMap["message"])
+		data, ok := errorMap["data"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Regexp(t, `^[0-9a-f]{16}$`, data["errorId"])
+		assert.NotContains(t, w.Body.String(), "postgres")
+	})
+
+	t.Run("JSON-RPC errors keep their message in production mode", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		cfg := config.DefaultConfig()
+		cfg.Errors.Production = true
+		h := &MCPHandler{config: cfg}
+		h.handleError(w, protocol.NewInvalidParamsError("bad token=abc123", 1), 1)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		errorMap := decode(t, w)
+		assert.Equal(t, "Invalid params: bad token=[REDACTED]", errorMap["message"])
+	})
+}
+
+type mockServerInfo struct {
+	serverinfo.Stub
+
+	sessionStore sessionstore.Store
+	rateLimiter  *ratelimit.Limiter
+}
 
 type mockToolRegistry struct{}
 
@@ -296,24 +355,16 @@ func (m *mockServerInfo) GetFeatureRegistry() resources.FeatureRegistry {
 	}
 }
 
-func (m *mockServerInfo) GetServerCapabilities() protocol.ServerCapabilities {
-	return protocol.ServerCapabilities{}
-}
-
-func (m *mockServerInfo) GetServerConfig() *config.ServerConfig {
-	return nil
-}
-
-func (m *mockServerInfo) GetExecutors() config.MethodHandler {
-	return nil
-}
-
 func (m *mockServerInfo) GetAuthHandler() config.AuthHandler {
 	return &mockAuthHandler{}
 }
 
-func (m *mockServerInfo) GetTraceHandler() config.TraceHandler {
-	return nil
+func (m *mockServerInfo) GetRateLimiter() *ratelimit.Limiter {
+	return m.rateLimiter
+}
+
+func (m *mockServerInfo) GetSessionStore() sessionstore.Store {
+	return m.sessionStore
 }
 
 type mockAuthInfo struct{}
@@ -4,25 +4,43 @@ import (
 	"context"
 	"fmt"
 	"github.com/traego/scaled-mcp/internal/actors"
-	"github.com/traego/scaled-mcp/pkg/auth"
-	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/tochemey/goakt/v3/actor"
+	"github.com/traego/scaled-mcp/pkg/metrics"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/tracing"
 	"github.com/traego/scaled-mcp/pkg/utils"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HandleMCPPost handles an MCP request
 func (h *MCPHandler) HandleMCPPost(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	defer h.trackInFlight()()
+
+	start := time.Now()
+	method := "invalid"
+	ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	w = ww
+	defer func() {
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.OrNop(h.serverInfo.GetMetrics()).HTTPRequest(method, status, time.Since(start))
+	}()
 
 	mcpRequest, err := parseMessageRequest(r)
 	if err != nil {
-		handleError(w, err, "")
+		h.handleError(w, err, "")
 		return
 	}
+	method = requestMethodLabel(mcpRequest)
+	trace.SpanFromContext(ctx).SetAttributes(tracing.AttrMethod.String(method))
 
 	sessionId := r.Header.Get("Mcp-Session-Id")
 	if sessionId == "" && mcpRequest.Message.Method == "initialize" {
@@ -34,21 +52,47 @@ func (h *MCPHandler) HandleMCPPost(w http.ResponseWriter, r *http.Request) {
 	demandInitialize := sessionId == ""
 
 	if demandInitialize {
+		if h.Draining() {
+			writeDraining(w)
+			return
+		}
 		h.handleMcpInitDemand(ctx, w, r, mcpRequest)
 		return
 	} else {
+		if err := h.resumeSession(ctx, sessionId); err != nil {
+			h.handleError(w, err, mcpRequest.Message.ID)
+			return
+		}
 		h.handleMcpMessages(ctx, sessionId, w, r, mcpRequest)
 		return
 	}
 }
 
+// requestMethodLabel names a posted message for metrics
+func requestMethodLabel(mr McpRequest) string {
+	switch {
+	case mr.IsBatch:
+		return "batch"
+	case mr.Message.Method == "":
+		return "response"
+	default:
+		return mr.Message.Method
+	}
+}
+
 func (h *MCPHandler) handleMcpMessages(ctx context.Context, sessionId string, w http.ResponseWriter, r *http.Request, mr McpRequest) {
 	if !mr.IsBatch {
 		protoMsg, err := protocol.ConvertJSONToProtoRequest(mr.Message)
 		if err != nil {
-			handleError(w, err, mr.Message.ID)
+			h.handleError(w, err, mr.Message.ID)
+			return
+		}
+
+		release, ok := h.acquireRateLimit(ctx, w, r, sessionId, protoMsg, mr.Message.ID)
+		if !ok {
 			return
 		}
+		defer release()
 
 		san := utils.GetSessionActorName(sessionId)
 
@@ -56,89 +100,105 @@ func (h *MCPHandler) handleMcpMessages(ctx context.Context, sessionId string, w
 			IsAsk:                 true,
 			RespondToConnectionId: "",
 			Request:               protoMsg,
-			TraceId:               utils.GetTraceId(ctx),
+			TraceContext:          tracing.Inject(ctx),
 		}
 
-		if ai := auth.GetAuthInfo(ctx); ai != nil && h.serverInfo.GetAuthHandler() != nil {
-			ser, err := h.serverInfo.GetAuthHandler().Serialize(ai)
-			if err != nil {
-				handleError(w, fmt.Errorf("unable to serialize auth"), mr.Message.ID)
-			}
-			wrapped.AuthInfo = ser
+		authInfo, err := h.serializeAuth(ctx)
+		if err != nil {
+			h.handleError(w, fmt.Errorf("unable to serialize auth"), mr.Message.ID)
+			return
 		}
+		wrapped.AuthInfo = authInfo
 
 		_, rid, err := h.actorSystem.ActorOf(ctx, "root")
 		if err != nil {
-			handleError(w, err, mr.Message.ID)
+			h.handleError(w, err, mr.Message.ID)
+			return
 		}
 
 		// So there's a one off one way only request which is notifications/initialized that we need to handle specially
 		if protocol.IsOnewayMethod(mr.Message.Method) {
 			err = rid.SendAsync(ctx, san, &wrapped)
 			if err != nil {
-				handleError(w, err, mr.Message.ID)
+				h.handleSendError(w, err, mr.Message.ID)
 				return
 			}
 		} else {
 			respMsg, err := rid.SendSync(ctx, san, &wrapped, h.config.RequestTimeout)
 			if err != nil {
-				handleError(w, err, mr.Message.ID)
+				h.handleSendError(w, err, mr.Message.ID)
 				return
 			}
 
 			rjm, ok := respMsg.(*mcppb.JsonRpcResponse)
 			if !ok {
 				err := actor.NewInternalError(fmt.Errorf("failed to parse json-rpc response type"))
-				handleError(w, err, mr.Message.ID)
+				h.handleError(w, err, mr.Message.ID)
 				return
 			}
 			rm, err := protocol.ConvertProtoToJSONResponse(rjm)
 			if err != nil {
-				handleError(w, err, mr.Message.ID)
+				h.handleError(w, err, mr.Message.ID)
 				return
 			}
 
 			err = writeMessage(w, rm, nil)
 			if err != nil {
-				handleError(w, err, mr.Message.ID)
+				h.handleError(w, err, mr.Message.ID)
 				return
 			}
 			return
 		}
 	} else {
 		err := actor.NewInternalError(fmt.Errorf("batch messaging not implemented"))
-		handleError(w, err, mr.Message.ID)
+		h.handleError(w, err, mr.Message.ID)
+		return
+	}
+}
+
+// handleSendError handles a failure to reach a session actor. Sessions drained off a node that is shutting
+// down are gone, so their clients are asked to retry, and reach another node.
+func (h *MCPHandler) handleSendError(w http.ResponseWriter, err error, id interface{}) {
+	if h.Draining() && utils.IsActorNotFound(err) {
+		writeDraining(w)
 		return
 	}
+	h.handleError(w, err, id)
 }
 
 func (h *MCPHandler) handleMcpInitDemand(ctx context.Context, w http.ResponseWriter, r *http.Request, mr McpRequest) {
 	// If no session and it's a post, check that it's an initialize message. If it's not, it's a bad request
 	if mr.IsBatch {
-		slog.Debug("Received batch request without sessionId (expecting single initialize message")
+		h.logger().DebugContext(ctx, "Received batch request without sessionId (expecting single initialize message")
 		respErr := protocol.NewInvalidRequestError("batch requests are disallowed before initialization", "")
-		handleError(w, respErr, "")
+		h.handleError(w, respErr, "")
 		return
 	} else {
 		msg := mr.Message
 		if msg.Method == "initialize" {
 			sessionId, err := utils.GenerateSecureID(20)
 			if err != nil {
-				handleError(w, err, msg.ID)
+				h.handleError(w, err, msg.ID)
 				return
 			}
 
-			sa := actors.NewMcpSessionStateMachine(h.serverInfo, sessionId)
+			authInfo, err := h.serializeAuth(ctx)
+			if err != nil {
+				h.handleError(w, fmt.Errorf("unable to serialize auth"), msg.ID)
+				return
+			}
+
+			sa := actors.NewMcpSessionStateMachine(h.serverInfo, sessionId, authInfo)
 			san := utils.GetSessionActorName(sessionId)
-			_, err = h.actorSystem.Spawn(ctx, san, sa)
+			_, err = h.actorSystem.Spawn(ctx, san, sa, actors.SessionSpawnOptions(h.serverInfo.GetMetrics())...)
 			if err != nil {
-				handleError(w, err, msg.ID)
+				h.handleError(w, err, msg.ID)
 				return
 			}
 
 			protoInit, err := protocol.ConvertJSONToProtoRequest(msg)
 			if err != nil {
-				handleError(w, err, msg.ID)
+				h.handleError(w, err, msg.ID)
 				return
 			}
 
@@ -146,49 +206,42 @@ func (h *MCPHandler) handleMcpInitDemand(ctx context.Context, w http.ResponseWri
 				IsAsk:                 true,
 				RespondToConnectionId: "",
 				Request:               protoInit,
-				TraceId:               utils.GetTraceId(ctx),
-			}
-
-			if ai := auth.GetAuthInfo(ctx); ai != nil && h.serverInfo.GetAuthHandler() != nil {
-				ser, err := h.serverInfo.GetAuthHandler().Serialize(ai)
-				if err != nil {
-					handleError(w, fmt.Errorf("unable to serialize auth"), mr.Message.ID)
-				}
-				wrapped.AuthInfo = ser
+				TraceContext:          tracing.Inject(ctx),
+				AuthInfo:              authInfo,
 			}
 
 			_, rid, err := h.actorSystem.ActorOf(ctx, san)
 			if err != nil {
-				handleError(w, err, msg.ID)
+				h.handleError(w, err, msg.ID)
 				return
 			}
 
 			initResp, err := rid.SendSync(ctx, san, &wrapped, h.config.RequestTimeout)
 			if err != nil {
-				handleError(w, err, msg.ID)
+				h.handleError(w, err, msg.ID)
 				return
 			}
 
 			jrr, ok := initResp.(*mcppb.JsonRpcResponse)
 			if !ok {
-				handleError(w, fmt.Errorf("unable to parse init response"), msg.ID)
+				h.handleError(w, fmt.Errorf("unable to parse init response"), msg.ID)
 				return
 			}
 
 			ir, err := protocol.ConvertProtoToJSONResponse(jrr)
 			if err != nil {
-				handleError(w, err, msg.ID)
+				h.handleError(w, err, msg.ID)
 				return
 			}
 
 			err = writeMessage(w, ir, &sessionId)
 			if err != nil {
-				handleError(w, err, msg.ID)
+				h.handleError(w, err, msg.ID)
 			}
 			return
 		} else {
 			respErr := protocol.NewInvalidRequestError("missing Mcp-Session-Id, expecting initialize message", msg.ID)
-			handleError(w, respErr, "")
+			h.handleError(w, respErr, "")
 			return
 		}
 	}
@@ -2,14 +2,13 @@ package httphandlers
 
 import (
 	"fmt"
-	"github.com/traego/scaled-mcp/pkg/auth"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"net/http"
-	"strings"
 
 	"github.com/traego/scaled-mcp/pkg/utils"
 
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/tracing"
 )
 
 /*
@@ -20,18 +19,19 @@ Route Message to Session Actor
 // This is backwards compatibility for 2024 for client to server messages
 func (h *MCPHandler) HandleMessagePost(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	defer h.trackInFlight()()
 	sessionId := r.URL.Query().Get("sessionId")
 
 	mcpRequest, err := parseMessageRequest(r)
 	if err != nil {
-		handleError(w, err, "")
+		h.handleError(w, err, "")
 		return
 	}
 
 	if mcpRequest.IsBatch {
 		// in the 2024 spec batch is not allowed
 		respErr := protocol.NewInvalidRequestError("batched json rpc calls are not allowed in the 2024-11-05 spec", "")
-		handleError(w, respErr, "")
+		h.handleError(w, respErr, "")
 		return
 	}
 
@@ -39,7 +39,7 @@ func (h *MCPHandler) HandleMessagePost(w http.ResponseWriter, r *http.Request) {
 
 	protoMsg, err := protocol.ConvertJSONToProtoRequest(mcpRequest.Message)
 	if err != nil {
-		handleError(w, err, mcpRequest)
+		h.handleError(w, err, mcpRequest)
 		return
 	}
 
@@ -47,31 +47,46 @@ func (h *MCPHandler) HandleMessagePost(w http.ResponseWriter, r *http.Request) {
 		IsAsk:                 false,
 		RespondToConnectionId: utils.GetDefaultSSEConnectionName(sessionId),
 		Request:               protoMsg,
-		TraceId:               utils.GetTraceId(ctx),
+		TraceContext:          tracing.Inject(ctx),
 	}
 
-	if ai := auth.GetAuthInfo(ctx); ai != nil && h.serverInfo.GetAuthHandler() != nil {
-		ser, err := h.serverInfo.GetAuthHandler().Serialize(ai)
-		if err != nil {
-			handleError(w, fmt.Errorf("unable to serialize auth"), mcpRequest.Message.ID)
-		}
-		wrapped.AuthInfo = ser
+	authInfo, err := h.serializeAuth(ctx)
+	if err != nil {
+		h.handleError(w, fmt.Errorf("unable to serialize auth"), mcpRequest.Message.ID)
+		return
+	}
+	wrapped.AuthInfo = authInfo
+
+	if err := h.resumeSession(ctx, sessionId); err != nil {
+		h.handleError(w, err, mcpRequest)
+		return
 	}
 
 	_, rid, err := h.actorSystem.ActorOf(ctx, "root")
 	if err != nil {
-		handleError(w, err, mcpRequest)
+		h.handleError(w, err, mcpRequest)
 		return
 	}
 
+	release, ok := h.acquireRateLimit(ctx, w, r, sessionId, protoMsg, mcpRequest.Message.ID)
+	if !ok {
+		return
+	}
+
+	// Requests are answered by the session over the client's SSE stream, however long they take, so their
+	// rate limit slots are only held until they are handed to the session
+	defer release()
 	err = rid.SendAsync(ctx, san, &wrapped)
 	if err != nil {
-		if strings.HasSuffix(err.Error(), " not found") {
+		if h.Draining() && utils.IsActorNotFound(err) {
+			writeDraining(w)
+			return
+		} else if utils.IsActorNotFound(err) {
 			w.WriteHeader(http.StatusNotFound)
 			_, _ = w.Write([]byte(string("session not found")))
 			return
 		} else {
-			handleError(w, err, mcpRequest)
+			h.handleError(w, err, mcpRequest)
 			return
 		}
 	}
@@ -0,0 +1,75 @@
+package httphandlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/traego/scaled-mcp/pkg/audit"
+	"github.com/traego/scaled-mcp/pkg/auth"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/ratelimit"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+)
+
+// acquireRateLimit applies the server's rate limits to a posted message before it is sent to the session actor,
+// so that requests over a limit never reach its mailbox. It returns a function releasing the request's
+// concurrency slots, to be called once its response has been written, or once it was handed to the session for
+// requests answered over an SSE stream. It returns false if the request was rejected with 429 Too Many
+// Requests. If the limiter itself fails the request is let through, so an outage of a shared limit store does
+// not take the server down.
+func (h *MCPHandler) acquireRateLimit(ctx context.Context, w http.ResponseWriter, r *http.Request, sessionId string, req *mcppb.JsonRpcRequest, id interface{}) (func(), bool) {
+	limiter := h.serverInfo.GetRateLimiter()
+	if limiter == nil || !isRateLimited(req.Method) {
+		return func() {}, true
+	}
+
+	principalId := ""
+	if ai := auth.GetAuthInfo(ctx); ai != nil {
+		principalId = ai.GetPrincipalId()
+	}
+
+	limited := ratelimit.RequestFromParams(principalId, sessionId, req.Method, req.ParamsJson)
+	release, err := limiter.Acquire(ctx, limited)
+	if err == nil {
+		return release, true
+	}
+
+	var exceeded *ratelimit.ExceededError
+	if !errors.As(err, &exceeded) {
+		h.logger().ErrorContext(ctx, "rate limiter failed, allowing request", "session_id", sessionId, "err", err)
+		return func() {}, true
+	}
+
+	h.logger().InfoContext(ctx, "request rejected by rate limit", "session_id", sessionId, "method", req.Method, "scope", exceeded.Scope)
+	audit.Emit(ctx, h.serverInfo.GetAuditSink(), &audit.Record{
+		Event:       audit.EventRequest,
+		SessionId:   sessionId,
+		PrincipalId: principalId,
+		TraceId:     tracing.TraceId(ctx),
+		Method:      req.Method,
+		Target:      limited.Tool,
+		Outcome:     audit.OutcomeDenied,
+		ErrorCode:   protocol.ErrRateLimited,
+		Error:       exceeded.Error(),
+		RemoteAddr:  r.RemoteAddr,
+	})
+
+	rpcErr := protocol.NewRateLimitedError(exceeded.Error(), exceeded.RetryAfterSeconds(), id)
+	w.Header().Set("Retry-After", strconv.Itoa(exceeded.RetryAfterSeconds()))
+	_ = writeMessageWithStatus(w, rpcErr.ToResponse(), nil, http.StatusTooManyRequests)
+	return nil, false
+}
+
+// isRateLimited reports whether messages for method count against the rate limits. The session lifecycle and
+// responses to the server's own requests are never limited, so clients can always initialize and shut down.
+func isRateLimited(method string) bool {
+	switch method {
+	case "", "initialize", "notifications/initialized", "shutdown":
+		return false
+	default:
+		return true
+	}
+}
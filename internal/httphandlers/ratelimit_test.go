@@ -0,0 +1,194 @@
+package httphandlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/goakt/v3/actor"
+
+	"github.com/traego/scaled-mcp/internal/actors"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/ratelimit"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// blockingSession is a session actor answering requests once release is closed
+type blockingSession struct {
+	received atomic.Int32
+	release  chan struct{}
+}
+
+func (s *blockingSession) PreStart(context.Context) error { return nil }
+
+func (s *blockingSession) Receive(ctx *actor.ReceiveContext) {
+	msg, ok := ctx.Message().(*mcppb.WrappedRequest)
+	if !ok {
+		return
+	}
+	s.received.Add(1)
+	<-s.release
+	response := &mcppb.JsonRpcResponse{
+		Jsonrpc:  "2.0",
+		Id:       &mcppb.JsonRpcResponse_IntId{IntId: msg.GetRequest().GetIntId()},
+		Response: &mcppb.JsonRpcResponse_ResultJson{ResultJson: "{}"},
+	}
+	if msg.GetIsAsk() {
+		ctx.Response(response)
+		return
+	}
+
+	// Like sessions, answer messages posted by SSE clients over their connection
+	if conn, err := ctx.ActorSystem().LocalActor(msg.GetRespondToConnectionId()); err == nil {
+		ctx.Tell(conn, response)
+	}
+}
+
+func (s *blockingSession) PostStop(context.Context) error { return nil }
+
+// recordingConnection is a client connection actor recording the responses sent to it
+type recordingConnection struct {
+	mu        sync.Mutex
+	responses []*mcppb.JsonRpcResponse
+}
+
+func (c *recordingConnection) PreStart(context.Context) error { return nil }
+
+func (c *recordingConnection) Receive(ctx *actor.ReceiveContext) {
+	if response, ok := ctx.Message().(*mcppb.JsonRpcResponse); ok {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.responses = append(c.responses, response)
+	}
+}
+
+func (c *recordingConnection) PostStop(context.Context) error { return nil }
+
+func (c *recordingConnection) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.responses)
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	actorSystem, err := actor.NewActorSystem("test-system")
+	require.NoError(t, err)
+	require.NoError(t, actorSystem.Start(ctx))
+	defer func() {
+		_ = actorSystem.Stop(ctx)
+	}()
+	_, err = actorSystem.Spawn(ctx, "root", actors.NewRootActor())
+	require.NoError(t, err)
+
+	handler := NewMCPHandler(config.DefaultConfig(), actorSystem, &mockServerInfo{
+		rateLimiter: ratelimit.NewLimiter(ratelimit.Config{
+			Enable:  true,
+			Session: ratelimit.Limit{MaxConcurrent: 1},
+			Methods: map[string]ratelimit.Limit{"tools/list": {RequestsPerSecond: 0.1, Burst: 1}},
+		}, ratelimit.NewMemoryStore()),
+	})
+
+	spawnSession := func(sessionId string) *blockingSession {
+		session := &blockingSession{release: make(chan struct{})}
+		_, err := actorSystem.Spawn(ctx, utils.GetSessionActorName(sessionId), session)
+		require.NoError(t, err)
+		return session
+	}
+
+	body := func(id int, method string) *bytes.Reader {
+		b, err := json.Marshal(protocol.JSONRPCMessage{JSONRPC: "2.0", ID: id, Method: method, Params: map[string]interface{}{}})
+		require.NoError(t, err)
+		return bytes.NewReader(b)
+	}
+	post := func(sessionId string, id int, method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", body(id, method))
+		req.Header.Set("Mcp-Session-Id", sessionId)
+		w := httptest.NewRecorder()
+		handler.HandleMCPPost(w, req)
+		return w
+	}
+	assertRejected := func(t *testing.T, w *httptest.ResponseRecorder, retryAfter string) {
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, retryAfter, w.Header().Get("Retry-After"))
+		var response protocol.JSONRPCMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotNil(t, response.Error)
+		assert.Equal(t, float64(protocol.ErrRateLimited), response.Error.(map[string]interface{})["code"])
+	}
+
+	t.Run("requests over the concurrency cap never reach the session", func(t *testing.T) {
+		session := spawnSession("concurrent")
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- post("concurrent", 1, "tools/call")
+		}()
+		require.Eventually(t, func() bool { return session.received.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+		assertRejected(t, post("concurrent", 2, "tools/call"), "1")
+		assert.Equal(t, int32(1), session.received.Load())
+
+		// The slot is released once the response has been written
+		close(session.release)
+		assert.Equal(t, http.StatusOK, (<-done).Code)
+		assert.Equal(t, http.StatusOK, post("concurrent", 3, "tools/call").Code)
+	})
+
+	t.Run("requests over the rate are rejected", func(t *testing.T) {
+		session := spawnSession("rate")
+		close(session.release)
+
+		assert.Equal(t, http.StatusOK, post("rate", 1, "tools/list").Code)
+		assertRejected(t, post("rate", 2, "tools/list"), "10")
+		assert.Equal(t, int32(1), session.received.Load())
+
+		// The lifecycle is never limited
+		assert.Equal(t, http.StatusOK, post("rate", 3, "shutdown").Code)
+	})
+
+	t.Run("SSE requests are answered however long they take", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.RequestTimeout = 50 * time.Millisecond
+		sseHandler := NewMCPHandler(cfg, actorSystem, &mockServerInfo{
+			rateLimiter: ratelimit.NewLimiter(ratelimit.Config{Enable: true, Session: ratelimit.Limit{MaxConcurrent: 1}}, ratelimit.NewMemoryStore()),
+		})
+
+		session := spawnSession("sse")
+		connection := &recordingConnection{}
+		_, err := actorSystem.Spawn(ctx, utils.GetDefaultSSEConnectionName("sse"), connection)
+		require.NoError(t, err)
+
+		postMessage := func(id int) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			sseHandler.HandleMessagePost(w, httptest.NewRequest(http.MethodPost, "/messages?sessionId=sse", body(id, "tools/call")))
+			return w
+		}
+
+		// The session takes the requests, which hold their slots only until then
+		assert.Equal(t, http.StatusAccepted, postMessage(1).Code)
+		assert.Equal(t, http.StatusAccepted, postMessage(2).Code)
+		require.Eventually(t, func() bool { return session.received.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+		// A tool slower than the request timeout still gets its result to the client
+		time.Sleep(3 * cfg.RequestTimeout)
+		close(session.release)
+		require.Eventually(t, func() bool { return connection.count() == 2 }, time.Second, 5*time.Millisecond)
+		connection.mu.Lock()
+		defer connection.mu.Unlock()
+		for _, response := range connection.responses {
+			assert.Nil(t, response.GetError())
+			assert.Equal(t, "{}", response.GetResultJson())
+		}
+	})
+}
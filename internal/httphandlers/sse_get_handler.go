@@ -6,6 +6,7 @@ import (
 
 	actors2 "github.com/traego/scaled-mcp/internal/actors"
 	"github.com/traego/scaled-mcp/internal/channels"
+	"github.com/traego/scaled-mcp/pkg/metrics"
 	"github.com/traego/scaled-mcp/pkg/utils"
 )
 
@@ -27,17 +28,27 @@ func (h *MCPHandler) SSEGetFunc(w http.ResponseWriter, r *http.Request, basePath
 	// err will be reused throughout this function
 	var err error
 
+	// A stream opened now would only be closed again once the node drains
+	if h.Draining() {
+		writeDraining(w)
+		return
+	}
+
 	// Attempt to retrieve the session ID from cookie (set during initial connection)
 	var sessionId string
 	cookie, cerr := r.Cookie("mcp_session_id")
 	if cerr == nil && cookie != nil && cookie.Value != "" {
 		sessionId = cookie.Value
+		if err = h.resumeSession(ctx, sessionId); err != nil {
+			h.handleError(w, err, "")
+			return
+		}
 	} else {
 		// Fallback to generating a fresh secure session ID
 		var gerr error
 		sessionId, gerr = utils.GenerateSecureID(20)
 		if gerr != nil {
-			handleError(w, gerr, "")
+			h.handleError(w, gerr, "")
 			return
 		}
 	}
@@ -47,10 +58,15 @@ func (h *MCPHandler) SSEGetFunc(w http.ResponseWriter, r *http.Request, basePath
 	// Ensure the session actor exists; spawn only if we don't find it running
 	_, existingPid, _ := h.actorSystem.ActorOf(ctx, san)
 	if existingPid == nil {
-		sa := actors2.NewMcpSessionStateMachine(h.serverInfo, sessionId)
-		_, err = h.actorSystem.Spawn(ctx, san, sa)
+		authInfo, err := h.serializeAuth(ctx)
+		if err != nil {
+			h.handleError(w, fmt.Errorf("unable to serialize auth"), "")
+			return
+		}
+		sa := actors2.NewMcpSessionStateMachine(h.serverInfo, sessionId, authInfo)
+		_, err = h.actorSystem.Spawn(ctx, san, sa, actors2.SessionSpawnOptions(h.serverInfo.GetMetrics())...)
 		if err != nil {
-			handleError(w, err, "")
+			h.handleError(w, err, "")
 			return
 		}
 	}
@@ -58,17 +74,17 @@ func (h *MCPHandler) SSEGetFunc(w http.ResponseWriter, r *http.Request, basePath
 	// Create an SSE channel for communication
 	channel := channels.NewSSEChannel(w, r, sessionId)
 
-	cca := actors2.NewClientConnectionActor(h.config, sessionId, nil, channel, true, true, basePath)
-	clientActorName := fmt.Sprintf("%s-client", sessionId)
-	clientActor, err := h.actorSystem.Spawn(ctx, clientActorName, cca)
+	cca := actors2.NewClientConnectionActor(h.config, sessionId, nil, channel, true, true, basePath, h.serverInfo.GetMetrics())
+	clientActorName := utils.GetSSEClientActorName(sessionId)
+	clientActor, err := h.actorSystem.Spawn(ctx, clientActorName, cca, actors2.WithInstrumentedMailbox(h.serverInfo.GetMetrics(), metrics.ActorClientConnection))
 	if err != nil {
 		respErr := fmt.Errorf("error spawning sse session: %w", err)
-		handleError(w, respErr, "")
+		h.handleError(w, respErr, "")
 	}
 
 	_, dc, err := actors2.SpawnDeathWatcher(ctx, h.actorSystem, clientActor)
 	if err != nil {
-		handleError(w, err, "")
+		h.handleError(w, err, "")
 	}
 
 	select {
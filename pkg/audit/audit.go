@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Audited events
+const (
+	// EventInitialize is recorded when a client initializes a session
+	EventInitialize = "initialize"
+	// EventRequest is recorded for every request handled by an executor
+	EventRequest = "request"
+	// EventSessionTerminated is recorded when a session ends
+	EventSessionTerminated = "session_terminated"
+	// EventAuthFailure is recorded when a request's credentials are rejected
+	EventAuthFailure = "auth_failure"
+)
+
+// Outcomes of an audited event
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomeDenied  = "denied"
+)
+
+// MaxResultSize is the largest result, in bytes, copied into a record. Larger results are replaced by a
+// truncation marker.
+const MaxResultSize = 64 * 1024
+
+// Record is a single audit entry
+type Record struct {
+	Time        time.Time       `json:"time"`
+	Event       string          `json:"event"`
+	SessionId   string          `json:"session_id,omitempty"`
+	PrincipalId string          `json:"principal_id,omitempty"`
+	TraceId     string          `json:"trace_id,omitempty"`
+	Method      string          `json:"method,omitempty"`
+	Target      string          `json:"target,omitempty"`
+	DurationMs  float64         `json:"duration_ms"`
+	Outcome     string          `json:"outcome"`
+	ErrorCode   int             `json:"error_code,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+	RemoteAddr  string          `json:"remote_addr,omitempty"`
+	Params      json.RawMessage `json:"params,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Truncated   bool            `json:"truncated,omitempty"`
+}
+
+// SetDuration records the elapsed time since start
+func (r *Record) SetDuration(start time.Time) {
+	r.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+}
+
+// SetParams stores params, which should already be redacted, as JSON
+func (r *Record) SetParams(params interface{}) {
+	if params == nil {
+		return
+	}
+	if b, err := json.Marshal(params); err == nil {
+		r.Params = b
+	}
+}
+
+// SetResult stores a JSON result, replacing it with a marker if it is larger than MaxResultSize
+func (r *Record) SetResult(result string) {
+	if result == "" {
+		return
+	}
+	if len(result) > MaxResultSize {
+		r.Truncated = true
+		return
+	}
+	if !json.Valid([]byte(result)) {
+		return
+	}
+	r.Result = json.RawMessage(result)
+}
+
+// Sink receives audit records. Sinks are called synchronously from session actors, so implementations
+// should be fast and buffer internally if they talk to slow backends. Implementations must be safe for
+// concurrent use.
+type Sink interface {
+	Write(ctx context.Context, record *Record) error
+}
+
+// SinkFunc adapts a function to a Sink, for plugging in custom destinations
+type SinkFunc func(ctx context.Context, record *Record) error
+
+// Write implements Sink
+func (f SinkFunc) Write(ctx context.Context, record *Record) error {
+	return f(ctx, record)
+}
+
+// MultiSink writes every record to each of its sinks
+type MultiSink []Sink
+
+// Write implements Sink. All sinks are written to even if some fail.
+func (m MultiSink) Write(ctx context.Context, record *Record) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Write(ctx, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Emit writes a record to the sink, stamping its time if unset. A nil sink is a no-op, and failures are
+// logged rather than returned so that auditing never breaks request handling.
+func Emit(ctx context.Context, sink Sink, record *Record) {
+	if sink == nil {
+		return
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	if err := sink.Write(ctx, record); err != nil {
+		slog.ErrorContext(ctx, "failed to write audit record", "event", record.Event, "session_id", record.SessionId, "err", err)
+	}
+}
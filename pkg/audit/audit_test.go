@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readLines(t *testing.T, data []byte) []Record {
+	var records []Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONLinesSink(&buf)
+
+	record := &Record{Event: EventRequest, SessionId: "s1", PrincipalId: "svc", Method: "tools/call", Target: "search", Outcome: OutcomeSuccess}
+	record.SetParams(map[string]interface{}{"name": "search"})
+	record.SetResult(`{"content":[]}`)
+
+	Emit(context.Background(), sink, record)
+	Emit(context.Background(), sink, &Record{Event: EventSessionTerminated, SessionId: "s1", Outcome: OutcomeSuccess, Reason: "shutdown"})
+
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+
+	records := readLines(t, buf.Bytes())
+	require.Len(t, records, 2)
+	assert.Equal(t, "search", records[0].Target)
+	assert.False(t, records[0].Time.IsZero(), "Emit should stamp the time")
+	assert.JSONEq(t, `{"name":"search"}`, string(records[0].Params))
+	assert.JSONEq(t, `{"content":[]}`, string(records[0].Result))
+	assert.Equal(t, "shutdown", records[1].Reason)
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	Emit(context.Background(), sink, &Record{Event: EventInitialize, Outcome: OutcomeSuccess})
+	require.NoError(t, sink.Close())
+
+	sink, err = NewFileSink(path)
+	require.NoError(t, err)
+	Emit(context.Background(), sink, &Record{Event: EventRequest, Outcome: OutcomeError})
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records := readLines(t, data)
+	require.Len(t, records, 2)
+	assert.Equal(t, EventInitialize, records[0].Event)
+	assert.Equal(t, EventRequest, records[1].Event)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestMultiSink(t *testing.T) {
+	var got []string
+	ok := SinkFunc(func(ctx context.Context, r *Record) error {
+		got = append(got, r.Event)
+		return nil
+	})
+	failing := SinkFunc(func(ctx context.Context, r *Record) error {
+		return errors.New("backend down")
+	})
+
+	err := MultiSink{failing, ok}.Write(context.Background(), &Record{Event: EventAuthFailure})
+	assert.ErrorContains(t, err, "backend down")
+	assert.Equal(t, []string{EventAuthFailure}, got, "later sinks should still be written to")
+
+	// Emit swallows sink failures and tolerates a nil sink
+	Emit(context.Background(), failing, &Record{Event: EventRequest})
+	Emit(context.Background(), nil, &Record{Event: EventRequest})
+}
+
+func TestRecordHelpers(t *testing.T) {
+	r := &Record{}
+
+	r.SetResult(strings.Repeat("x", MaxResultSize+1))
+	assert.True(t, r.Truncated)
+	assert.Nil(t, r.Result)
+
+	r = &Record{}
+	r.SetResult("not json")
+	assert.Nil(t, r.Result)
+
+	r.SetParams(nil)
+	assert.Nil(t, r.Params)
+
+	r.SetDuration(time.Now().Add(-1500 * time.Millisecond))
+	assert.InDelta(t, 1500, r.DurationMs, 100)
+}
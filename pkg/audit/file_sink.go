@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// JSONLinesSink writes each record as a single line of JSON
+type JSONLinesSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLinesSink writes records to w
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+// NewFileSink appends records to the file at path, creating it if needed
+func NewFileSink(path string) (*JSONLinesSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &JSONLinesSink{w: f, closer: f}, nil
+}
+
+// Write implements Sink
+func (s *JSONLinesSink) Write(_ context.Context, record *Record) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A single write per record keeps lines intact when several processes append to the same file
+	if _, err := s.w.Write(b); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying file, if the sink owns one
+func (s *JSONLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closer.Close()
+}
+
+var _ Sink = (*JSONLinesSink)(nil)
+var _ Sink = SinkFunc(nil)
+var _ Sink = MultiSink(nil)
@@ -0,0 +1,377 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix is prepended to every key minted by an APIKeyManager
+const APIKeyPrefix = "smk"
+
+// APIKeyHeader is the dedicated header API keys may be sent in
+const APIKeyHeader = "X-API-Key"
+
+var (
+	// ErrAPIKeyNotFound is returned by stores when a key id does not exist
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey is returned when a presented key is malformed, unknown or does not match its hash
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyExpired is returned when a presented key is past its expiry
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrAPIKeyRevoked is returned when a presented key has been revoked
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+	// ErrMissingCredentials is returned when a request carries no credentials and they are required
+	ErrMissingCredentials = errors.New("missing credentials")
+)
+
+// APIKey is the stored record for an API key. The secret itself is never stored, only a salted hash of it.
+type APIKey struct {
+	Id          string     `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	PrincipalId string     `json:"principal_id"`
+	Scopes      []string   `json:"scopes,omitempty"`
+	Salt        string     `json:"salt,omitempty"`
+	Hash        string     `json:"hash,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Revoked     bool       `json:"revoked"`
+	RotatedFrom string     `json:"rotated_from,omitempty"`
+}
+
+// IsExpired reports whether the key has expired at the given time
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// Matches reports whether the secret hashes to the stored hash
+func (k *APIKey) Matches(secret string) bool {
+	expected, err := hex.DecodeString(k.Hash)
+	if err != nil {
+		return false
+	}
+	actual := hashSecret(k.Salt, secret)
+	return subtle.ConstantTimeCompare(expected, actual) == 1
+}
+
+// Redacted returns a copy of the key without its salt and hash, suitable for returning from APIs
+func (k *APIKey) Redacted() *APIKey {
+	c := *k
+	c.Salt = ""
+	c.Hash = ""
+	return &c
+}
+
+// APIKeyAuthInfo is the AuthInfo produced from a valid API key
+type APIKeyAuthInfo struct {
+	PrincipalId string   `json:"principal_id"`
+	KeyId       string   `json:"key_id"`
+	Scopes      []string `json:"scopes,omitempty"`
+}
+
+// GetPrincipalId implements AuthInfo
+func (a *APIKeyAuthInfo) GetPrincipalId() string {
+	return a.PrincipalId
+}
+
+// HasScope reports whether the key was granted the given scope
+func (a *APIKeyAuthInfo) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
+}
+
+var _ AuthInfo = (*APIKeyAuthInfo)(nil)
+
+// MintRequest describes a new API key
+type MintRequest struct {
+	Name        string
+	PrincipalId string
+	Scopes      []string
+	// TTL is the lifetime of the key; zero means the key does not expire
+	TTL time.Duration
+}
+
+// APIKeyManager mints, verifies, rotates and revokes API keys held in an APIKeyStore
+type APIKeyManager struct {
+	store APIKeyStore
+	now   func() time.Time
+}
+
+// NewAPIKeyManager creates a manager backed by the given store
+func NewAPIKeyManager(store APIKeyStore) *APIKeyManager {
+	return &APIKeyManager{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Store returns the underlying key store
+func (m *APIKeyManager) Store() APIKeyStore {
+	return m.store
+}
+
+// Mint creates and stores a new key. The returned plaintext key is only available here; it cannot be
+// recovered from the store later.
+func (m *APIKeyManager) Mint(ctx context.Context, req MintRequest) (string, *APIKey, error) {
+	return m.mint(ctx, req, "")
+}
+
+func (m *APIKeyManager) mint(ctx context.Context, req MintRequest, rotatedFrom string) (string, *APIKey, error) {
+	if req.PrincipalId == "" {
+		return "", nil, errors.New("principal id is required")
+	}
+
+	id, err := randomString(12)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+	salt, err := randomString(16)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Key ids are embedded in the plaintext key, so keep them free of the separator
+	id = strings.ReplaceAll(id, "_", "-")
+
+	now := m.now().UTC()
+	key := &APIKey{
+		Id:          id,
+		Name:        req.Name,
+		PrincipalId: req.PrincipalId,
+		Scopes:      req.Scopes,
+		Salt:        salt,
+		Hash:        hex.EncodeToString(hashSecret(salt, secret)),
+		CreatedAt:   now,
+		RotatedFrom: rotatedFrom,
+	}
+	if req.TTL > 0 {
+		expires := now.Add(req.TTL)
+		key.ExpiresAt = &expires
+	}
+
+	if err := m.store.Put(ctx, key); err != nil {
+		return "", nil, fmt.Errorf("failed to store api key: %w", err)
+	}
+
+	return formatAPIKey(id, secret), key, nil
+}
+
+// Verify checks a plaintext key against the store and returns its record
+func (m *APIKeyManager) Verify(ctx context.Context, plaintext string) (*APIKey, error) {
+	id, secret, ok := parseAPIKey(plaintext)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := m.store.Get(ctx, id)
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.Matches(secret) {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.Revoked {
+		return nil, ErrAPIKeyRevoked
+	}
+	if key.IsExpired(m.now()) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	return key, nil
+}
+
+// Revoke marks a key as revoked. Revoked keys are kept in the store so that they show up in listings.
+func (m *APIKeyManager) Revoke(ctx context.Context, id string) error {
+	key, err := m.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	key.Revoked = true
+	return m.store.Put(ctx, key)
+}
+
+// Rotate mints a replacement for an existing key with the same principal and scopes. The old key keeps
+// working for the overlap period so callers can roll over without downtime; an overlap of zero expires
+// it immediately.
+func (m *APIKeyManager) Rotate(ctx context.Context, id string, overlap time.Duration) (string, *APIKey, error) {
+	old, err := m.store.Get(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if old.Revoked {
+		return "", nil, ErrAPIKeyRevoked
+	}
+
+	req := MintRequest{
+		Name:        old.Name,
+		PrincipalId: old.PrincipalId,
+		Scopes:      old.Scopes,
+	}
+	if old.ExpiresAt != nil {
+		// Keep the original lifetime
+		req.TTL = old.ExpiresAt.Sub(old.CreatedAt)
+	}
+
+	plaintext, key, err := m.mint(ctx, req, old.Id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cutoff := m.now().UTC().Add(overlap)
+	if old.ExpiresAt == nil || cutoff.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &cutoff
+		if err := m.store.Put(ctx, old); err != nil {
+			return "", nil, fmt.Errorf("failed to update rotated api key: %w", err)
+		}
+	}
+
+	return plaintext, key, nil
+}
+
+// List returns the keys for a principal, or all keys if principalId is empty
+func (m *APIKeyManager) List(ctx context.Context, principalId string) ([]*APIKey, error) {
+	return m.store.List(ctx, principalId)
+}
+
+// APIKeyAuthHandler authenticates requests carrying an API key in the Authorization header
+// (as "Bearer <key>" or "ApiKey <key>") or in the X-API-Key header
+type APIKeyAuthHandler struct {
+	manager  *APIKeyManager
+	required bool
+}
+
+// APIKeyOption configures an APIKeyAuthHandler
+type APIKeyOption func(*APIKeyAuthHandler)
+
+// WithAPIKeyRequired rejects requests that do not carry a key at all. By default such requests are passed
+// through without AuthInfo.
+func WithAPIKeyRequired(required bool) APIKeyOption {
+	return func(h *APIKeyAuthHandler) {
+		h.required = required
+	}
+}
+
+// NewAPIKeyAuthHandler creates a new API key auth handler
+func NewAPIKeyAuthHandler(manager *APIKeyManager, opts ...APIKeyOption) *APIKeyAuthHandler {
+	h := &APIKeyAuthHandler{
+		manager: manager,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ExtractAuth returns the AuthInfo for a valid key, or nil if the request has no valid key
+func (h *APIKeyAuthHandler) ExtractAuth(r *http.Request) AuthInfo {
+	ai, err := h.VerifyAuth(r)
+	if err != nil {
+		return nil
+	}
+	return ai
+}
+
+// VerifyAuth returns the AuthInfo for the request's key. Requests presenting an invalid, expired or revoked
+// key are rejected with an error, as are requests without a key when one is required.
+func (h *APIKeyAuthHandler) VerifyAuth(r *http.Request) (AuthInfo, error) {
+	plaintext := APIKeyFromRequest(r)
+	if plaintext == "" {
+		if h.required {
+			return nil, ErrMissingCredentials
+		}
+		return nil, nil
+	}
+
+	key, err := h.manager.Verify(r.Context(), plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIKeyAuthInfo{
+		PrincipalId: key.PrincipalId,
+		KeyId:       key.Id,
+		Scopes:      key.Scopes,
+	}, nil
+}
+
+// Serialize encodes API key auth info for transfer to the session actor
+func (h *APIKeyAuthHandler) Serialize(ai AuthInfo) ([]byte, error) {
+	ki, ok := ai.(*APIKeyAuthInfo)
+	if !ok {
+		return nil, errors.New("invalid auth type")
+	}
+	return json.Marshal(ki)
+}
+
+// Deserialize decodes API key auth info produced by Serialize
+func (h *APIKeyAuthHandler) Deserialize(b []byte) (AuthInfo, error) {
+	var ki APIKeyAuthInfo
+	if err := json.Unmarshal(b, &ki); err != nil {
+		return nil, fmt.Errorf("failed to decode api key auth info: %w", err)
+	}
+	return &ki, nil
+}
+
+// APIKeyFromRequest returns the API key presented by a request, or an empty string if there is none
+func APIKeyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+
+	scheme, value, ok := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !ok {
+		return ""
+	}
+	if strings.EqualFold(scheme, "Bearer") || strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
+
+func formatAPIKey(id, secret string) string {
+	return APIKeyPrefix + "_" + id + "_" + secret
+}
+
+func parseAPIKey(plaintext string) (string, string, bool) {
+	prefix, rest, ok := strings.Cut(plaintext, "_")
+	if !ok || prefix != APIKeyPrefix {
+		return "", "", false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func hashSecret(salt, secret string) []byte {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return sum[:]
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
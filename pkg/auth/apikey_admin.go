@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AdminAuthorizer decides whether a request may use the API key admin endpoints
+type AdminAuthorizer func(r *http.Request) bool
+
+// RequireAPIKeyScope authorizes admin requests that present a valid API key carrying the given scope
+func RequireAPIKeyScope(manager *APIKeyManager, scope string) AdminAuthorizer {
+	return func(r *http.Request) bool {
+		plaintext := APIKeyFromRequest(r)
+		if plaintext == "" {
+			return false
+		}
+		key, err := manager.Verify(r.Context(), plaintext)
+		if err != nil {
+			return false
+		}
+		return (&APIKeyAuthInfo{Scopes: key.Scopes}).HasScope(scope)
+	}
+}
+
+type mintKeyRequest struct {
+	Name        string   `json:"name,omitempty"`
+	PrincipalId string   `json:"principal_id"`
+	Scopes      []string `json:"scopes,omitempty"`
+	TTL         string   `json:"ttl,omitempty"`
+}
+
+type rotateKeyRequest struct {
+	Overlap string `json:"overlap,omitempty"`
+}
+
+type mintKeyResponse struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
+
+type listKeysResponse struct {
+	Keys []*APIKey `json:"keys"`
+}
+
+// NewAPIKeyAdminHandler returns an http.Handler exposing a small admin API over the manager:
+//
+//	POST   /keys              mint a key: {"principal_id", "name", "scopes", "ttl"}
+//	GET    /keys              list keys, optionally filtered with ?principal_id=
+//	GET    /keys/{id}         fetch a key
+//	DELETE /keys/{id}         revoke a key
+//	POST   /keys/{id}/rotate  mint a replacement: {"overlap"}; the old key stays valid for the overlap
+//
+// Durations are Go duration strings such as "720h". Responses never include key hashes; the plaintext key
+// is only returned when it is minted. Requests are rejected with 403 unless authorize allows them, so a nil
+// authorizer is only appropriate when the handler is mounted behind other access control.
+func NewAPIKeyAdminHandler(manager *APIKeyManager, authorize AdminAuthorizer) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if authorize != nil && !authorize(req) {
+				writeAdminError(w, http.StatusForbidden, errors.New("forbidden"))
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+
+	r.Post("/keys", func(w http.ResponseWriter, req *http.Request) {
+		var body mintKeyRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+
+		ttl, err := parseAdminDuration(body.TTL)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid ttl: %w", err))
+			return
+		}
+
+		plaintext, key, err := manager.Mint(req.Context(), MintRequest{
+			Name:        body.Name,
+			PrincipalId: body.PrincipalId,
+			Scopes:      body.Scopes,
+			TTL:         ttl,
+		})
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeAdminJSON(w, http.StatusCreated, mintKeyResponse{Key: plaintext, APIKey: key.Redacted()})
+	})
+
+	r.Get("/keys", func(w http.ResponseWriter, req *http.Request) {
+		keys, err := manager.List(req.Context(), req.URL.Query().Get("principal_id"))
+		if err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp := listKeysResponse{Keys: make([]*APIKey, 0, len(keys))}
+		for _, k := range keys {
+			resp.Keys = append(resp.Keys, k.Redacted())
+		}
+		writeAdminJSON(w, http.StatusOK, resp)
+	})
+
+	r.Get("/keys/{id}", func(w http.ResponseWriter, req *http.Request) {
+		key, err := manager.Store().Get(req.Context(), chi.URLParam(req, "id"))
+		if err != nil {
+			writeAdminStoreError(w, err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, key.Redacted())
+	})
+
+	r.Delete("/keys/{id}", func(w http.ResponseWriter, req *http.Request) {
+		if err := manager.Revoke(req.Context(), chi.URLParam(req, "id")); err != nil {
+			writeAdminStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	r.Post("/keys/{id}/rotate", func(w http.ResponseWriter, req *http.Request) {
+		var body rotateKeyRequest
+		if req.ContentLength != 0 {
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+				return
+			}
+		}
+
+		overlap, err := parseAdminDuration(body.Overlap)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid overlap: %w", err))
+			return
+		}
+
+		plaintext, key, err := manager.Rotate(req.Context(), chi.URLParam(req, "id"), overlap)
+		if err != nil {
+			writeAdminStoreError(w, err)
+			return
+		}
+
+		writeAdminJSON(w, http.StatusCreated, mintKeyResponse{Key: plaintext, APIKey: key.Redacted()})
+	})
+
+	return r
+}
+
+func parseAdminDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, errors.New("duration must not be negative")
+	}
+	return d, nil
+}
+
+func writeAdminStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrAPIKeyNotFound):
+		writeAdminError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrAPIKeyRevoked):
+		writeAdminError(w, http.StatusConflict, err)
+	default:
+		writeAdminError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adminRequest(t *testing.T, h http.Handler, method, path, body string, headers map[string]string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	r := httptest.NewRequest(method, path, reader)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var decoded map[string]interface{}
+	if w.Body.Len() > 0 {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+	}
+	return w, decoded
+}
+
+func TestAPIKeyAdminHandler(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager()
+	h := NewAPIKeyAdminHandler(m, nil)
+
+	w, body := adminRequest(t, h, http.MethodPost, "/keys", `{"principal_id":"svc","scopes":["tools:call"],"ttl":"24h"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	plaintext := body["key"].(string)
+	minted := body["api_key"].(map[string]interface{})
+	id := minted["id"].(string)
+	assert.NotContains(t, minted, "hash")
+	assert.Empty(t, minted["salt"])
+	assert.NotEmpty(t, minted["expires_at"])
+
+	_, err := m.Verify(ctx, plaintext)
+	require.NoError(t, err)
+
+	w, body = adminRequest(t, h, http.MethodGet, "/keys?principal_id=svc", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, body["keys"], 1)
+	assert.NotContains(t, w.Body.String(), plaintext)
+
+	w, body = adminRequest(t, h, http.MethodGet, "/keys/"+id, "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "svc", body["principal_id"])
+
+	w, body = adminRequest(t, h, http.MethodPost, "/keys/"+id+"/rotate", `{"overlap":"1h"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	rotated := body["key"].(string)
+	assert.NotEqual(t, plaintext, rotated)
+
+	_, err = m.Verify(ctx, plaintext)
+	assert.NoError(t, err, "the old key is still valid during the overlap")
+	_, err = m.Verify(ctx, rotated)
+	assert.NoError(t, err)
+
+	w, _ = adminRequest(t, h, http.MethodDelete, "/keys/"+id, "", nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	_, err = m.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrAPIKeyRevoked)
+
+	w, _ = adminRequest(t, h, http.MethodDelete, "/keys/missing", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = adminRequest(t, h, http.MethodPost, "/keys/"+id+"/rotate", "", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w, _ = adminRequest(t, h, http.MethodPost, "/keys", `{"principal_id":"svc","ttl":"soon"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = adminRequest(t, h, http.MethodPost, "/keys", `{"scopes":["x"]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIKeyAdminRequiresScope(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager()
+	h := NewAPIKeyAdminHandler(m, RequireAPIKeyScope(m, "apikeys:admin"))
+
+	admin, _, err := m.Mint(ctx, MintRequest{PrincipalId: "ops", Scopes: []string{"apikeys:admin"}})
+	require.NoError(t, err)
+	user, _, err := m.Mint(ctx, MintRequest{PrincipalId: "svc"})
+	require.NoError(t, err)
+
+	w, _ := adminRequest(t, h, http.MethodGet, "/keys", "", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, _ = adminRequest(t, h, http.MethodGet, "/keys", "", map[string]string{APIKeyHeader: user})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w, body := adminRequest(t, h, http.MethodGet, "/keys", "", map[string]string{"Authorization": "Bearer " + admin})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, body["keys"], 2)
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisAPIKeyPrefix is the key prefix used by RedisAPIKeyStore when none is given
+const DefaultRedisAPIKeyPrefix = "mcp:apikey:"
+
+// RedisAPIKeyStore keeps keys in Redis so they are shared by every node in a cluster. Each key is stored as
+// JSON under <prefix>key:<id>, and a set per principal indexes the key ids.
+type RedisAPIKeyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisAPIKeyStore creates a store using the given client. An empty prefix uses DefaultRedisAPIKeyPrefix.
+func NewRedisAPIKeyStore(client redis.UniversalClient, prefix string) *RedisAPIKeyStore {
+	if prefix == "" {
+		prefix = DefaultRedisAPIKeyPrefix
+	}
+	return &RedisAPIKeyStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisAPIKeyStore) keyName(id string) string {
+	return s.prefix + "key:" + id
+}
+
+func (s *RedisAPIKeyStore) principalSet(principalId string) string {
+	return s.prefix + "principal:" + principalId
+}
+
+func (s *RedisAPIKeyStore) allSet() string {
+	return s.prefix + "all"
+}
+
+// Get implements APIKeyStore
+func (s *RedisAPIKeyStore) Get(ctx context.Context, id string) (*APIKey, error) {
+	b, err := s.client.Get(ctx, s.keyName(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api key: %w", err)
+	}
+
+	var key APIKey
+	if err := json.Unmarshal(b, &key); err != nil {
+		return nil, fmt.Errorf("failed to decode api key: %w", err)
+	}
+	return &key, nil
+}
+
+// putAttempts is how many times Put retries when the key changes while it is being written
+const putAttempts = 5
+
+// Put implements APIKeyStore. When the key moves to another principal it is removed from the old principal's
+// set in the same transaction.
+func (s *RedisAPIKeyStore) Put(ctx context.Context, key *APIKey) error {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode api key: %w", err)
+	}
+
+	name := s.keyName(key.Id)
+	put := func(tx *redis.Tx) error {
+		var previous APIKey
+		old, err := tx.Get(ctx, name).Bytes()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		if err == nil {
+			if err := json.Unmarshal(old, &previous); err != nil {
+				return fmt.Errorf("failed to decode api key: %w", err)
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, name, b, 0)
+			if old != nil && previous.PrincipalId != key.PrincipalId {
+				pipe.SRem(ctx, s.principalSet(previous.PrincipalId), key.Id)
+			}
+			pipe.SAdd(ctx, s.principalSet(key.PrincipalId), key.Id)
+			pipe.SAdd(ctx, s.allSet(), key.Id)
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < putAttempts; i++ {
+		err = s.client.Watch(ctx, put, name)
+		if !errors.Is(err, redis.TxFailedErr) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store api key: %w", err)
+	}
+	return nil
+}
+
+// List implements APIKeyStore
+func (s *RedisAPIKeyStore) List(ctx context.Context, principalId string) ([]*APIKey, error) {
+	set := s.allSet()
+	if principalId != "" {
+		set = s.principalSet(principalId)
+	}
+
+	ids, err := s.client.SMembers(ctx, set).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make(map[string]*APIKey, len(ids))
+	for _, id := range ids {
+		key, err := s.Get(ctx, id)
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys[id] = key
+	}
+
+	return filterKeys(keys, principalId), nil
+}
+
+// Delete implements APIKeyStore
+func (s *RedisAPIKeyStore) Delete(ctx context.Context, id string) error {
+	key, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.keyName(id))
+		pipe.SRem(ctx, s.principalSet(key.PrincipalId), id)
+		pipe.SRem(ctx, s.allSet(), id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete api key: %w", err)
+	}
+	return nil
+}
+
+var _ APIKeyStore = (*RedisAPIKeyStore)(nil)
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) redis.UniversalClient {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+func TestRedisAPIKeyStore(t *testing.T) {
+	testAPIKeyStore(t, NewRedisAPIKeyStore(newTestRedis(t), ""))
+}
+
+func TestRedisAPIKeyStoreReassignsPrincipal(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedis(t)
+	store := NewRedisAPIKeyStore(client, "")
+
+	key := &APIKey{Id: "a", PrincipalId: "old", Salt: "s", Hash: "h"}
+	require.NoError(t, store.Put(ctx, key))
+	key.PrincipalId = "new"
+	require.NoError(t, store.Put(ctx, key))
+
+	// The old principal's index no longer holds the key
+	members, err := client.SMembers(ctx, store.principalSet("old")).Result()
+	require.NoError(t, err)
+	assert.Empty(t, members)
+	members, err = client.SMembers(ctx, store.principalSet("new")).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, members)
+}
+
+func TestRedisAPIKeyStoreSharedBetweenNodes(t *testing.T) {
+	ctx := context.Background()
+	client := newTestRedis(t)
+
+	nodeA := NewAPIKeyManager(NewRedisAPIKeyStore(client, "test:"))
+	nodeB := NewAPIKeyManager(NewRedisAPIKeyStore(client, "test:"))
+
+	plaintext, key, err := nodeA.Mint(ctx, MintRequest{PrincipalId: "svc"})
+	require.NoError(t, err)
+
+	_, err = nodeB.Verify(ctx, plaintext)
+	require.NoError(t, err)
+
+	require.NoError(t, nodeB.Revoke(ctx, key.Id))
+	_, err = nodeA.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrAPIKeyRevoked)
+
+	// Stores with a different prefix do not see each other's keys
+	isolated := NewAPIKeyManager(NewRedisAPIKeyStore(client, "other:"))
+	_, err = isolated.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
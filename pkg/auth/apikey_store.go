@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// APIKeyStore persists API key records. Implementations must be safe for concurrent use.
+type APIKeyStore interface {
+	// Get returns the key with the given id, or ErrAPIKeyNotFound
+	Get(ctx context.Context, id string) (*APIKey, error)
+	// Put creates or replaces a key
+	Put(ctx context.Context, key *APIKey) error
+	// List returns the keys for a principal, or all keys if principalId is empty
+	List(ctx context.Context, principalId string) ([]*APIKey, error)
+	// Delete removes a key entirely
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryAPIKeyStore is an in-process APIKeyStore, mostly useful for tests and single node deployments
+type MemoryAPIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*APIKey
+}
+
+// NewMemoryAPIKeyStore creates an empty in-memory store
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{
+		keys: make(map[string]*APIKey),
+	}
+}
+
+// Get implements APIKeyStore
+func (s *MemoryAPIKeyStore) Get(_ context.Context, id string) (*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	c := *key
+	return &c, nil
+}
+
+// Put implements APIKeyStore
+func (s *MemoryAPIKeyStore) Put(_ context.Context, key *APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *key
+	s.keys[key.Id] = &c
+	return nil
+}
+
+// List implements APIKeyStore
+func (s *MemoryAPIKeyStore) List(_ context.Context, principalId string) ([]*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filterKeys(s.keys, principalId), nil
+}
+
+// Delete implements APIKeyStore
+func (s *MemoryAPIKeyStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[id]; !ok {
+		return ErrAPIKeyNotFound
+	}
+	delete(s.keys, id)
+	return nil
+}
+
+// FileAPIKeyStore keeps keys in a JSON file. Writes replace the file atomically, and the file is re-read
+// whenever its modification time changes so that edits from other processes are picked up.
+type FileAPIKeyStore struct {
+	path    string
+	mu      sync.RWMutex
+	keys    map[string]*APIKey
+	modTime time.Time
+}
+
+type apiKeyFile struct {
+	Keys []*APIKey `json:"keys"`
+}
+
+// NewFileAPIKeyStore opens (or creates on first write) a file-backed store at path
+func NewFileAPIKeyStore(path string) (*FileAPIKeyStore, error) {
+	s := &FileAPIKeyStore{
+		path: path,
+		keys: make(map[string]*APIKey),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileAPIKeyStore) load() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat api key file: %w", err)
+	}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read api key file: %w", err)
+	}
+
+	var f apiKeyFile
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &f); err != nil {
+			return fmt.Errorf("failed to parse api key file: %w", err)
+		}
+	}
+
+	keys := make(map[string]*APIKey, len(f.Keys))
+	for _, k := range f.Keys {
+		keys[k.Id] = k
+	}
+
+	s.keys = keys
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// refresh reloads the file if it was modified since it was last read
+func (s *FileAPIKeyStore) refresh() error {
+	s.mu.RLock()
+	info, err := os.Stat(s.path)
+	stale := err == nil && !info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+func (s *FileAPIKeyStore) save() error {
+	f := apiKeyFile{Keys: make([]*APIKey, 0, len(s.keys))}
+	for _, k := range s.keys {
+		f.Keys = append(f.Keys, k)
+	}
+	sort.Slice(f.Keys, func(i, j int) bool {
+		return f.Keys[i].Id < f.Keys[j].Id
+	})
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode api keys: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write api key file: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write api key file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write api key file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace api key file: %w", err)
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// Get implements APIKeyStore
+func (s *FileAPIKeyStore) Get(_ context.Context, id string) (*APIKey, error) {
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	c := *key
+	return &c, nil
+}
+
+// Put implements APIKeyStore
+func (s *FileAPIKeyStore) Put(_ context.Context, key *APIKey) error {
+	if err := s.refresh(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := *key
+	s.keys[key.Id] = &c
+	return s.save()
+}
+
+// List implements APIKeyStore
+func (s *FileAPIKeyStore) List(_ context.Context, principalId string) ([]*APIKey, error) {
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filterKeys(s.keys, principalId), nil
+}
+
+// Delete implements APIKeyStore
+func (s *FileAPIKeyStore) Delete(_ context.Context, id string) error {
+	if err := s.refresh(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[id]; !ok {
+		return ErrAPIKeyNotFound
+	}
+	delete(s.keys, id)
+	return s.save()
+}
+
+func filterKeys(keys map[string]*APIKey, principalId string) []*APIKey {
+	result := make([]*APIKey, 0, len(keys))
+	for _, k := range keys {
+		if principalId == "" || k.PrincipalId == principalId {
+			c := *k
+			result = append(result, &c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].Id < result[j].Id
+	})
+	return result
+}
+
+var _ APIKeyStore = (*MemoryAPIKeyStore)(nil)
+var _ APIKeyStore = (*FileAPIKeyStore)(nil)
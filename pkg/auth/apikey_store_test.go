@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAPIKeyStore exercises the APIKeyStore contract
+func testAPIKeyStore(t *testing.T, store APIKeyStore) {
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := &APIKey{Id: "a", PrincipalId: "p1", Salt: "s", Hash: "h", CreatedAt: created, Scopes: []string{"x"}}
+	b := &APIKey{Id: "b", PrincipalId: "p1", Salt: "s", Hash: "h", CreatedAt: created.Add(time.Minute)}
+	c := &APIKey{Id: "c", PrincipalId: "p2", Salt: "s", Hash: "h", CreatedAt: created}
+
+	for _, k := range []*APIKey{a, b, c} {
+		require.NoError(t, store.Put(ctx, k))
+	}
+
+	got, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "p1", got.PrincipalId)
+	assert.Equal(t, []string{"x"}, got.Scopes)
+
+	// Returned records are copies
+	got.Revoked = true
+	got, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, got.Revoked)
+
+	got.Revoked = true
+	require.NoError(t, store.Put(ctx, got))
+	got, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, got.Revoked)
+
+	keys, err := store.List(ctx, "p1")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "a", keys[0].Id)
+	assert.Equal(t, "b", keys[1].Id)
+
+	keys, err = store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+
+	// Keys can move to another principal
+	moved := *b
+	moved.PrincipalId = "p3"
+	require.NoError(t, store.Put(ctx, &moved))
+	keys, err = store.List(ctx, "p1")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "a", keys[0].Id)
+	keys, err = store.List(ctx, "p3")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "b", keys[0].Id)
+
+	require.NoError(t, store.Delete(ctx, "c"))
+	assert.ErrorIs(t, store.Delete(ctx, "c"), ErrAPIKeyNotFound)
+	keys, err = store.List(ctx, "p2")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestMemoryAPIKeyStore(t *testing.T) {
+	testAPIKeyStore(t, NewMemoryAPIKeyStore())
+}
+
+func TestFileAPIKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	store, err := NewFileAPIKeyStore(path)
+	require.NoError(t, err)
+
+	testAPIKeyStore(t, store)
+
+	// A fresh store reads what was written
+	reopened, err := NewFileAPIKeyStore(path)
+	require.NoError(t, err)
+	keys, err := reopened.List(context.Background(), "")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestFileAPIKeyStorePicksUpExternalChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.json")
+
+	store, err := NewFileAPIKeyStore(path)
+	require.NoError(t, err)
+
+	m := NewAPIKeyManager(store)
+	plaintext, key, err := m.Mint(ctx, MintRequest{PrincipalId: "svc"})
+	require.NoError(t, err)
+
+	// Another process revokes the key through its own store
+	other, err := NewFileAPIKeyStore(path)
+	require.NoError(t, err)
+	require.NoError(t, NewAPIKeyManager(other).Revoke(ctx, key.Id))
+
+	// Make sure the modification time moves even on filesystems with coarse timestamps
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, future, future))
+
+	_, err = m.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrAPIKeyRevoked)
+}
+
+func TestFileAPIKeyStoreRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := NewFileAPIKeyStore(path)
+	assert.Error(t, err)
+}
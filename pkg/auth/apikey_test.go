@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager() (*APIKeyManager, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewAPIKeyManager(NewMemoryAPIKeyStore())
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestAPIKeyMintAndVerify(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager()
+
+	plaintext, key, err := m.Mint(ctx, MintRequest{PrincipalId: "svc-a", Scopes: []string{"tools:call"}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(plaintext, APIKeyPrefix+"_"+key.Id+"_"))
+	assert.NotContains(t, key.Hash, plaintext)
+	assert.NotEmpty(t, key.Salt)
+
+	verified, err := m.Verify(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, "svc-a", verified.PrincipalId)
+
+	// Wrong secret for a real id
+	_, err = m.Verify(ctx, APIKeyPrefix+"_"+key.Id+"_wrong")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	for _, bad := range []string{"", "garbage", "smk_", "other_" + key.Id + "_x", "smk_missing_secret"} {
+		_, err = m.Verify(ctx, bad)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey, bad)
+	}
+
+	_, _, err = m.Mint(ctx, MintRequest{})
+	assert.Error(t, err, "a principal is required")
+}
+
+func TestAPIKeySaltsDiffer(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager()
+
+	_, a, err := m.Mint(ctx, MintRequest{PrincipalId: "svc"})
+	require.NoError(t, err)
+	_, b, err := m.Mint(ctx, MintRequest{PrincipalId: "svc"})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a.Id, b.Id)
+	assert.NotEqual(t, a.Salt, b.Salt)
+	assert.NotEqual(t, a.Hash, b.Hash)
+}
+
+func TestAPIKeyExpiryAndRevocation(t *testing.T) {
+	ctx := context.Background()
+	m, now := newTestManager()
+
+	plaintext, key, err := m.Mint(ctx, MintRequest{PrincipalId: "svc", TTL: time.Hour})
+	require.NoError(t, err)
+	require.NotNil(t, key.ExpiresAt)
+
+	_, err = m.Verify(ctx, plaintext)
+	require.NoError(t, err)
+
+	*now = now.Add(time.Hour)
+	_, err = m.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrAPIKeyExpired)
+
+	plaintext, key, err = m.Mint(ctx, MintRequest{PrincipalId: "svc"})
+	require.NoError(t, err)
+	require.NoError(t, m.Revoke(ctx, key.Id))
+
+	_, err = m.Verify(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrAPIKeyRevoked)
+
+	assert.ErrorIs(t, m.Revoke(ctx, "missing"), ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyRotationOverlap(t *testing.T) {
+	ctx := context.Background()
+	m, now := newTestManager()
+
+	oldPlaintext, old, err := m.Mint(ctx, MintRequest{PrincipalId: "svc", Scopes: []string{"a", "b"}})
+	require.NoError(t, err)
+
+	newPlaintext, rotated, err := m.Rotate(ctx, old.Id, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, old.Id, rotated.RotatedFrom)
+	assert.Equal(t, old.PrincipalId, rotated.PrincipalId)
+	assert.Equal(t, old.Scopes, rotated.Scopes)
+	assert.Nil(t, rotated.ExpiresAt)
+
+	// Both keys work during the overlap
+	_, err = m.Verify(ctx, oldPlaintext)
+	require.NoError(t, err)
+	_, err = m.Verify(ctx, newPlaintext)
+	require.NoError(t, err)
+
+	*now = now.Add(10 * time.Minute)
+	_, err = m.Verify(ctx, oldPlaintext)
+	assert.ErrorIs(t, err, ErrAPIKeyExpired)
+	_, err = m.Verify(ctx, newPlaintext)
+	assert.NoError(t, err)
+
+	keys, err := m.List(ctx, "svc")
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	require.NoError(t, m.Revoke(ctx, rotated.Id))
+	_, _, err = m.Rotate(ctx, rotated.Id, 0)
+	assert.ErrorIs(t, err, ErrAPIKeyRevoked)
+}
+
+func TestAPIKeyRotationKeepsEarlierExpiry(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager()
+
+	_, old, err := m.Mint(ctx, MintRequest{PrincipalId: "svc", TTL: time.Minute})
+	require.NoError(t, err)
+
+	_, rotated, err := m.Rotate(ctx, old.Id, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, rotated.ExpiresAt)
+	assert.Equal(t, time.Minute, rotated.ExpiresAt.Sub(rotated.CreatedAt))
+
+	stored, err := m.Store().Get(ctx, old.Id)
+	require.NoError(t, err)
+	assert.Equal(t, *old.ExpiresAt, *stored.ExpiresAt, "the overlap should never extend a key's life")
+}
+
+func TestAPIKeyFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"x-api-key", map[string]string{"X-API-Key": "k1"}, "k1"},
+		{"bearer", map[string]string{"Authorization": "Bearer k2"}, "k2"},
+		{"apikey scheme", map[string]string{"Authorization": "ApiKey k3"}, "k3"},
+		{"case insensitive scheme", map[string]string{"Authorization": "bearer k4"}, "k4"},
+		{"basic is ignored", map[string]string{"Authorization": "Basic abc"}, ""},
+		{"header preferred", map[string]string{"X-API-Key": "k5", "Authorization": "Bearer other"}, "k5"},
+		{"none", map[string]string{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, APIKeyFromRequest(r))
+		})
+	}
+}
+
+func TestAPIKeyAuthHandler(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newTestManager()
+
+	plaintext, key, err := m.Mint(ctx, MintRequest{PrincipalId: "svc", Scopes: []string{"tools:call"}})
+	require.NoError(t, err)
+
+	h := NewAPIKeyAuthHandler(m)
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer "+plaintext)
+
+	ai := h.ExtractAuth(r)
+	require.NotNil(t, ai)
+	assert.Equal(t, "svc", ai.GetPrincipalId())
+
+	ki, ok := ai.(*APIKeyAuthInfo)
+	require.True(t, ok)
+	assert.Equal(t, key.Id, ki.KeyId)
+	assert.True(t, ki.HasScope("tools:call"))
+	assert.False(t, ki.HasScope("admin"))
+
+	b, err := h.Serialize(ai)
+	require.NoError(t, err)
+	decoded, err := h.Deserialize(b)
+	require.NoError(t, err)
+	assert.Equal(t, ai, decoded)
+
+	_, err = h.Serialize(&CertificateAuthInfo{})
+	assert.Error(t, err)
+
+	// Invalid keys are rejected by VerifyAuth and yield no AuthInfo from ExtractAuth
+	r.Header.Set("Authorization", "Bearer smk_nope_nope")
+	assert.Nil(t, h.ExtractAuth(r))
+	_, err = h.VerifyAuth(r)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	// Missing keys pass through unless required
+	anon := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	ai, err = h.VerifyAuth(anon)
+	assert.NoError(t, err)
+	assert.Nil(t, ai)
+
+	_, err = NewAPIKeyAuthHandler(m, WithAPIKeyRequired(true)).VerifyAuth(anon)
+	assert.ErrorIs(t, err, ErrMissingCredentials)
+}
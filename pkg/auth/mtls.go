@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// PrincipalSource selects which part of a client certificate identifies the caller
+type PrincipalSource string
+
+const (
+	// PrincipalFromCommonName uses the subject common name
+	PrincipalFromCommonName PrincipalSource = "cn"
+	// PrincipalFromDNSName uses the first DNS subject alternative name
+	PrincipalFromDNSName PrincipalSource = "san-dns"
+	// PrincipalFromURI uses the first URI subject alternative name (e.g. a SPIFFE id)
+	PrincipalFromURI PrincipalSource = "san-uri"
+	// PrincipalFromEmail uses the first email subject alternative name
+	PrincipalFromEmail PrincipalSource = "san-email"
+)
+
+// CertificateAuthInfo is the AuthInfo produced from a verified client certificate
+type CertificateAuthInfo struct {
+	PrincipalId  string   `json:"principal_id"`
+	Subject      string   `json:"subject"`
+	Issuer       string   `json:"issuer"`
+	SerialNumber string   `json:"serial_number"`
+	Fingerprint  string   `json:"fingerprint"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	URIs         []string `json:"uris,omitempty"`
+	Emails       []string `json:"emails,omitempty"`
+}
+
+// GetPrincipalId implements AuthInfo
+func (c *CertificateAuthInfo) GetPrincipalId() string {
+	return c.PrincipalId
+}
+
+var _ AuthInfo = (*CertificateAuthInfo)(nil)
+
+// PrincipalMapper turns a verified certificate into a principal id. Returning false
+// rejects the certificate.
+type PrincipalMapper func(cert *x509.Certificate) (string, bool)
+
+// MTLSAuthHandler extracts AuthInfo from the verified peer certificate of a mutual TLS connection
+type MTLSAuthHandler struct {
+	source     PrincipalSource
+	principals map[string]string
+	mapper     PrincipalMapper
+}
+
+// MTLSOption configures an MTLSAuthHandler
+type MTLSOption func(*MTLSAuthHandler)
+
+// WithPrincipalSource selects the certificate field the principal is taken from
+func WithPrincipalSource(source PrincipalSource) MTLSOption {
+	return func(h *MTLSAuthHandler) {
+		h.source = source
+	}
+}
+
+// WithPrincipalMapping maps certificate identities to principal ids. When set, certificates whose
+// identity is not in the mapping are rejected.
+func WithPrincipalMapping(mapping map[string]string) MTLSOption {
+	return func(h *MTLSAuthHandler) {
+		h.principals = mapping
+	}
+}
+
+// WithPrincipalMapper sets a custom function for deriving the principal from a certificate. It takes
+// precedence over the principal source and mapping.
+func WithPrincipalMapper(mapper PrincipalMapper) MTLSOption {
+	return func(h *MTLSAuthHandler) {
+		h.mapper = mapper
+	}
+}
+
+// NewMTLSAuthHandler creates a new mutual TLS auth handler. By default the principal is the subject CN.
+func NewMTLSAuthHandler(opts ...MTLSOption) *MTLSAuthHandler {
+	h := &MTLSAuthHandler{
+		source: PrincipalFromCommonName,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// ExtractAuth returns the AuthInfo for the request's verified client certificate, or nil if the
+// connection did not present one
+func (h *MTLSAuthHandler) ExtractAuth(r *http.Request) AuthInfo {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	principal, ok := h.principalFor(cert)
+	if !ok {
+		return nil
+	}
+
+	return NewCertificateAuthInfo(principal, cert)
+}
+
+func (h *MTLSAuthHandler) principalFor(cert *x509.Certificate) (string, bool) {
+	if h.mapper != nil {
+		return h.mapper(cert)
+	}
+
+	identity := CertificateIdentity(cert, h.source)
+	if identity == "" {
+		return "", false
+	}
+
+	if h.principals != nil {
+		principal, ok := h.principals[identity]
+		return principal, ok
+	}
+
+	return identity, true
+}
+
+// Serialize encodes certificate auth info for transfer to the session actor
+func (h *MTLSAuthHandler) Serialize(ai AuthInfo) ([]byte, error) {
+	ci, ok := ai.(*CertificateAuthInfo)
+	if !ok {
+		return nil, errors.New("invalid auth type")
+	}
+	return json.Marshal(ci)
+}
+
+// Deserialize decodes certificate auth info produced by Serialize
+func (h *MTLSAuthHandler) Deserialize(b []byte) (AuthInfo, error) {
+	var ci CertificateAuthInfo
+	if err := json.Unmarshal(b, &ci); err != nil {
+		return nil, fmt.Errorf("failed to decode certificate auth info: %w", err)
+	}
+	return &ci, nil
+}
+
+// CertificateIdentity returns the value of the given certificate field, or an empty string if it is not present
+func CertificateIdentity(cert *x509.Certificate, source PrincipalSource) string {
+	switch source {
+	case PrincipalFromDNSName:
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+	case PrincipalFromURI:
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String()
+		}
+	case PrincipalFromEmail:
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+	default:
+		return cert.Subject.CommonName
+	}
+	return ""
+}
+
+// NewCertificateAuthInfo builds a CertificateAuthInfo for the given principal and certificate
+func NewCertificateAuthInfo(principal string, cert *x509.Certificate) *CertificateAuthInfo {
+	sum := sha256.Sum256(cert.Raw)
+
+	uris := make([]string, 0, len(cert.URIs))
+	for _, u := range cert.URIs {
+		uris = append(uris, u.String())
+	}
+
+	return &CertificateAuthInfo{
+		PrincipalId:  principal,
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+		DNSNames:     cert.DNSNames,
+		URIs:         uris,
+		Emails:       cert.EmailAddresses,
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/test/testutils"
+)
+
+func newVerifiedRequest(cert *x509.Certificate) *http.Request {
+	r := httptest.NewRequest("POST", "/mcp", nil)
+	if cert != nil {
+		r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+	return r
+}
+
+func TestMTLSAuthHandler(t *testing.T) {
+	ca, err := testutils.NewTestCA("test-ca")
+	require.NoError(t, err)
+
+	client, err := ca.Issue(testutils.CertOptions{
+		CommonName: "billing-service",
+		DNSNames:   []string{"billing.internal"},
+		URIs:       []string{"spiffe://example.org/billing"},
+		Emails:     []string{"billing@example.org"},
+	})
+	require.NoError(t, err)
+
+	t.Run("principal from common name by default", func(t *testing.T) {
+		h := NewMTLSAuthHandler()
+
+		ai := h.ExtractAuth(newVerifiedRequest(client.Cert))
+		require.NotNil(t, ai)
+		assert.Equal(t, "billing-service", ai.GetPrincipalId())
+
+		ci := ai.(*CertificateAuthInfo)
+		assert.Equal(t, []string{"billing.internal"}, ci.DNSNames)
+		assert.Equal(t, []string{"spiffe://example.org/billing"}, ci.URIs)
+		assert.NotEmpty(t, ci.Fingerprint)
+		assert.Contains(t, ci.Issuer, "test-ca")
+	})
+
+	t.Run("principal from subject alternative names", func(t *testing.T) {
+		cases := map[PrincipalSource]string{
+			PrincipalFromDNSName: "billing.internal",
+			PrincipalFromURI:     "spiffe://example.org/billing",
+			PrincipalFromEmail:   "billing@example.org",
+		}
+		for source, expected := range cases {
+			h := NewMTLSAuthHandler(WithPrincipalSource(source))
+			ai := h.ExtractAuth(newVerifiedRequest(client.Cert))
+			require.NotNil(t, ai, string(source))
+			assert.Equal(t, expected, ai.GetPrincipalId())
+		}
+	})
+
+	t.Run("principal mapping", func(t *testing.T) {
+		h := NewMTLSAuthHandler(WithPrincipalMapping(map[string]string{
+			"billing-service": "svc:billing",
+		}))
+
+		ai := h.ExtractAuth(newVerifiedRequest(client.Cert))
+		require.NotNil(t, ai)
+		assert.Equal(t, "svc:billing", ai.GetPrincipalId())
+
+		other, err := ca.Issue(testutils.CertOptions{CommonName: "unknown"})
+		require.NoError(t, err)
+		assert.Nil(t, h.ExtractAuth(newVerifiedRequest(other.Cert)), "unmapped identities are rejected")
+	})
+
+	t.Run("custom mapper", func(t *testing.T) {
+		h := NewMTLSAuthHandler(WithPrincipalMapper(func(cert *x509.Certificate) (string, bool) {
+			return "custom:" + cert.Subject.CommonName, true
+		}))
+
+		ai := h.ExtractAuth(newVerifiedRequest(client.Cert))
+		require.NotNil(t, ai)
+		assert.Equal(t, "custom:billing-service", ai.GetPrincipalId())
+	})
+
+	t.Run("no verified certificate", func(t *testing.T) {
+		h := NewMTLSAuthHandler()
+		assert.Nil(t, h.ExtractAuth(newVerifiedRequest(nil)))
+
+		r := httptest.NewRequest("POST", "/mcp", nil)
+		r.TLS = &tls.ConnectionState{}
+		assert.Nil(t, h.ExtractAuth(r))
+	})
+
+	t.Run("serialize round trip", func(t *testing.T) {
+		h := NewMTLSAuthHandler()
+		ai := h.ExtractAuth(newVerifiedRequest(client.Cert))
+		require.NotNil(t, ai)
+
+		b, err := h.Serialize(ai)
+		require.NoError(t, err)
+
+		decoded, err := h.Deserialize(b)
+		require.NoError(t, err)
+		assert.Equal(t, ai, decoded)
+
+		_, err = h.Serialize(&MockAuthInfo{principalId: "x"})
+		assert.Error(t, err)
+	})
+}
@@ -5,6 +5,8 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 
@@ -163,6 +165,30 @@ func WithAuthHeader(authHeader string) McpClientOptions {
 	}
 }
 
+// WithTLSConfig sets the TLS configuration used for connections to the server.
+// Certificates and CAs set with WithClientCertificate and WithRootCAs are added to it.
+func WithTLSConfig(tlsConfig *tls.Config) McpClientOptions {
+	return func(httpClient *httpClient) {
+		httpClient.tlsConfig = tlsConfig.Clone()
+	}
+}
+
+// WithClientCertificate presents the given certificate to servers that require mutual TLS.
+// Use tls.LoadX509KeyPair to load a certificate and key from disk.
+func WithClientCertificate(cert tls.Certificate) McpClientOptions {
+	return func(httpClient *httpClient) {
+		tc := httpClient.ensureTLSConfig()
+		tc.Certificates = append(tc.Certificates, cert)
+	}
+}
+
+// WithRootCAs sets the CA pool used to verify the server's certificate
+func WithRootCAs(pool *x509.CertPool) McpClientOptions {
+	return func(httpClient *httpClient) {
+		httpClient.ensureTLSConfig().RootCAs = pool
+	}
+}
+
 // NewMcpClient creates a new MCP client with the given server URL and options.
 func NewMcpClient(serverURL string, config ClientOptions, options ...McpClientOptions) (McpClient, error) {
 	client, err := NewHTTPClient(serverURL, config)
@@ -174,5 +200,9 @@ func NewMcpClient(serverURL string, config ClientOptions, options ...McpClientOp
 		opt(client)
 	}
 
+	if client.tlsConfig != nil {
+		client.applyTLSConfig()
+	}
+
 	return client, nil
 }
@@ -3,6 +3,7 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -41,6 +42,7 @@ type httpClient struct {
 	protocolMutex    sync.RWMutex
 	cancelSSE        context.CancelFunc
 	authHeader       string
+	tlsConfig        *tls.Config
 }
 
 // NewHTTPClient creates a new HTTP-based MCP client.
@@ -65,6 +67,40 @@ func NewHTTPClient(serverURL string, options ClientOptions) (*httpClient, error)
 	return client, nil
 }
 
+// ensureTLSConfig returns the client's TLS config, creating an empty one if needed
+func (c *httpClient) ensureTLSConfig() *tls.Config {
+	if c.tlsConfig == nil {
+		c.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return c.tlsConfig
+}
+
+// applyTLSConfig replaces the HTTP client with one whose transport uses the client's TLS config.
+// The caller's HTTP client is copied rather than modified, since it may be shared (e.g. http.DefaultClient).
+func (c *httpClient) applyTLSConfig() {
+	var transport *http.Transport
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.TLSClientConfig = c.tlsConfig
+
+	hc := *c.httpClient
+	hc.Transport = transport
+	c.httpClient = &hc
+}
+
+// streamingHTTPClient returns an HTTP client for long-lived SSE streams. It shares the
+// transport of the regular client but has no overall request timeout.
+func (c *httpClient) streamingHTTPClient() *http.Client {
+	return &http.Client{
+		Transport:     c.httpClient.Transport,
+		CheckRedirect: c.httpClient.CheckRedirect,
+		Jar:           c.httpClient.Jar,
+	}
+}
+
 // Connect establishes a connection with the server and performs protocol initialization.
 func (c *httpClient) Connect(ctx context.Context) error {
 	// Determine which protocol version to use
@@ -101,19 +137,6 @@ func (c *httpClient) Connect(ctx context.Context) error {
 
 		// Determine the connection method - 2025 spec supports direct HTTP
 		c.connectionMethod = ConnectionMethodHTTP
-
-		// If UseSSEForEvents is enabled, try to establish an SSE connection as well
-		if c.options.UseSSEForEvents {
-			slog.Info("Setting up SSE connection for events (2025 protocol)")
-			// Try to establish SSE connection - but don't fail if it doesn't work
-			// We still have HTTP as fallback
-			if err := c.setupSSE(ctx, c.sseEndpoint); err != nil {
-				slog.Warn("Failed to set up SSE connection for events, will use HTTP only", "error", err)
-				// We don't return error here - 2025 can work fine without SSE
-			} else {
-				slog.Info("Successfully established SSE connection for events")
-			}
-		}
 	}
 
 	// Now that the transport is set up, send the initialize request
@@ -158,6 +181,20 @@ func (c *httpClient) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to send notifications/initialized: %w", err)
 	}
 
+	// If UseSSEForEvents is enabled, try to establish an SSE connection for server notifications as well.
+	// The server only opens the stream for an existing session, so this must follow initialization.
+	if c.GetConnectionMethod() == ConnectionMethodHTTP && c.options.UseSSEForEvents {
+		slog.Info("Setting up SSE connection for events (2025 protocol)")
+		// Try to establish SSE connection - but don't fail if it doesn't work
+		// We still have HTTP as fallback
+		if err := c.setupSSE(ctx, c.sseEndpoint); err != nil {
+			slog.Warn("Failed to set up SSE connection for events, will use HTTP only", "error", err)
+			// We don't return error here - 2025 can work fine without SSE
+		} else {
+			slog.Info("Successfully established SSE connection for events")
+		}
+	}
+
 	c.initialized = true
 	return nil
 }
@@ -274,8 +311,20 @@ func (c *httpClient) setupSSE(ctx context.Context, endpoint string) error {
 		return fmt.Errorf("failed to create SSE request: %w", err)
 	}
 
+	if c.authHeader != "" {
+		req.Header.Set("Authorization", c.authHeader)
+	}
+
+	// Add session ID if we have one
+	c.sessionIdMutex.Lock()
+	if c.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	c.sessionIdMutex.Unlock()
+
 	// Create a new SSE connection
-	c.sseConnection = sse.NewConnection(req)
+	sseClient := &sse.Client{HTTPClient: c.streamingHTTPClient()}
+	c.sseConnection = sseClient.NewConnection(req)
 
 	// Set up a channel to signal when connection is established
 	connectionEstablished := make(chan struct{})
@@ -290,8 +339,13 @@ func (c *httpClient) setupSSE(ctx context.Context, endpoint string) error {
 			// Already signaled
 		}
 
-		// Check if this is the endpoint event
+		// Check if this is the endpoint event. The 2025 spec posts everything to the MCP endpoint, so it
+		// only matters to the 2024 spec.
 		if event.Type == "endpoint" {
+			if c.GetProtocolVersion() == protocol.ProtocolVersion20250326 {
+				return
+			}
+
 			// The endpoint is a plain string, not JSON
 			endpointURL := event.Data
 
@@ -711,6 +765,11 @@ func (c *httpClient) sendNotificationsInitialized(ctx context.Context) error {
 
 // extractJSONRPCError converts a JSON-RPC error to a Go error
 func (c *httpClient) extractJSONRPCError(prefix string, jsonRpcErr interface{}) error {
+	return extractJSONRPCError(prefix, jsonRpcErr)
+}
+
+// extractJSONRPCError converts the error of a JSON-RPC response to a *protocol.JsonRpcError, if it is well formed
+func extractJSONRPCError(prefix string, jsonRpcErr interface{}) error {
 	// Try to convert the error to a structured format
 	if errObj, ok := jsonRpcErr.(map[string]interface{}); ok {
 		if msg, ok := errObj["message"].(string); ok {
@@ -149,8 +149,8 @@ func TestToolsClient(t *testing.T) {
 	// We can't directly check the actor's state, but we can verify it's running
 	// which means it didn't shut down due to initialization failure
 	assert.True(t, sessionActor.IsRunning(), "Session actor should be running")
-	sma, ok := sessionActor.Actor().(*utils.StateMachineActor)
-	assert.True(t, ok, "Session actor should be *utils.StateMachineActor")
+	sma, ok := sessionActor.Actor().(*actors.McpSessionActor)
+	assert.True(t, ok, "Session actor should be *actors.McpSessionActor")
 	sd, ok := sma.GetData().(*actors.SessionData)
 	assert.True(t, ok, "Session actor should be actors.SessionData")
 
@@ -0,0 +1,407 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// ConnectionMethodStdio represents a connection over the standard input and output of a process.
+const ConnectionMethodStdio ConnectionMethod = "stdio"
+
+// ErrClientClosed is returned for requests to a stdio client whose stream has ended
+var ErrClientClosed = errors.New("client closed")
+
+// stdioClient implements the McpClient interface over a stream of newline-delimited JSON-RPC messages,
+// such as the standard input and output of a server process.
+type stdioClient struct {
+	options ClientOptions
+	cmd     *exec.Cmd
+
+	reader io.ReadCloser
+	writer io.WriteCloser
+
+	writeMutex sync.Mutex
+
+	stateMutex  sync.RWMutex
+	initialized bool
+	closed      chan struct{}
+	closeOnce   sync.Once
+
+	protocolVersion protocol.ProtocolVersion
+
+	requestIDMutex   sync.Mutex
+	requestIDCounter int
+
+	responseMapMutex sync.Mutex
+	responseMap      map[string]chan *protocol.JSONRPCMessage
+
+	handlersMutex sync.RWMutex
+	eventHandlers []EventHandler
+}
+
+// NewStdioClient creates a client of the MCP server cmd runs. Connect starts the process, and Close stops it.
+// Anything the server writes to its standard error goes wherever cmd.Stderr does.
+func NewStdioClient(cmd *exec.Cmd, options ClientOptions) (McpClient, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin of %s: %w", cmd.Path, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout of %s: %w", cmd.Path, err)
+	}
+
+	client := newStdioClient(stdout, stdin, options)
+	client.cmd = cmd
+	return client, nil
+}
+
+// NewStreamClient creates a client of an MCP server reading requests from w and writing responses to r, one
+// JSON-RPC message per line. Close closes both.
+func NewStreamClient(r io.ReadCloser, w io.WriteCloser, options ClientOptions) McpClient {
+	return newStdioClient(r, w, options)
+}
+
+func newStdioClient(r io.ReadCloser, w io.WriteCloser, options ClientOptions) *stdioClient {
+	return &stdioClient{
+		options:     options,
+		reader:      r,
+		writer:      w,
+		closed:      make(chan struct{}),
+		responseMap: make(map[string]chan *protocol.JSONRPCMessage),
+	}
+}
+
+// Connect starts the server process, if any, and performs protocol initialization.
+func (c *stdioClient) Connect(ctx context.Context) error {
+	if c.cmd != nil {
+		if err := c.cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start %s: %w", c.cmd.Path, err)
+		}
+	}
+
+	go c.readLoop()
+
+	protocolVersion := c.options.ProtocolVersion
+	if protocolVersion == protocol.ProtocolVersionAuto || protocolVersion == "" {
+		protocolVersion = protocol.ProtocolVersion20250326
+	}
+
+	resp, err := c.SendRequest(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": string(protocolVersion),
+		"capabilities": map[string]interface{}{
+			"roots": map[string]interface{}{
+				"listChanged": c.options.Capabilities.Roots.ListChanged,
+			},
+			"sampling": map[string]interface{}{},
+		},
+		"clientInfo": map[string]interface{}{
+			"name":    c.options.ClientInfo.Name,
+			"version": c.options.ClientInfo.Version,
+		},
+	})
+	if err != nil {
+		_ = c.Close(ctx)
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	// Use the version the server answered with
+	if result, ok := resp.Result.(map[string]interface{}); ok {
+		if version, ok := result["protocolVersion"].(string); ok && version != "" {
+			protocolVersion = protocol.ProtocolVersion(version)
+		}
+	}
+
+	c.stateMutex.Lock()
+	c.protocolVersion = protocolVersion
+	c.initialized = true
+	c.stateMutex.Unlock()
+
+	if err := c.SendNotification(ctx, "notifications/initialized", nil); err != nil {
+		_ = c.Close(ctx)
+		return fmt.Errorf("failed to send notifications/initialized: %w", err)
+	}
+	return nil
+}
+
+// readLoop reads messages until the stream ends, routing responses to their requests and everything else
+// to the event handlers
+func (c *stdioClient) readLoop() {
+	defer c.shutdown()
+
+	scanner := bufio.NewScanner(c.reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var message protocol.JSONRPCMessage
+		if err := json.Unmarshal(line, &message); err != nil {
+			slog.Error("Failed to parse message from stdio server", "error", err)
+			continue
+		}
+
+		switch {
+		case message.Method == "" && message.ID != nil:
+			c.deliverResponse(&message)
+		case message.Method == "ping" && message.ID != nil:
+			c.respond(message.ID, map[string]interface{}{}, nil)
+		case message.ID != nil:
+			// Requests from the server, such as sampling, are not supported
+			c.respond(message.ID, nil, protocol.NewMethodNotFoundError(message.Method, message.ID))
+		default:
+			c.dispatchEvent(&message)
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrClosedPipe) && !errors.Is(err, io.EOF) {
+		slog.Debug("Stdio server stream ended", "error", err)
+	}
+}
+
+// deliverResponse hands a response to the request waiting for it
+func (c *stdioClient) deliverResponse(message *protocol.JSONRPCMessage) {
+	requestID := fmt.Sprintf("%v", message.ID)
+	c.responseMapMutex.Lock()
+	responseChan, ok := c.responseMap[requestID]
+	delete(c.responseMap, requestID)
+	c.responseMapMutex.Unlock()
+
+	if !ok {
+		slog.Debug("No response channel found for request", "id", message.ID)
+		return
+	}
+	responseChan <- message
+}
+
+// respond answers a request of the server
+func (c *stdioClient) respond(id interface{}, result interface{}, rpcErr *protocol.JsonRpcError) {
+	response := protocol.JSONRPCMessage{JSONRPC: "2.0", ID: id, Result: result}
+	if rpcErr != nil {
+		response.Result = nil
+		response.Error = rpcErr
+	}
+	if err := c.write(response); err != nil {
+		slog.Debug("Failed to answer stdio server request", "error", err)
+	}
+}
+
+// shutdown marks the client closed and fails the requests waiting for a response
+func (c *stdioClient) shutdown() {
+	c.closeOnce.Do(func() {
+		c.stateMutex.Lock()
+		c.initialized = false
+		close(c.closed)
+		c.stateMutex.Unlock()
+	})
+}
+
+// write sends one message, on a line of its own
+func (c *stdioClient) write(message protocol.JSONRPCMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	if _, err := c.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Close closes the streams and stops the server process, if any.
+func (c *stdioClient) Close(ctx context.Context) error {
+	_ = c.writer.Close()
+	_ = c.reader.Close()
+	c.shutdown()
+
+	if c.cmd == nil || c.cmd.Process == nil {
+		return nil
+	}
+
+	// Give the server a moment to exit on its own once its input is closed
+	exited := make(chan error, 1)
+	go func() { exited <- c.cmd.Wait() }()
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		_ = c.cmd.Process.Kill()
+		<-exited
+	case <-ctx.Done():
+		_ = c.cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}
+
+// IsInitialized returns whether the client is initialized.
+func (c *stdioClient) IsInitialized() bool {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+	return c.initialized
+}
+
+// GetSessionID returns an empty string, as stdio connections have no session ID.
+func (c *stdioClient) GetSessionID() string {
+	return ""
+}
+
+// GetProtocolVersion returns the negotiated protocol version.
+func (c *stdioClient) GetProtocolVersion() protocol.ProtocolVersion {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+	return c.protocolVersion
+}
+
+// GetConnectionMethod returns ConnectionMethodStdio.
+func (c *stdioClient) GetConnectionMethod() ConnectionMethod {
+	return ConnectionMethodStdio
+}
+
+// SendRequest sends a request to the server and waits for a response.
+func (c *stdioClient) SendRequest(ctx context.Context, method string, params interface{}) (*protocol.JSONRPCMessage, error) {
+	if !c.IsInitialized() && method != "initialize" {
+		return nil, fmt.Errorf("client not initialized")
+	}
+
+	c.requestIDMutex.Lock()
+	c.requestIDCounter++
+	requestID := fmt.Sprintf("%d", c.requestIDCounter)
+	c.requestIDMutex.Unlock()
+
+	responseChan := make(chan *protocol.JSONRPCMessage, 1)
+	c.responseMapMutex.Lock()
+	c.responseMap[requestID] = responseChan
+	c.responseMapMutex.Unlock()
+	defer func() {
+		c.responseMapMutex.Lock()
+		delete(c.responseMap, requestID)
+		c.responseMapMutex.Unlock()
+	}()
+
+	request := protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      requestID,
+		Method:  method,
+		Params:  params,
+	}
+	if err := c.write(request); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, ErrClientClosed
+	case response := <-responseChan:
+		if response.Error != nil {
+			return response, extractJSONRPCError("JSON-RPC error", response.Error)
+		}
+		return response, nil
+	}
+}
+
+// SendNotification sends a notification to the server without waiting for a response.
+func (c *stdioClient) SendNotification(ctx context.Context, method string, params interface{}) error {
+	if !c.IsInitialized() {
+		return fmt.Errorf("client not initialized")
+	}
+	return c.write(protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+// AddEventHandler adds an event handler to the client.
+func (c *stdioClient) AddEventHandler(handler EventHandler) {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+	c.eventHandlers = append(c.eventHandlers, handler)
+}
+
+// RemoveEventHandler removes an event handler from the client.
+func (c *stdioClient) RemoveEventHandler(handler EventHandler) {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+
+	for i, h := range c.eventHandlers {
+		if h == handler {
+			c.eventHandlers = append(c.eventHandlers[:i], c.eventHandlers[i+1:]...)
+			break
+		}
+	}
+}
+
+// dispatchEvent dispatches an event to all registered handlers.
+func (c *stdioClient) dispatchEvent(event *protocol.JSONRPCMessage) {
+	c.handlersMutex.RLock()
+	defer c.handlersMutex.RUnlock()
+
+	for _, handler := range c.eventHandlers {
+		go handler.HandleEvent(event)
+	}
+}
+
+// ListTools retrieves the list of available tools from the server
+func (c *stdioClient) ListTools(ctx context.Context) (*protocol.ToolListResult, error) {
+	resp, err := c.SendRequest(ctx, "tools/list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	var toolsList protocol.ToolListResult
+	resultBytes, err := json.Marshal(resp.Result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	if err := json.Unmarshal(resultBytes, &toolsList); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tools: %w", err)
+	}
+	return &toolsList, nil
+}
+
+// FindTool searches for a tool by name in the tools list
+func (c *stdioClient) FindTool(ctx context.Context, toolName string) (*protocol.Tool, error) {
+	toolsList, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range toolsList.Tools {
+		if tool.Name == toolName {
+			return &tool, nil
+		}
+	}
+
+	return nil, fmt.Errorf("tool not found: %s", toolName)
+}
+
+// CallTool calls a specific tool with the given parameters
+func (c *stdioClient) CallTool(ctx context.Context, toolName string, params interface{}) (*protocol.JSONRPCMessage, error) {
+	resp, err := c.SendRequest(ctx, "tools/call", map[string]interface{}{
+		"name":      toolName,
+		"arguments": params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call tool %s: %w", toolName, err)
+	}
+	return resp, nil
+}
+
+// Ensure stdioClient implements McpClient
+var _ McpClient = (*stdioClient)(nil)
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/test/testutils"
+)
+
+func TestClientCertificateOptions(t *testing.T) {
+	ca, err := testutils.NewTestCA("test-ca")
+	require.NoError(t, err)
+
+	serverCert, err := ca.Issue(testutils.CertOptions{
+		CommonName: "localhost",
+		IPs:        []net.IP{net.ParseIP("127.0.0.1")},
+		IsServer:   true,
+	})
+	require.NoError(t, err)
+	serverTLSCert, err := serverCert.TLSCertificate()
+	require.NoError(t, err)
+
+	clientCert, err := ca.Issue(testutils.CertOptions{CommonName: "test-client"})
+	require.NoError(t, err)
+	clientTLSCert, err := clientCert.TLSCertificate()
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverTLSCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.Pool(),
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	options := DefaultClientOptions()
+	c, err := NewMcpClient(srv.URL, options, WithRootCAs(ca.Pool()), WithClientCertificate(clientTLSCert))
+	require.NoError(t, err)
+
+	hc := c.(*httpClient)
+	assert.NotSame(t, http.DefaultClient, hc.httpClient, "the shared default client must not be modified")
+	assert.Nil(t, http.DefaultClient.Transport)
+
+	resp, err := hc.httpClient.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	buf := make([]byte, 64)
+	n, _ := resp.Body.Read(buf)
+	assert.Equal(t, "test-client", string(buf[:n]))
+
+	// The SSE client shares the TLS transport
+	assert.Same(t, hc.httpClient.Transport, hc.streamingHTTPClient().Transport)
+}
+
+func TestWithTLSConfigIsCopied(t *testing.T) {
+	base := &tls.Config{ServerName: "example"}
+
+	c, err := NewMcpClient("https://localhost", DefaultClientOptions(), WithTLSConfig(base), WithRootCAs(nil))
+	require.NoError(t, err)
+
+	hc := c.(*httpClient)
+	transport, ok := hc.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, "example", transport.TLSClientConfig.ServerName)
+	assert.NotSame(t, base, transport.TLSClientConfig)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/ratelimit"
 	"time"
 )
 
@@ -43,6 +44,102 @@ type ServerConfig struct {
 	ServerCapabilities protocol.ServerCapabilities `json:"server_capabilities"`
 
 	RequestTimeout time.Duration `json:"request_timeout"`
+
+	// Rate limiting configuration
+	RateLimit ratelimit.Config `json:"rate_limit"`
+
+	// Audit log configuration
+	Audit AuditConfig `json:"audit"`
+
+	// Error reporting configuration
+	Errors ErrorConfig `json:"errors"`
+
+	// Metrics configuration
+	Metrics MetricsConfig `json:"metrics"`
+
+	// Liveness and readiness endpoint configuration
+	Health HealthConfig `json:"health"`
+
+	// Graceful shutdown configuration
+	Shutdown ShutdownConfig `json:"shutdown"`
+
+	// Pagination of tools/list, prompts/list, resources/list and resources/templates/list
+	Pagination PaginationConfig `json:"pagination"`
+}
+
+// PaginationConfig holds the configuration of list pagination. Cursors handed to clients are signed, so they
+// cannot be forged, and expire.
+type PaginationConfig struct {
+	// Number of items per page. Zero leaves it to each registry, which defaults to 20.
+	PageSize int `json:"page_size"`
+
+	// Secret cursors are signed with. When empty a random secret is used, valid only on this process, so the
+	// nodes of a cluster must share one for cursors to be valid on every node.
+	CursorSecret string `json:"cursor_secret"`
+
+	// How long a cursor stays valid. Zero means cursors never expire.
+	CursorTTL time.Duration `json:"cursor_ttl"`
+}
+
+// HealthConfig holds the liveness and readiness endpoint configuration
+type HealthConfig struct {
+	// Path of the liveness endpoint, reporting whether the process should be restarted
+	LivenessPath string `json:"liveness_path"`
+
+	// Path of the readiness endpoint, reporting whether the node should receive traffic
+	ReadinessPath string `json:"readiness_path"`
+
+	// How long each check may take before it is reported as failed
+	CheckTimeout time.Duration `json:"check_timeout"`
+
+	// Number of other cluster nodes that must be visible for a clustered node to be ready. Zero only
+	// requires the node to have joined the cluster.
+	MinPeers int `json:"min_peers"`
+}
+
+// ShutdownConfig holds the graceful shutdown configuration. When the server stops it reports itself not
+// ready, waits ReadinessDelay, then waits up to DrainTimeout for in-flight requests to finish and for sessions
+// to be handed off before stopping the HTTP server and the actors.
+type ShutdownConfig struct {
+	// How long to keep serving after reporting not ready, so load balancers stop routing to the node first
+	ReadinessDelay time.Duration `json:"readiness_delay"`
+
+	// How long in-flight requests and session handoff may take
+	DrainTimeout time.Duration `json:"drain_timeout"`
+
+	// How long stopping the HTTP server and the actor system may take once draining is done
+	StopTimeout time.Duration `json:"stop_timeout"`
+}
+
+// MetricsConfig holds the metrics endpoint configuration
+type MetricsConfig struct {
+	// Whether to record metrics and serve them in the Prometheus text format
+	Enable bool `json:"enable"`
+
+	// Path the metrics are served on, next to /health
+	Path string `json:"path"`
+
+	// Namespace prefixed to every metric name
+	Namespace string `json:"namespace"`
+}
+
+// ErrorConfig controls how much detail about internal failures is returned to clients
+type ErrorConfig struct {
+	// Production replaces the details of internal errors and panics with an opaque error id. The full
+	// error is logged server-side under the same id. Known secrets are redacted from client-visible
+	// errors and logs whether or not this is set.
+	Production bool `json:"production"`
+}
+
+// AuditConfig holds the audit log configuration
+type AuditConfig struct {
+	// Path of a JSON-lines file every audit record is appended to. Empty disables the file sink; custom
+	// sinks can still be added with server options.
+	FilePath string `json:"file_path"`
+
+	// Key of the HMAC that replaces arguments redacted by hashing. When empty a random key is used, known only
+	// to this process, so the nodes of a cluster must share one for hashes to correlate across nodes.
+	HashSecret string `json:"hash_secret"`
 }
 
 // ServerInfo holds information about the server
@@ -88,8 +185,29 @@ type TLSConfig struct {
 
 	// Path to the key file
 	KeyFile string `json:"key_file"`
+
+	// Path to a PEM bundle of CA certificates used to verify client certificates
+	ClientCAFile string `json:"client_ca_file"`
+
+	// Client certificate verification mode
+	ClientAuth TLSClientAuthMode `json:"client_auth"`
+
+	// How often the certificate, key and client CA files are checked for changes.
+	// Zero disables reloading.
+	ReloadInterval time.Duration `json:"reload_interval"`
 }
 
+type TLSClientAuthMode = string
+
+const (
+	// TLSClientAuthNone does not request a client certificate
+	TLSClientAuthNone TLSClientAuthMode = "none"
+	// TLSClientAuthOptional verifies a client certificate if one is presented
+	TLSClientAuthOptional TLSClientAuthMode = "optional"
+	// TLSClientAuthRequired rejects connections without a verified client certificate
+	TLSClientAuthRequired TLSClientAuthMode = "required"
+)
+
 // CORSConfig holds the CORS configuration
 type CORSConfig struct {
 	// Whether to enable CORS
@@ -139,6 +257,9 @@ type SessionConfig struct {
 
 	// Key prefix for session storage
 	KeyPrefix string `json:"key_prefix"`
+
+	// Size limit of the key/value state each session keeps for its tools, in bytes
+	MaxStateBytes int `json:"max_state_bytes"`
 }
 
 // RedisConfig holds the Redis configuration
@@ -185,7 +306,8 @@ func DefaultConfig() *ServerConfig {
 			SSEPath:     "/sse",
 			MessagePath: "/messages",
 			TLS: TLSConfig{
-				Enable: false,
+				Enable:     false,
+				ClientAuth: TLSClientAuthNone,
 			},
 			CORS: CORSConfig{
 				Enable:           false,
@@ -201,6 +323,7 @@ func DefaultConfig() *ServerConfig {
 			TTL:               5 * time.Minute,
 			UseInMemory:       true,
 			KeyPrefix:         "mcp:session:",
+			MaxStateBytes:     64 * 1024,
 		},
 		Actor: ActorConfig{
 			NumWorkers:      10,
@@ -223,6 +346,23 @@ func DefaultConfig() *ServerConfig {
 			Prompts:   &protocol.PromptsServerCapability{},
 			Resources: &protocol.ResourcesServerCapability{},
 		},
+		Metrics: MetricsConfig{
+			Enable:    false,
+			Path:      "/metrics",
+			Namespace: "mcp",
+		},
+		Health: HealthConfig{
+			LivenessPath:  "/livez",
+			ReadinessPath: "/readyz",
+			CheckTimeout:  2 * time.Second,
+		},
+		Shutdown: ShutdownConfig{
+			DrainTimeout: 30 * time.Second,
+			StopTimeout:  5 * time.Second,
+		},
+		Pagination: PaginationConfig{
+			CursorTTL: time.Hour,
+		},
 	}
 }
 
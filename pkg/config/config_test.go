@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/traego/scaled-mcp/pkg/protocol"
@@ -17,6 +18,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "/sse", cfg.HTTP.SSEPath)
 	assert.Equal(t, "/messages", cfg.HTTP.MessagePath)
 	assert.False(t, cfg.HTTP.TLS.Enable)
+	assert.Equal(t, TLSClientAuthNone, cfg.HTTP.TLS.ClientAuth)
 	assert.False(t, cfg.HTTP.CORS.Enable)
 	assert.Equal(t, []string{"*"}, cfg.HTTP.CORS.AllowedOrigins)
 	assert.Equal(t, 10, cfg.Actor.NumWorkers)
@@ -39,7 +41,19 @@ func TestTestConfig(t *testing.T) {
 
 	assert.NotNil(t, cfg)
 	assert.Nil(t, cfg.Redis)
+	assert.False(t, cfg.RateLimit.Enable)
+	assert.False(t, cfg.Metrics.Enable)
+	assert.Equal(t, "/metrics", cfg.Metrics.Path)
+	assert.Equal(t, "/livez", cfg.Health.LivenessPath)
+	assert.Equal(t, "/readyz", cfg.Health.ReadinessPath)
+	assert.Equal(t, 2*time.Second, cfg.Health.CheckTimeout)
+	assert.Equal(t, time.Duration(0), cfg.Shutdown.ReadinessDelay)
+	assert.Equal(t, 30*time.Second, cfg.Shutdown.DrainTimeout)
+	assert.Equal(t, 5*time.Second, cfg.Shutdown.StopTimeout)
 	assert.True(t, cfg.Session.UseInMemory)
+	assert.Equal(t, 64*1024, cfg.Session.MaxStateBytes)
+	assert.Equal(t, 0, cfg.Pagination.PageSize)
+	assert.Equal(t, time.Hour, cfg.Pagination.CursorTTL)
 
 	defaultCfg := DefaultConfig()
 	assert.Equal(t, defaultCfg.HTTP, cfg.HTTP)
@@ -2,11 +2,18 @@ package config
 
 import (
 	"context"
+	"github.com/traego/scaled-mcp/pkg/audit"
 	"github.com/traego/scaled-mcp/pkg/auth"
+	"github.com/traego/scaled-mcp/pkg/metrics"
 	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/ratelimit"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"log/slog"
 	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type McpServerInfo interface {
@@ -16,6 +23,44 @@ type McpServerInfo interface {
 	GetExecutors() MethodHandler
 	GetAuthHandler() AuthHandler
 	GetTraceHandler() TraceHandler
+	GetRateLimiter() *ratelimit.Limiter
+	GetAuditSink() audit.Sink
+	GetMetrics() metrics.Recorder
+	GetTracerProvider() trace.TracerProvider
+	GetLogger() *slog.Logger
+	GetSessionStore() sessionstore.Store
+	GetSessionHooks() *SessionHooks
+	GetToolInterceptors() []ToolInterceptor
+	GetResourceSubscriptions() *resources.Subscriptions
+}
+
+// Logger returns the server's logger, or the default logger if the server has none
+func Logger(info McpServerInfo) *slog.Logger {
+	if info == nil {
+		return slog.Default()
+	}
+	if l := info.GetLogger(); l != nil {
+		return l
+	}
+	return slog.Default()
+}
+
+// SessionHooks are callbacks applications use to set up and tear down per-session resources. Any of them may
+// be nil. They run on the session's actor, which handles no other message until they return, and the context
+// they get carries the session id (utils.GetSessionId) and the auth info of the client (auth.GetAuthInfo).
+//
+// A session resumed on another node after a handoff runs OnSessionCreated and OnInitialized again there, after
+// OnSessionClosed ran on the node it left with the handed_off reason.
+type SessionHooks struct {
+	// OnSessionCreated runs when the session's actor starts, before the client initializes it
+	OnSessionCreated func(ctx context.Context)
+
+	// OnInitialized runs when the client initializes the session. An error rejects the initialization and
+	// ends the session; a *protocol.JsonRpcError is returned to the client as is.
+	OnInitialized func(ctx context.Context, clientInfo protocol.ClientInfo, capabilities protocol.ClientCapabilities) error
+
+	// OnSessionClosed runs when the session ends, with one of the metrics.SessionEnd reasons
+	OnSessionClosed func(ctx context.Context, reason string)
 }
 
 type AuthHandler interface {
@@ -24,6 +69,17 @@ type AuthHandler interface {
 	Deserialize(b []byte) (auth.AuthInfo, error)
 }
 
+// AuthVerifier may be implemented by an AuthHandler that needs to reject requests outright. When the
+// server's auth handler implements it, VerifyAuth is used instead of ExtractAuth, and requests for which
+// it returns an error are answered with 401 Unauthorized. A nil AuthInfo with a nil error lets the request
+// through unauthenticated.
+type AuthVerifier interface {
+	VerifyAuth(r *http.Request) (auth.AuthInfo, error)
+}
+
+// TraceHandler lets applications use their own trace ids, e.g. from a request id header. The id returned by
+// ExtractTraceId is carried to the session actor alongside the W3C trace context and handed back to
+// SetTraceId. When it returns an empty string, the id of the request's OpenTelemetry trace is used.
 type TraceHandler interface {
 	ExtractTraceId(r *http.Request) string
 	SetTraceId(ctx context.Context, traceId string) context.Context
@@ -33,3 +89,19 @@ type MethodHandler interface {
 	CanHandleMethod(method string) bool
 	HandleMethod(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error)
 }
+
+// MethodInvoker handles a request for method, as the executors do
+type MethodInvoker func(ctx context.Context, method string, req *mcppb.JsonRpcRequest) (*mcppb.JsonRpcResponse, error)
+
+// MethodInterceptor wraps the handling of every request a session's executors handle. It may inspect or rewrite
+// the request, call next to carry on, and inspect or replace the response; not calling next short-circuits the
+// request, e.g. to reject it or answer it from a cache. Interceptors run on the session's actor.
+type MethodInterceptor func(ctx context.Context, method string, req *mcppb.JsonRpcRequest, next MethodInvoker) (*mcppb.JsonRpcResponse, error)
+
+// ToolInvoker calls the tool name with args, as the tool registry does
+type ToolInvoker func(ctx context.Context, name string, args map[string]interface{}) (interface{}, error)
+
+// ToolInterceptor wraps every tools/call, whichever tool registry is used, seeing the tool's name and decoded
+// arguments. It may rewrite them before calling next, or return its own result or error instead; an error is
+// reported to the client as a failed tool call.
+type ToolInterceptor func(ctx context.Context, name string, args map[string]interface{}, next ToolInvoker) (interface{}, error)
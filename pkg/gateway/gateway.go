@@ -0,0 +1,242 @@
+// Package gateway proxies the tools, prompts and resources of upstream MCP servers, so that one server
+// aggregates many. Each upstream is namespaced by its name, connected to over HTTP, SSE or stdio, and
+// reconnected to when its connection is lost.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/client"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+// Defaults of Options
+const (
+	DefaultPingInterval     = 15 * time.Second
+	DefaultRequestTimeout   = 30 * time.Second
+	DefaultMinReconnectWait = 500 * time.Millisecond
+	DefaultMaxReconnectWait = 30 * time.Second
+)
+
+// ErrUpstreamUnavailable is returned for calls to an upstream that is not connected
+var ErrUpstreamUnavailable = errors.New("upstream unavailable")
+
+// Upstream describes an MCP server the gateway proxies. Exactly one of URL, Command and Dial is set.
+type Upstream struct {
+	// Name namespaces the upstream's items: tools and prompts are exposed as name_item, and resources as
+	// name+uri, with the separators of the gateway's Options
+	Name string
+
+	// URL is the address of an HTTP or SSE upstream
+	URL string
+
+	// ClientOptions configures the client of an HTTP or SSE upstream. If nil, client.DefaultClientOptions is
+	// used, with UseSSEForEvents so that the upstream's notifications are received.
+	ClientOptions *client.ClientOptions
+
+	// Options configure the client of an HTTP or SSE upstream, such as client.WithAuthHeader for the
+	// credentials of the upstream
+	Options []client.McpClientOptions
+
+	// Command runs a stdio upstream, with Args, Env and Dir as in exec.Cmd. The process is started again
+	// when the gateway reconnects.
+	Command string
+	Args    []string
+	Env     []string
+	Dir     string
+
+	// Dial creates the client of an upstream connected in some other way; the gateway calls Connect on it
+	Dial func(ctx context.Context) (client.McpClient, error)
+}
+
+// Options configures a Gateway
+type Options struct {
+	// ToolSeparator and PromptSeparator join an upstream's name to the names of its tools and prompts, "_" if
+	// empty. ResourceSeparator joins it to the URIs of its resources, "+" if empty.
+	ToolSeparator     string
+	PromptSeparator   string
+	ResourceSeparator string
+
+	// PingInterval is how often upstreams are pinged to notice lost connections, DefaultPingInterval if zero
+	PingInterval time.Duration
+
+	// RequestTimeout bounds each request to an upstream, DefaultRequestTimeout if zero
+	RequestTimeout time.Duration
+
+	// MinReconnectWait and MaxReconnectWait bound the exponential backoff between attempts to connect to an
+	// upstream, DefaultMinReconnectWait and DefaultMaxReconnectWait if zero
+	MinReconnectWait time.Duration
+	MaxReconnectWait time.Duration
+
+	// Logger receives the gateway's logs, such as an upstream it failed to connect to. Pass the server's logger,
+	// the one given to server.WithLogger; slog.Default() is used if nil.
+	Logger *slog.Logger
+}
+
+// Gateway connects to upstream MCP servers and exposes their items through registries, which a server is
+// given with server.WithToolRegistry, server.WithPromptRegistry and server.WithResourceRegistry. The
+// registries are composite registries, so the server's own registries can be added to them as well.
+//
+// An upstream that is not connected lists nothing; its items reappear once it reconnects. Notifications of
+// upstreams, such as notifications/tools/list_changed, reach clients while the server watches the registries.
+type Gateway struct {
+	opts Options
+
+	tools      *resources.CompositeToolRegistry
+	prompts    *resources.CompositePromptRegistry
+	resources  *resources.CompositeResourceRegistry
+	upstreams  []*upstream
+	stop       context.CancelFunc
+	wg         sync.WaitGroup
+	startMutex sync.Mutex
+}
+
+// New creates a gateway of the upstreams. Start connects to them.
+func New(opts Options, upstreams ...Upstream) (*Gateway, error) {
+	if opts.PingInterval == 0 {
+		opts.PingInterval = DefaultPingInterval
+	}
+	if opts.RequestTimeout == 0 {
+		opts.RequestTimeout = DefaultRequestTimeout
+	}
+	if opts.MinReconnectWait == 0 {
+		opts.MinReconnectWait = DefaultMinReconnectWait
+	}
+	if opts.MaxReconnectWait == 0 {
+		opts.MaxReconnectWait = DefaultMaxReconnectWait
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	g := &Gateway{
+		opts:      opts,
+		tools:     resources.NewCompositeToolRegistry(resources.CompositeOptions{Separator: opts.ToolSeparator, Logger: opts.Logger}),
+		prompts:   resources.NewCompositePromptRegistry(resources.CompositeOptions{Separator: opts.PromptSeparator, Logger: opts.Logger}),
+		resources: resources.NewCompositeResourceRegistry(resources.CompositeOptions{Separator: opts.ResourceSeparator, Logger: opts.Logger}),
+	}
+
+	for _, def := range upstreams {
+		if err := g.add(def); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// add validates an upstream and adds its registries
+func (g *Gateway) add(def Upstream) error {
+	if def.Name == "" {
+		return fmt.Errorf("upstream name is required")
+	}
+
+	transports := 0
+	for _, set := range []bool{def.URL != "", def.Command != "", def.Dial != nil} {
+		if set {
+			transports++
+		}
+	}
+	if transports != 1 {
+		return fmt.Errorf("upstream %s must set exactly one of URL, Command and Dial", def.Name)
+	}
+
+	u := newUpstream(def, g.opts)
+	child := resources.CompositeChildOptions{Namespace: def.Name, Prefix: true}
+	if err := g.tools.AddRegistry(&toolRegistry{u}, child); err != nil {
+		return err
+	}
+	if err := g.prompts.AddRegistry(&promptRegistry{u}, child); err != nil {
+		return err
+	}
+	if err := g.resources.AddRegistry(&resourceRegistry{u}, child); err != nil {
+		return err
+	}
+	g.upstreams = append(g.upstreams, u)
+	return nil
+}
+
+// dial creates the client of an upstream
+func (def Upstream) dial(ctx context.Context) (client.McpClient, error) {
+	switch {
+	case def.Dial != nil:
+		return def.Dial(ctx)
+	case def.Command != "":
+		cmd := exec.CommandContext(ctx, def.Command, def.Args...)
+		cmd.Env = def.Env
+		cmd.Dir = def.Dir
+		return client.NewStdioClient(cmd, clientOptions(def.ClientOptions))
+	default:
+		return client.NewMcpClient(def.URL, clientOptions(def.ClientOptions), def.Options...)
+	}
+}
+
+func clientOptions(options *client.ClientOptions) client.ClientOptions {
+	if options != nil {
+		return *options
+	}
+	defaults := client.DefaultClientOptions()
+	defaults.UseSSEForEvents = true
+	return defaults
+}
+
+// Start connects to the upstreams in the background, and keeps reconnecting to them until Stop
+func (g *Gateway) Start(ctx context.Context) {
+	g.startMutex.Lock()
+	defer g.startMutex.Unlock()
+	if g.stop != nil {
+		return
+	}
+
+	ctx, g.stop = context.WithCancel(ctx)
+	for _, u := range g.upstreams {
+		g.wg.Add(1)
+		go func(u *upstream) {
+			defer g.wg.Done()
+			u.run(ctx)
+		}(u)
+	}
+}
+
+// Stop disconnects from the upstreams
+func (g *Gateway) Stop() {
+	g.startMutex.Lock()
+	defer g.startMutex.Unlock()
+	if g.stop == nil {
+		return
+	}
+
+	g.stop()
+	g.wg.Wait()
+	g.stop = nil
+}
+
+// Connected reports whether the upstream of the name is connected
+func (g *Gateway) Connected(name string) bool {
+	for _, u := range g.upstreams {
+		if u.def.Name == name {
+			return u.current() != nil
+		}
+	}
+	return false
+}
+
+// ToolRegistry returns the registry of the upstreams' tools
+func (g *Gateway) ToolRegistry() *resources.CompositeToolRegistry {
+	return g.tools
+}
+
+// PromptRegistry returns the registry of the upstreams' prompts
+func (g *Gateway) PromptRegistry() *resources.CompositePromptRegistry {
+	return g.prompts
+}
+
+// ResourceRegistry returns the registry of the upstreams' resources
+func (g *Gateway) ResourceRegistry() *resources.CompositeResourceRegistry {
+	return g.resources
+}
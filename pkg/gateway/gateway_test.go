@@ -0,0 +1,368 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/auth"
+	"github.com/traego/scaled-mcp/pkg/client"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/server"
+	"github.com/traego/scaled-mcp/test/testutils"
+)
+
+type tokenInfo string
+
+func (t tokenInfo) GetPrincipalId() string { return string(t) }
+
+// tokenAuthHandler authenticates requests by their Authorization header, as is
+type tokenAuthHandler struct{}
+
+func (tokenAuthHandler) ExtractAuth(r *http.Request) auth.AuthInfo {
+	return tokenInfo(r.Header.Get("Authorization"))
+}
+
+func (tokenAuthHandler) Serialize(info auth.AuthInfo) ([]byte, error) {
+	return []byte(info.GetPrincipalId()), nil
+}
+
+func (tokenAuthHandler) Deserialize(data []byte) (auth.AuthInfo, error) {
+	return tokenInfo(data), nil
+}
+
+// startUpstream starts an MCP server with a tool, a prompt and a resource, and returns its URL
+func startUpstream(t *testing.T, ctx context.Context) (*server.McpServer, string) {
+	port, err := testutils.GetAvailablePort()
+	require.NoError(t, err)
+	cfg := config.DefaultConfig()
+	cfg.HTTP.Port = port
+
+	tools := resources.NewStaticToolRegistry()
+	require.NoError(t, tools.RegisterTool(protocol.Tool{Name: "whoami"}, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		return auth.GetAuthInfo(ctx).GetPrincipalId(), nil
+	}))
+	require.NoError(t, tools.RegisterTool(protocol.Tool{Name: "image"}, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		return protocol.NewToolCallResult([]protocol.ToolCallContent{protocol.NewImageContent("aGk=", "image/png")}, false), nil
+	}))
+
+	prompts := resources.NewStaticPromptRegistry()
+	require.NoError(t, prompts.RegisterPrompt(resources.NewPrompt("greet").WithUserMessage("Hello upstream").Build()))
+
+	resourceRegistry := resources.NewStaticResourceRegistry()
+	require.NoError(t, resourceRegistry.RegisterResource(resources.Resource{URI: "file:///readme.md", Name: "readme"},
+		func(ctx context.Context, uri string) ([]resources.ResourceContents, error) {
+			return []resources.ResourceContents{resources.NewTextResourceContents(uri, "text/markdown", "# Upstream")}, nil
+		}))
+
+	upstream, err := server.NewMcpServer(cfg,
+		server.WithToolRegistry(tools),
+		server.WithPromptRegistry(prompts),
+		server.WithResourceRegistry(resourceRegistry),
+		server.WithAuthHandler(tokenAuthHandler{}))
+	require.NoError(t, err)
+	require.NoError(t, upstream.Start(ctx))
+	t.Cleanup(func() { upstream.Stop(context.Background()) })
+
+	return upstream, "http://localhost:" + strconv.Itoa(port)
+}
+
+// recorder collects notifications
+type recorder struct {
+	mu      sync.Mutex
+	methods []string
+	params  []interface{}
+}
+
+func (r *recorder) notify(ctx context.Context, method string, params interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.methods = append(r.methods, method)
+	r.params = append(r.params, params)
+}
+
+func (r *recorder) count(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, m := range r.methods {
+		if m == method {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGateway_HTTPUpstream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	upstream, url := startUpstream(t, ctx)
+
+	gw, err := New(Options{}, Upstream{
+		Name:    "alpha",
+		URL:     url,
+		Options: []client.McpClientOptions{client.WithAuthHeader("alpha-token")},
+	})
+	require.NoError(t, err)
+	gw.Start(ctx)
+	defer gw.Stop()
+	require.Eventually(t, func() bool { return gw.Connected("alpha") }, 5*time.Second, 20*time.Millisecond)
+
+	tools, err := gw.ToolRegistry().ListTools(ctx, protocol.ToolListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"alpha_image", "alpha_whoami"}, names)
+
+	t.Run("calls tools with the upstream's credentials", func(t *testing.T) {
+		result, err := gw.ToolRegistry().CallTool(ctx, "alpha_whoami", map[string]interface{}{})
+		require.NoError(t, err)
+		data, err := json.Marshal(result)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"content":[{"type":"text","text":"alpha-token"}],"isError":false}`, string(data))
+
+		result, err = gw.ToolRegistry().CallTool(ctx, "alpha_image", map[string]interface{}{})
+		require.NoError(t, err)
+		data, err = json.Marshal(result)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"content":[{"type":"image","data":"aGk=","mimeType":"image/png"}],"isError":false}`, string(data))
+
+		_, err = gw.ToolRegistry().CallTool(ctx, "alpha_missing", map[string]interface{}{})
+		assert.ErrorIs(t, err, resources.ErrToolNotFound)
+	})
+
+	t.Run("gets prompts", func(t *testing.T) {
+		prompt, ok := gw.PromptRegistry().GetPrompt(ctx, "alpha_greet")
+		require.True(t, ok)
+		require.Len(t, prompt.Messages, 1)
+		assert.Equal(t, "user", prompt.Messages[0].Role)
+	})
+
+	t.Run("reads resources", func(t *testing.T) {
+		listed := gw.ResourceRegistry().ListResources(ctx, resources.ResourceListOptions{}).Resources
+		require.Len(t, listed, 1)
+		assert.Equal(t, "alpha+file:///readme.md", listed[0].URI)
+
+		contents, err := gw.ResourceRegistry().ReadResource(ctx, "alpha+file:///readme.md")
+		require.NoError(t, err)
+		require.Len(t, contents, 1)
+		assert.Equal(t, "# Upstream", contents[0].GetText())
+		assert.Equal(t, "alpha+file:///readme.md", contents[0].GetURI())
+
+		_, err = gw.ResourceRegistry().ReadResource(ctx, "alpha+file:///missing.md")
+		assert.ErrorIs(t, err, resources.ErrResourceNotFound)
+	})
+
+	t.Run("propagates list changes", func(t *testing.T) {
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		defer stopWatch()
+		rec := &recorder{}
+		go func() { _ = gw.ToolRegistry().Watch(watchCtx, rec.notify) }()
+
+		assert.Eventually(t, func() bool {
+			_, _ = upstream.BroadcastNotification(ctx, "notifications/tools/list_changed", nil)
+			return rec.count("notifications/tools/list_changed") > 0
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+}
+
+// stdioUpstream is an in-process MCP server speaking over pipes, as a stdio server would. Each Dial starts a
+// new connection, and Crash ends the current one.
+type stdioUpstream struct {
+	mu     sync.Mutex
+	tools  []string
+	dials  int
+	conn   io.Closer
+	notify chan string
+}
+
+func newStdioUpstream(tools ...string) *stdioUpstream {
+	return &stdioUpstream{tools: tools, notify: make(chan string, 10)}
+}
+
+func (s *stdioUpstream) Dial(ctx context.Context) (client.McpClient, error) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	s.mu.Lock()
+	s.dials++
+	s.conn = serverWriter
+	s.mu.Unlock()
+
+	go s.serve(serverReader, serverWriter)
+	return client.NewStreamClient(clientReader, clientWriter, client.DefaultClientOptions()), nil
+}
+
+func (s *stdioUpstream) Crash() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.conn.Close()
+}
+
+func (s *stdioUpstream) SetTools(tools ...string) {
+	s.mu.Lock()
+	s.tools = tools
+	s.mu.Unlock()
+	s.notify <- "notifications/tools/list_changed"
+}
+
+func (s *stdioUpstream) serve(r *io.PipeReader, w *io.PipeWriter) {
+	defer r.Close()
+	var writeMu sync.Mutex
+	write := func(message protocol.JSONRPCMessage) {
+		data, _ := json.Marshal(message)
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, _ = w.Write(append(data, '\n'))
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case method := <-s.notify:
+				write(protocol.JSONRPCMessage{JSONRPC: "2.0", Method: method})
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var request protocol.JSONRPCMessage
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil || request.ID == nil {
+			continue
+		}
+
+		response := protocol.JSONRPCMessage{JSONRPC: "2.0", ID: request.ID}
+		switch request.Method {
+		case "initialize":
+			response.Result = map[string]interface{}{"protocolVersion": "2025-03-26", "capabilities": map[string]interface{}{}}
+		case "ping":
+			response.Result = map[string]interface{}{}
+		case "tools/list":
+			s.mu.Lock()
+			tools := make([]protocol.Tool, 0, len(s.tools))
+			for _, name := range s.tools {
+				tools = append(tools, protocol.Tool{Name: name})
+			}
+			s.mu.Unlock()
+			response.Result = protocol.ToolListResult{Tools: tools}
+		case "tools/call":
+			params, _ := request.Params.(map[string]interface{})
+			if params["name"] == "fail" {
+				response.Error = protocol.NewInvalidParamsError("bad arguments", request.ID)
+				break
+			}
+			response.Result = protocol.NewToolCallResult([]protocol.ToolCallContent{protocol.NewTextContent("stdio:" + params["name"].(string))}, false)
+		default:
+			response.Result = map[string]interface{}{}
+		}
+		write(response)
+	}
+}
+
+func TestGateway_StdioUpstream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stdio := newStdioUpstream("echo", "fail")
+	gw, err := New(Options{PingInterval: 50 * time.Millisecond, MinReconnectWait: 10 * time.Millisecond},
+		Upstream{Name: "local", Dial: stdio.Dial})
+	require.NoError(t, err)
+
+	rec := &recorder{}
+	go func() { _ = gw.ToolRegistry().Watch(ctx, rec.notify) }()
+
+	gw.Start(ctx)
+	defer gw.Stop()
+	require.Eventually(t, func() bool { return gw.Connected("local") }, 5*time.Second, 10*time.Millisecond)
+
+	result, err := gw.ToolRegistry().CallTool(ctx, "local_echo", map[string]interface{}{})
+	require.NoError(t, err)
+	callResult, ok := result.(protocol.ToolCallResult)
+	require.True(t, ok)
+	require.Len(t, callResult.Content, 1)
+	assert.Equal(t, "text", callResult.Content[0].GetType())
+
+	// Errors of the upstream are passed on as they are
+	_, err = gw.ToolRegistry().CallTool(ctx, "local_fail", map[string]interface{}{})
+	var rpcErr *protocol.JsonRpcError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, protocol.ErrInvalidParams, rpcErr.Code)
+
+	t.Run("list changes reach watchers and refresh the list", func(t *testing.T) {
+		before := rec.count("notifications/tools/list_changed")
+		stdio.SetTools("echo", "added")
+		require.Eventually(t, func() bool { return rec.count("notifications/tools/list_changed") > before }, 5*time.Second, 10*time.Millisecond)
+
+		_, err := gw.ToolRegistry().GetTool(ctx, "local_added")
+		assert.NoError(t, err)
+	})
+
+	t.Run("reconnects", func(t *testing.T) {
+		stdio.Crash()
+		require.Eventually(t, func() bool {
+			stdio.mu.Lock()
+			defer stdio.mu.Unlock()
+			return stdio.dials >= 2
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Eventually(t, func() bool { return gw.Connected("local") }, 5*time.Second, 10*time.Millisecond)
+
+		result, err := gw.ToolRegistry().CallTool(ctx, "local_echo", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "text", result.(protocol.ToolCallResult).Content[0].GetType())
+	})
+}
+
+func TestGateway_UnavailableUpstream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	port, err := testutils.GetAvailablePort()
+	require.NoError(t, err)
+	gw, err := New(Options{MinReconnectWait: 10 * time.Millisecond},
+		Upstream{Name: "down", URL: "http://localhost:" + strconv.Itoa(port)},
+		Upstream{Name: "local", Dial: newStdioUpstream("echo").Dial})
+	require.NoError(t, err)
+	gw.Start(ctx)
+	defer gw.Stop()
+	require.Eventually(t, func() bool { return gw.Connected("local") }, 5*time.Second, 10*time.Millisecond)
+
+	// The items of the other upstreams are still served
+	tools, err := gw.ToolRegistry().ListTools(ctx, protocol.ToolListOptions{})
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "local_echo", tools.Tools[0].Name)
+	assert.False(t, gw.Connected("down"))
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(Options{}, Upstream{URL: "http://localhost"})
+	assert.Error(t, err, "upstreams need a name")
+
+	_, err = New(Options{}, Upstream{Name: "a"})
+	assert.Error(t, err, "upstreams need a transport")
+
+	_, err = New(Options{}, Upstream{Name: "a", URL: "http://localhost", Command: "server"})
+	assert.Error(t, err, "upstreams have one transport")
+
+	_, err = New(Options{}, Upstream{Name: "a", URL: "http://localhost"}, Upstream{Name: "a", Command: "server"})
+	assert.Error(t, err, "upstream names are unique")
+}
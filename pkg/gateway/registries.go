@@ -0,0 +1,248 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/uritemplate"
+)
+
+// toolRegistry serves the tools of an upstream
+type toolRegistry struct {
+	u *upstream
+}
+
+// GetTool returns a tool of the upstream
+func (r *toolRegistry) GetTool(ctx context.Context, name string) (protocol.Tool, error) {
+	for _, tool := range list(ctx, r.u, "tools/list", "tools", &r.u.tools) {
+		if tool.Name == name {
+			return tool, nil
+		}
+	}
+	return protocol.Tool{}, resources.ErrToolNotFound
+}
+
+// ListTools returns a page of the upstream's tools
+func (r *toolRegistry) ListTools(ctx context.Context, opts protocol.ToolListOptions) (protocol.ToolListResult, error) {
+	tools, next := resources.Paginate(list(ctx, r.u, "tools/list", "tools", &r.u.tools),
+		func(tool protocol.Tool) string { return tool.Name }, opts.Cursor, opts.PageSize)
+	return protocol.ToolListResult{Tools: tools, NextCursor: next}, nil
+}
+
+// CallTool calls a tool of the upstream, returning its result as the upstream made it
+func (r *toolRegistry) CallTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	if _, err := r.GetTool(ctx, name); err != nil {
+		return nil, err
+	}
+
+	result, err := r.u.request(ctx, "tools/call", map[string]interface{}{"name": name, "arguments": params})
+	if err != nil {
+		return nil, err
+	}
+
+	var call struct {
+		Content []json.RawMessage `json:"content"`
+		IsError bool              `json:"isError"`
+	}
+	if err := decode(result, &call); err != nil {
+		return nil, fmt.Errorf("invalid result of tool %s of upstream %s: %w", name, r.u.def.Name, err)
+	}
+
+	items := make([]protocol.ToolCallContent, 0, len(call.Content))
+	for _, raw := range call.Content {
+		var item struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, fmt.Errorf("invalid content of tool %s of upstream %s: %w", name, r.u.def.Name, err)
+		}
+		items = append(items, content{kind: item.Type, raw: raw})
+	}
+	return protocol.NewToolCallResult(items, call.IsError), nil
+}
+
+// Watch passes on the upstream's tool notifications
+func (r *toolRegistry) Watch(ctx context.Context, notify resources.Notifier) error {
+	return r.u.watch(ctx, "notifications/tools/", notify)
+}
+
+// content is an item of an upstream's tool result, passed on unchanged, whatever its type
+type content struct {
+	kind string
+	raw  json.RawMessage
+}
+
+// GetType returns the type of the item
+func (c content) GetType() string {
+	return c.kind
+}
+
+// MarshalJSON returns the item as the upstream made it
+func (c content) MarshalJSON() ([]byte, error) {
+	return c.raw, nil
+}
+
+// promptRegistry serves the prompts of an upstream
+type promptRegistry struct {
+	u *upstream
+}
+
+// GetPrompt returns a prompt of the upstream. The messages of a prompt without required arguments are fetched
+// from the upstream, as listings do not have them.
+func (r *promptRegistry) GetPrompt(ctx context.Context, name string) (resources.Prompt, bool) {
+	for _, prompt := range list(ctx, r.u, "prompts/list", "prompts", &r.u.prompts) {
+		if prompt.Name != name {
+			continue
+		}
+		for _, argument := range prompt.Arguments {
+			if argument.Required {
+				return prompt, true
+			}
+		}
+		if messages, err := r.get(ctx, name, nil); err == nil {
+			prompt.Messages = messages
+		}
+		return prompt, true
+	}
+	return resources.Prompt{}, false
+}
+
+// ListPrompts returns a page of the upstream's prompts
+func (r *promptRegistry) ListPrompts(ctx context.Context, opts resources.PromptListOptions) resources.PromptListResult {
+	prompts, next := resources.Paginate(list(ctx, r.u, "prompts/list", "prompts", &r.u.prompts),
+		func(prompt resources.Prompt) string { return prompt.Name }, opts.Cursor, opts.PageSize)
+	return resources.PromptListResult{Prompts: prompts, NextCursor: next}
+}
+
+// ProcessPrompt gets a prompt of the upstream with the arguments
+func (r *promptRegistry) ProcessPrompt(ctx context.Context, name string, arguments map[string]string) ([]resources.PromptMessage, error) {
+	if _, ok := r.GetPrompt(ctx, name); !ok {
+		return nil, resources.ErrPromptNotFound
+	}
+	return r.get(ctx, name, arguments)
+}
+
+// get returns the messages of a prompt of the upstream
+func (r *promptRegistry) get(ctx context.Context, name string, arguments map[string]string) ([]resources.PromptMessage, error) {
+	params := map[string]interface{}{"name": name}
+	if arguments != nil {
+		params["arguments"] = arguments
+	}
+	result, err := r.u.request(ctx, "prompts/get", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []resources.PromptMessage
+	if err := decodeField(result, "messages", &messages); err != nil {
+		return nil, fmt.Errorf("invalid messages of prompt %s of upstream %s: %w", name, r.u.def.Name, err)
+	}
+	return messages, nil
+}
+
+// Watch passes on the upstream's prompt notifications
+func (r *promptRegistry) Watch(ctx context.Context, notify resources.Notifier) error {
+	return r.u.watch(ctx, "notifications/prompts/", notify)
+}
+
+// resourceRegistry serves the resources and resource templates of an upstream
+type resourceRegistry struct {
+	u *upstream
+}
+
+// ListResources returns a page of the upstream's resources
+func (r *resourceRegistry) ListResources(ctx context.Context, opts resources.ResourceListOptions) resources.ResourceListResult {
+	page, next := resources.Paginate(list(ctx, r.u, "resources/list", "resources", &r.u.resources),
+		func(resource resources.Resource) string { return resource.URI }, opts.Cursor, opts.PageSize)
+	return resources.ResourceListResult{Resources: page, NextCursor: next}
+}
+
+// ListResourceTemplates returns a page of the upstream's resource templates
+func (r *resourceRegistry) ListResourceTemplates(ctx context.Context, opts resources.ResourceTemplateListOptions) resources.ResourceTemplateListResult {
+	page, next := resources.Paginate(list(ctx, r.u, "resources/templates/list", "resourceTemplates", &r.u.templates),
+		func(template resources.ResourceTemplate) string { return template.URITemplate }, opts.Cursor, opts.PageSize)
+	return resources.ResourceTemplateListResult{ResourceTemplates: page, NextCursor: next}
+}
+
+// serves reports whether the upstream lists a resource of the URI, or a template matching it
+func (r *resourceRegistry) serves(ctx context.Context, uri string) bool {
+	for _, resource := range list(ctx, r.u, "resources/list", "resources", &r.u.resources) {
+		if resource.URI == uri {
+			return true
+		}
+	}
+	for _, template := range list(ctx, r.u, "resources/templates/list", "resourceTemplates", &r.u.templates) {
+		if t, err := uritemplate.Parse(template.URITemplate); err == nil {
+			if _, ok := t.Match(uri); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadResource reads a resource of the upstream
+func (r *resourceRegistry) ReadResource(ctx context.Context, uri string) ([]resources.ResourceContents, error) {
+	if !r.serves(ctx, uri) {
+		return nil, resources.ErrResourceNotFound
+	}
+
+	result, err := r.u.request(ctx, "resources/read", map[string]interface{}{"uri": uri})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []struct {
+		URI      string  `json:"uri"`
+		MimeType string  `json:"mimeType"`
+		Text     *string `json:"text"`
+		Blob     []byte  `json:"blob"`
+	}
+	// The spec wraps the contents in an object, but servers of this module answer with the array itself
+	if fields, ok := result.(map[string]interface{}); ok {
+		result = fields["contents"]
+	}
+	if err := decode(result, &items); err != nil {
+		return nil, fmt.Errorf("invalid contents of resource %s of upstream %s: %w", uri, r.u.def.Name, err)
+	}
+
+	contents := make([]resources.ResourceContents, 0, len(items))
+	for _, item := range items {
+		if item.Text != nil {
+			contents = append(contents, resources.NewTextResourceContents(item.URI, item.MimeType, *item.Text))
+		} else {
+			contents = append(contents, resources.NewBinaryResourceContents(item.URI, item.MimeType, item.Blob))
+		}
+	}
+	return contents, nil
+}
+
+// SubscribeResource subscribes to updates of a resource of the upstream
+func (r *resourceRegistry) SubscribeResource(ctx context.Context, uri string) error {
+	if !r.serves(ctx, uri) {
+		return resources.ErrResourceNotFound
+	}
+	return r.u.subscribe(ctx, uri)
+}
+
+// UnsubscribeResource unsubscribes from updates of a resource of the upstream
+func (r *resourceRegistry) UnsubscribeResource(ctx context.Context, uri string) error {
+	return r.u.unsubscribe(ctx, uri)
+}
+
+// Watch passes on the upstream's resource notifications
+func (r *resourceRegistry) Watch(ctx context.Context, notify resources.Notifier) error {
+	return r.u.watch(ctx, "notifications/resources/", notify)
+}
+
+var (
+	_ resources.ToolRegistry     = (*toolRegistry)(nil)
+	_ resources.PromptRegistry   = (*promptRegistry)(nil)
+	_ resources.ResourceRegistry = (*resourceRegistry)(nil)
+	_ resources.Watcher          = (*toolRegistry)(nil)
+	_ resources.Watcher          = (*promptRegistry)(nil)
+	_ resources.Watcher          = (*resourceRegistry)(nil)
+)
@@ -0,0 +1,390 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/client"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+// upstream is the connection to one upstream server, and the cache of its lists
+type upstream struct {
+	def  Upstream
+	opts Options
+
+	mu     sync.RWMutex
+	client client.McpClient
+	lost   chan struct{} // closed when the connection of client is found broken
+
+	tools     []protocol.Tool
+	prompts   []resources.Prompt
+	resources []resources.Resource
+	templates []resources.ResourceTemplate
+	cached    map[string]bool // list method -> whether its list is cached
+	version   int             // changes whenever cached lists become stale
+
+	subscriptions map[string]map[string]bool // uri -> set of subscriber IDs
+
+	listenersMutex sync.Mutex
+	listeners      map[int]listener
+	nextListener   int
+}
+
+// listener is a Watch of one of the upstream's registries, told of the notifications under prefix
+type listener struct {
+	ctx    context.Context
+	prefix string
+	notify resources.Notifier
+}
+
+func newUpstream(def Upstream, opts Options) *upstream {
+	return &upstream{
+		def:           def,
+		opts:          opts,
+		cached:        make(map[string]bool),
+		subscriptions: make(map[string]map[string]bool),
+		listeners:     make(map[int]listener),
+	}
+}
+
+// run connects to the upstream, and reconnects whenever the connection is lost, until ctx is done
+func (u *upstream) run(ctx context.Context) {
+	wait := u.opts.MinReconnectWait
+	for {
+		c, err := u.connect(ctx)
+		if err == nil {
+			wait = u.opts.MinReconnectWait
+			u.serve(ctx, c)
+		} else if ctx.Err() == nil {
+			u.opts.Logger.Warn("Failed to connect to upstream", "upstream", u.def.Name, "error", err, "retry_in", wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if err != nil {
+			wait = min(wait*2, u.opts.MaxReconnectWait)
+		}
+	}
+}
+
+// connect creates and initializes a client of the upstream. Clients may keep ctx for the life of the
+// connection, such as for an SSE stream, so it is not bounded by the request timeout.
+func (u *upstream) connect(ctx context.Context) (client.McpClient, error) {
+	c, err := u.def.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Connect(ctx); err != nil {
+		_ = c.Close(context.Background())
+		return nil, err
+	}
+	return c, nil
+}
+
+// serve uses a connected client until its connection is lost or ctx is done
+func (u *upstream) serve(ctx context.Context, c client.McpClient) {
+	c.AddEventHandler(u)
+
+	lost := make(chan struct{})
+	u.mu.Lock()
+	u.client = c
+	u.lost = lost
+	u.cached = make(map[string]bool)
+	u.version++
+	u.mu.Unlock()
+	u.opts.Logger.Info("Connected to upstream", "upstream", u.def.Name)
+
+	u.resubscribe(ctx)
+	// The upstream's items may have changed while it was away
+	u.broadcast("notifications/tools/list_changed", nil)
+	u.broadcast("notifications/prompts/list_changed", nil)
+	u.broadcast("notifications/resources/list_changed", nil)
+
+	ticker := time.NewTicker(u.opts.PingInterval)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-lost:
+			break loop
+		case <-ticker.C:
+			// An upstream answering with an error, even one not knowing ping, is still there
+			if _, err := u.request(ctx, "ping", nil); errors.Is(err, ErrUpstreamUnavailable) {
+				break loop
+			}
+		}
+	}
+
+	u.mu.Lock()
+	u.client = nil
+	u.cached = make(map[string]bool)
+	u.version++
+	u.mu.Unlock()
+	c.RemoveEventHandler(u)
+	_ = c.Close(context.Background())
+
+	if ctx.Err() == nil {
+		u.opts.Logger.Warn("Lost connection to upstream", "upstream", u.def.Name)
+		u.broadcast("notifications/tools/list_changed", nil)
+		u.broadcast("notifications/prompts/list_changed", nil)
+		u.broadcast("notifications/resources/list_changed", nil)
+	}
+}
+
+// current returns the client of the upstream, nil while it is not connected
+func (u *upstream) current() client.McpClient {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.client
+}
+
+// request sends a request to the upstream and returns its result. JSON-RPC errors of the upstream are returned
+// as *protocol.JsonRpcError; other errors mean the connection is broken, and start a reconnect.
+func (u *upstream) request(ctx context.Context, method string, params interface{}) (interface{}, error) {
+	u.mu.RLock()
+	c, lost := u.client, u.lost
+	u.mu.RUnlock()
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUpstreamUnavailable, u.def.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.opts.RequestTimeout)
+	defer cancel()
+	resp, err := c.SendRequest(ctx, method, params)
+	if err != nil {
+		var rpcErr *protocol.JsonRpcError
+		if errors.As(err, &rpcErr) {
+			return nil, rpcErr
+		}
+		if ctx.Err() == nil {
+			u.markLost(c, lost)
+		}
+		return nil, fmt.Errorf("%w: %s: %v", ErrUpstreamUnavailable, u.def.Name, err)
+	}
+	return resp.Result, nil
+}
+
+// markLost ends the connection of c, if it is still the current one
+func (u *upstream) markLost(c client.McpClient, lost chan struct{}) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.client != c {
+		return
+	}
+	select {
+	case <-lost:
+	default:
+		close(lost)
+	}
+}
+
+// HandleEvent invalidates the lists a notification of the upstream changes, and passes it on to watchers
+func (u *upstream) HandleEvent(event *protocol.JSONRPCMessage) {
+	switch event.Method {
+	case "notifications/tools/list_changed":
+		u.invalidate("tools/list")
+	case "notifications/prompts/list_changed":
+		u.invalidate("prompts/list")
+	case "notifications/resources/list_changed":
+		u.invalidate("resources/list", "resources/templates/list")
+	case "notifications/resources/updated":
+	default:
+		return
+	}
+	u.broadcast(event.Method, event.Params)
+}
+
+func (u *upstream) invalidate(methods ...string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, method := range methods {
+		delete(u.cached, method)
+	}
+	u.version++
+}
+
+// watch tells notify of the notifications under prefix until ctx is done
+func (u *upstream) watch(ctx context.Context, prefix string, notify resources.Notifier) error {
+	u.listenersMutex.Lock()
+	id := u.nextListener
+	u.nextListener++
+	u.listeners[id] = listener{ctx: ctx, prefix: prefix, notify: notify}
+	u.listenersMutex.Unlock()
+
+	<-ctx.Done()
+
+	u.listenersMutex.Lock()
+	delete(u.listeners, id)
+	u.listenersMutex.Unlock()
+	return nil
+}
+
+// broadcast tells the watchers of a notification
+func (u *upstream) broadcast(method string, params interface{}) {
+	u.listenersMutex.Lock()
+	defer u.listenersMutex.Unlock()
+	for _, l := range u.listeners {
+		if strings.HasPrefix(method, l.prefix) {
+			l.notify(l.ctx, method, params)
+		}
+	}
+}
+
+// list returns every item of a list method, following the upstream's cursors, and caches them until the
+// upstream says the list changed or the connection is lost. Lists of an upstream that is not connected are
+// empty.
+func list[T any](ctx context.Context, u *upstream, method, field string, items *[]T) []T {
+	u.mu.RLock()
+	if u.cached[method] {
+		cached := *items
+		u.mu.RUnlock()
+		return cached
+	}
+	c, version := u.client, u.version
+	u.mu.RUnlock()
+	if c == nil {
+		return nil
+	}
+
+	var all []T
+	cursor := ""
+	for {
+		var params interface{}
+		if cursor != "" {
+			params = map[string]interface{}{"cursor": cursor}
+		}
+		result, err := u.request(ctx, method, params)
+		if err != nil {
+			u.opts.Logger.Warn("Failed to list items of upstream", "upstream", u.def.Name, "method", method, "error", err)
+			return nil
+		}
+
+		var page []T
+		var next string
+		if err := decodeField(result, field, &page); err == nil {
+			err = decodeField(result, "nextCursor", &next)
+		}
+		if err != nil {
+			u.opts.Logger.Warn("Invalid list of upstream", "upstream", u.def.Name, "method", method, "error", err)
+			return nil
+		}
+		all = append(all, page...)
+
+		if next == "" || next == cursor {
+			break
+		}
+		cursor = next
+	}
+
+	// Keep the list unless it changed, or the connection did, while it was fetched
+	u.mu.Lock()
+	if u.version == version {
+		*items = all
+		u.cached[method] = true
+	}
+	u.mu.Unlock()
+	return all
+}
+
+// decode converts a result decoded as generic JSON into v
+func decode(result interface{}, v interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// decodeField converts one field of a result decoded as generic JSON into v
+func decodeField(result interface{}, field string, v interface{}) error {
+	fields, ok := result.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("result is not an object")
+	}
+	return decode(fields[field], v)
+}
+
+// subscriberID returns the ID of the subscriber of ctx, as the other registries do
+func subscriberID(ctx context.Context) string {
+	if id, ok := ctx.Value(resources.SubscriberIDKey).(string); ok && id != "" {
+		return id
+	}
+	return "default"
+}
+
+// subscribe subscribes to a resource upstream for its first subscriber
+func (u *upstream) subscribe(ctx context.Context, uri string) error {
+	id := subscriberID(ctx)
+
+	u.mu.Lock()
+	first := len(u.subscriptions[uri]) == 0
+	u.mu.Unlock()
+
+	if first {
+		if _, err := u.request(ctx, "resources/subscribe", map[string]interface{}{"uri": uri}); err != nil {
+			return err
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.subscriptions[uri] == nil {
+		u.subscriptions[uri] = make(map[string]bool)
+	}
+	u.subscriptions[uri][id] = true
+	return nil
+}
+
+// unsubscribe unsubscribes from a resource upstream once its last subscriber left
+func (u *upstream) unsubscribe(ctx context.Context, uri string) error {
+	id := subscriberID(ctx)
+
+	u.mu.Lock()
+	subscribers := u.subscriptions[uri]
+	if !subscribers[id] {
+		u.mu.Unlock()
+		return resources.ErrResourceNotFound
+	}
+	delete(subscribers, id)
+	last := len(subscribers) == 0
+	if last {
+		delete(u.subscriptions, uri)
+	}
+	u.mu.Unlock()
+
+	if last {
+		if _, err := u.request(ctx, "resources/unsubscribe", map[string]interface{}{"uri": uri}); err != nil {
+			u.opts.Logger.Debug("Failed to unsubscribe from upstream resource", "upstream", u.def.Name, "uri", uri, "error", err)
+		}
+	}
+	return nil
+}
+
+// resubscribe subscribes again to the resources subscribed to before a reconnect
+func (u *upstream) resubscribe(ctx context.Context) {
+	u.mu.RLock()
+	uris := make([]string, 0, len(u.subscriptions))
+	for uri := range u.subscriptions {
+		uris = append(uris, uri)
+	}
+	u.mu.RUnlock()
+
+	for _, uri := range uris {
+		if _, err := u.request(ctx, "resources/subscribe", map[string]interface{}{"uri": uri}); err != nil {
+			u.opts.Logger.Warn("Failed to subscribe again to upstream resource", "upstream", u.def.Name, "uri", uri, "error", err)
+		}
+	}
+}
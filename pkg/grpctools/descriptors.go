@@ -0,0 +1,191 @@
+// Package grpctools turns the RPCs of gRPC services into MCP tools, called with dynamic messages.
+package grpctools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// reflectionService is the package of the reflection services, which are never made into tools
+const reflectionService = "grpc.reflection."
+
+// LoadDescriptorSet reads a binary FileDescriptorSet, as written by protoc --descriptor_set_out or buf build -o,
+// which must include the imports of its files
+func LoadDescriptorSet(path string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	return ParseDescriptorSet(data)
+}
+
+// ParseDescriptorSet parses a binary FileDescriptorSet
+func ParseDescriptorSet(data []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	return files, nil
+}
+
+// Reflect discovers the services of a server through the v1 server reflection service, returning the
+// descriptors of their files and the names of the services. Imports the server does not describe, such as
+// well-known types, are taken from protoregistry.GlobalFiles.
+func Reflect(ctx context.Context, conn grpc.ClientConnInterface) (*protoregistry.Files, []string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open reflection stream: %w", err)
+	}
+	r := &reflector{stream: stream, protos: make(map[string]*descriptorpb.FileDescriptorProto)}
+
+	resp, err := r.roundTrip(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		if strings.HasPrefix(service.GetName(), reflectionService) {
+			continue
+		}
+		services = append(services, service.GetName())
+		if err := r.fetch(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service.GetName()},
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to describe service %s: %w", service.GetName(), err)
+		}
+	}
+	if err := r.fetchImports(); err != nil {
+		return nil, nil, err
+	}
+	_ = stream.CloseSend()
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fdp := range r.protos {
+		set.File = append(set.File, fdp)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid descriptors from reflection: %w", err)
+	}
+	sort.Strings(services)
+	return files, services, nil
+}
+
+// reflector collects file descriptors over a reflection stream
+type reflector struct {
+	stream reflectionpb.ServerReflection_ServerReflectionInfoClient
+	protos map[string]*descriptorpb.FileDescriptorProto
+}
+
+// roundTrip sends a request and receives its response, turning an error response into an error
+func (r *reflector) roundTrip(req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	if err := r.stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := r.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, fmt.Errorf("reflection error %d: %s", e.GetErrorCode(), e.GetErrorMessage())
+	}
+	return resp, nil
+}
+
+// fetch adds the file descriptors a request returns, which are usually a file and its imports
+func (r *reflector) fetch(req *reflectionpb.ServerReflectionRequest) error {
+	resp, err := r.roundTrip(req)
+	if err != nil {
+		return err
+	}
+	for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fdp := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(data, fdp); err != nil {
+			return fmt.Errorf("invalid file descriptor: %w", err)
+		}
+		r.protos[fdp.GetName()] = fdp
+	}
+	return nil
+}
+
+// fetchImports adds the imports that were not sent along with the files importing them
+func (r *reflector) fetchImports() error {
+	for {
+		var missing []string
+		for _, fdp := range r.protos {
+			for _, dependency := range fdp.GetDependency() {
+				if _, ok := r.protos[dependency]; !ok {
+					missing = append(missing, dependency)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		for _, path := range missing {
+			if _, ok := r.protos[path]; ok {
+				continue
+			}
+			if fd, err := protoregistry.GlobalFiles.FindFileByPath(path); err == nil {
+				r.protos[path] = protodesc.ToFileDescriptorProto(fd)
+				continue
+			}
+			if err := r.fetch(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: path},
+			}); err != nil {
+				return fmt.Errorf("failed to describe file %s: %w", path, err)
+			}
+			if _, ok := r.protos[path]; !ok {
+				return fmt.Errorf("server did not describe file %s", path)
+			}
+		}
+	}
+}
+
+// fileServices returns the names of the services of the files
+func fileServices(files *protoregistry.Files) []string {
+	var services []string
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			services = append(services, string(fd.Services().Get(i).FullName()))
+		}
+		return true
+	})
+	sort.Strings(services)
+	return services
+}
+
+// findService returns the descriptor of a service
+func findService(files *protoregistry.Files, name string) (protoreflect.ServiceDescriptor, error) {
+	d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("service %s is not described: %w", name, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", name)
+	}
+	return sd, nil
+}
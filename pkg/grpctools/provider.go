@@ -0,0 +1,245 @@
+package grpctools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/protomcp"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DefaultMaxStreamMessages is how many messages of a server stream are put in a tool result when no limit is
+// configured
+const DefaultMaxStreamMessages = 100
+
+// MetadataFunc returns metadata to send with a call, such as credentials. It gets the context of the tool call,
+// so it may pass on credentials of the caller.
+type MetadataFunc func(ctx context.Context) (metadata.MD, error)
+
+// BearerAuth authenticates calls with a bearer token
+func BearerAuth(token string) MetadataFunc {
+	return func(ctx context.Context) (metadata.MD, error) {
+		return metadata.Pairs("authorization", "Bearer "+token), nil
+	}
+}
+
+// ProgressFunc is told of each message of a server stream as it arrives, counting from 1, with the message in
+// JSON. It may send them on as progress notifications of the tool call.
+type ProgressFunc func(ctx context.Context, tool string, progress int, message string)
+
+// Options configures a ToolProvider
+type Options struct {
+	// Files describes the services, such as a descriptor set of LoadDescriptorSet. If nil, the services and
+	// their descriptors are discovered through server reflection.
+	Files *protoregistry.Files
+
+	// IncludeServices selects the services, by full name, whose RPCs are made into tools. All services are
+	// selected if empty.
+	IncludeServices []string
+
+	// ExcludeServices leaves out services, even selected ones
+	ExcludeServices []string
+
+	// Metadata adds metadata to each call, such as BearerAuth
+	Metadata MetadataFunc
+
+	// CallOptions are passed to each call
+	CallOptions []grpc.CallOption
+
+	// Progress is told of the messages of server streams
+	Progress ProgressFunc
+
+	// MaxStreamMessages is how many messages of a server stream are put in a tool result, DefaultMaxStreamMessages
+	// if zero. The stream is cancelled after that many.
+	MaxStreamMessages int
+
+	// PageSize is the number of tools listed per page, resources.DefaultPageSize if zero
+	PageSize int
+}
+
+// method is an RPC made into a tool
+type method struct {
+	tool   protocol.Tool
+	desc   protoreflect.MethodDescriptor
+	path   string // /package.Service/Method
+	stream bool   // whether the server streams its response
+}
+
+// ToolProvider serves the unary and server streaming RPCs of gRPC services as tools, for a
+// resources.DynamicToolRegistry. Tools are named by protomcp.ToolName, such as helloworld_Greeter_SayHello, and
+// take the fields of their request message as arguments, in their protobuf
+// JSON form. Client and bidirectional streaming RPCs are not made into tools.
+type ToolProvider struct {
+	conn    grpc.ClientConnInterface
+	opts    Options
+	methods map[string]*method
+	tools   []protocol.Tool
+}
+
+// NewToolProvider creates a provider of the RPCs of the services served on conn that opts selects
+func NewToolProvider(ctx context.Context, conn grpc.ClientConnInterface, opts Options) (*ToolProvider, error) {
+	if opts.MaxStreamMessages <= 0 {
+		opts.MaxStreamMessages = DefaultMaxStreamMessages
+	}
+
+	var services []string
+	if opts.Files == nil {
+		files, reflected, err := Reflect(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		opts.Files, services = files, reflected
+	} else {
+		services = fileServices(opts.Files)
+	}
+
+	p := &ToolProvider{
+		conn:    conn,
+		opts:    opts,
+		methods: make(map[string]*method),
+	}
+	for _, name := range services {
+		if !p.selected(name) {
+			continue
+		}
+		sd, err := findService(opts.Files, name)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < sd.Methods().Len(); i++ {
+			md := sd.Methods().Get(i)
+			if md.IsStreamingClient() {
+				continue
+			}
+			m := newMethod(md)
+			if _, ok := p.methods[m.tool.Name]; ok {
+				return nil, fmt.Errorf("RPCs %s and %s are both named %s", p.methods[m.tool.Name].path, m.path, m.tool.Name)
+			}
+			p.methods[m.tool.Name] = m
+			p.tools = append(p.tools, m.tool)
+		}
+	}
+	sort.Slice(p.tools, func(i, j int) bool { return p.tools[i].Name < p.tools[j].Name })
+	return p, nil
+}
+
+// selected reports whether the options select a service
+func (p *ToolProvider) selected(service string) bool {
+	if slices.Contains(p.opts.ExcludeServices, service) {
+		return false
+	}
+	return len(p.opts.IncludeServices) == 0 || slices.Contains(p.opts.IncludeServices, service)
+}
+
+// newMethod makes a tool of an RPC
+func newMethod(md protoreflect.MethodDescriptor) *method {
+	return &method{
+		tool: protocol.Tool{
+			Name:        protomcp.ToolName(md),
+			Description: protomcp.Description(md),
+			InputSchema: protomcp.InputSchema(md.Input()),
+		},
+		desc:   md,
+		path:   fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name()),
+		stream: md.IsStreamingServer(),
+	}
+}
+
+// GetTool returns the tool of an RPC
+func (p *ToolProvider) GetTool(ctx context.Context, name string) (protocol.Tool, error) {
+	m, ok := p.methods[name]
+	if !ok {
+		return protocol.Tool{}, resources.ErrToolNotFound
+	}
+	return m.tool, nil
+}
+
+// ListTools returns a page of the tools, ordered by name
+func (p *ToolProvider) ListTools(ctx context.Context, cursor string) (protocol.ToolListResult, error) {
+	tools, next := resources.Paginate(p.tools, func(tool protocol.Tool) string { return tool.Name }, cursor, p.opts.PageSize)
+	return protocol.ToolListResult{Tools: tools, NextCursor: next}, nil
+}
+
+// HandleToolInvocation calls the RPC of a tool, with the arguments as its request message. The response, or
+// each streamed message, is a text item of the result in protobuf JSON. RPCs failing with a status are error
+// results.
+func (p *ToolProvider) HandleToolInvocation(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	m, ok := p.methods[name]
+	if !ok {
+		return nil, resources.ErrToolNotFound
+	}
+
+	req := dynamicpb.NewMessage(m.desc.Input())
+	if err := protomcp.UnmarshalArguments(params, req); err != nil {
+		return nil, err
+	}
+
+	if p.opts.Metadata != nil {
+		md, err := p.opts.Metadata(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get call metadata: %w", err)
+		}
+		outgoing, _ := metadata.FromOutgoingContext(ctx)
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Join(outgoing, md))
+	}
+
+	if !m.stream {
+		resp := dynamicpb.NewMessage(m.desc.Output())
+		return protomcp.Result(resp, p.conn.Invoke(ctx, m.path, req, resp, p.opts.CallOptions...))
+	}
+	items, err := p.stream(ctx, m, req)
+	if err != nil {
+		return protomcp.StatusResult(err)
+	}
+	return protocol.NewToolCallResult(items, false), nil
+}
+
+// stream calls a server streaming RPC, returning up to MaxStreamMessages of its messages
+func (p *ToolProvider) stream(ctx context.Context, m *method, req *dynamicpb.Message) ([]protocol.ToolCallContent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := p.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, m.path, p.opts.CallOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var items []protocol.ToolCallContent
+	for len(items) < p.opts.MaxStreamMessages {
+		resp := dynamicpb.NewMessage(m.desc.Output())
+		if err := stream.RecvMsg(resp); err != nil {
+			if errors.Is(err, io.EOF) {
+				return items, nil
+			}
+			return nil, err
+		}
+		item, err := protomcp.MarshalContent(resp)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.opts.Progress != nil {
+			p.opts.Progress(ctx, m.tool.Name, len(items), item.(protocol.TextContent).Text)
+		}
+	}
+	return items, nil
+}
+
+// Ensure ToolProvider implements resources.ToolProvider
+var _ resources.ToolProvider = (*ToolProvider)(nil)
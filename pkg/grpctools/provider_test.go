@@ -0,0 +1,275 @@
+package grpctools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// petstore describes the service served in tests, which has no generated code
+const petstore = `{
+	"name": "petstore/v1/petstore.proto",
+	"package": "petstore.v1",
+	"dependency": ["google/protobuf/timestamp.proto"],
+	"syntax": "proto3",
+	"messageType": [
+		{"name": "GetPetRequest", "field": [
+			{"name": "pet_id", "number": 1, "type": "TYPE_STRING", "label": "LABEL_OPTIONAL", "jsonName": "petId"}
+		]},
+		{"name": "ListPetsRequest", "field": [
+			{"name": "limit", "number": 1, "type": "TYPE_INT32", "label": "LABEL_OPTIONAL", "jsonName": "limit"},
+			{"name": "kind", "number": 2, "type": "TYPE_ENUM", "typeName": ".petstore.v1.Kind", "label": "LABEL_OPTIONAL", "jsonName": "kind"}
+		]},
+		{"name": "Pet", "field": [
+			{"name": "pet_id", "number": 1, "type": "TYPE_STRING", "label": "LABEL_OPTIONAL", "jsonName": "petId"},
+			{"name": "tags", "number": 2, "type": "TYPE_STRING", "label": "LABEL_REPEATED", "jsonName": "tags"},
+			{"name": "born", "number": 3, "type": "TYPE_MESSAGE", "typeName": ".google.protobuf.Timestamp", "label": "LABEL_OPTIONAL", "jsonName": "born"}
+		]}
+	],
+	"enumType": [
+		{"name": "Kind", "value": [{"name": "KIND_UNSPECIFIED", "number": 0}, {"name": "KIND_CAT", "number": 1}]}
+	],
+	"service": [
+		{"name": "PetService", "method": [
+			{"name": "GetPet", "inputType": ".petstore.v1.GetPetRequest", "outputType": ".petstore.v1.Pet"},
+			{"name": "ListPets", "inputType": ".petstore.v1.ListPetsRequest", "outputType": ".petstore.v1.Pet", "serverStreaming": true},
+			{"name": "AddPets", "inputType": ".petstore.v1.Pet", "outputType": ".petstore.v1.Pet", "clientStreaming": true}
+		]}
+	],
+	"sourceCodeInfo": {"location": [
+		{"path": [6, 0, 2, 0], "span": [0, 0, 0], "leadingComments": " Returns a pet by its ID\n"},
+		{"path": [4, 0, 2, 0], "span": [0, 0, 0], "leadingComments": " The pet's ID\n"}
+	]}
+}`
+
+// petstoreFiles returns the descriptors of the petstore and its imports
+func petstoreFiles(t *testing.T) *descriptorpb.FileDescriptorSet {
+	fdp := &descriptorpb.FileDescriptorProto{}
+	require.NoError(t, protojson.Unmarshal([]byte(petstore), fdp))
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+		fdp,
+	}}
+}
+
+// newServer serves the petstore in process, with reflection, returning a connection to it
+func newServer(t *testing.T) *grpc.ClientConn {
+	files, err := protodesc.NewFiles(petstoreFiles(t))
+	require.NoError(t, err)
+	message := func(name string) protoreflect.MessageDescriptor {
+		d, err := files.FindDescriptorByName(protoreflect.FullName("petstore.v1." + name))
+		require.NoError(t, err)
+		return d.(protoreflect.MessageDescriptor)
+	}
+	pet := func(id string) proto.Message {
+		msg := dynamicpb.NewMessage(message("Pet"))
+		require.NoError(t, protojson.Unmarshal([]byte(fmt.Sprintf(`{"petId":%q,"tags":["a","b"],"born":"2024-01-02T03:04:05Z"}`, id)), msg))
+		return msg
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "petstore.v1.PetService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "GetPet",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := dynamicpb.NewMessage(message("GetPetRequest"))
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("authorization")) == 0 || md.Get("authorization")[0] != "Bearer secret" {
+					return nil, status.Error(codes.Unauthenticated, "no credentials")
+				}
+				id := req.ProtoReflect().Get(message("GetPetRequest").Fields().ByName("pet_id")).String()
+				if id == "404" {
+					return nil, status.Error(codes.NotFound, "no such pet")
+				}
+				return pet(id), nil
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "ListPets",
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				req := dynamicpb.NewMessage(message("ListPetsRequest"))
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				limit := req.ProtoReflect().Get(message("ListPetsRequest").Fields().ByName("limit")).Int()
+				for i := int64(1); i <= limit; i++ {
+					if err := stream.SendMsg(pet(fmt.Sprint(i))); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}},
+	}, struct{}{})
+	reflectionpb.RegisterServerReflectionServer(server, reflection.NewServerV1(reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: files,
+	}))
+
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///petstore",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestToolProvider_Tools(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewToolProvider(ctx, newServer(t), Options{})
+	require.NoError(t, err)
+
+	// Client streaming RPCs and the reflection service are left out
+	result, err := provider.ListTools(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, result.Tools, 2)
+	assert.Equal(t, "petstore_v1_PetService_GetPet", result.Tools[0].Name)
+	assert.Equal(t, "petstore_v1_PetService_ListPets", result.Tools[1].Name)
+
+	tool, err := provider.GetTool(ctx, "petstore_v1_PetService_GetPet")
+	require.NoError(t, err)
+	assert.Equal(t, "Returns a pet by its ID", tool.Description)
+	assert.Equal(t, protocol.SchemaProperty{Type: "string", Description: "The pet's ID"}, tool.InputSchema.Properties["petId"])
+
+	tool, err = provider.GetTool(ctx, "petstore_v1_PetService_ListPets")
+	require.NoError(t, err)
+	assert.Contains(t, tool.Description, "Calls the ListPets RPC of petstore.v1.PetService")
+	assert.Equal(t, "integer", tool.InputSchema.Properties["limit"].Type)
+	assert.Equal(t, "string", tool.InputSchema.Properties["kind"].Type)
+	assert.Contains(t, tool.InputSchema.Properties["kind"].Description, "One of KIND_UNSPECIFIED, KIND_CAT")
+
+	_, err = provider.GetTool(ctx, "missing")
+	assert.ErrorIs(t, err, resources.ErrToolNotFound)
+
+	excluded, err := NewToolProvider(ctx, newServer(t), Options{ExcludeServices: []string{"petstore.v1.PetService"}})
+	require.NoError(t, err)
+	result, err = excluded.ListTools(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Tools)
+}
+
+func TestToolProvider_Call(t *testing.T) {
+	ctx := context.Background()
+	var progress []int
+	provider, err := NewToolProvider(ctx, newServer(t), Options{
+		Metadata: BearerAuth("secret"),
+		Progress: func(ctx context.Context, tool string, n int, message string) {
+			progress = append(progress, n)
+		},
+		MaxStreamMessages: 3,
+	})
+	require.NoError(t, err)
+	registry := resources.NewDynamicToolRegistry(provider)
+
+	t.Run("unary", func(t *testing.T) {
+		result, err := registry.CallTool(ctx, "petstore_v1_PetService_GetPet", map[string]interface{}{"petId": "7"})
+		require.NoError(t, err)
+		callResult := result.(protocol.ToolCallResult)
+		assert.False(t, callResult.IsError)
+		require.Len(t, callResult.Content, 1)
+		assert.JSONEq(t, `{"petId":"7","tags":["a","b"],"born":"2024-01-02T03:04:05Z"}`, callResult.Content[0].(protocol.TextContent).Text)
+	})
+
+	t.Run("server streaming", func(t *testing.T) {
+		result, err := registry.CallTool(ctx, "petstore_v1_PetService_ListPets", map[string]interface{}{"limit": float64(2)})
+		require.NoError(t, err)
+		callResult := result.(protocol.ToolCallResult)
+		require.Len(t, callResult.Content, 2)
+		assert.JSONEq(t, `{"petId":"2","tags":["a","b"],"born":"2024-01-02T03:04:05Z"}`, callResult.Content[1].(protocol.TextContent).Text)
+		assert.Equal(t, []int{1, 2}, progress)
+
+		// Streams are cut at MaxStreamMessages
+		result, err = registry.CallTool(ctx, "petstore_v1_PetService_ListPets", map[string]interface{}{"limit": float64(10)})
+		require.NoError(t, err)
+		assert.Len(t, result.(protocol.ToolCallResult).Content, 3)
+	})
+
+	t.Run("status errors", func(t *testing.T) {
+		result, err := registry.CallTool(ctx, "petstore_v1_PetService_GetPet", map[string]interface{}{"petId": "404"})
+		require.NoError(t, err)
+		callResult := result.(protocol.ToolCallResult)
+		assert.True(t, callResult.IsError)
+		assert.Equal(t, protocol.NewTextContent("NotFound: no such pet"), callResult.Content[0])
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := registry.CallTool(ctx, "petstore_v1_PetService_GetPet", map[string]interface{}{"name": "Rex"})
+		assert.ErrorIs(t, err, resources.ErrInvalidParams)
+	})
+
+	t.Run("without credentials", func(t *testing.T) {
+		unauthenticated, err := NewToolProvider(ctx, newServer(t), Options{})
+		require.NoError(t, err)
+		result, err := unauthenticated.HandleToolInvocation(ctx, "petstore_v1_PetService_GetPet", nil)
+		require.NoError(t, err)
+		assert.Equal(t, protocol.NewTextContent("Unauthenticated: no credentials"), result.(protocol.ToolCallResult).Content[0])
+	})
+}
+
+func TestLoadDescriptorSet(t *testing.T) {
+	data, err := proto.Marshal(petstoreFiles(t))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "petstore.binpb")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	files, err := LoadDescriptorSet(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"petstore.v1.PetService"}, fileServices(files))
+
+	// Supplied descriptors are used instead of reflection
+	ctx := context.Background()
+	provider, err := NewToolProvider(ctx, newServer(t), Options{Files: files, Metadata: BearerAuth("secret")})
+	require.NoError(t, err)
+	result, err := provider.HandleToolInvocation(ctx, "petstore_v1_PetService_GetPet", map[string]interface{}{"petId": "1"})
+	require.NoError(t, err)
+	assert.False(t, result.(protocol.ToolCallResult).IsError)
+
+	_, err = ParseDescriptorSet([]byte("not a descriptor set"))
+	assert.Error(t, err)
+	_, err = ParseDescriptorSet(mustMarshal(t, &descriptorpb.FileDescriptorSet{File: petstoreFiles(t).File[1:]}))
+	assert.Error(t, err, "imports must be in the set")
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	data, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return data
+}
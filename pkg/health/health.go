@@ -0,0 +1,208 @@
+// Package health reports whether a node is alive and whether it is ready to take sessions. A Checker runs a
+// set of checks and serves the outcome as JSON, for use as Kubernetes liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/redact"
+)
+
+// Status is the outcome of a check or of a whole report
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+// DefaultTimeout bounds each check when a Checker is created without a timeout
+const DefaultTimeout = 2 * time.Second
+
+// DrainingCheck is the name of the failed check reported while a Checker is draining
+const DrainingCheck = "draining"
+
+// ErrDraining is reported while a Checker is draining
+var ErrDraining = errors.New("node is draining")
+
+// Check reports whether something the node depends on is healthy
+type Check interface {
+	// Name identifies the check in reports
+	Name() string
+	// Check returns an error if the dependency is unhealthy. It should return promptly once ctx is done.
+	Check(ctx context.Context) error
+}
+
+type funcCheck struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c funcCheck) Name() string {
+	return c.name
+}
+
+func (c funcCheck) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+// NewCheck creates a check named name from fn
+func NewCheck(name string, fn func(ctx context.Context) error) Check {
+	return funcCheck{name: name, fn: fn}
+}
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Name     string  `json:"name"`
+	Status   Status  `json:"status"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_ms"`
+}
+
+// Report is the outcome of running all of a Checker's checks. It fails if any check fails.
+type Report struct {
+	Status Status        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// Checker runs a set of checks. Checks can be added at any time. A draining Checker always fails, so that
+// a node shutting down stops receiving traffic before it stops serving it.
+type Checker struct {
+	mu       sync.RWMutex
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewChecker creates a Checker that gives each check up to timeout to complete, or DefaultTimeout if
+// timeout is not positive
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, checks: checks}
+}
+
+// Add adds checks to the Checker
+func (c *Checker) Add(checks ...Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, checks...)
+}
+
+// SetDraining sets whether the Checker reports the node as draining
+func (c *Checker) SetDraining(draining bool) {
+	c.draining.Store(draining)
+}
+
+// Draining reports whether the Checker reports the node as draining
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Run runs all checks concurrently and reports their outcome, in the order the checks were added
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make([]Check, len(c.checks))
+	copy(checks, c.checks)
+	c.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	if c.Draining() {
+		results = append(results, CheckResult{Name: DrainingCheck, Status: StatusFail, Error: ErrDraining.Error()})
+	}
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+			break
+		}
+	}
+	return report
+}
+
+// run runs a single check, turning a panic or a timeout into a failure
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := CheckResult{Name: check.Name(), Status: StatusOK}
+
+	// The check runs on its own goroutine so that one ignoring ctx cannot hold up the report
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.New("check panicked")
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result.Duration = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = redact.String(err.Error())
+	}
+	return result
+}
+
+// ServeHTTP runs the checks and writes the report as JSON, with status 200 if the node is healthy and 503
+// otherwise. Results of individual checks are only included with the verbose query parameter.
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+	if !verbose(r) {
+		report.Checks = nil
+	}
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// verbose reports whether the request asks for the results of individual checks, with ?verbose,
+// ?verbose=true or ?verbose=1
+func verbose(r *http.Request) bool {
+	q := r.URL.Query()
+	if !q.Has("verbose") {
+		return false
+	}
+	switch q.Get("verbose") {
+	case "", "true", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+var _ http.Handler = (*Checker)(nil)
@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func okCheck(name string) Check {
+	return NewCheck(name, func(context.Context) error { return nil })
+}
+
+func TestChecker_Run(t *testing.T) {
+	t.Run("no checks", func(t *testing.T) {
+		report := NewChecker(0).Run(context.Background())
+		assert.Equal(t, StatusOK, report.Status)
+		assert.Empty(t, report.Checks)
+	})
+
+	t.Run("all checks pass", func(t *testing.T) {
+		report := NewChecker(0, okCheck("a"), okCheck("b")).Run(context.Background())
+		assert.Equal(t, StatusOK, report.Status)
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, "a", report.Checks[0].Name)
+		assert.Equal(t, "b", report.Checks[1].Name)
+	})
+
+	t.Run("a failing check fails the report", func(t *testing.T) {
+		c := NewChecker(0, okCheck("a"))
+		c.Add(NewCheck("db", func(context.Context) error {
+			return errors.New("dial postgres://app:pa55word@db/app: refused")
+		}))
+
+		report := c.Run(context.Background())
+		assert.Equal(t, StatusFail, report.Status)
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, StatusOK, report.Checks[0].Status)
+		assert.Equal(t, StatusFail, report.Checks[1].Status)
+		assert.NotContains(t, report.Checks[1].Error, "pa55word", "errors should be redacted")
+	})
+
+	t.Run("slow checks time out", func(t *testing.T) {
+		c := NewChecker(20*time.Millisecond, NewCheck("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}))
+
+		start := time.Now()
+		report := c.Run(context.Background())
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, StatusFail, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	})
+
+	t.Run("panicking checks fail", func(t *testing.T) {
+		report := NewChecker(0, NewCheck("panics", func(context.Context) error { panic("boom") })).Run(context.Background())
+		assert.Equal(t, StatusFail, report.Status)
+		assert.Equal(t, "check panicked", report.Checks[0].Error)
+	})
+
+	t.Run("draining fails the report", func(t *testing.T) {
+		c := NewChecker(0, okCheck("a"))
+		c.SetDraining(true)
+		assert.True(t, c.Draining())
+
+		report := c.Run(context.Background())
+		assert.Equal(t, StatusFail, report.Status)
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, DrainingCheck, report.Checks[1].Name)
+
+		c.SetDraining(false)
+		assert.Equal(t, StatusOK, c.Run(context.Background()).Status)
+	})
+}
+
+func TestChecker_ServeHTTP(t *testing.T) {
+	failing := false
+	c := NewChecker(0, okCheck("a"), NewCheck("b", func(context.Context) error {
+		if failing {
+			return errors.New("down")
+		}
+		return nil
+	}))
+
+	serve := func(target string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		c.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	code, body := serve("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"status": "ok"}, body)
+
+	code, body = serve("/readyz?verbose")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, body["checks"], 2)
+
+	failing = true
+	code, body = serve("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "fail", body["status"])
+	assert.NotContains(t, body, "checks")
+
+	code, body = serve("/readyz?verbose=true")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	checks := body["checks"].([]interface{})
+	assert.Equal(t, "down", checks[1].(map[string]interface{})["error"])
+
+	_, body = serve("/readyz?verbose=false")
+	assert.NotContains(t, body, "checks")
+}
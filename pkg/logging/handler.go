@@ -0,0 +1,157 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/redact"
+)
+
+// sessionHandler sends records to both the server's handler and, if the client's level allows, the client
+type sessionHandler struct {
+	server  slog.Handler
+	client  slog.Handler
+	session *Session
+}
+
+// Enabled implements slog.Handler
+func (h *sessionHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.server.Enabled(ctx, level) || h.session.Enabled(level)
+}
+
+// Handle implements slog.Handler
+func (h *sessionHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	if h.server.Enabled(ctx, record.Level) {
+		errs = append(errs, h.server.Handle(ctx, record.Clone()))
+	}
+	if h.session.Enabled(record.Level) {
+		errs = append(errs, h.client.Handle(ctx, record))
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler
+func (h *sessionHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sessionHandler{server: h.server.WithAttrs(attrs), client: h.client.WithAttrs(attrs), session: h.session}
+}
+
+// WithGroup implements slog.Handler
+func (h *sessionHandler) WithGroup(name string) slog.Handler {
+	return &sessionHandler{server: h.server.WithGroup(name), client: h.client.WithGroup(name), session: h.session}
+}
+
+// newClientHandler returns the handler turning records into notifications/message for the session's
+// client. Secrets are redacted before anything leaves the server.
+func newClientHandler(s *Session) slog.Handler {
+	return redact.NewHandler(&notifyHandler{session: s})
+}
+
+// notifyHandler turns records into notifications/message. The data of a notification is an object holding
+// the record's message and attributes, with groups as nested objects.
+type notifyHandler struct {
+	session *Session
+	// goas are the groups and attributes added with WithGroup and WithAttrs, in order
+	goas []groupOrAttrs
+}
+
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// Enabled implements slog.Handler
+func (h *notifyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.session.Enabled(level)
+}
+
+// Handle implements slog.Handler
+func (h *notifyHandler) Handle(_ context.Context, record slog.Record) error {
+	data := map[string]interface{}{"message": record.Message}
+	current := data
+	for _, goa := range h.goas {
+		if goa.group != "" {
+			group := map[string]interface{}{}
+			current[goa.group] = group
+			current = group
+			continue
+		}
+		for _, a := range goa.attrs {
+			addAttr(current, a)
+		}
+	}
+	record.Attrs(func(a slog.Attr) bool {
+		addAttr(current, a)
+		return true
+	})
+
+	h.session.notify(protocol.LoggingMessageParams{
+		Level: protocol.LoggingLevelFromSlog(record.Level),
+		Data:  data,
+	})
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+func (h *notifyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+// WithGroup implements slog.Handler
+func (h *notifyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *notifyHandler) with(goa groupOrAttrs) *notifyHandler {
+	goas := make([]groupOrAttrs, len(h.goas), len(h.goas)+1)
+	copy(goas, h.goas)
+	return &notifyHandler{session: h.session, goas: append(goas, goa)}
+}
+
+// addAttr adds a to m as a JSON-friendly value
+func addAttr(m map[string]interface{}, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		target := m
+		if a.Key != "" {
+			target = map[string]interface{}{}
+			m[a.Key] = target
+		}
+		for _, ga := range attrs {
+			addAttr(target, ga)
+		}
+	case slog.KindTime:
+		m[a.Key] = a.Value.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		m[a.Key] = a.Value.Duration().String()
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			m[a.Key] = err.Error()
+			return
+		}
+		m[a.Key] = a.Value.Any()
+	default:
+		m[a.Key] = a.Value.Any()
+	}
+}
+
+var _ slog.Handler = (*sessionHandler)(nil)
+var _ slog.Handler = (*notifyHandler)(nil)
@@ -0,0 +1,92 @@
+// Package logging lets tools, prompts and resources log to the client of the session handling a request.
+// A client opts in with logging/setLevel; from then on records at or above its chosen level are sent to it
+// as notifications/message, in addition to the server's own log.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// Notifier delivers a notifications/message to a session's client. It must not block, as it is called
+// from request paths.
+type Notifier func(params protocol.LoggingMessageParams)
+
+// Session holds the logging level a client chose for its session
+type Session struct {
+	// level is the minimum slog level forwarded to the client, valid once enabled is set
+	level   atomic.Int64
+	enabled atomic.Bool
+	notify  Notifier
+	logger  *slog.Logger
+}
+
+// NewSession creates the logging state of a session. Records logged through its Logger go to server and,
+// once the client has set a level, to notify.
+func NewSession(server *slog.Logger, notify Notifier) *Session {
+	if server == nil {
+		server = slog.Default()
+	}
+	s := &Session{notify: notify}
+	s.logger = slog.New(&sessionHandler{
+		server:  server.Handler(),
+		client:  newClientHandler(s),
+		session: s,
+	})
+	return s
+}
+
+// SetLevel sets the minimum level of the records sent to the client
+func (s *Session) SetLevel(level protocol.LoggingLevel) error {
+	slogLevel, ok := level.SlogLevel()
+	if !ok {
+		return fmt.Errorf("unknown logging level %q", level)
+	}
+	s.level.Store(int64(slogLevel))
+	s.enabled.Store(true)
+	return nil
+}
+
+// Level returns the level set by the client, and false if it has not set one
+func (s *Session) Level() (protocol.LoggingLevel, bool) {
+	if !s.enabled.Load() {
+		return "", false
+	}
+	return protocol.LoggingLevelFromSlog(slog.Level(s.level.Load())), true
+}
+
+// Enabled reports whether records at level are sent to the client
+func (s *Session) Enabled(level slog.Level) bool {
+	return s.notify != nil && s.enabled.Load() && level >= slog.Level(s.level.Load())
+}
+
+// Logger returns a logger writing to both the server's log and the session's client
+func (s *Session) Logger() *slog.Logger {
+	return s.logger
+}
+
+type sessionContextKey struct{}
+
+// WithSession returns ctx carrying the logging state of a session
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, s)
+}
+
+// SessionFromContext returns the logging state of the session handling the request in ctx, or nil
+func SessionFromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return s
+}
+
+// FromContext returns a logger for the session handling the request in ctx. Outside a session it returns
+// the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if s := SessionFromContext(ctx); s != nil {
+		return s.Logger()
+	}
+	return slog.Default()
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+func newTestSession(t *testing.T) (*Session, *[]protocol.LoggingMessageParams, *bytes.Buffer) {
+	t.Helper()
+	var sent []protocol.LoggingMessageParams
+	var buf bytes.Buffer
+	server := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	s := NewSession(server, func(params protocol.LoggingMessageParams) {
+		sent = append(sent, params)
+	})
+	return s, &sent, &buf
+}
+
+func TestSession_NothingSentUntilLevelSet(t *testing.T) {
+	s, sent, buf := newTestSession(t)
+
+	s.Logger().Error("before set level")
+	assert.Empty(t, *sent)
+	assert.Contains(t, buf.String(), "before set level", "server log should still get the record")
+
+	_, ok := s.Level()
+	assert.False(t, ok)
+}
+
+func TestSession_SetLevel(t *testing.T) {
+	s, sent, buf := newTestSession(t)
+	require.NoError(t, s.SetLevel(protocol.LoggingLevelWarning))
+
+	level, ok := s.Level()
+	require.True(t, ok)
+	assert.Equal(t, protocol.LoggingLevelWarning, level)
+
+	s.Logger().Info("too quiet")
+	s.Logger().Warn("loud enough", "count", 3)
+
+	require.Len(t, *sent, 1)
+	assert.Equal(t, protocol.LoggingLevelWarning, (*sent)[0].Level)
+	assert.Equal(t, map[string]interface{}{"message": "loud enough", "count": int64(3)}, (*sent)[0].Data)
+	assert.Contains(t, buf.String(), "too quiet")
+
+	assert.Error(t, s.SetLevel("verbose"))
+	level, _ = s.Level()
+	assert.Equal(t, protocol.LoggingLevelWarning, level, "an invalid level should leave the level unchanged")
+}
+
+func TestSession_DebugBelowServerLevel(t *testing.T) {
+	s, sent, buf := newTestSession(t)
+	require.NoError(t, s.SetLevel(protocol.LoggingLevelDebug))
+
+	s.Logger().Debug("client only")
+	require.Len(t, *sent, 1)
+	assert.Equal(t, protocol.LoggingLevelDebug, (*sent)[0].Level)
+	assert.NotContains(t, buf.String(), "client only", "server log level should still apply")
+}
+
+func TestSession_GroupsAndAttrs(t *testing.T) {
+	s, sent, _ := newTestSession(t)
+	require.NoError(t, s.SetLevel(protocol.LoggingLevelInfo))
+
+	logger := s.Logger().With("tool", "search").WithGroup("req").With("id", "r1")
+	logger.Info("done", slog.Group("stats", "hits", 2), "err", errors.New("partial"))
+
+	require.Len(t, *sent, 1)
+	assert.Equal(t, map[string]interface{}{
+		"message": "done",
+		"tool":    "search",
+		"req": map[string]interface{}{
+			"id":    "r1",
+			"stats": map[string]interface{}{"hits": int64(2)},
+			"err":   "partial",
+		},
+	}, (*sent)[0].Data)
+}
+
+func TestSession_RedactsClientRecords(t *testing.T) {
+	s, sent, _ := newTestSession(t)
+	require.NoError(t, s.SetLevel(protocol.LoggingLevelInfo))
+
+	s.Logger().Info("connecting", "password", "hunter2")
+
+	require.Len(t, *sent, 1)
+	data := (*sent)[0].Data.(map[string]interface{})
+	assert.NotEqual(t, "hunter2", data["password"])
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+	assert.Nil(t, SessionFromContext(context.Background()))
+
+	s, sent, _ := newTestSession(t)
+	require.NoError(t, s.SetLevel(protocol.LoggingLevelInfo))
+	ctx := WithSession(context.Background(), s)
+
+	assert.Same(t, s, SessionFromContext(ctx))
+	FromContext(ctx).InfoContext(ctx, "from a tool")
+	require.Len(t, *sent, 1)
+}
@@ -0,0 +1,81 @@
+// Package metrics instruments sessions, requests, connections and actors. Instrumented code talks to a
+// Recorder, so backends other than Prometheus can be plugged in with WithMetricsRecorder.
+package metrics
+
+import (
+	"time"
+)
+
+// Outcomes of a handled request or tool call
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Reasons a session ends
+const (
+	SessionEndShutdown          = "shutdown"
+	SessionEndInitializeTimeout = "initialize_timeout"
+	SessionEndTTLExpired        = "ttl_expired"
+	SessionEndStopped           = "stopped"
+	SessionEndDrained           = "drained"
+	SessionEndHandedOff         = "handed_off"
+	SessionEndTerminated        = "terminated"
+	SessionEndDeleted           = "deleted"
+	SessionEndRejected          = "rejected"
+)
+
+// Actor kinds whose mailboxes are measured
+const (
+	ActorSession          = "session"
+	ActorClientConnection = "client_connection"
+)
+
+// TransportSSE is the transport label for server-sent event streams
+const TransportSSE = "sse"
+
+// Recorder receives measurements from the server. Implementations must be safe for concurrent use and
+// should not block, as they are called from request paths and actors.
+type Recorder interface {
+	// SessionStarted is called when a session actor starts
+	SessionStarted()
+	// SessionEnded is called when a session actor stops, with the reason it ended
+	SessionEnded(reason string)
+	// HTTPRequest is called for each JSON-RPC message posted over HTTP, with the status code returned
+	HTTPRequest(method string, status int, duration time.Duration)
+	// RequestHandled is called after an executor handles a request
+	RequestHandled(method, outcome string, duration time.Duration)
+	// ToolCalled is called after a tools/call request
+	ToolCalled(tool, outcome string, duration time.Duration)
+	// ConnectionOpened is called when a server-to-client stream opens
+	ConnectionOpened(transport string)
+	// ConnectionClosed is called when a server-to-client stream closes
+	ConnectionClosed(transport string)
+	// MailboxDepthChanged adjusts the number of messages queued for a kind of actor
+	MailboxDepthChanged(actorKind string, delta int)
+	// ClusterMembers sets the number of nodes in the cluster, including this one
+	ClusterMembers(n int)
+}
+
+// NopRecorder discards all measurements
+type NopRecorder struct{}
+
+func (NopRecorder) SessionStarted()                              {}
+func (NopRecorder) SessionEnded(string)                          {}
+func (NopRecorder) HTTPRequest(string, int, time.Duration)       {}
+func (NopRecorder) RequestHandled(string, string, time.Duration) {}
+func (NopRecorder) ToolCalled(string, string, time.Duration)     {}
+func (NopRecorder) ConnectionOpened(string)                      {}
+func (NopRecorder) ConnectionClosed(string)                      {}
+func (NopRecorder) MailboxDepthChanged(string, int)              {}
+func (NopRecorder) ClusterMembers(int)                           {}
+
+// OrNop returns r, or a NopRecorder if r is nil
+func OrNop(r Recorder) Recorder {
+	if r == nil {
+		return NopRecorder{}
+	}
+	return r
+}
+
+var _ Recorder = NopRecorder{}
@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultNamespace prefixes every metric name
+const DefaultNamespace = "mcp"
+
+// MaxLabelValues caps the distinct method and tool names tracked per metric, as both come from clients.
+// Further values are reported as OtherLabel.
+const MaxLabelValues = 256
+
+// OtherLabel replaces label values past MaxLabelValues
+const OtherLabel = "other"
+
+// PrometheusRecorder records metrics with the Prometheus client and serves them in the Prometheus text
+// format
+type PrometheusRecorder struct {
+	handler http.Handler
+
+	sessionsActive   prometheus.Gauge
+	sessionsStarted  prometheus.Counter
+	sessionsEnded    *prometheus.CounterVec
+	httpRequests     *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	toolCalls        *prometheus.CounterVec
+	toolCallDuration *prometheus.HistogramVec
+	connections      *prometheus.GaugeVec
+	mailboxDepth     *prometheus.GaugeVec
+	clusterMembers   prometheus.Gauge
+
+	methods *labelSet
+	tools   *labelSet
+}
+
+// NewRegistry creates a registry with the Go runtime and process collectors registered
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return reg
+}
+
+// NewPrometheusRecorder registers the server's metrics with registry under namespace, which defaults to
+// DefaultNamespace
+func NewPrometheusRecorder(namespace string, registry *prometheus.Registry) (*PrometheusRecorder, error) {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	r := &PrometheusRecorder{
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		sessionsActive: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "sessions_active",
+			Help: "Number of sessions currently running on this node.",
+		}),
+		sessionsStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "sessions_started_total",
+			Help: "Total number of sessions started on this node.",
+		}),
+		sessionsEnded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "sessions_ended_total",
+			Help: "Total number of sessions ended on this node, by reason.",
+		}, []string{"reason"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "http_requests_total",
+			Help: "Total number of JSON-RPC messages posted over HTTP, by method and status code.",
+		}, []string{"method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "http_request_duration_seconds",
+			Help:    "Time taken to answer JSON-RPC messages posted over HTTP.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "requests_total",
+			Help: "Total number of requests handled by executors, by method and outcome.",
+		}, []string{"method", "outcome"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "request_duration_seconds",
+			Help:    "Time taken by executors to handle requests.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "tool_calls_total",
+			Help: "Total number of tool calls, by tool and outcome.",
+		}, []string{"tool", "outcome"}),
+		toolCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "tool_call_duration_seconds",
+			Help:    "Time taken by tool calls.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"tool"}),
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "connections_active",
+			Help: "Number of open server-to-client streams, by transport.",
+		}, []string{"transport"}),
+		mailboxDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "actor_mailbox_depth",
+			Help: "Number of messages waiting in actor mailboxes, by kind of actor.",
+		}, []string{"actor"}),
+		clusterMembers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "cluster_members",
+			Help: "Number of nodes in the cluster, as seen by this node.",
+		}),
+		methods: newLabelSet(MaxLabelValues),
+		tools:   newLabelSet(MaxLabelValues),
+	}
+
+	for _, c := range []prometheus.Collector{
+		r.sessionsActive, r.sessionsStarted, r.sessionsEnded, r.httpRequests, r.httpDuration, r.requests,
+		r.requestDuration, r.toolCalls, r.toolCallDuration, r.connections, r.mailboxDepth, r.clusterMembers,
+	} {
+		if err := registry.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register metric: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// ServeHTTP serves the registry's metrics in the Prometheus text format
+func (r *PrometheusRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
+
+func (r *PrometheusRecorder) SessionStarted() {
+	r.sessionsStarted.Inc()
+	r.sessionsActive.Inc()
+}
+
+func (r *PrometheusRecorder) SessionEnded(reason string) {
+	r.sessionsEnded.WithLabelValues(reason).Inc()
+	r.sessionsActive.Dec()
+}
+
+func (r *PrometheusRecorder) HTTPRequest(method string, status int, duration time.Duration) {
+	method = r.methods.label(method)
+	r.httpRequests.WithLabelValues(method, strconv.Itoa(status)).Inc()
+	r.httpDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+func (r *PrometheusRecorder) RequestHandled(method, outcome string, duration time.Duration) {
+	method = r.methods.label(method)
+	r.requests.WithLabelValues(method, outcome).Inc()
+	r.requestDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+func (r *PrometheusRecorder) ToolCalled(tool, outcome string, duration time.Duration) {
+	tool = r.tools.label(tool)
+	r.toolCalls.WithLabelValues(tool, outcome).Inc()
+	r.toolCallDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+func (r *PrometheusRecorder) ConnectionOpened(transport string) {
+	r.connections.WithLabelValues(transport).Inc()
+}
+
+func (r *PrometheusRecorder) ConnectionClosed(transport string) {
+	r.connections.WithLabelValues(transport).Dec()
+}
+
+func (r *PrometheusRecorder) MailboxDepthChanged(actorKind string, delta int) {
+	r.mailboxDepth.WithLabelValues(actorKind).Add(float64(delta))
+}
+
+func (r *PrometheusRecorder) ClusterMembers(n int) {
+	r.clusterMembers.Set(float64(n))
+}
+
+// labelSet bounds the number of distinct values used for a label
+type labelSet struct {
+	mu     sync.RWMutex
+	max    int
+	values map[string]struct{}
+}
+
+func newLabelSet(max int) *labelSet {
+	return &labelSet{max: max, values: make(map[string]struct{})}
+}
+
+// label returns value if it is already tracked or there is room to track it, and OtherLabel otherwise
+func (s *labelSet) label(value string) string {
+	s.mu.RLock()
+	_, ok := s.values[value]
+	s.mu.RUnlock()
+	if ok {
+		return value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[value]; ok {
+		return value
+	}
+	if len(s.values) >= s.max {
+		return OtherLabel
+	}
+	s.values[value] = struct{}{}
+	return value
+}
+
+var _ Recorder = (*PrometheusRecorder)(nil)
+var _ http.Handler = (*PrometheusRecorder)(nil)
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, r *PrometheusRecorder) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestPrometheusRecorder(t *testing.T) {
+	r, err := NewPrometheusRecorder("", NewRegistry())
+	require.NoError(t, err)
+
+	r.SessionStarted()
+	r.SessionStarted()
+	r.SessionEnded(SessionEndShutdown)
+	r.HTTPRequest("tools/call", http.StatusOK, 10*time.Millisecond)
+	r.RequestHandled("tools/call", OutcomeSuccess, 5*time.Millisecond)
+	r.ToolCalled("echo", OutcomeError, 5*time.Millisecond)
+	r.ConnectionOpened(TransportSSE)
+	r.MailboxDepthChanged(ActorSession, 3)
+	r.MailboxDepthChanged(ActorSession, -1)
+	r.ClusterMembers(3)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, "mcp_sessions_active 1")
+	assert.Contains(t, body, "mcp_sessions_started_total 2")
+	assert.Contains(t, body, `mcp_sessions_ended_total{reason="shutdown"} 1`)
+	assert.Contains(t, body, `mcp_http_requests_total{method="tools/call",status="200"} 1`)
+	assert.Contains(t, body, `mcp_http_request_duration_seconds_count{method="tools/call"} 1`)
+	assert.Contains(t, body, `mcp_requests_total{method="tools/call",outcome="success"} 1`)
+	assert.Contains(t, body, `mcp_tool_calls_total{outcome="error",tool="echo"} 1`)
+	assert.Contains(t, body, `mcp_tool_call_duration_seconds_count{tool="echo"} 1`)
+	assert.Contains(t, body, `mcp_connections_active{transport="sse"} 1`)
+	assert.Contains(t, body, `mcp_actor_mailbox_depth{actor="session"} 2`)
+	assert.Contains(t, body, "mcp_cluster_members 3")
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestPrometheusRecorder_Namespace(t *testing.T) {
+	r, err := NewPrometheusRecorder("scaled", prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	r.SessionStarted()
+	assert.Contains(t, scrape(t, r), "scaled_sessions_active 1")
+}
+
+func TestPrometheusRecorder_DuplicateRegistration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := NewPrometheusRecorder("", reg)
+	require.NoError(t, err)
+
+	_, err = NewPrometheusRecorder("", reg)
+	assert.Error(t, err)
+}
+
+func TestPrometheusRecorder_LabelCardinality(t *testing.T) {
+	r, err := NewPrometheusRecorder("", prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	for i := 0; i < MaxLabelValues+10; i++ {
+		r.ToolCalled(fmt.Sprintf("tool-%d", i), OutcomeSuccess, time.Millisecond)
+	}
+	r.ToolCalled("tool-0", OutcomeSuccess, time.Millisecond)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `mcp_tool_calls_total{outcome="success",tool="tool-0"} 2`)
+	assert.Contains(t, body, `mcp_tool_calls_total{outcome="success",tool="other"} 10`)
+	assert.NotContains(t, body, fmt.Sprintf(`tool="tool-%d"`, MaxLabelValues))
+}
+
+func TestOrNop(t *testing.T) {
+	assert.Equal(t, NopRecorder{}, OrNop(nil))
+
+	r, err := NewPrometheusRecorder("", prometheus.NewRegistry())
+	require.NoError(t, err)
+	assert.Same(t, r, OrNop(r))
+}
@@ -0,0 +1,160 @@
+// Package openapi turns the operations of an OpenAPI 3 document into MCP tools that call the API over HTTP.
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxDocumentSize bounds the size of a document loaded from a URL
+const maxDocumentSize = 16 << 20
+
+// ErrUnsupportedVersion is returned for documents that are not OpenAPI 3
+var ErrUnsupportedVersion = errors.New("unsupported OpenAPI version")
+
+// Document is a parsed OpenAPI 3 document
+type Document struct {
+	root map[string]interface{}
+
+	// location is where the document was loaded from, against which relative server URLs are resolved
+	location *url.URL
+}
+
+// Load reads a document, in JSON or YAML, from a file path or an http(s) URL. client fetches URLs,
+// http.DefaultClient if nil.
+func Load(ctx context.Context, location string, client *http.Client) (*Document, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+		}
+		return Parse(data)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for OpenAPI document: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenAPI document: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OpenAPI document: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	doc.location = resp.Request.URL
+	return doc, nil
+}
+
+// Parse parses a document in JSON or YAML
+func Parse(data []byte) (*Document, error) {
+	var root map[string]interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedVersion, version)
+	}
+	return &Document{root: root}, nil
+}
+
+// ServerURL returns the URL of the document's first server, resolved against where the document was loaded
+// from, or an empty string if it has none
+func (d *Document) ServerURL() string {
+	servers, _ := d.root["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]interface{})
+	raw, _ := server["url"].(string)
+
+	// Substitute the default values of server variables
+	variables, _ := server["variables"].(map[string]interface{})
+	for name, v := range variables {
+		variable, _ := v.(map[string]interface{})
+		if value, ok := variable["default"].(string); ok {
+			raw = strings.ReplaceAll(raw, "{"+name+"}", value)
+		}
+	}
+
+	if d.location == nil {
+		return raw
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return d.location.ResolveReference(ref).String()
+}
+
+// resolve follows the local $ref of a node, such as #/components/schemas/Pet. Other nodes are returned as they
+// are. References to other documents are not supported.
+func (d *Document) resolve(node interface{}) (map[string]interface{}, error) {
+	seen := make(map[string]bool)
+	for {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m, nil
+		}
+		if seen[ref] {
+			return nil, fmt.Errorf("circular reference %s", ref)
+		}
+		seen[ref] = true
+
+		target, err := d.pointer(ref)
+		if err != nil {
+			return nil, err
+		}
+		node = target
+	}
+}
+
+// pointer returns the node a local JSON pointer reference names
+func (d *Document) pointer(ref string) (interface{}, error) {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported reference %s", ref)
+	}
+
+	var node interface{} = d.root
+	for _, token := range strings.Split(path, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference %s", ref)
+		}
+		if node, ok = m[token]; !ok {
+			return nil, fmt.Errorf("unresolved reference %s", ref)
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,628 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+// DefaultMaxResponseSize is how much of a response is put in a tool result when no limit is configured
+const DefaultMaxResponseSize = 1 << 20
+
+// methods are the HTTP methods of a path item, in the order tools are made from them
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// invalidNameChars are the characters tool names may not have
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// AuthFunc adds credentials to a request to the API
+type AuthFunc func(ctx context.Context, req *http.Request) error
+
+// BearerAuth authenticates requests with a bearer token
+func BearerAuth(token string) AuthFunc {
+	return HeaderAuth("Authorization", "Bearer "+token)
+}
+
+// BasicAuth authenticates requests with a user name and password
+func BasicAuth(username, password string) AuthFunc {
+	return func(ctx context.Context, req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+}
+
+// HeaderAuth authenticates requests with a header, such as an API key
+func HeaderAuth(name, value string) AuthFunc {
+	return func(ctx context.Context, req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	}
+}
+
+// sameHostRedirect follows up to 10 redirects, like http.Client by default, as long as they keep to the scheme
+// and host of the request
+func sameHostRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if origin := via[0].URL; req.URL.Scheme != origin.Scheme || req.URL.Host != origin.Host {
+		return fmt.Errorf("refusing to follow a redirect to %s://%s", req.URL.Scheme, req.URL.Host)
+	}
+	return nil
+}
+
+// Options configures a ToolProvider
+type Options struct {
+	// BaseURL is prepended to the paths of operations. If empty, the URL of the document's first server is used.
+	BaseURL string
+
+	// HTTPClient calls the API. If nil, a client like http.DefaultClient is used, except that it refuses to
+	// follow redirects to another scheme or host: Go only drops Authorization and Cookie headers on those, so
+	// credentials of Auth in other headers would be sent on. A client of your own should do the same.
+	HTTPClient *http.Client
+
+	// Auth adds credentials to each request, such as BearerAuth. It gets the context of the tool call, so it may
+	// pass on credentials of the caller.
+	Auth AuthFunc
+
+	// IncludeTags and IncludeOperations select the operations made into tools, by tag or operationId. All
+	// operations are selected if both are empty.
+	IncludeTags       []string
+	IncludeOperations []string
+
+	// ExcludeTags and ExcludeOperations leave out operations, even selected ones
+	ExcludeTags       []string
+	ExcludeOperations []string
+
+	// PageSize is the number of tools listed per page, resources.DefaultPageSize if zero
+	PageSize int
+
+	// MaxResponseSize is how many bytes of a response are put in a tool result, DefaultMaxResponseSize if zero
+	MaxResponseSize int64
+}
+
+// argument is a tool argument, and where it goes in the request
+type argument struct {
+	name  string // name of the tool argument
+	in    string // path, query, header, cookie or body
+	param string // name of the parameter, or of the property of a flattened body
+}
+
+// operation is an API operation made into a tool
+type operation struct {
+	tool        protocol.Tool
+	method      string
+	path        string
+	arguments   []argument
+	contentType string // media type of the request body, empty if it has none
+	flatBody    bool   // whether the properties of the body are arguments of their own, rather than one body argument
+}
+
+// ToolProvider serves the operations of an OpenAPI document as tools, for a resources.DynamicToolRegistry.
+// Tools are named after operationIds, or the method and path of operations without one. Their arguments are
+// the operations' path, query, header and cookie parameters, and the properties of a JSON object body, or else
+// a single body argument.
+type ToolProvider struct {
+	opts       Options
+	baseURL    string
+	operations map[string]*operation
+	tools      []protocol.Tool
+}
+
+// NewToolProvider creates a provider of the operations of doc that opts selects
+func NewToolProvider(doc *Document, opts Options) (*ToolProvider, error) {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{CheckRedirect: sameHostRedirect}
+	}
+	if opts.MaxResponseSize <= 0 {
+		opts.MaxResponseSize = DefaultMaxResponseSize
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = doc.ServerURL()
+	}
+	if u, err := url.Parse(baseURL); err != nil || !u.IsAbs() {
+		return nil, fmt.Errorf("OpenAPI tools need an absolute base URL, got %q", baseURL)
+	}
+
+	p := &ToolProvider{
+		opts:       opts,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		operations: make(map[string]*operation),
+	}
+
+	paths, _ := doc.root["paths"].(map[string]interface{})
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	for _, path := range pathNames {
+		item, err := doc.resolve(paths[path])
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", path, err)
+		}
+		for _, method := range methods {
+			raw, ok := item[method].(map[string]interface{})
+			if !ok || !p.selected(raw) {
+				continue
+			}
+
+			op, err := newOperation(doc, method, path, item, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			if _, ok := p.operations[op.tool.Name]; ok {
+				return nil, fmt.Errorf("%s %s: duplicate tool name %s", strings.ToUpper(method), path, op.tool.Name)
+			}
+			p.operations[op.tool.Name] = op
+			p.tools = append(p.tools, op.tool)
+		}
+	}
+	return p, nil
+}
+
+// selected reports whether the options select an operation
+func (p *ToolProvider) selected(raw map[string]interface{}) bool {
+	id, _ := raw["operationId"].(string)
+	var tags []string
+	for _, tag := range asSlice(raw["tags"]) {
+		if s, ok := tag.(string); ok {
+			tags = append(tags, s)
+		}
+	}
+	hasTag := func(list []string) bool {
+		for _, tag := range tags {
+			if slices.Contains(list, tag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasTag(p.opts.ExcludeTags) || (id != "" && slices.Contains(p.opts.ExcludeOperations, id)) {
+		return false
+	}
+	if len(p.opts.IncludeTags) == 0 && len(p.opts.IncludeOperations) == 0 {
+		return true
+	}
+	return hasTag(p.opts.IncludeTags) || (id != "" && slices.Contains(p.opts.IncludeOperations, id))
+}
+
+// newOperation makes a tool of an operation
+func newOperation(doc *Document, method, path string, item, raw map[string]interface{}) (*operation, error) {
+	name, _ := raw["operationId"].(string)
+	if name == "" {
+		name = method + "_" + strings.Trim(invalidNameChars.ReplaceAllString(path, "_"), "_")
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	summary, _ := raw["summary"].(string)
+	description, _ := raw["description"].(string)
+	if summary != "" && description != "" {
+		description = summary + "\n\n" + description
+	} else if summary != "" {
+		description = summary
+	}
+
+	op := &operation{
+		tool: protocol.Tool{
+			Name:        name,
+			Description: description,
+			InputSchema: protocol.InputSchema{Type: "object", Properties: map[string]protocol.SchemaProperty{}},
+		},
+		method: strings.ToUpper(method),
+		path:   path,
+	}
+
+	// Parameters of the operation override those of the path with the same name and location
+	params := make(map[string]map[string]interface{})
+	var order []string
+	for _, list := range []interface{}{item["parameters"], raw["parameters"]} {
+		for _, node := range asSlice(list) {
+			param, err := doc.resolve(node)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			if name == "" || in == "" {
+				continue
+			}
+			key := in + ":" + name
+			if _, ok := params[key]; !ok {
+				order = append(order, key)
+			}
+			params[key] = param
+		}
+	}
+
+	for _, key := range order {
+		param := params[key]
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		schema, err := doc.resolve(param["schema"])
+		if err != nil {
+			return nil, err
+		}
+		description, _ := param["description"].(string)
+		required, _ := param["required"].(bool)
+		op.addArgument(argument{name: name, in: in, param: name}, schemaProperty(schema, description, "string"), required || in == "path")
+	}
+
+	if err := op.addBody(doc, raw["requestBody"]); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// addArgument adds an argument to the tool, named after its location if another argument has its name
+func (op *operation) addArgument(arg argument, property protocol.SchemaProperty, required bool) {
+	if _, ok := op.tool.InputSchema.Properties[arg.name]; ok {
+		arg.name = arg.in + "_" + arg.name
+	}
+	op.arguments = append(op.arguments, arg)
+	op.tool.InputSchema.Properties[arg.name] = property
+	if required {
+		op.tool.InputSchema.Required = append(op.tool.InputSchema.Required, arg.name)
+	}
+}
+
+// addBody adds the arguments of the request body. The properties of a JSON object become arguments of their
+// own unless one has the name of a parameter; any other body is a single body argument.
+func (op *operation) addBody(doc *Document, node interface{}) error {
+	body, err := doc.resolve(node)
+	if err != nil || body == nil {
+		return err
+	}
+	content, _ := body["content"].(map[string]interface{})
+	if len(content) == 0 {
+		return nil
+	}
+
+	// Prefer JSON, then forms, then whatever comes first
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	op.contentType = mediaTypes[0]
+	for _, preferred := range []string{"application/x-www-form-urlencoded", "application/json"} {
+		if _, ok := content[preferred]; ok {
+			op.contentType = preferred
+		}
+	}
+
+	media, _ := content[op.contentType].(map[string]interface{})
+	schema, err := doc.resolve(media["schema"])
+	if err != nil {
+		return err
+	}
+	required, _ := body["required"].(bool)
+	description, _ := body["description"].(string)
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	structured := isJSON(op.contentType) || op.contentType == "application/x-www-form-urlencoded"
+	if structured && len(properties) > 0 && !op.collides(properties) {
+		op.flatBody = true
+		var requiredProperties []string
+		if required {
+			for _, name := range asSlice(schema["required"]) {
+				if s, ok := name.(string); ok {
+					requiredProperties = append(requiredProperties, s)
+				}
+			}
+		}
+
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, err := doc.resolve(properties[name])
+			if err != nil {
+				return err
+			}
+			op.addArgument(argument{name: name, in: "body", param: name}, schemaProperty(property, "", "string"), slices.Contains(requiredProperties, name))
+		}
+		return nil
+	}
+
+	fallback := "string"
+	if structured {
+		fallback = "object"
+	}
+	op.addArgument(argument{name: "body", in: "body"}, schemaProperty(schema, description, fallback), required)
+	return nil
+}
+
+// collides reports whether a body property has the name of an argument
+func (op *operation) collides(properties map[string]interface{}) bool {
+	for name := range properties {
+		if _, ok := op.tool.InputSchema.Properties[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaProperty describes a schema as a property of the tool's input schema
+func schemaProperty(schema map[string]interface{}, description, fallback string) protocol.SchemaProperty {
+	property := protocol.SchemaProperty{Type: fallback, Description: description}
+	if schema == nil {
+		return property
+	}
+
+	switch t := schema["type"].(type) {
+	case string:
+		property.Type = t
+	case []interface{}:
+		// OpenAPI 3.1 allows several types, such as ["string", "null"]
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				property.Type = s
+				break
+			}
+		}
+	default:
+		if _, ok := schema["properties"]; ok {
+			property.Type = "object"
+		} else if _, ok := schema["items"]; ok {
+			property.Type = "array"
+		}
+	}
+
+	if property.Description == "" {
+		property.Description, _ = schema["description"].(string)
+	}
+	property.Default = schema["default"]
+	return property
+}
+
+// GetTool returns the tool of an operation
+func (p *ToolProvider) GetTool(ctx context.Context, name string) (protocol.Tool, error) {
+	op, ok := p.operations[name]
+	if !ok {
+		return protocol.Tool{}, resources.ErrToolNotFound
+	}
+	return op.tool, nil
+}
+
+// ListTools returns a page of the tools, ordered by name
+func (p *ToolProvider) ListTools(ctx context.Context, cursor string) (protocol.ToolListResult, error) {
+	tools, next := resources.Paginate(p.tools, func(tool protocol.Tool) string { return tool.Name }, cursor, p.opts.PageSize)
+	return protocol.ToolListResult{Tools: tools, NextCursor: next}, nil
+}
+
+// HandleToolInvocation calls the operation of a tool. Responses with an error status are error results.
+func (p *ToolProvider) HandleToolInvocation(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	op, ok := p.operations[name]
+	if !ok {
+		return nil, resources.ErrToolNotFound
+	}
+	for _, required := range op.tool.InputSchema.Required {
+		if _, ok := params[required]; !ok {
+			return nil, fmt.Errorf("%w: argument %s is required", resources.ErrInvalidParams, required)
+		}
+	}
+
+	req, err := p.newRequest(ctx, op, params)
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.Auth != nil {
+		if err := p.opts.Auth(ctx, req); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
+	}
+
+	resp, err := p.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s %s: %w", op.method, op.path, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	return p.result(resp)
+}
+
+// newRequest builds the request of a call
+func (p *ToolProvider) newRequest(ctx context.Context, op *operation, params map[string]interface{}) (*http.Request, error) {
+	path := op.path
+	query := url.Values{}
+	header := http.Header{}
+	var cookies []*http.Cookie
+	var body io.Reader
+	bodyFields := make(map[string]interface{})
+
+	for _, arg := range op.arguments {
+		value, ok := params[arg.name]
+		if !ok || value == nil {
+			continue
+		}
+		switch arg.in {
+		case "path":
+			// Escaping leaves dot segments as they are, which would address another path
+			segment := formatValue(value)
+			if segment == "." || segment == ".." {
+				return nil, fmt.Errorf("%w: argument %s cannot be %q", resources.ErrInvalidParams, arg.name, segment)
+			}
+			path = strings.ReplaceAll(path, "{"+arg.param+"}", url.PathEscape(segment))
+		case "query":
+			for _, v := range asValues(value) {
+				query.Add(arg.param, formatValue(v))
+			}
+		case "header":
+			values := asValues(value)
+			formatted := make([]string, 0, len(values))
+			for _, v := range values {
+				formatted = append(formatted, formatValue(v))
+			}
+			header.Set(arg.param, strings.Join(formatted, ","))
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: arg.param, Value: formatValue(value)})
+		case "body":
+			if op.flatBody {
+				bodyFields[arg.param] = value
+				continue
+			}
+			encoded, err := encodeBody(op.contentType, value)
+			if err != nil {
+				return nil, err
+			}
+			body = encoded
+		}
+	}
+	if op.flatBody && len(bodyFields) > 0 {
+		encoded, err := encodeBody(op.contentType, bodyFields)
+		if err != nil {
+			return nil, err
+		}
+		body = encoded
+	}
+
+	target := p.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, op.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", op.contentType)
+	}
+	req.Header.Set("Accept", "application/json, */*;q=0.8")
+	return req, nil
+}
+
+// encodeBody encodes a request body of the media type
+func encodeBody(contentType string, value interface{}) (io.Reader, error) {
+	switch {
+	case isJSON(contentType):
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode body: %w", err)
+		}
+		return bytes.NewReader(data), nil
+	case contentType == "application/x-www-form-urlencoded":
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: body must be an object", resources.ErrInvalidParams)
+		}
+		form := url.Values{}
+		for name, v := range fields {
+			for _, item := range asValues(v) {
+				form.Add(name, formatValue(item))
+			}
+		}
+		return strings.NewReader(form.Encode()), nil
+	default:
+		return strings.NewReader(formatValue(value)), nil
+	}
+}
+
+// result maps a response into a tool result: images and audio as such, text and JSON as text, and other
+// contents as a resource of the request's URL
+func (p *ToolProvider) result(resp *http.Response) (protocol.ToolCallResult, error) {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.opts.MaxResponseSize+1))
+	if err != nil {
+		return protocol.ToolCallResult{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	truncated := int64(len(data)) > p.opts.MaxResponseSize
+	if truncated {
+		data = data[:p.opts.MaxResponseSize]
+	}
+
+	isError := resp.StatusCode >= http.StatusBadRequest
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	var item protocol.ToolCallContent
+	switch {
+	case isError:
+		item = protocol.NewTextContent(fmt.Sprintf("%s\n%s", resp.Status, data))
+	case strings.HasPrefix(mediaType, "image/") && !truncated:
+		item = protocol.NewImageContent(base64.StdEncoding.EncodeToString(data), mediaType)
+	case strings.HasPrefix(mediaType, "audio/") && !truncated:
+		item = protocol.NewAudioContent(base64.StdEncoding.EncodeToString(data), mediaType)
+	case isJSON(mediaType) || strings.HasPrefix(mediaType, "text/") || mediaType == "" && utf8.Valid(data):
+		text := string(data)
+		if truncated {
+			text += "\n[response truncated]"
+		}
+		item = protocol.NewTextContent(text)
+	default:
+		item = protocol.NewResourceContent(map[string]interface{}{
+			"uri":      resp.Request.URL.String(),
+			"mimeType": mediaType,
+			"blob":     base64.StdEncoding.EncodeToString(data),
+		})
+	}
+	return protocol.NewToolCallResult([]protocol.ToolCallContent{item}, isError), nil
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// formatValue formats an argument for a path, query, header or cookie
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// asValues returns the items of an array argument, or the argument itself
+func asValues(value interface{}) []interface{} {
+	if items, ok := value.([]interface{}); ok {
+		return items
+	}
+	return []interface{}{value}
+}
+
+func asSlice(node interface{}) []interface{} {
+	items, _ := node.([]interface{})
+	return items
+}
+
+// Ensure ToolProvider implements resources.ToolProvider
+var _ resources.ToolProvider = (*ToolProvider)(nil)
@@ -0,0 +1,307 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+const petstore = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: "1.0"
+servers:
+  - url: /api
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, default: 10}
+        - name: tag
+          in: query
+          schema: {type: array, items: {type: string}}
+    post:
+      operationId: createPet
+      tags: [pets, admin]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewPet'
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      summary: Get a pet
+      tags: [pets]
+      parameters:
+        - name: X-Trace
+          in: header
+          schema: {type: string}
+    delete:
+      operationId: deletePet
+      tags: [admin]
+  /pets/{petId}/photo:
+    get:
+      operationId: getPhoto
+      tags: [pets]
+      parameters:
+        - $ref: '#/components/parameters/PetId'
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      required: true
+      description: The pet's ID
+      schema: {type: string}
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, description: Name of the pet}
+        age: {type: integer}
+`
+
+// newAPI serves the petstore document and API, recording the last request
+func newAPI(t *testing.T) (*httptest.Server, *http.Request, *[]byte) {
+	var (
+		last     http.Request
+		lastBody []byte
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, petstore)
+	})
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		last = *r.Clone(context.Background())
+		lastBody, _ = io.ReadAll(r.Body)
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/pets/7/photo":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG"))
+		case r.URL.Path == "/api/pets/404":
+			http.Error(w, `{"error":"no such pet"}`, http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"ok":true}`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &last, &lastBody
+}
+
+func loadProvider(t *testing.T, server *httptest.Server, opts Options) *ToolProvider {
+	doc, err := Load(context.Background(), server.URL+"/openapi.yaml", nil)
+	require.NoError(t, err)
+	provider, err := NewToolProvider(doc, opts)
+	require.NoError(t, err)
+	return provider
+}
+
+func toolNames(t *testing.T, provider *ToolProvider) []string {
+	result, err := provider.ListTools(context.Background(), "")
+	require.NoError(t, err)
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestToolProvider_Tools(t *testing.T) {
+	server, _, _ := newAPI(t)
+	provider := loadProvider(t, server, Options{})
+
+	assert.Equal(t, []string{"createPet", "deletePet", "getPhoto", "get_pets_petId", "listPets"}, toolNames(t, provider))
+
+	tool, err := provider.GetTool(context.Background(), "listPets")
+	require.NoError(t, err)
+	assert.Equal(t, "List pets", tool.Description)
+	assert.Equal(t, "integer", tool.InputSchema.Properties["limit"].Type)
+	assert.Equal(t, 10, tool.InputSchema.Properties["limit"].Default)
+	assert.Equal(t, "array", tool.InputSchema.Properties["tag"].Type)
+	assert.Empty(t, tool.InputSchema.Required)
+
+	// Path-level parameters and references are resolved
+	tool, err = provider.GetTool(context.Background(), "get_pets_petId")
+	require.NoError(t, err)
+	assert.Equal(t, "The pet's ID", tool.InputSchema.Properties["petId"].Description)
+	assert.Contains(t, tool.InputSchema.Properties, "X-Trace")
+	assert.Equal(t, []string{"petId"}, tool.InputSchema.Required)
+
+	// The properties of a JSON body are arguments of their own
+	tool, err = provider.GetTool(context.Background(), "createPet")
+	require.NoError(t, err)
+	assert.Equal(t, "Name of the pet", tool.InputSchema.Properties["name"].Description)
+	assert.Equal(t, "integer", tool.InputSchema.Properties["age"].Type)
+	assert.Equal(t, []string{"name"}, tool.InputSchema.Required)
+
+	_, err = provider.GetTool(context.Background(), "missing")
+	assert.ErrorIs(t, err, resources.ErrToolNotFound)
+}
+
+func TestToolProvider_Filters(t *testing.T) {
+	server, _, _ := newAPI(t)
+
+	assert.Equal(t, []string{"createPet", "deletePet"}, toolNames(t, loadProvider(t, server, Options{IncludeTags: []string{"admin"}})))
+	assert.Equal(t, []string{"deletePet", "listPets"}, toolNames(t, loadProvider(t, server, Options{
+		IncludeTags:       []string{"admin"},
+		IncludeOperations: []string{"listPets"},
+		ExcludeOperations: []string{"createPet"},
+	})))
+	assert.Equal(t, []string{"deletePet"}, toolNames(t, loadProvider(t, server, Options{ExcludeTags: []string{"pets"}})))
+}
+
+func TestToolProvider_Call(t *testing.T) {
+	ctx := context.Background()
+	server, last, lastBody := newAPI(t)
+	provider := loadProvider(t, server, Options{Auth: BearerAuth("secret")})
+	registry := resources.NewDynamicToolRegistry(provider)
+
+	t.Run("query parameters", func(t *testing.T) {
+		result, err := registry.CallTool(ctx, "listPets", map[string]interface{}{"limit": float64(5), "tag": []interface{}{"cat", "dog"}})
+		require.NoError(t, err)
+		callResult := result.(protocol.ToolCallResult)
+		assert.False(t, callResult.IsError)
+		assert.Equal(t, protocol.NewTextContent(`{"ok":true}`), callResult.Content[0])
+
+		assert.Equal(t, http.MethodGet, last.Method)
+		assert.Equal(t, "/api/pets", last.URL.Path)
+		assert.Equal(t, "5", last.URL.Query().Get("limit"))
+		assert.Equal(t, []string{"cat", "dog"}, last.URL.Query()["tag"])
+	})
+
+	t.Run("path and header parameters", func(t *testing.T) {
+		_, err := registry.CallTool(ctx, "get_pets_petId", map[string]interface{}{"petId": "a b", "X-Trace": "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, "/api/pets/a%20b", last.URL.EscapedPath())
+		assert.Equal(t, "abc", last.Header.Get("X-Trace"))
+	})
+
+	t.Run("dot segments", func(t *testing.T) {
+		for _, petId := range []string{".", ".."} {
+			_, err := registry.CallTool(ctx, "getPhoto", map[string]interface{}{"petId": petId})
+			assert.ErrorIs(t, err, resources.ErrInvalidParams)
+		}
+	})
+
+	t.Run("body", func(t *testing.T) {
+		_, err := registry.CallTool(ctx, "createPet", map[string]interface{}{"name": "Rex", "age": float64(3)})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, last.Method)
+		assert.Equal(t, "application/json", last.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"name":"Rex","age":3}`, string(*lastBody))
+
+		_, err = registry.CallTool(ctx, "createPet", map[string]interface{}{"age": float64(3)})
+		assert.ErrorIs(t, err, resources.ErrInvalidParams)
+	})
+
+	t.Run("images", func(t *testing.T) {
+		result, err := registry.CallTool(ctx, "getPhoto", map[string]interface{}{"petId": "7"})
+		require.NoError(t, err)
+		assert.Equal(t, protocol.NewImageContent("iVBORw==", "image/png"), result.(protocol.ToolCallResult).Content[0])
+	})
+
+	t.Run("error statuses", func(t *testing.T) {
+		result, err := registry.CallTool(ctx, "deletePet", map[string]interface{}{"petId": "404"})
+		require.NoError(t, err)
+		callResult := result.(protocol.ToolCallResult)
+		assert.True(t, callResult.IsError)
+		data, err := json.Marshal(callResult)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "404 Not Found")
+		assert.Contains(t, string(data), "no such pet")
+	})
+
+	t.Run("without credentials", func(t *testing.T) {
+		unauthenticated := loadProvider(t, server, Options{})
+		result, err := unauthenticated.HandleToolInvocation(ctx, "listPets", nil)
+		require.NoError(t, err)
+		assert.True(t, result.(protocol.ToolCallResult).IsError)
+	})
+}
+
+func TestToolProvider_Redirects(t *testing.T) {
+	ctx := context.Background()
+
+	// Another host must not get the API key
+	var leaked []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = append(leaked, r.Header.Get("X-API-Key"))
+	}))
+	t.Cleanup(other.Close)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, petstore)
+	})
+	mux.HandleFunc("/api/pets", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/api/pets/moved", http.StatusFound)
+	})
+	mux.HandleFunc("/api/pets/moved", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("X-API-Key"))
+	})
+	mux.HandleFunc("/api/pets/7", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/steal", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	provider := loadProvider(t, server, Options{Auth: HeaderAuth("X-API-Key", "secret")})
+
+	// Redirects on the same host are followed
+	result, err := provider.HandleToolInvocation(ctx, "listPets", nil)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.NewTextContent("secret"), result.(protocol.ToolCallResult).Content[0])
+
+	_, err = provider.HandleToolInvocation(ctx, "get_pets_petId", map[string]interface{}{"petId": "7"})
+	assert.ErrorContains(t, err, "refusing to follow a redirect")
+	assert.Empty(t, leaked)
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+
+	// Relative server URLs need a base URL when the document is read from a file
+	path := filepath.Join(t.TempDir(), "petstore.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(petstore), 0o600))
+	doc, err := Load(ctx, path, nil)
+	require.NoError(t, err)
+	_, err = NewToolProvider(doc, Options{})
+	assert.Error(t, err)
+	_, err = NewToolProvider(doc, Options{BaseURL: "https://api.example.com/v1"})
+	assert.NoError(t, err)
+
+	// JSON is YAML too
+	doc, err = Parse([]byte(`{"openapi":"3.1.0","servers":[{"url":"https://{region}.example.com","variables":{"region":{"default":"eu"}}}],"paths":{}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://eu.example.com", doc.ServerURL())
+
+	_, err = Parse([]byte(`{"swagger":"2.0"}`))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
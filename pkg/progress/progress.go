@@ -0,0 +1,86 @@
+// Package progress lets tools, prompts and resources report the progress of a request to the client that sent
+// it. A client asks for progress by putting a progressToken in the _meta of the request's params; progress
+// reported for other requests goes nowhere.
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// Notifier delivers a notifications/progress to the client of a request. It must not block, as it is called
+// from request paths.
+type Notifier func(params protocol.ProgressNotificationParams)
+
+// reporter sends the progress of one request, keeping it increasing as the spec requires
+type reporter struct {
+	token  json.RawMessage
+	notify Notifier
+
+	mu   sync.Mutex
+	last float64
+}
+
+type contextKey struct{}
+
+// TokenFromParams returns the progressToken in the _meta of a request's JSON params, or nil if there is none
+func TokenFromParams(paramsJSON string) json.RawMessage {
+	if paramsJSON == "" {
+		return nil
+	}
+	var params struct {
+		Meta struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		return nil
+	}
+	token := params.Meta.ProgressToken
+	if len(token) == 0 || string(token) == "null" {
+		return nil
+	}
+	return token
+}
+
+// WithReporter returns ctx reporting the progress of its request with token to notify. A nil token leaves ctx
+// as it is, as the client did not ask for progress.
+func WithReporter(ctx context.Context, token json.RawMessage, notify Notifier) context.Context {
+	if token == nil || notify == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, &reporter{token: token, notify: notify})
+}
+
+// Enabled reports whether the client of the request in ctx asked for its progress
+func Enabled(ctx context.Context) bool {
+	_, ok := ctx.Value(contextKey{}).(*reporter)
+	return ok
+}
+
+// Report sends the progress of the request in ctx to its client, if it asked for it. total is 0 when it is not
+// known. Progress that does not increase on the last report is dropped.
+func Report(ctx context.Context, progress, total float64, message string) {
+	r, ok := ctx.Value(contextKey{}).(*reporter)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	if progress <= r.last {
+		r.mu.Unlock()
+		return
+	}
+	r.last = progress
+	r.mu.Unlock()
+
+	r.notify(protocol.ProgressNotificationParams{
+		ProgressToken: r.token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+}
@@ -0,0 +1,46 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+func TestTokenFromParams(t *testing.T) {
+	assert.Equal(t, json.RawMessage(`"abc"`), TokenFromParams(`{"name":"x","_meta":{"progressToken":"abc"}}`))
+	assert.Equal(t, json.RawMessage(`12345678901234567890`), TokenFromParams(`{"_meta":{"progressToken":12345678901234567890}}`))
+	assert.Nil(t, TokenFromParams(`{"_meta":{"progressToken":null}}`))
+	assert.Nil(t, TokenFromParams(`{"name":"x"}`))
+	assert.Nil(t, TokenFromParams(""))
+	assert.Nil(t, TokenFromParams("not json"))
+}
+
+func TestReport(t *testing.T) {
+	var sent []protocol.ProgressNotificationParams
+	notify := func(params protocol.ProgressNotificationParams) {
+		sent = append(sent, params)
+	}
+
+	// Without a token the client did not ask for progress
+	ctx := WithReporter(context.Background(), nil, notify)
+	assert.False(t, Enabled(ctx))
+	Report(ctx, 1, 0, "ignored")
+	assert.Empty(t, sent)
+
+	ctx = WithReporter(context.Background(), json.RawMessage(`7`), notify)
+	assert.True(t, Enabled(ctx))
+	Report(ctx, 1, 3, "one")
+	Report(ctx, 1, 3, "not increasing")
+	Report(ctx, 2, 3, "two")
+
+	data, err := json.Marshal(sent)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"progressToken": 7, "progress": 1, "total": 3, "message": "one"},
+		{"progressToken": 7, "progress": 2, "total": 3, "message": "two"}
+	]`, string(data))
+}
@@ -28,9 +28,10 @@ type WrappedRequest struct {
 	RespondToConnectionId string                 `protobuf:"bytes,2,opt,name=respond_to_connection_id,json=respondToConnectionId,proto3" json:"respond_to_connection_id,omitempty"`
 	Request               *JsonRpcRequest        `protobuf:"bytes,3,opt,name=request,proto3" json:"request,omitempty"`
 	AuthInfo              []byte                 `protobuf:"bytes,4,opt,name=auth_info,json=authInfo,proto3" json:"auth_info,omitempty"`
-	TraceId               string                 `protobuf:"bytes,5,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+	// W3C trace context (traceparent, tracestate) of the caller, see pkg/tracing
+	TraceContext  map[string]string `protobuf:"bytes,6,rep,name=trace_context,json=traceContext,proto3" json:"trace_context,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *WrappedRequest) Reset() {
@@ -91,11 +92,11 @@ func (x *WrappedRequest) GetAuthInfo() []byte {
 	return nil
 }
 
-func (x *WrappedRequest) GetTraceId() string {
+func (x *WrappedRequest) GetTraceContext() map[string]string {
 	if x != nil {
-		return x.TraceId
+		return x.TraceContext
 	}
-	return ""
+	return nil
 }
 
 // JsonRpcRequest represents a JSON-RPC request message
@@ -776,17 +777,65 @@ func (x *McpSessionInitialize) GetServerCapabilitiesJson() string {
 	return ""
 }
 
+// SessionNotification asks a session actor to send a server-initiated notification to its client
+type SessionNotification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Notification  *JsonRpcRequest        `protobuf:"bytes,1,opt,name=notification,proto3" json:"notification,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionNotification) Reset() {
+	*x = SessionNotification{}
+	mi := &file_proto_mcppb_jsonrpc_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionNotification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionNotification) ProtoMessage() {}
+
+func (x *SessionNotification) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_jsonrpc_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionNotification.ProtoReflect.Descriptor instead.
+func (*SessionNotification) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_jsonrpc_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SessionNotification) GetNotification() *JsonRpcRequest {
+	if x != nil {
+		return x.Notification
+	}
+	return nil
+}
+
 var File_proto_mcppb_jsonrpc_proto protoreflect.FileDescriptor
 
 const file_proto_mcppb_jsonrpc_proto_rawDesc = "" +
 	"\n" +
-	"\x19proto/mcppb/jsonrpc.proto\x12\x05mcppb\x1a\x1cgoogle/protobuf/struct.proto\"\xc9\x01\n" +
+	"\x19proto/mcppb/jsonrpc.proto\x12\x05mcppb\x1a\x1cgoogle/protobuf/struct.proto\"\xcd\x02\n" +
 	"\x0eWrappedRequest\x12\x15\n" +
 	"\x06is_ask\x18\x01 \x01(\bR\x05isAsk\x127\n" +
 	"\x18respond_to_connection_id\x18\x02 \x01(\tR\x15respondToConnectionId\x12/\n" +
 	"\arequest\x18\x03 \x01(\v2\x15.mcppb.JsonRpcRequestR\arequest\x12\x1b\n" +
-	"\tauth_info\x18\x04 \x01(\fR\bauthInfo\x12\x19\n" +
-	"\btrace_id\x18\x05 \x01(\tR\atraceId\"\xbc\x01\n" +
+	"\tauth_info\x18\x04 \x01(\fR\bauthInfo\x12L\n" +
+	"\rtrace_context\x18\x06 \x03(\v2'.mcppb.WrappedRequest.TraceContextEntryR\ftraceContext\x1a?\n" +
+	"\x11TraceContextEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01J\x04\b\x05\x10\x06R\btrace_id\"\xbc\x01\n" +
 	"\x0eJsonRpcRequest\x12\x18\n" +
 	"\ajsonrpc\x18\x01 \x01(\tR\ajsonrpc\x12\x17\n" +
 	"\x06int_id\x18\x02 \x01(\x03H\x00R\x05intId\x12\x1d\n" +
@@ -834,7 +883,9 @@ const file_proto_mcppb_jsonrpc_proto_rawDesc = "" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
 	"\n" +
 	"prefer_sse\x18\x02 \x01(\bR\tpreferSse\x128\n" +
-	"\x18server_capabilities_json\x18\x03 \x01(\tR\x16serverCapabilitiesJsonB.Z,github.com/traego/scaled-mcp/pkg/proto/mcppbb\x06proto3"
+	"\x18server_capabilities_json\x18\x03 \x01(\tR\x16serverCapabilitiesJson\"P\n" +
+	"\x13SessionNotification\x129\n" +
+	"\fnotification\x18\x01 \x01(\v2\x15.mcppb.JsonRpcRequestR\fnotificationB.Z,github.com/traego/scaled-mcp/pkg/proto/mcppbb\x06proto3"
 
 var (
 	file_proto_mcppb_jsonrpc_proto_rawDescOnce sync.Once
@@ -848,7 +899,7 @@ func file_proto_mcppb_jsonrpc_proto_rawDescGZIP() []byte {
 	return file_proto_mcppb_jsonrpc_proto_rawDescData
 }
 
-var file_proto_mcppb_jsonrpc_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_proto_mcppb_jsonrpc_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_proto_mcppb_jsonrpc_proto_goTypes = []any{
 	(*WrappedRequest)(nil),       // 0: mcppb.WrappedRequest
 	(*JsonRpcRequest)(nil),       // 1: mcppb.JsonRpcRequest
@@ -859,21 +910,25 @@ var file_proto_mcppb_jsonrpc_proto_goTypes = []any{
 	(*McpSessionRequest)(nil),    // 6: mcppb.McpSessionRequest
 	(*McpSessionResponse)(nil),   // 7: mcppb.McpSessionResponse
 	(*McpSessionInitialize)(nil), // 8: mcppb.McpSessionInitialize
+	(*SessionNotification)(nil),  // 9: mcppb.SessionNotification
+	nil,                          // 10: mcppb.WrappedRequest.TraceContextEntry
 }
 var file_proto_mcppb_jsonrpc_proto_depIdxs = []int32{
-	1, // 0: mcppb.WrappedRequest.request:type_name -> mcppb.JsonRpcRequest
-	3, // 1: mcppb.JsonRpcResponse.error:type_name -> mcppb.JsonRpcError
-	1, // 2: mcppb.JsonRpcBatchRequest.requests:type_name -> mcppb.JsonRpcRequest
-	2, // 3: mcppb.JsonRpcBatchResponse.responses:type_name -> mcppb.JsonRpcResponse
-	1, // 4: mcppb.McpSessionRequest.single_request:type_name -> mcppb.JsonRpcRequest
-	4, // 5: mcppb.McpSessionRequest.batch_request:type_name -> mcppb.JsonRpcBatchRequest
-	2, // 6: mcppb.McpSessionResponse.single_response:type_name -> mcppb.JsonRpcResponse
-	5, // 7: mcppb.McpSessionResponse.batch_response:type_name -> mcppb.JsonRpcBatchResponse
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	1,  // 0: mcppb.WrappedRequest.request:type_name -> mcppb.JsonRpcRequest
+	10, // 1: mcppb.WrappedRequest.trace_context:type_name -> mcppb.WrappedRequest.TraceContextEntry
+	3,  // 2: mcppb.JsonRpcResponse.error:type_name -> mcppb.JsonRpcError
+	1,  // 3: mcppb.JsonRpcBatchRequest.requests:type_name -> mcppb.JsonRpcRequest
+	2,  // 4: mcppb.JsonRpcBatchResponse.responses:type_name -> mcppb.JsonRpcResponse
+	1,  // 5: mcppb.McpSessionRequest.single_request:type_name -> mcppb.JsonRpcRequest
+	4,  // 6: mcppb.McpSessionRequest.batch_request:type_name -> mcppb.JsonRpcBatchRequest
+	2,  // 7: mcppb.McpSessionResponse.single_response:type_name -> mcppb.JsonRpcResponse
+	5,  // 8: mcppb.McpSessionResponse.batch_response:type_name -> mcppb.JsonRpcBatchResponse
+	1,  // 9: mcppb.SessionNotification.notification:type_name -> mcppb.JsonRpcRequest
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_proto_mcppb_jsonrpc_proto_init() }
@@ -907,7 +962,7 @@ func file_proto_mcppb_jsonrpc_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_mcppb_jsonrpc_proto_rawDesc), len(file_proto_mcppb_jsonrpc_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -233,6 +233,392 @@ func (x *StringMsg) GetMessage() string {
 	return ""
 }
 
+// DrainSession asks a session actor to hand its state to the session store, if one is configured, and to
+// tell its clients to reconnect, as its node shuts down
+type DrainSession struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RetryAfterMs  int64                  `protobuf:"varint,1,opt,name=retryAfterMs,proto3" json:"retryAfterMs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainSession) Reset() {
+	*x = DrainSession{}
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainSession) ProtoMessage() {}
+
+func (x *DrainSession) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainSession.ProtoReflect.Descriptor instead.
+func (*DrainSession) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_mcp_messages_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DrainSession) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+type DrainSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HandedOff     bool                   `protobuf:"varint,1,opt,name=handedOff,proto3" json:"handedOff,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainSessionResponse) Reset() {
+	*x = DrainSessionResponse{}
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainSessionResponse) ProtoMessage() {}
+
+func (x *DrainSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainSessionResponse.ProtoReflect.Descriptor instead.
+func (*DrainSessionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_mcp_messages_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DrainSessionResponse) GetHandedOff() bool {
+	if x != nil {
+		return x.HandedOff
+	}
+	return false
+}
+
+func (x *DrainSessionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// CloseConnection asks a connection actor to tell its client when to reconnect and close the connection
+type CloseConnection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RetryAfterMs  int64                  `protobuf:"varint,1,opt,name=retryAfterMs,proto3" json:"retryAfterMs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseConnection) Reset() {
+	*x = CloseConnection{}
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseConnection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseConnection) ProtoMessage() {}
+
+func (x *CloseConnection) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseConnection.ProtoReflect.Descriptor instead.
+func (*CloseConnection) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_mcp_messages_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CloseConnection) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+// GetSessionInfo asks a session actor to describe its session
+type GetSessionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionInfo) Reset() {
+	*x = GetSessionInfo{}
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionInfo) ProtoMessage() {}
+
+func (x *GetSessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionInfo.ProtoReflect.Descriptor instead.
+func (*GetSessionInfo) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_mcp_messages_proto_rawDescGZIP(), []int{8}
+}
+
+type SessionInfo struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SessionId          string                 `protobuf:"bytes,1,opt,name=sessionId,proto3" json:"sessionId,omitempty"`
+	State              string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	ProtocolVersion    string                 `protobuf:"bytes,3,opt,name=protocolVersion,proto3" json:"protocolVersion,omitempty"`
+	ClientName         string                 `protobuf:"bytes,4,opt,name=clientName,proto3" json:"clientName,omitempty"`
+	ClientVersion      string                 `protobuf:"bytes,5,opt,name=clientVersion,proto3" json:"clientVersion,omitempty"`
+	PrincipalId        string                 `protobuf:"bytes,6,opt,name=principalId,proto3" json:"principalId,omitempty"`
+	LastActivityUnixMs int64                  `protobuf:"varint,7,opt,name=lastActivityUnixMs,proto3" json:"lastActivityUnixMs,omitempty"`
+	ConnectionIds      []string               `protobuf:"bytes,8,rep,name=connectionIds,proto3" json:"connectionIds,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SessionInfo) Reset() {
+	*x = SessionInfo{}
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionInfo) ProtoMessage() {}
+
+func (x *SessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionInfo.ProtoReflect.Descriptor instead.
+func (*SessionInfo) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_mcp_messages_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SessionInfo) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetProtocolVersion() string {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetClientVersion() string {
+	if x != nil {
+		return x.ClientVersion
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetPrincipalId() string {
+	if x != nil {
+		return x.PrincipalId
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetLastActivityUnixMs() int64 {
+	if x != nil {
+		return x.LastActivityUnixMs
+	}
+	return 0
+}
+
+func (x *SessionInfo) GetConnectionIds() []string {
+	if x != nil {
+		return x.ConnectionIds
+	}
+	return nil
+}
+
+// TerminateSession asks a session actor to end its session
+type TerminateSession struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Reason string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Set when the client itself ended the session with an HTTP DELETE
+	ByClient      bool `protobuf:"varint,2,opt,name=byClient,proto3" json:"byClient,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TerminateSession) Reset() {
+	*x = TerminateSession{}
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TerminateSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminateSession) ProtoMessage() {}
+
+func (x *TerminateSession) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminateSession.ProtoReflect.Descriptor instead.
+func (*TerminateSession) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_mcp_messages_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TerminateSession) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *TerminateSession) GetByClient() bool {
+	if x != nil {
+		return x.ByClient
+	}
+	return false
+}
+
+type TerminateSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TerminateSessionResponse) Reset() {
+	*x = TerminateSessionResponse{}
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TerminateSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminateSessionResponse) ProtoMessage() {}
+
+func (x *TerminateSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mcppb_mcp_messages_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminateSessionResponse.ProtoReflect.Descriptor instead.
+func (*TerminateSessionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mcppb_mcp_messages_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TerminateSessionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TerminateSessionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 var File_proto_mcppb_mcp_messages_proto protoreflect.FileDescriptor
 
 const file_proto_mcppb_mcp_messages_proto_rawDesc = "" +
@@ -246,7 +632,32 @@ const file_proto_mcppb_mcp_messages_proto_rawDesc = "" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\"%\n" +
 	"\tStringMsg\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessageB4Z2github.com/traego/scaled-mcp/pkg/proto/mcppb;mcppbb\x06proto3"
+	"\amessage\x18\x01 \x01(\tR\amessage\"2\n" +
+	"\fDrainSession\x12\"\n" +
+	"\fretryAfterMs\x18\x01 \x01(\x03R\fretryAfterMs\"J\n" +
+	"\x14DrainSessionResponse\x12\x1c\n" +
+	"\thandedOff\x18\x01 \x01(\bR\thandedOff\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"5\n" +
+	"\x0fCloseConnection\x12\"\n" +
+	"\fretryAfterMs\x18\x01 \x01(\x03R\fretryAfterMs\"\x10\n" +
+	"\x0eGetSessionInfo\"\xa9\x02\n" +
+	"\vSessionInfo\x12\x1c\n" +
+	"\tsessionId\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12(\n" +
+	"\x0fprotocolVersion\x18\x03 \x01(\tR\x0fprotocolVersion\x12\x1e\n" +
+	"\n" +
+	"clientName\x18\x04 \x01(\tR\n" +
+	"clientName\x12$\n" +
+	"\rclientVersion\x18\x05 \x01(\tR\rclientVersion\x12 \n" +
+	"\vprincipalId\x18\x06 \x01(\tR\vprincipalId\x12.\n" +
+	"\x12lastActivityUnixMs\x18\a \x01(\x03R\x12lastActivityUnixMs\x12$\n" +
+	"\rconnectionIds\x18\b \x03(\tR\rconnectionIds\"F\n" +
+	"\x10TerminateSession\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12\x1a\n" +
+	"\bbyClient\x18\x02 \x01(\bR\bbyClient\"J\n" +
+	"\x18TerminateSessionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05errorB4Z2github.com/traego/scaled-mcp/pkg/proto/mcppb;mcppbb\x06proto3"
 
 var (
 	file_proto_mcppb_mcp_messages_proto_rawDescOnce sync.Once
@@ -260,13 +671,20 @@ func file_proto_mcppb_mcp_messages_proto_rawDescGZIP() []byte {
 	return file_proto_mcppb_mcp_messages_proto_rawDescData
 }
 
-var file_proto_mcppb_mcp_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_mcppb_mcp_messages_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_proto_mcppb_mcp_messages_proto_goTypes = []any{
 	(*TryCleanupIfUninitialized)(nil),  // 0: mcppb.TryCleanupIfUninitialized
 	(*CheckSessionTTL)(nil),            // 1: mcppb.CheckSessionTTL
 	(*RegisterConnection)(nil),         // 2: mcppb.RegisterConnection
 	(*RegisterConnectionResponse)(nil), // 3: mcppb.RegisterConnectionResponse
 	(*StringMsg)(nil),                  // 4: mcppb.StringMsg
+	(*DrainSession)(nil),               // 5: mcppb.DrainSession
+	(*DrainSessionResponse)(nil),       // 6: mcppb.DrainSessionResponse
+	(*CloseConnection)(nil),            // 7: mcppb.CloseConnection
+	(*GetSessionInfo)(nil),             // 8: mcppb.GetSessionInfo
+	(*SessionInfo)(nil),                // 9: mcppb.SessionInfo
+	(*TerminateSession)(nil),           // 10: mcppb.TerminateSession
+	(*TerminateSessionResponse)(nil),   // 11: mcppb.TerminateSessionResponse
 }
 var file_proto_mcppb_mcp_messages_proto_depIdxs = []int32{
 	0, // [0:0] is the sub-list for method output_type
@@ -287,7 +705,7 @@ func file_proto_mcppb_mcp_messages_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_mcppb_mcp_messages_proto_rawDesc), len(file_proto_mcppb_mcp_messages_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
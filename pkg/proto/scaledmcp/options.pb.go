@@ -0,0 +1,274 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: proto/scaledmcp/options.proto
+
+package scaledmcp
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ToolOptions configures the tool protoc-gen-scaledmcp generates for an RPC
+type ToolOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name replaces the tool's name, which is the RPC's full name with dots replaced by underscores
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// description replaces the tool's description, which is the RPC's leading comments
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// exclude leaves the RPC out of the generated tools
+	Exclude bool `protobuf:"varint,3,opt,name=exclude,proto3" json:"exclude,omitempty"`
+	// annotations are hints to clients about the tool's behaviour
+	Annotations   *ToolAnnotations `protobuf:"bytes,4,opt,name=annotations,proto3" json:"annotations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolOptions) Reset() {
+	*x = ToolOptions{}
+	mi := &file_proto_scaledmcp_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolOptions) ProtoMessage() {}
+
+func (x *ToolOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scaledmcp_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolOptions.ProtoReflect.Descriptor instead.
+func (*ToolOptions) Descriptor() ([]byte, []int) {
+	return file_proto_scaledmcp_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ToolOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ToolOptions) GetExclude() bool {
+	if x != nil {
+		return x.Exclude
+	}
+	return false
+}
+
+func (x *ToolOptions) GetAnnotations() *ToolAnnotations {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+// ToolAnnotations are hints to clients about a tool's behaviour
+type ToolAnnotations struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// title is a human readable title for the tool
+	Title string `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	// read_only_hint is whether the tool does not modify its environment
+	ReadOnlyHint *bool `protobuf:"varint,2,opt,name=read_only_hint,json=readOnlyHint,proto3,oneof" json:"read_only_hint,omitempty"`
+	// destructive_hint is whether the tool may make destructive updates
+	DestructiveHint *bool `protobuf:"varint,3,opt,name=destructive_hint,json=destructiveHint,proto3,oneof" json:"destructive_hint,omitempty"`
+	// idempotent_hint is whether calling the tool again with the same arguments has no further effect
+	IdempotentHint *bool `protobuf:"varint,4,opt,name=idempotent_hint,json=idempotentHint,proto3,oneof" json:"idempotent_hint,omitempty"`
+	// open_world_hint is whether the tool interacts with an open world of external entities
+	OpenWorldHint *bool `protobuf:"varint,5,opt,name=open_world_hint,json=openWorldHint,proto3,oneof" json:"open_world_hint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolAnnotations) Reset() {
+	*x = ToolAnnotations{}
+	mi := &file_proto_scaledmcp_options_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolAnnotations) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolAnnotations) ProtoMessage() {}
+
+func (x *ToolAnnotations) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_scaledmcp_options_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolAnnotations.ProtoReflect.Descriptor instead.
+func (*ToolAnnotations) Descriptor() ([]byte, []int) {
+	return file_proto_scaledmcp_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ToolAnnotations) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ToolAnnotations) GetReadOnlyHint() bool {
+	if x != nil && x.ReadOnlyHint != nil {
+		return *x.ReadOnlyHint
+	}
+	return false
+}
+
+func (x *ToolAnnotations) GetDestructiveHint() bool {
+	if x != nil && x.DestructiveHint != nil {
+		return *x.DestructiveHint
+	}
+	return false
+}
+
+func (x *ToolAnnotations) GetIdempotentHint() bool {
+	if x != nil && x.IdempotentHint != nil {
+		return *x.IdempotentHint
+	}
+	return false
+}
+
+func (x *ToolAnnotations) GetOpenWorldHint() bool {
+	if x != nil && x.OpenWorldHint != nil {
+		return *x.OpenWorldHint
+	}
+	return false
+}
+
+var file_proto_scaledmcp_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*ToolOptions)(nil),
+		Field:         52100,
+		Name:          "scaledmcp.tool",
+		Tag:           "bytes,52100,opt,name=tool",
+		Filename:      "proto/scaledmcp/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// tool configures the tool generated for the RPC
+	//
+	// optional scaledmcp.ToolOptions tool = 52100;
+	E_Tool = &file_proto_scaledmcp_options_proto_extTypes[0]
+)
+
+var File_proto_scaledmcp_options_proto protoreflect.FileDescriptor
+
+const file_proto_scaledmcp_options_proto_rawDesc = "" +
+	"\n" +
+	"\x1dproto/scaledmcp/options.proto\x12\tscaledmcp\x1a google/protobuf/descriptor.proto\"\x9b\x01\n" +
+	"\vToolOptions\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x18\n" +
+	"\aexclude\x18\x03 \x01(\bR\aexclude\x12<\n" +
+	"\vannotations\x18\x04 \x01(\v2\x1a.scaledmcp.ToolAnnotationsR\vannotations\"\xad\x02\n" +
+	"\x0fToolAnnotations\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12)\n" +
+	"\x0eread_only_hint\x18\x02 \x01(\bH\x00R\freadOnlyHint\x88\x01\x01\x12.\n" +
+	"\x10destructive_hint\x18\x03 \x01(\bH\x01R\x0fdestructiveHint\x88\x01\x01\x12,\n" +
+	"\x0fidempotent_hint\x18\x04 \x01(\bH\x02R\x0eidempotentHint\x88\x01\x01\x12+\n" +
+	"\x0fopen_world_hint\x18\x05 \x01(\bH\x03R\ropenWorldHint\x88\x01\x01B\x11\n" +
+	"\x0f_read_only_hintB\x13\n" +
+	"\x11_destructive_hintB\x12\n" +
+	"\x10_idempotent_hintB\x12\n" +
+	"\x10_open_world_hint:L\n" +
+	"\x04tool\x12\x1e.google.protobuf.MethodOptions\x18\x84\x97\x03 \x01(\v2\x16.scaledmcp.ToolOptionsR\x04toolB2Z0github.com/traego/scaled-mcp/pkg/proto/scaledmcpb\x06proto3"
+
+var (
+	file_proto_scaledmcp_options_proto_rawDescOnce sync.Once
+	file_proto_scaledmcp_options_proto_rawDescData []byte
+)
+
+func file_proto_scaledmcp_options_proto_rawDescGZIP() []byte {
+	file_proto_scaledmcp_options_proto_rawDescOnce.Do(func() {
+		file_proto_scaledmcp_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_scaledmcp_options_proto_rawDesc), len(file_proto_scaledmcp_options_proto_rawDesc)))
+	})
+	return file_proto_scaledmcp_options_proto_rawDescData
+}
+
+var file_proto_scaledmcp_options_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_scaledmcp_options_proto_goTypes = []any{
+	(*ToolOptions)(nil),                // 0: scaledmcp.ToolOptions
+	(*ToolAnnotations)(nil),            // 1: scaledmcp.ToolAnnotations
+	(*descriptorpb.MethodOptions)(nil), // 2: google.protobuf.MethodOptions
+}
+var file_proto_scaledmcp_options_proto_depIdxs = []int32{
+	1, // 0: scaledmcp.ToolOptions.annotations:type_name -> scaledmcp.ToolAnnotations
+	2, // 1: scaledmcp.tool:extendee -> google.protobuf.MethodOptions
+	0, // 2: scaledmcp.tool:type_name -> scaledmcp.ToolOptions
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	2, // [2:3] is the sub-list for extension type_name
+	1, // [1:2] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_scaledmcp_options_proto_init() }
+func file_proto_scaledmcp_options_proto_init() {
+	if File_proto_scaledmcp_options_proto != nil {
+		return
+	}
+	file_proto_scaledmcp_options_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_scaledmcp_options_proto_rawDesc), len(file_proto_scaledmcp_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_scaledmcp_options_proto_goTypes,
+		DependencyIndexes: file_proto_scaledmcp_options_proto_depIdxs,
+		MessageInfos:      file_proto_scaledmcp_options_proto_msgTypes,
+		ExtensionInfos:    file_proto_scaledmcp_options_proto_extTypes,
+	}.Build()
+	File_proto_scaledmcp_options_proto = out.File
+	file_proto_scaledmcp_options_proto_goTypes = nil
+	file_proto_scaledmcp_options_proto_depIdxs = nil
+}
@@ -90,3 +90,29 @@ func ConvertProtoToJSONResponse(protoResp *mcppb.JsonRpcResponse) (JSONRPCMessag
 
 	return jsonResp, nil
 }
+
+// ConvertProtoToJSONRequest converts a protobuf request, such as a server-initiated notification, to a
+// JSON-RPC message
+func ConvertProtoToJSONRequest(protoReq *mcppb.JsonRpcRequest) (JSONRPCMessage, error) {
+	jsonReq := JSONRPCMessage{
+		JSONRPC: protoReq.Jsonrpc,
+		Method:  protoReq.Method,
+	}
+
+	switch id := protoReq.Id.(type) {
+	case *mcppb.JsonRpcRequest_IntId:
+		jsonReq.ID = id.IntId
+	case *mcppb.JsonRpcRequest_StringId:
+		jsonReq.ID = id.StringId
+	}
+
+	if protoReq.ParamsJson != "" {
+		var params interface{}
+		if err := json.Unmarshal([]byte(protoReq.ParamsJson), &params); err != nil {
+			return jsonReq, fmt.Errorf("failed to unmarshal params: %w", err)
+		}
+		jsonReq.Params = params
+	}
+
+	return jsonReq, nil
+}
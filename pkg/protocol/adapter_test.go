@@ -318,3 +318,46 @@ func TestConvertProtoToJSONResponse(t *testing.T) {
 		assert.False(t, exists, "Error data should not be present")
 	})
 }
+
+func TestConvertProtoToJSONRequest(t *testing.T) {
+	t.Run("notification has no ID", func(t *testing.T) {
+		protoReq := &mcppb.JsonRpcRequest{
+			Jsonrpc:    "2.0",
+			Id:         &mcppb.JsonRpcRequest_NullId{NullId: true},
+			Method:     "notifications/message",
+			ParamsJson: `{"level":"info","data":"hello"}`,
+		}
+
+		jsonReq, err := ConvertProtoToJSONRequest(protoReq)
+		require.NoError(t, err)
+
+		assert.Nil(t, jsonReq.ID)
+		assert.Equal(t, "notifications/message", jsonReq.Method)
+		params, ok := jsonReq.Params.(map[string]interface{})
+		require.True(t, ok, "Params should be a map")
+		assert.Equal(t, "info", params["level"])
+
+		// The id must not be serialized for a notification
+		data, err := json.Marshal(jsonReq)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), `"id"`)
+	})
+
+	t.Run("request with string ID", func(t *testing.T) {
+		protoReq := &mcppb.JsonRpcRequest{
+			Jsonrpc: "2.0",
+			Id:      &mcppb.JsonRpcRequest_StringId{StringId: "ping-1"},
+			Method:  "ping",
+		}
+
+		jsonReq, err := ConvertProtoToJSONRequest(protoReq)
+		require.NoError(t, err)
+		assert.Equal(t, "ping-1", jsonReq.ID)
+		assert.Nil(t, jsonReq.Params)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := ConvertProtoToJSONRequest(&mcppb.JsonRpcRequest{Jsonrpc: "2.0", Method: "x", ParamsJson: "{"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+package protocol
+
+// Reference types of a completion/complete request
+const (
+	CompletionRefPrompt   = "ref/prompt"
+	CompletionRefResource = "ref/resource"
+)
+
+// MaxCompletionValues is the most values a completion result may hold
+const MaxCompletionValues = 100
+
+// CompleteParams are the params of a completion/complete request
+type CompleteParams struct {
+	Ref      CompletionReference `json:"ref"`
+	Argument CompletionArgument  `json:"argument"`
+	Context  *CompletionContext  `json:"context,omitempty"`
+}
+
+// CompletionReference names the prompt, by name, or the resource template, by URI template, being completed
+type CompletionReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument being completed and what the client has typed so far
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionContext holds the values of the arguments the client already filled in
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompleteResult is the result of a completion/complete request
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+// Completion holds the candidate values of a completion
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// NewCompleteResult creates a completion result, keeping at most MaxCompletionValues values
+func NewCompleteResult(values []string, hasMore bool) CompleteResult {
+	if values == nil {
+		values = []string{}
+	}
+	completion := Completion{Values: values, HasMore: hasMore}
+	if len(values) > MaxCompletionValues {
+		completion.Values = values[:MaxCompletionValues]
+		completion.Total = len(values)
+		completion.HasMore = true
+	}
+	return CompleteResult{Completion: completion}
+}
@@ -18,6 +18,10 @@ const (
 	ErrInternal = -32603
 	// Server error (reserved for implementation-defined server errors)
 	ErrServer = -32000
+	// Unauthorized (implementation-defined: the request's credentials were rejected)
+	ErrUnauthorized = -32001
+	// Rate limited (implementation-defined: the request exceeded a rate or concurrency limit)
+	ErrRateLimited = -32029
 )
 
 // JsonRpcError represents a JSON-RPC error
@@ -126,6 +130,31 @@ func NewInternalError(details string, id interface{}) *JsonRpcError {
 	return NewError(ErrInternal, message, nil, id)
 }
 
+// NewOpaqueInternalError creates an internal error that reveals nothing but an error id, which can be
+// matched against server logs
+func NewOpaqueInternalError(errorId string, id interface{}) *JsonRpcError {
+	return NewError(ErrInternal, "Internal error", map[string]interface{}{"errorId": errorId}, id)
+}
+
+// NewUnauthorizedError creates a new unauthorized error
+func NewUnauthorizedError(details string, id interface{}) *JsonRpcError {
+	message := "Unauthorized"
+	if details != "" {
+		message += ": " + details
+	}
+	return NewError(ErrUnauthorized, message, nil, id)
+}
+
+// NewRateLimitedError creates a new rate limited error. The data carries the number of seconds to wait
+// before retrying as retryAfter.
+func NewRateLimitedError(details string, retryAfterSeconds int, id interface{}) *JsonRpcError {
+	message := "Rate limit exceeded"
+	if details != "" {
+		message += ": " + details
+	}
+	return NewError(ErrRateLimited, message, map[string]interface{}{"retryAfter": retryAfterSeconds}, id)
+}
+
 // NewServerError creates a new server error
 func NewServerError(code int, message string, data interface{}, id interface{}) *JsonRpcError {
 	if code >= -31999 && code <= -32000 {
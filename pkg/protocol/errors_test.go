@@ -187,3 +187,11 @@ func TestIsJsonRpcError(t *testing.T) {
 		assert.Nil(t, rpcErr)
 	})
 }
+
+func TestNewOpaqueInternalError(t *testing.T) {
+	err := NewOpaqueInternalError("0123456789abcdef", 7)
+	assert.Equal(t, ErrInternal, err.Code)
+	assert.Equal(t, "Internal error", err.Message)
+	assert.Equal(t, map[string]interface{}{"errorId": "0123456789abcdef"}, err.Data)
+	assert.Equal(t, 7, err.ID)
+}
@@ -0,0 +1,73 @@
+package protocol
+
+import (
+	"log/slog"
+)
+
+// LoggingLevel is the severity of a log message, following the syslog levels of RFC 5424
+type LoggingLevel string
+
+// Logging levels, from least to most severe
+const (
+	LoggingLevelDebug     LoggingLevel = "debug"
+	LoggingLevelInfo      LoggingLevel = "info"
+	LoggingLevelNotice    LoggingLevel = "notice"
+	LoggingLevelWarning   LoggingLevel = "warning"
+	LoggingLevelError     LoggingLevel = "error"
+	LoggingLevelCritical  LoggingLevel = "critical"
+	LoggingLevelAlert     LoggingLevel = "alert"
+	LoggingLevelEmergency LoggingLevel = "emergency"
+)
+
+// loggingLevels maps each logging level to a slog level. Levels slog has no name for sit between or
+// above its own.
+var loggingLevels = map[LoggingLevel]slog.Level{
+	LoggingLevelDebug:     slog.LevelDebug,
+	LoggingLevelInfo:      slog.LevelInfo,
+	LoggingLevelNotice:    slog.LevelInfo + 2,
+	LoggingLevelWarning:   slog.LevelWarn,
+	LoggingLevelError:     slog.LevelError,
+	LoggingLevelCritical:  slog.LevelError + 4,
+	LoggingLevelAlert:     slog.LevelError + 8,
+	LoggingLevelEmergency: slog.LevelError + 12,
+}
+
+// SlogLevel returns the slog level matching l, and false if l is not a known level
+func (l LoggingLevel) SlogLevel() (slog.Level, bool) {
+	level, ok := loggingLevels[l]
+	return level, ok
+}
+
+// LoggingLevelFromSlog returns the most severe logging level at or below level
+func LoggingLevelFromSlog(level slog.Level) LoggingLevel {
+	switch {
+	case level >= slog.LevelError+12:
+		return LoggingLevelEmergency
+	case level >= slog.LevelError+8:
+		return LoggingLevelAlert
+	case level >= slog.LevelError+4:
+		return LoggingLevelCritical
+	case level >= slog.LevelError:
+		return LoggingLevelError
+	case level >= slog.LevelWarn:
+		return LoggingLevelWarning
+	case level >= slog.LevelInfo+2:
+		return LoggingLevelNotice
+	case level >= slog.LevelInfo:
+		return LoggingLevelInfo
+	default:
+		return LoggingLevelDebug
+	}
+}
+
+// SetLevelParams are the params of a logging/setLevel request
+type SetLevelParams struct {
+	Level LoggingLevel `json:"level"`
+}
+
+// LoggingMessageParams are the params of a notifications/message notification
+type LoggingMessageParams struct {
+	Level  LoggingLevel `json:"level"`
+	Logger string       `json:"logger,omitempty"`
+	Data   interface{}  `json:"data"`
+}
@@ -0,0 +1,32 @@
+package protocol
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggingLevels(t *testing.T) {
+	levels := []LoggingLevel{
+		LoggingLevelDebug, LoggingLevelInfo, LoggingLevelNotice, LoggingLevelWarning,
+		LoggingLevelError, LoggingLevelCritical, LoggingLevelAlert, LoggingLevelEmergency,
+	}
+
+	var previous slog.Level
+	for i, level := range levels {
+		slogLevel, ok := level.SlogLevel()
+		assert.True(t, ok, level)
+		if i > 0 {
+			assert.Greater(t, slogLevel, previous, "levels should be ordered by severity")
+		}
+		assert.Equal(t, level, LoggingLevelFromSlog(slogLevel), "round trip of %s", level)
+		previous = slogLevel
+	}
+
+	_, ok := LoggingLevel("verbose").SlogLevel()
+	assert.False(t, ok)
+
+	assert.Equal(t, LoggingLevelDebug, LoggingLevelFromSlog(slog.LevelDebug-4))
+	assert.Equal(t, LoggingLevelWarning, LoggingLevelFromSlog(slog.LevelWarn+1))
+}
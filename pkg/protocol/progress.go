@@ -0,0 +1,12 @@
+package protocol
+
+import "encoding/json"
+
+// ProgressNotificationParams are the params of a notifications/progress notification
+type ProgressNotificationParams struct {
+	// ProgressToken is the token the client gave in the _meta of the request, a string or an integer
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
@@ -1,7 +1,12 @@
 package protocol
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"sync"
 )
 
 // SESSION_ID_CONTEXT_KEY is the key used to store and retrieve the session ID from the context
@@ -20,9 +25,20 @@ type JSONRPCMessage struct {
 
 // Tool represents an MCP tool definition
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description,omitempty"`
-	InputSchema InputSchema `json:"inputSchema,omitempty,omitzero"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	InputSchema InputSchema      `json:"inputSchema,omitempty,omitzero"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints to clients about a tool's behaviour. Clients should not trust them for tools of
+// untrusted servers.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
 }
 
 // InputSchema represents the schema for tool inputs
@@ -37,11 +53,90 @@ type SchemaProperty struct {
 	Type        string      `json:"type"`
 	Description string      `json:"description,omitempty"`
 	Default     interface{} `json:"default,omitempty"`
+
+	// Redaction controls how the property's value appears in audit records and logs. It is server-side
+	// only and never sent to clients.
+	Redaction RedactionMode `json:"-"`
+}
+
+// RedactionMode controls how a sensitive value is recorded
+type RedactionMode string
+
+const (
+	// RedactNone records the value as is
+	RedactNone RedactionMode = ""
+	// RedactMask replaces the value with a fixed placeholder
+	RedactMask RedactionMode = "mask"
+	// RedactHash replaces the value with a keyed hash of it, so that equal values can still be correlated
+	// without the value being guessable from the hash
+	RedactHash RedactionMode = "hash"
+	// RedactOmit drops the value entirely
+	RedactOmit RedactionMode = "omit"
+)
+
+// RedactedPlaceholder replaces masked values
+const RedactedPlaceholder = "[REDACTED]"
+
+// processHashKey keys hashed arguments when no key is given. It is only known to this process, so servers of a
+// cluster must share a configured key for hashes to correlate across nodes.
+var processHashKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+})
+
+// RedactArguments returns a copy of args with the schema's redaction rules applied. Arguments that are not
+// described by the schema are kept as is. Hashed arguments are an HMAC keyed with hashKey, or with a random
+// key of the process if it is empty.
+func (s InputSchema) RedactArguments(args map[string]interface{}, hashKey []byte) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(args))
+	for name, value := range args {
+		switch s.Properties[name].Redaction {
+		case RedactMask:
+			redacted[name] = RedactedPlaceholder
+		case RedactHash:
+			redacted[name] = hashValue(hashKey, value)
+		case RedactOmit:
+			continue
+		default:
+			redacted[name] = value
+		}
+	}
+	return redacted
+}
+
+func hashValue(key []byte, value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return RedactedPlaceholder
+	}
+	if len(key) == 0 {
+		key = processHashKey()
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// IsToolErrorResult reports whether a JSON result is a tool call result flagged as an error
+func IsToolErrorResult(resultJson string) bool {
+	if resultJson == "" {
+		return false
+	}
+	var r struct {
+		IsError bool `json:"isError"`
+	}
+	return json.Unmarshal([]byte(resultJson), &r) == nil && r.IsError
 }
 
 // ToolListOptions provides pagination options for listing resources
 type ToolListOptions struct {
-	Cursor string // Cursor for pagination
+	Cursor   string // Cursor for pagination
+	PageSize int    // Number of tools per page, the registry's default if zero
 }
 
 // ToolListResult represents a paginated list of resources
@@ -96,11 +191,12 @@ type SamplingClientCapability struct {
 
 // ServerCapabilities represents the capabilities of the server
 type ServerCapabilities struct {
-	Prompts      *PromptsServerCapability   `json:"prompts,omitempty"`
-	Resources    *ResourcesServerCapability `json:"resources,omitempty"`
-	Tools        *ToolsServerCapability     `json:"tools,omitempty"`
-	Logging      *LoggingServerCapability   `json:"logging,omitempty"`
-	Experimental map[string]interface{}     `json:"experimental,omitempty"`
+	Prompts      *PromptsServerCapability     `json:"prompts,omitempty"`
+	Resources    *ResourcesServerCapability   `json:"resources,omitempty"`
+	Tools        *ToolsServerCapability       `json:"tools,omitempty"`
+	Logging      *LoggingServerCapability     `json:"logging,omitempty"`
+	Completions  *CompletionsServerCapability `json:"completions,omitempty"`
+	Experimental map[string]interface{}       `json:"experimental,omitempty"`
 }
 
 // PromptsServerCapability represents the prompts capability of the server
@@ -124,6 +220,11 @@ type LoggingServerCapability struct {
 	// Empty struct as per the 2025 spec
 }
 
+// CompletionsServerCapability represents the argument completion capability of the server
+type CompletionsServerCapability struct {
+	// Empty struct as per the 2025 spec
+}
+
 // ToolCallResult represents the result of a tool call
 type ToolCallResult struct {
 	Content []ToolCallContent `json:"content"`
@@ -635,3 +635,46 @@ func TestToolCallResultMarshaling(t *testing.T) {
 		assert.True(t, isError)
 	})
 }
+
+func TestInputSchemaRedactArguments(t *testing.T) {
+	schema := InputSchema{
+		Type: "object",
+		Properties: map[string]SchemaProperty{
+			"api_key":  {Type: "string", Redaction: RedactMask},
+			"account":  {Type: "string", Redaction: RedactHash},
+			"password": {Type: "string", Redaction: RedactOmit},
+			"query":    {Type: "string"},
+		},
+	}
+
+	args := map[string]interface{}{
+		"api_key":  "sk-secret",
+		"account":  "acct-42",
+		"password": "hunter2",
+		"query":    "weather",
+		"extra":    1.0,
+	}
+
+	key := []byte("audit-key")
+	redacted := schema.RedactArguments(args, key)
+	assert.Equal(t, RedactedPlaceholder, redacted["api_key"])
+	assert.Regexp(t, `^hmac-sha256:[0-9a-f]{32}$`, redacted["account"])
+	assert.Equal(t, redacted["account"], schema.RedactArguments(map[string]interface{}{"account": "acct-42"}, key)["account"], "hashes should be stable")
+	assert.NotEqual(t, redacted["account"], schema.RedactArguments(map[string]interface{}{"account": "acct-42"}, []byte("other-key"))["account"], "hashes depend on the key")
+	assert.Equal(t,
+		schema.RedactArguments(map[string]interface{}{"account": "acct-42"}, nil)["account"],
+		schema.RedactArguments(map[string]interface{}{"account": "acct-42"}, nil)["account"],
+		"the process key is used when none is given")
+	assert.NotContains(t, redacted, "password")
+	assert.Equal(t, "weather", redacted["query"])
+	assert.Equal(t, 1.0, redacted["extra"])
+
+	// The caller's arguments are left untouched
+	assert.Equal(t, "sk-secret", args["api_key"])
+	assert.Nil(t, schema.RedactArguments(nil, key))
+
+	// Redaction rules are server-side only and never sent to clients
+	b, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "mask")
+}
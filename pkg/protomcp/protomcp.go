@@ -0,0 +1,185 @@
+// Package protomcp maps protobuf RPCs to MCP tools: tool names and input schemas from descriptors, arguments to
+// request messages and responses to results, in protobuf JSON. It serves the gRPC tool provider and the code
+// protoc-gen-scaledmcp generates.
+package protomcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// invalidNameChars are the characters tool names may not have
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// ToolName returns the name of the tool of an RPC, its full name with dots replaced by underscores, such as
+// helloworld_Greeter_SayHello
+func ToolName(md protoreflect.MethodDescriptor) string {
+	return invalidNameChars.ReplaceAllString(string(md.FullName()), "_")
+}
+
+// Description returns the description of the tool of an RPC, its leading comments if its file has source info
+func Description(md protoreflect.MethodDescriptor) string {
+	description := Comments(md)
+	if description == "" {
+		description = fmt.Sprintf("Calls the %s RPC of %s", md.Name(), md.Parent().FullName())
+	}
+	if md.IsStreamingServer() {
+		description += "\n\nEach message the RPC streams is an item of the result."
+	}
+	return description
+}
+
+// Comments returns the leading comments of a descriptor, if its file has source info
+func Comments(d protoreflect.Descriptor) string {
+	return strings.TrimSpace(d.ParentFile().SourceLocations().ByDescriptor(d).LeadingComments)
+}
+
+// InputSchema returns the input schema of a tool taking a message as arguments. Each field is a property named
+// after its JSON name, and proto2 required fields are required.
+func InputSchema(md protoreflect.MessageDescriptor) protocol.InputSchema {
+	schema := protocol.InputSchema{
+		Type:       "object",
+		Properties: make(map[string]protocol.SchemaProperty),
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		schema.Properties[fd.JSONName()] = fieldProperty(fd)
+		if fd.Cardinality() == protoreflect.Required {
+			schema.Required = append(schema.Required, fd.JSONName())
+		}
+	}
+	return schema
+}
+
+// fieldProperty describes a field as a property of an input schema
+func fieldProperty(fd protoreflect.FieldDescriptor) protocol.SchemaProperty {
+	property := protocol.SchemaProperty{Type: kindType(fd), Description: Comments(fd)}
+	switch {
+	case fd.IsMap():
+		property.Type = "object"
+	case fd.IsList():
+		property.Type = "array"
+	}
+
+	if fd.Kind() == protoreflect.EnumKind && !fd.IsMap() {
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		property.Description = strings.TrimSpace(property.Description + "\n\nOne of " + strings.Join(names, ", "))
+	}
+
+	if fd.HasDefault() {
+		switch fd.Kind() {
+		case protoreflect.EnumKind:
+			property.Default = string(fd.DefaultEnumValue().Name())
+		case protoreflect.BytesKind:
+			property.Default = base64.StdEncoding.EncodeToString(fd.Default().Bytes())
+		default:
+			property.Default = fd.Default().Interface()
+		}
+	}
+	return property
+}
+
+// kindType returns the JSON type of a field's values in their protobuf JSON form
+func kindType(fd protoreflect.FieldDescriptor) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "boolean"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "integer"
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "number"
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageType(fd.Message())
+	default:
+		return "string"
+	}
+}
+
+// messageType returns the JSON type of a message in its protobuf JSON form, which is a scalar for some
+// well-known types
+func messageType(md protoreflect.MessageDescriptor) string {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp", "google.protobuf.Duration", "google.protobuf.FieldMask",
+		"google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return "string"
+	case "google.protobuf.BoolValue":
+		return "boolean"
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return "integer"
+	case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return "number"
+	case "google.protobuf.ListValue":
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// UnmarshalArguments sets the fields of a message from tool arguments in protobuf JSON. Arguments that are not
+// fields are invalid params.
+func UnmarshalArguments(params map[string]interface{}, msg proto.Message) error {
+	if params == nil {
+		return nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("%w: %v", resources.ErrInvalidParams, err)
+	}
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("%w: %v", resources.ErrInvalidParams, err)
+	}
+	return nil
+}
+
+// MarshalContent returns a message as a text item of a tool result, in protobuf JSON
+func MarshalContent(msg proto.Message) (protocol.ToolCallContent, error) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", msg.ProtoReflect().Descriptor().FullName(), err)
+	}
+	return protocol.NewTextContent(string(data)), nil
+}
+
+// Result returns the tool result of a unary RPC, made of its response, or of its error if it failed with a
+// status
+func Result(msg proto.Message, err error) (interface{}, error) {
+	if err != nil {
+		return StatusResult(err)
+	}
+	item, err := MarshalContent(msg)
+	if err != nil {
+		return nil, err
+	}
+	return protocol.NewToolCallResult([]protocol.ToolCallContent{item}, false), nil
+}
+
+// StatusResult returns an error result of the code and message of a status error. Other errors are returned as
+// they are.
+func StatusResult(err error) (interface{}, error) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil, err
+	}
+	return protocol.NewToolCallResult([]protocol.ToolCallContent{
+		protocol.NewTextContent(fmt.Sprintf("%s: %s", s.Code(), s.Message())),
+	}, true), nil
+}
@@ -0,0 +1,145 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/progress"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestInputSchema(t *testing.T) {
+	// FieldOptions is a proto2 message with defaults
+	schema := InputSchema((&descriptorpb.FieldOptions{}).ProtoReflect().Descriptor())
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, protocol.SchemaProperty{
+		Type:        "string",
+		Description: "One of STRING, CORD, STRING_PIECE",
+		Default:     "STRING",
+	}, schema.Properties["ctype"])
+	assert.Equal(t, false, schema.Properties["deprecated"].Default)
+	assert.Equal(t, "array", schema.Properties["targets"].Type)
+	assert.Equal(t, "object", schema.Properties["features"].Type)
+	assert.Empty(t, schema.Required)
+
+	// Well-known types are typed after their JSON form
+	schema = InputSchema((&durationpb.Duration{}).ProtoReflect().Descriptor())
+	assert.Equal(t, "integer", schema.Properties["seconds"].Type)
+
+	// Uninterpreted options have required fields
+	schema = InputSchema((&descriptorpb.UninterpretedOption_NamePart{}).ProtoReflect().Descriptor())
+	assert.Equal(t, []string{"namePart", "isExtension"}, schema.Required)
+}
+
+func TestToolName(t *testing.T) {
+	health := healthpb.File_grpc_health_v1_health_proto.Services().ByName("Health")
+	check, watch := health.Methods().ByName("Check"), health.Methods().ByName("Watch")
+
+	assert.Equal(t, "grpc_health_v1_Health_Check", ToolName(check))
+	// Generated descriptors have no source info, so no comments
+	assert.Equal(t, "Calls the Check RPC of grpc.health.v1.Health", Description(check))
+	assert.Equal(t, "Calls the Watch RPC of grpc.health.v1.Health\n\nEach message the RPC streams is an item of the result.", Description(watch))
+}
+
+func TestUnmarshalArguments(t *testing.T) {
+	msg := &descriptorpb.FieldDescriptorProto{}
+	require.NoError(t, UnmarshalArguments(map[string]interface{}{"name": "id", "number": float64(1), "type": "TYPE_STRING"}, msg))
+	assert.Equal(t, "id", msg.GetName())
+	assert.Equal(t, int32(1), msg.GetNumber())
+	assert.Equal(t, descriptorpb.FieldDescriptorProto_TYPE_STRING, msg.GetType())
+
+	err := UnmarshalArguments(map[string]interface{}{"missing": true}, msg)
+	assert.ErrorIs(t, err, resources.ErrInvalidParams)
+	err = UnmarshalArguments(map[string]interface{}{"number": "one"}, msg)
+	assert.ErrorIs(t, err, resources.ErrInvalidParams)
+}
+
+func TestResult(t *testing.T) {
+	result, err := Result(wrapperspb.String("hi"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.NewToolCallResult([]protocol.ToolCallContent{protocol.NewTextContent(`"hi"`)}, false), result)
+
+	// Status errors are error results, other errors are errors
+	result, err = Result(nil, status.Error(codes.PermissionDenied, "not yours"))
+	require.NoError(t, err)
+	assert.Equal(t, protocol.NewToolCallResult([]protocol.ToolCallContent{protocol.NewTextContent("PermissionDenied: not yours")}, true), result)
+
+	failure := errors.New("failure")
+	_, err = Result(nil, failure)
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestServerStream(t *testing.T) {
+	ctx := context.Background()
+	stream := NewServerStream[wrapperspb.StringValue](ctx)
+	assert.Equal(t, ctx, stream.Context())
+	require.NoError(t, stream.Send(wrapperspb.String("a")))
+	require.NoError(t, stream.Send(wrapperspb.String("b")))
+	assert.Error(t, stream.SendMsg("not a message"))
+	assert.Error(t, stream.RecvMsg(&wrapperspb.StringValue{}))
+
+	result, err := stream.Result(nil)
+	require.NoError(t, err)
+	assert.Equal(t, protocol.NewToolCallResult([]protocol.ToolCallContent{
+		protocol.NewTextContent(`"a"`),
+		protocol.NewTextContent(`"b"`),
+	}, false), result)
+
+	result, err = stream.Result(status.Error(codes.Unavailable, "down"))
+	require.NoError(t, err)
+	assert.True(t, result.(protocol.ToolCallResult).IsError)
+}
+
+func TestServerStream_Truncated(t *testing.T) {
+	var reports []protocol.ProgressNotificationParams
+	ctx := progress.WithReporter(context.Background(), json.RawMessage(`7`), func(params protocol.ProgressNotificationParams) {
+		reports = append(reports, params)
+	})
+	stream := NewServerStream[wrapperspb.Int32Value](ctx)
+
+	// The RPC stops once the result is full, returning the send's error
+	var err error
+	sent := 0
+	for err == nil {
+		if err = stream.Send(wrapperspb.Int32(int32(sent))); err == nil {
+			sent++
+		}
+	}
+	assert.ErrorIs(t, err, ErrStreamTruncated)
+	assert.Equal(t, MaxStreamMessages, sent)
+
+	result, err := stream.Result(err)
+	require.NoError(t, err)
+	callResult := result.(protocol.ToolCallResult)
+	assert.False(t, callResult.IsError)
+	require.Len(t, callResult.Content, MaxStreamMessages+1)
+	assert.Equal(t, protocol.NewTextContent("[stream truncated after 100 messages]"), callResult.Content[MaxStreamMessages])
+
+	// Each message kept was reported as progress
+	require.Len(t, reports, MaxStreamMessages)
+	assert.Equal(t, "7", string(reports[1].ProgressToken))
+	assert.Equal(t, float64(2), reports[1].Progress)
+	assert.Equal(t, "1", reports[1].Message)
+
+	t.Run("bytes", func(t *testing.T) {
+		stream := NewServerStream[wrapperspb.StringValue](context.Background())
+		big := strings.Repeat("x", MaxStreamBytes/2)
+		require.NoError(t, stream.Send(wrapperspb.String(big)))
+		assert.ErrorIs(t, stream.Send(wrapperspb.String(big)), ErrStreamTruncated)
+		result, err := stream.Result(nil)
+		require.NoError(t, err)
+		assert.Len(t, result.(protocol.ToolCallResult).Content, 2)
+	})
+}
@@ -0,0 +1,111 @@
+package protomcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/traego/scaled-mcp/pkg/progress"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Caps of the messages of a server stream put in a tool result
+const (
+	MaxStreamMessages = 100
+	MaxStreamBytes    = 1 << 20
+)
+
+// ErrStreamTruncated is returned by ServerStream.SendMsg once the result has as many messages as it can take
+var ErrStreamTruncated = errors.New("stream truncated")
+
+// ServerStream collects the messages a server streaming RPC sends, so a gRPC server implementation can be
+// called as a tool. It implements grpc.ServerStreamingServer[T]. Each message is reported as progress of the
+// tool call. The result takes up to MaxStreamMessages messages and MaxStreamBytes of them; sending more fails
+// with ErrStreamTruncated, so the RPC stops, and the result notes that it was truncated.
+type ServerStream[T any] struct {
+	ctx       context.Context
+	items     []protocol.ToolCallContent
+	size      int
+	truncated bool
+}
+
+// NewServerStream creates a stream of the tool call's context
+func NewServerStream[T any](ctx context.Context) *ServerStream[T] {
+	return &ServerStream[T]{ctx: ctx}
+}
+
+// Send adds a message to the result
+func (s *ServerStream[T]) Send(m *T) error {
+	return s.SendMsg(m)
+}
+
+// SendMsg adds a message to the result
+func (s *ServerStream[T]) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("cannot send %T, which is not a protobuf message", m)
+	}
+	if s.truncated {
+		return ErrStreamTruncated
+	}
+	item, err := MarshalContent(msg)
+	if err != nil {
+		return err
+	}
+	content, ok := item.(protocol.TextContent)
+	if !ok {
+		return fmt.Errorf("cannot send %T, which is not marshalled as text", m)
+	}
+	text := content.Text
+	if len(s.items) == MaxStreamMessages || s.size+len(text) > MaxStreamBytes {
+		s.truncated = true
+		return ErrStreamTruncated
+	}
+	s.items = append(s.items, item)
+	s.size += len(text)
+	progress.Report(s.ctx, float64(len(s.items)), 0, text)
+	return nil
+}
+
+// RecvMsg fails, as the request is passed to the RPC rather than received
+func (s *ServerStream[T]) RecvMsg(m any) error {
+	return errors.New("a server stream has no messages to receive")
+}
+
+// SetHeader does nothing, as a tool result has no headers
+func (s *ServerStream[T]) SetHeader(metadata.MD) error {
+	return nil
+}
+
+// SendHeader does nothing, as a tool result has no headers
+func (s *ServerStream[T]) SendHeader(metadata.MD) error {
+	return nil
+}
+
+// SetTrailer does nothing, as a tool result has no trailers
+func (s *ServerStream[T]) SetTrailer(metadata.MD) {}
+
+// Context returns the context of the tool call
+func (s *ServerStream[T]) Context() context.Context {
+	return s.ctx
+}
+
+// Result returns the tool result of the RPC, made of the messages sent, or of the error it returned if it
+// failed with a status. A truncated stream makes a result of the messages kept, whatever the RPC returned, as
+// it most likely failed because it could not send more.
+func (s *ServerStream[T]) Result(err error) (interface{}, error) {
+	if s.truncated {
+		items := append(s.items, protocol.NewTextContent(fmt.Sprintf("[stream truncated after %d messages]", len(s.items))))
+		return protocol.NewToolCallResult(items, false), nil
+	}
+	if err != nil {
+		return StatusResult(err)
+	}
+	return protocol.NewToolCallResult(s.items, false), nil
+}
+
+// Ensure ServerStream implements grpc.ServerStreamingServer
+var _ grpc.ServerStreamingServer[struct{}] = (*ServerStream[struct{}])(nil)
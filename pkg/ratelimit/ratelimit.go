@@ -0,0 +1,243 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Scopes a limit can apply to
+const (
+	ScopePrincipal = "principal"
+	ScopeSession   = "session"
+	ScopeMethod    = "method"
+	ScopeTool      = "tool"
+)
+
+// concurrencyRetryAfter is the retry hint given when a concurrency cap is hit, since there is no way to
+// know when an in-flight call will finish
+const concurrencyRetryAfter = time.Second
+
+// Limit is a token bucket rate plus a cap on concurrent in-flight calls. Zero values disable the
+// corresponding check.
+type Limit struct {
+	// Sustained requests per second
+	RequestsPerSecond float64 `json:"requests_per_second"`
+
+	// Maximum number of requests allowed in a burst. Defaults to the per second rate, rounded up.
+	Burst int `json:"burst"`
+
+	// Maximum number of calls in flight at once. Calls posted by SSE clients are in flight until the session
+	// takes them, as they are answered over the client's stream however long they take.
+	MaxConcurrent int `json:"max_concurrent"`
+}
+
+// IsZero reports whether the limit does not restrict anything
+func (l Limit) IsZero() bool {
+	return l.RequestsPerSecond <= 0 && l.MaxConcurrent <= 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return int(math.Max(1, math.Ceil(l.RequestsPerSecond)))
+}
+
+// Config holds the rate limiting configuration
+type Config struct {
+	// Whether to enable rate limiting
+	Enable bool `json:"enable"`
+
+	// Limit applied to each principal across all of its sessions. Requests without a principal are only
+	// subject to the session limit.
+	Principal Limit `json:"principal"`
+
+	// Overrides of the principal limit for specific principals
+	Principals map[string]Limit `json:"principals,omitempty"`
+
+	// Limit applied to each session
+	Session Limit `json:"session"`
+
+	// Limits applied per method, counted separately for each principal (or session when anonymous)
+	Methods map[string]Limit `json:"methods,omitempty"`
+
+	// Limits applied per tool for tools/call, counted separately for each principal (or session when anonymous)
+	Tools map[string]Limit `json:"tools,omitempty"`
+}
+
+// Request identifies a call being rate limited
+type Request struct {
+	PrincipalId string
+	SessionId   string
+	Method      string
+	Tool        string
+}
+
+// ExceededError is returned when a request is over one of its limits
+type ExceededError struct {
+	// The scope of the limit that was hit
+	Scope string
+
+	// How long the caller should wait before retrying
+	RetryAfter time.Duration
+
+	// Whether the concurrency cap, rather than the rate, was hit
+	Concurrency bool
+}
+
+func (e *ExceededError) Error() string {
+	if e.Concurrency {
+		return fmt.Sprintf("too many concurrent requests (%s limit)", e.Scope)
+	}
+	return fmt.Sprintf("rate limit exceeded (%s limit), retry after %s", e.Scope, e.RetryAfter)
+}
+
+// RetryAfterSeconds returns the retry delay rounded up to whole seconds, as used by the Retry-After header
+func (e *ExceededError) RetryAfterSeconds() int {
+	return int(math.Max(1, math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// Limiter enforces a Config against a Store
+type Limiter struct {
+	cfg   Config
+	store Store
+}
+
+// NewLimiter creates a limiter. Use a shared store such as RedisStore so limits hold across cluster nodes.
+func NewLimiter(cfg Config, store Store) *Limiter {
+	return &Limiter{
+		cfg:   cfg,
+		store: store,
+	}
+}
+
+type check struct {
+	scope string
+	key   string
+	limit Limit
+}
+
+func (l *Limiter) checks(req Request) []check {
+	subject := "session:" + req.SessionId
+	if req.PrincipalId != "" {
+		subject = "principal:" + req.PrincipalId
+	}
+
+	checks := make([]check, 0, 4)
+
+	if req.PrincipalId != "" {
+		limit, ok := l.cfg.Principals[req.PrincipalId]
+		if !ok {
+			limit = l.cfg.Principal
+		}
+		checks = append(checks, check{scope: ScopePrincipal, key: "principal:" + req.PrincipalId, limit: limit})
+	}
+
+	if req.SessionId != "" {
+		checks = append(checks, check{scope: ScopeSession, key: "session:" + req.SessionId, limit: l.cfg.Session})
+	}
+
+	if limit, ok := l.cfg.Methods[req.Method]; ok {
+		checks = append(checks, check{scope: ScopeMethod, key: "method:" + req.Method + ":" + subject, limit: limit})
+	}
+
+	if req.Tool != "" {
+		if limit, ok := l.cfg.Tools[req.Tool]; ok {
+			checks = append(checks, check{scope: ScopeTool, key: "tool:" + req.Tool + ":" + subject, limit: limit})
+		}
+	}
+
+	return checks
+}
+
+// Acquire checks every limit that applies to the request. On success it returns a release function that
+// must be called once the request has finished, freeing its concurrency slots. When a limit is hit the
+// error is an *ExceededError, and the tokens the request took from the buckets of other limits are refunded,
+// so that rejected requests do not use up any budget.
+func (l *Limiter) Acquire(ctx context.Context, req Request) (func(), error) {
+	if !l.cfg.Enable {
+		return func() {}, nil
+	}
+
+	checks := l.checks(req)
+
+	taken := make([]check, 0, len(checks))
+	refund := func() {
+		// Use a fresh context so tokens are refunded even if the request context was cancelled
+		rctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, c := range taken {
+			_ = l.store.Refund(rctx, "rate:"+c.key, c.limit.burst())
+		}
+	}
+
+	for _, c := range checks {
+		if c.limit.RequestsPerSecond <= 0 {
+			continue
+		}
+		allowed, retryAfter, err := l.store.Take(ctx, "rate:"+c.key, c.limit.RequestsPerSecond, c.limit.burst())
+		if err != nil {
+			refund()
+			return nil, fmt.Errorf("failed to check rate limit: %w", err)
+		}
+		if !allowed {
+			refund()
+			return nil, &ExceededError{Scope: c.scope, RetryAfter: retryAfter}
+		}
+		taken = append(taken, c)
+	}
+
+	acquired := make([]string, 0, len(checks))
+	release := func() {
+		// Use a fresh context so slots are freed even if the request context was cancelled
+		rctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, key := range acquired {
+			_ = l.store.Release(rctx, key)
+		}
+	}
+
+	for _, c := range checks {
+		if c.limit.MaxConcurrent <= 0 {
+			continue
+		}
+		key := "inflight:" + c.key
+		ok, err := l.store.Acquire(ctx, key, c.limit.MaxConcurrent)
+		if err != nil {
+			release()
+			refund()
+			return nil, fmt.Errorf("failed to check concurrency limit: %w", err)
+		}
+		if !ok {
+			release()
+			refund()
+			return nil, &ExceededError{Scope: c.scope, RetryAfter: concurrencyRetryAfter, Concurrency: true}
+		}
+		acquired = append(acquired, key)
+	}
+
+	return release, nil
+}
+
+// RequestFromParams builds a Request, reading the tool name out of tools/call params
+func RequestFromParams(principalId, sessionId, method, paramsJson string) Request {
+	req := Request{
+		PrincipalId: principalId,
+		SessionId:   sessionId,
+		Method:      method,
+	}
+
+	if method == "tools/call" && paramsJson != "" {
+		var params struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(paramsJson), &params); err == nil {
+			req.Tool = params.Name
+		}
+	}
+
+	return req
+}
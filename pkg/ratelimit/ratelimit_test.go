@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(cfg Config) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	cfg.Enable = true
+	return NewLimiter(cfg, store), clock
+}
+
+func requireExceeded(t *testing.T, err error, scope string) *ExceededError {
+	var exceeded *ExceededError
+	require.True(t, errors.As(err, &exceeded), "expected an ExceededError, got %v", err)
+	assert.Equal(t, scope, exceeded.Scope)
+	return exceeded
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := NewLimiter(Config{Session: Limit{RequestsPerSecond: 1, Burst: 1}}, NewMemoryStore())
+
+	for i := 0; i < 10; i++ {
+		release, err := l.Acquire(context.Background(), Request{SessionId: "s"})
+		require.NoError(t, err)
+		release()
+	}
+}
+
+func TestLimiterPrincipalLimitSpansSessions(t *testing.T) {
+	ctx := context.Background()
+	l, clock := newTestLimiter(Config{
+		Principal: Limit{RequestsPerSecond: 1, Burst: 2},
+	})
+
+	_, err := l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s1"})
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s2"})
+	require.NoError(t, err)
+
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s3"})
+	exceeded := requireExceeded(t, err, ScopePrincipal)
+	assert.False(t, exceeded.Concurrency)
+	assert.Equal(t, 1, exceeded.RetryAfterSeconds())
+
+	// Other principals are unaffected
+	_, err = l.Acquire(ctx, Request{PrincipalId: "other", SessionId: "s4"})
+	require.NoError(t, err)
+
+	clock.Advance(time.Second)
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s1"})
+	require.NoError(t, err)
+}
+
+func TestLimiterPrincipalOverrides(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(Config{
+		Principal:  Limit{RequestsPerSecond: 1, Burst: 1},
+		Principals: map[string]Limit{"batch-job": {RequestsPerSecond: 100, Burst: 100}},
+	})
+
+	for i := 0; i < 50; i++ {
+		_, err := l.Acquire(ctx, Request{PrincipalId: "batch-job", SessionId: "s"})
+		require.NoError(t, err)
+	}
+
+	_, err := l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s"})
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s"})
+	requireExceeded(t, err, ScopePrincipal)
+}
+
+func TestLimiterSessionLimit(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(Config{
+		Session: Limit{RequestsPerSecond: 1, Burst: 1},
+	})
+
+	_, err := l.Acquire(ctx, Request{SessionId: "s1"})
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, Request{SessionId: "s1"})
+	requireExceeded(t, err, ScopeSession)
+
+	_, err = l.Acquire(ctx, Request{SessionId: "s2"})
+	require.NoError(t, err)
+}
+
+func TestLimiterMethodAndToolLimits(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(Config{
+		Methods: map[string]Limit{"resources/read": {RequestsPerSecond: 1, Burst: 1}},
+		Tools:   map[string]Limit{"expensive": {RequestsPerSecond: 1, Burst: 1}},
+	})
+
+	_, err := l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s", Method: "resources/read"})
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s", Method: "resources/read"})
+	requireExceeded(t, err, ScopeMethod)
+
+	// Methods without a limit are unaffected
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s", Method: "tools/list"})
+	require.NoError(t, err)
+
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s", Method: "tools/call", Tool: "expensive"})
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s", Method: "tools/call", Tool: "expensive"})
+	requireExceeded(t, err, ScopeTool)
+
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s", Method: "tools/call", Tool: "cheap"})
+	require.NoError(t, err)
+
+	// Method and tool limits are counted per principal
+	_, err = l.Acquire(ctx, Request{PrincipalId: "other", SessionId: "s2", Method: "tools/call", Tool: "expensive"})
+	require.NoError(t, err)
+}
+
+func TestLimiterRejectionsUseNoBudget(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(Config{
+		Principal: Limit{RequestsPerSecond: 0.01, Burst: 3},
+		Tools: map[string]Limit{
+			"expensive": {RequestsPerSecond: 0.01, Burst: 1},
+			"slow":      {MaxConcurrent: 1},
+		},
+	})
+	expensive := Request{PrincipalId: "svc", SessionId: "s", Method: "tools/call", Tool: "expensive"}
+	slow := Request{PrincipalId: "svc", SessionId: "s", Method: "tools/call", Tool: "slow"}
+
+	_, err := l.Acquire(ctx, expensive)
+	require.NoError(t, err)
+
+	// Neither the per-tool rate nor the per-tool concurrency cap takes from the principal's budget
+	for i := 0; i < 3; i++ {
+		_, err = l.Acquire(ctx, expensive)
+		requireExceeded(t, err, ScopeTool)
+	}
+	releaseSlow, err := l.Acquire(ctx, slow)
+	require.NoError(t, err)
+	defer releaseSlow()
+	_, err = l.Acquire(ctx, slow)
+	requireExceeded(t, err, ScopeTool)
+
+	// The principal's budget is spent by the requests that went through only
+	list := Request{PrincipalId: "svc", SessionId: "s", Method: "tools/list"}
+	_, err = l.Acquire(ctx, list)
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, list)
+	requireExceeded(t, err, ScopePrincipal)
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(Config{
+		Principal: Limit{MaxConcurrent: 2},
+		Tools:     map[string]Limit{"slow": {MaxConcurrent: 1}},
+	})
+
+	slow := Request{PrincipalId: "svc", SessionId: "s1", Method: "tools/call", Tool: "slow"}
+
+	releaseSlow, err := l.Acquire(ctx, slow)
+	require.NoError(t, err)
+
+	_, err = l.Acquire(ctx, slow)
+	exceeded := requireExceeded(t, err, ScopeTool)
+	assert.True(t, exceeded.Concurrency)
+
+	// The rejected call must not hold on to its principal slot
+	releaseOther, err := l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s2", Method: "tools/list"})
+	require.NoError(t, err)
+
+	_, err = l.Acquire(ctx, Request{PrincipalId: "svc", SessionId: "s3", Method: "tools/list"})
+	requireExceeded(t, err, ScopePrincipal)
+
+	releaseSlow()
+	releaseOther()
+
+	release, err := l.Acquire(ctx, slow)
+	require.NoError(t, err)
+	release()
+}
+
+func TestRequestFromParams(t *testing.T) {
+	req := RequestFromParams("p", "s", "tools/call", `{"name":"search","arguments":{}}`)
+	assert.Equal(t, Request{PrincipalId: "p", SessionId: "s", Method: "tools/call", Tool: "search"}, req)
+
+	req = RequestFromParams("p", "s", "prompts/get", `{"name":"greeting"}`)
+	assert.Empty(t, req.Tool, "only tools/call names a tool")
+
+	req = RequestFromParams("p", "s", "tools/call", `not json`)
+	assert.Empty(t, req.Tool)
+}
+
+func TestExceededErrorRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, (&ExceededError{RetryAfter: 0}).RetryAfterSeconds())
+	assert.Equal(t, 1, (&ExceededError{RetryAfter: 200 * time.Millisecond}).RetryAfterSeconds())
+	assert.Equal(t, 3, (&ExceededError{RetryAfter: 2100 * time.Millisecond}).RetryAfterSeconds())
+}
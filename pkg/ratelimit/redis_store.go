@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisPrefix is the key prefix used by RedisStore when none is given
+const DefaultRedisPrefix = "mcp:ratelimit:"
+
+// inflightLeaseTTL bounds how long an in-flight counter survives without activity, so slots held by a
+// crashed node are eventually freed
+const inflightLeaseTTL = 10 * time.Minute
+
+// takeScript refills and takes from a token bucket stored as a hash of tokens and last refill time (ms).
+// It returns {allowed, retry_after_ms}.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+
+local elapsed = math.max(0, now - ts) / 1000
+tokens = math.min(burst, tokens + elapsed * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, retry}
+`)
+
+// refundScript puts a token back in a token bucket, up to the burst
+var refundScript = redis.NewScript(`
+local tokens = tonumber(redis.call("HGET", KEYS[1], "tokens"))
+if tokens == nil then
+  return 0
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(math.min(tonumber(ARGV[1]), tokens + 1)))
+return 1
+`)
+
+// acquireScript increments an in-flight counter unless it is at the maximum. It returns 1 when acquired.
+var acquireScript = redis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+if current >= tonumber(ARGV[1]) then
+  return 0
+end
+redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1
+`)
+
+// releaseScript decrements an in-flight counter, removing it when it reaches zero
+var releaseScript = redis.NewScript(`
+local current = redis.call("DECR", KEYS[1])
+if current <= 0 then
+  redis.call("DEL", KEYS[1])
+end
+return current
+`)
+
+// RedisStore keeps rate limit state in Redis so limits are shared by every node in a cluster
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore creates a store using the given client. An empty prefix uses DefaultRedisPrefix.
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = DefaultRedisPrefix
+	}
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+// Take implements Store
+func (s *RedisStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	res, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, rate, burst, s.now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// Refund implements Store
+func (s *RedisStore) Refund(ctx context.Context, key string, burst int) error {
+	if err := refundScript.Run(ctx, s.client, []string{s.prefix + key}, burst).Err(); err != nil {
+		return fmt.Errorf("failed to refund rate limit token: %w", err)
+	}
+	return nil
+}
+
+// Acquire implements Store
+func (s *RedisStore) Acquire(ctx context.Context, key string, max int) (bool, error) {
+	res, err := acquireScript.Run(ctx, s.client, []string{s.prefix + key}, max, inflightLeaseTTL.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire concurrency slot: %w", err)
+	}
+	return res == 1, nil
+}
+
+// Release implements Store
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if err := releaseScript.Run(ctx, s.client, []string{s.prefix + key}).Err(); err != nil {
+		return fmt.Errorf("failed to release concurrency slot: %w", err)
+	}
+	return nil
+}
+
+var _ Store = (*RedisStore)(nil)
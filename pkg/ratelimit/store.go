@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Store holds token buckets and in-flight counters. Implementations must be safe for concurrent use.
+type Store interface {
+	// Take removes a token from the bucket at key, refilling at rate tokens per second up to burst. When the
+	// bucket is empty it returns false and how long until a token is available.
+	Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+
+	// Refund puts back a token taken from the bucket at key, up to burst, for a request that a later limit
+	// rejected
+	Refund(ctx context.Context, key string, burst int) error
+
+	// Acquire increments the in-flight counter at key unless it has reached max
+	Acquire(ctx context.Context, key string, max int) (bool, error)
+
+	// Release decrements the in-flight counter at key
+	Release(ctx context.Context, key string) error
+}
+
+// idleBucketTTL is how long an untouched bucket is kept by MemoryStore
+const idleBucketTTL = 10 * time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore is an in-process Store. Limits are only enforced per node; use RedisStore in a cluster.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	inflight  map[string]int
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		inflight: make(map[string]int),
+		now:      time.Now,
+	}
+}
+
+// Take implements Store
+func (s *MemoryStore) Take(_ context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, wait, nil
+	}
+
+	b.tokens--
+	return true, 0, nil
+}
+
+// Refund implements Store
+func (s *MemoryStore) Refund(_ context.Context, key string, burst int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = math.Min(float64(burst), b.tokens+1)
+	}
+	return nil
+}
+
+// Acquire implements Store
+func (s *MemoryStore) Acquire(_ context.Context, key string, max int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inflight[key] >= max {
+		return false, nil
+	}
+	s.inflight[key]++
+	return true, nil
+}
+
+// Release implements Store
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inflight[key] <= 1 {
+		delete(s.inflight, key)
+		return nil
+	}
+	s.inflight[key]--
+	return nil
+}
+
+// prune drops buckets that have not been used for a while. Callers must hold the lock.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < idleBucketTTL {
+		return
+	}
+	s.lastPrune = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > idleBucketTTL {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+var _ Store = (*MemoryStore)(nil)
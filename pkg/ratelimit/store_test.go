@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// testStore exercises the Store contract
+func testStore(t *testing.T, store Store, clock *fakeClock) {
+	ctx := context.Background()
+
+	t.Run("token bucket", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			ok, _, err := store.Take(ctx, "bucket", 2, 3)
+			require.NoError(t, err)
+			assert.True(t, ok, "burst token %d", i)
+		}
+
+		ok, retryAfter, err := store.Take(ctx, "bucket", 2, 3)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.InDelta(t, 500*time.Millisecond, retryAfter, float64(10*time.Millisecond))
+
+		// Other keys have their own bucket
+		ok, _, err = store.Take(ctx, "other", 2, 3)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		clock.Advance(500 * time.Millisecond)
+		ok, _, err = store.Take(ctx, "bucket", 2, 3)
+		require.NoError(t, err)
+		assert.True(t, ok, "a token should have refilled")
+
+		ok, _, err = store.Take(ctx, "bucket", 2, 3)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// Refills never exceed the burst
+		clock.Advance(time.Hour)
+		for i := 0; i < 3; i++ {
+			ok, _, err = store.Take(ctx, "bucket", 2, 3)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+		ok, _, err = store.Take(ctx, "bucket", 2, 3)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("refund", func(t *testing.T) {
+		// Refunding a bucket that was never taken from is a no-op
+		require.NoError(t, store.Refund(ctx, "refunded", 2))
+
+		for i := 0; i < 2; i++ {
+			ok, _, err := store.Take(ctx, "refunded", 0.001, 2)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+		require.NoError(t, store.Refund(ctx, "refunded", 2))
+		ok, _, err := store.Take(ctx, "refunded", 0.001, 2)
+		require.NoError(t, err)
+		assert.True(t, ok, "the refunded token should be available")
+		ok, _, err = store.Take(ctx, "refunded", 0.001, 2)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// Refunds never exceed the burst
+		for i := 0; i < 5; i++ {
+			require.NoError(t, store.Refund(ctx, "refunded", 2))
+		}
+		for i := 0; i < 2; i++ {
+			ok, _, err = store.Take(ctx, "refunded", 0.001, 2)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+		ok, _, err = store.Take(ctx, "refunded", 0.001, 2)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			ok, err := store.Acquire(ctx, "inflight", 2)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		ok, err := store.Acquire(ctx, "inflight", 2)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		require.NoError(t, store.Release(ctx, "inflight"))
+		ok, err = store.Acquire(ctx, "inflight", 2)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, store.Release(ctx, "inflight"))
+		require.NoError(t, store.Release(ctx, "inflight"))
+		// Releasing more than was acquired must not leave spare capacity behind
+		require.NoError(t, store.Release(ctx, "inflight"))
+
+		for i := 0; i < 2; i++ {
+			ok, err = store.Acquire(ctx, "inflight", 2)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+		ok, err = store.Acquire(ctx, "inflight", 2)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	store := NewMemoryStore()
+	store.now = clock.Now
+
+	testStore(t, store, clock)
+}
+
+func TestMemoryStorePrunesIdleBuckets(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Now()}
+	store := NewMemoryStore()
+	store.now = clock.Now
+
+	_, _, err := store.Take(ctx, "idle", 1, 1)
+	require.NoError(t, err)
+	require.Len(t, store.buckets, 1)
+
+	clock.Advance(2 * idleBucketTTL)
+	_, _, err = store.Take(ctx, "active", 1, 1)
+	require.NoError(t, err)
+
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "active")
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	clock := &fakeClock{now: time.Now()}
+	store := NewRedisStore(client, "")
+	store.now = clock.Now
+
+	testStore(t, store, clock)
+
+	keys := mr.Keys()
+	require.NotEmpty(t, keys)
+	for _, k := range keys {
+		assert.Contains(t, k, DefaultRedisPrefix)
+	}
+}
@@ -0,0 +1,95 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// sensitiveKeys are attribute keys whose values are always replaced, whatever they contain
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "cookie", "dsn"}
+
+// Handler is a slog.Handler that redacts secrets from messages and string, error and stringer attributes
+// before passing records on
+type Handler struct {
+	next     slog.Handler
+	redactor *Redactor
+}
+
+// NewHandler wraps next so that everything it logs is redacted with the default patterns
+func NewHandler(next slog.Handler) *Handler {
+	return NewHandlerWithRedactor(next, defaultRedactor)
+}
+
+// NewHandlerWithRedactor wraps next using a custom redactor
+func NewHandlerWithRedactor(next slog.Handler, redactor *Redactor) *Handler {
+	return &Handler{next: next, redactor: redactor}
+}
+
+// Enabled implements slog.Handler
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.String(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted), redactor: h.redactor}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), redactor: h.redactor}
+}
+
+func (h *Handler) redactAttr(a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, Placeholder)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.redactor.String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redactAttr(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		switch val := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, h.redactor.String(val.Error()))
+		case interface{ String() string }:
+			return slog.String(a.Key, h.redactor.String(val.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if key == k || strings.HasSuffix(key, "_"+k) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ slog.Handler = (*Handler)(nil)
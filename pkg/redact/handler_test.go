@@ -0,0 +1,32 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.With("dsn", "postgres://app:pa55word@db/app").
+		WithGroup("req").
+		Error("failed with password=pa55word",
+			"err", errors.New("dial redis://:hunter2@cache:6379"),
+			"user_token", "plain-looking-value",
+			"tool", "search",
+			slog.Group("headers", "authorization", "Basic dXNlcjpwYXNz"),
+		)
+
+	out := buf.String()
+	assert.NotContains(t, out, "pa55word")
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "plain-looking-value")
+	assert.NotContains(t, out, "dXNlcjpwYXNz")
+	assert.Contains(t, out, `"tool":"search"`)
+	assert.Contains(t, out, Placeholder)
+}
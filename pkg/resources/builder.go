@@ -21,6 +21,7 @@ type ToolInput struct {
 	Description string
 	Required    bool
 	Default     interface{}
+	Redaction   protocol.RedactionMode
 }
 
 // NewTool creates a new tool builder
@@ -43,12 +44,19 @@ func (b *ToolBuilder) WithDescription(description string) *ToolBuilder {
 	return b
 }
 
+// WithAnnotations sets hints to clients about the tool's behaviour
+func (b *ToolBuilder) WithAnnotations(annotations protocol.ToolAnnotations) *ToolBuilder {
+	b.tool.Annotations = &annotations
+	return b
+}
+
 // WithInputs adds multiple input parameters to the tool at once
 func (b *ToolBuilder) WithInputs(inputs []ToolInput) *ToolBuilder {
 	for _, input := range inputs {
 		property := protocol.SchemaProperty{
 			Type:        input.Type,
 			Description: input.Description,
+			Redaction:   input.Redaction,
 		}
 
 		if input.Default != nil {
@@ -143,6 +151,17 @@ func (b *ParameterBuilder) Default(value interface{}) *ParameterBuilder {
 	return b
 }
 
+// Sensitive marks the parameter as sensitive, masking its value in audit records and logs
+func (b *ParameterBuilder) Sensitive() *ParameterBuilder {
+	return b.Redact(protocol.RedactMask)
+}
+
+// Redact sets how the parameter's value appears in audit records and logs
+func (b *ParameterBuilder) Redact(mode protocol.RedactionMode) *ParameterBuilder {
+	b.property.Redaction = mode
+	return b
+}
+
 // Add adds the parameter to the tool and returns the tool builder
 func (b *ParameterBuilder) Add() *ToolBuilder {
 	b.tool.tool.InputSchema.Properties[b.name] = b.property
@@ -44,6 +44,17 @@ func TestWithDescription(t *testing.T) {
 	}
 }
 
+func TestWithAnnotations(t *testing.T) {
+	readOnly := true
+	tool := NewTool("test-tool").
+		WithAnnotations(protocol.ToolAnnotations{Title: "Test", ReadOnlyHint: &readOnly}).
+		Build()
+
+	if tool.Annotations == nil || tool.Annotations.Title != "Test" || !*tool.Annotations.ReadOnlyHint {
+		t.Errorf("Expected annotations to be set, got %+v", tool.Annotations)
+	}
+}
+
 func TestWithInputs(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -279,6 +290,31 @@ func TestParameterBuilder_Default(t *testing.T) {
 	}
 }
 
+func TestParameterBuilder_Sensitive(t *testing.T) {
+	tool := NewTool("test-tool").
+		WithString("api_key").
+		Sensitive().
+		Add().
+		WithString("account").
+		Redact(protocol.RedactHash).
+		Add().
+		WithString("query").
+		Add().
+		Build()
+
+	if mode := tool.InputSchema.Properties["api_key"].Redaction; mode != protocol.RedactMask {
+		t.Errorf("Expected api_key to be masked, got %q", mode)
+	}
+
+	if mode := tool.InputSchema.Properties["account"].Redaction; mode != protocol.RedactHash {
+		t.Errorf("Expected account to be hashed, got %q", mode)
+	}
+
+	if mode := tool.InputSchema.Properties["query"].Redaction; mode != protocol.RedactNone {
+		t.Errorf("Expected query not to be redacted, got %q", mode)
+	}
+}
+
 func TestComplexToolDefinition(t *testing.T) {
 	// Test a complex tool definition using both WithInputs and individual parameter methods
 	tool := NewTool("complex-tool").
@@ -0,0 +1,524 @@
+package resources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/progress"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults of CommandTool and CommandToolOptions
+const (
+	DefaultCommandTimeout   = 30 * time.Second
+	DefaultCommandMaxOutput = 1 << 20
+)
+
+// When a command's stderr is put in its tool result
+const (
+	CommandStderrOnError = "on_error"
+	CommandStderrAlways  = "always"
+	CommandStderrNever   = "never"
+)
+
+// commandWaitDelay is how long a command's output is waited for once it is killed, as processes it started may
+// still hold its pipes
+const commandWaitDelay = time.Second
+
+// commandProgressInterval is the least time between two reports of a command's output as progress
+const commandProgressInterval = 100 * time.Millisecond
+
+// sandboxEntry is whether RunSandboxIfRequested was called, so that the binary can run the sandbox wrapper
+var sandboxEntry atomic.Bool
+
+// placeholder matches the {name} placeholders of argv templates
+var placeholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_-]*)\}`)
+
+// CommandTool declares a tool running a command. The command is run directly from its argv, never through a
+// shell, so arguments are never interpreted.
+type CommandTool struct {
+	Name        string               `json:"name" yaml:"name"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	InputSchema protocol.InputSchema `json:"input_schema" yaml:"input_schema"`
+
+	// Args is the argv template of the command, starting with the program. {name} in an element is replaced by
+	// the tool argument of that name. An element whose argument is absent is left out, and an element that is
+	// just the placeholder of an array argument becomes one element per item.
+	Args []string `json:"args" yaml:"args"`
+
+	// AllowLeadingDash lets arguments start with a dash where the command would see the dash: at the start of
+	// an element, or right after the = of an option. Without it, such arguments are invalid, so that they cannot
+	// pass options to the command.
+	AllowLeadingDash bool `json:"allow_leading_dash,omitempty" yaml:"allow_leading_dash,omitempty"`
+
+	// Env lists the environment variables passed on from the server's environment. Other variables are not.
+	Env []string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// SetEnv sets environment variables of the command
+	SetEnv map[string]string `json:"set_env,omitempty" yaml:"set_env,omitempty"`
+
+	// Dir is the working directory of the command, the server's if empty
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+
+	// Timeout bounds how long the command runs, the registry's DefaultTimeout if zero
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// MaxOutput caps how many bytes of stdout, and of stderr, are kept, DefaultCommandMaxOutput if zero. The
+	// rest is discarded, which the result notes.
+	MaxOutput int64 `json:"max_output,omitempty" yaml:"max_output,omitempty"`
+
+	// SuccessExitCodes are the exit codes of a successful run, only 0 if empty. Other exit codes make error
+	// results.
+	SuccessExitCodes []int `json:"success_exit_codes,omitempty" yaml:"success_exit_codes,omitempty"`
+
+	// Stderr is when stderr is put in the result: CommandStderrOnError, the default, CommandStderrAlways or
+	// CommandStderrNever
+	Stderr string `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+
+	// Sandbox confines the command. It is only supported on Linux.
+	Sandbox *CommandSandbox `json:"sandbox,omitempty" yaml:"sandbox,omitempty"`
+}
+
+// CommandSandbox confines a command to a user and resource limits. They are set before the command is
+// executed, by the server's own binary re-executed as a wrapper, and processes the command starts inherit them.
+type CommandSandbox struct {
+	// UID and GID run the command as another user and group, which the server must be allowed to switch to
+	UID *uint32 `json:"uid,omitempty" yaml:"uid,omitempty"`
+	GID *uint32 `json:"gid,omitempty" yaml:"gid,omitempty"`
+
+	// CPUTime limits the CPU time of the command, in whole seconds
+	CPUTime time.Duration `json:"cpu_time,omitempty" yaml:"cpu_time,omitempty"`
+
+	// MaxMemory limits the address space of the command, in bytes
+	MaxMemory uint64 `json:"max_memory,omitempty" yaml:"max_memory,omitempty"`
+
+	// MaxFileSize limits the size of files the command writes, in bytes
+	MaxFileSize uint64 `json:"max_file_size,omitempty" yaml:"max_file_size,omitempty"`
+
+	// MaxOpenFiles limits the number of files the command has open
+	MaxOpenFiles uint64 `json:"max_open_files,omitempty" yaml:"max_open_files,omitempty"`
+
+	// MaxProcesses limits the number of processes of the command's user, so it is best used with a UID of
+	// the command's own
+	MaxProcesses uint64 `json:"max_processes,omitempty" yaml:"max_processes,omitempty"`
+}
+
+// CommandToolOptions configures a CommandToolRegistry
+type CommandToolOptions struct {
+	// DefaultTimeout bounds how long commands without a timeout run, DefaultCommandTimeout if zero
+	DefaultTimeout time.Duration
+}
+
+// LoadCommandTools reads command tools from a YAML or JSON file with a list of them under "tools"
+func LoadCommandTools(path string) ([]CommandTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command tools: %w", err)
+	}
+	var file struct {
+		Tools []CommandTool `yaml:"tools"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse command tools: %w", err)
+	}
+	return file.Tools, nil
+}
+
+// CommandToolRegistry serves tools that run commands, such as wrappers around command line tools. A call runs
+// the command in a process group of its own, which is killed when the call is cancelled or times out. Tools
+// with a sandbox are run through the server's own binary re-executed as a wrapper, so its main must call
+// RunSandboxIfRequested before anything else.
+type CommandToolRegistry struct {
+	opts CommandToolOptions
+
+	mu    sync.RWMutex
+	tools map[string]*CommandTool
+}
+
+// NewCommandToolRegistry creates a registry of the tools
+func NewCommandToolRegistry(opts CommandToolOptions, tools ...CommandTool) (*CommandToolRegistry, error) {
+	if opts.DefaultTimeout <= 0 {
+		opts.DefaultTimeout = DefaultCommandTimeout
+	}
+	r := &CommandToolRegistry{
+		opts:  opts,
+		tools: make(map[string]*CommandTool),
+	}
+	for _, tool := range tools {
+		if err := r.RegisterTool(tool); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// RegisterTool adds a tool, checking its declaration
+func (r *CommandToolRegistry) RegisterTool(tool CommandTool) error {
+	if tool.Name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+	if len(tool.Args) == 0 || tool.Args[0] == "" {
+		return fmt.Errorf("tool %s has no command", tool.Name)
+	}
+	if placeholder.MatchString(tool.Args[0]) {
+		return fmt.Errorf("the program of tool %s cannot be an argument", tool.Name)
+	}
+	for _, arg := range tool.Args {
+		for _, match := range placeholder.FindAllStringSubmatch(arg, -1) {
+			if _, ok := tool.InputSchema.Properties[match[1]]; !ok {
+				return fmt.Errorf("argument %s of tool %s is not in its input schema", match[1], tool.Name)
+			}
+		}
+	}
+	switch tool.Stderr {
+	case "":
+		tool.Stderr = CommandStderrOnError
+	case CommandStderrOnError, CommandStderrAlways, CommandStderrNever:
+	default:
+		return fmt.Errorf("tool %s has an invalid stderr mode %q", tool.Name, tool.Stderr)
+	}
+	if tool.Sandbox != nil && !sandboxSupported {
+		return fmt.Errorf("tool %s has a sandbox, which is only supported on Linux", tool.Name)
+	}
+	if tool.Sandbox != nil && !sandboxEntry.Load() {
+		return fmt.Errorf("tool %s has a sandbox, which needs main to call resources.RunSandboxIfRequested", tool.Name)
+	}
+
+	if tool.InputSchema.Type == "" {
+		tool.InputSchema.Type = "object"
+	}
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = make(map[string]protocol.SchemaProperty)
+	}
+	if tool.Timeout <= 0 {
+		tool.Timeout = r.opts.DefaultTimeout
+	}
+	if tool.MaxOutput <= 0 {
+		tool.MaxOutput = DefaultCommandMaxOutput
+	}
+	if len(tool.SuccessExitCodes) == 0 {
+		tool.SuccessExitCodes = []int{0}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[tool.Name]; exists {
+		return fmt.Errorf("tool with name %q already exists", tool.Name)
+	}
+	r.tools[tool.Name] = &tool
+	return nil
+}
+
+// GetTool returns a tool by name
+func (r *CommandToolRegistry) GetTool(ctx context.Context, name string) (protocol.Tool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.tools[name]
+	if !ok {
+		return protocol.Tool{}, ErrToolNotFound
+	}
+	return tool.protocolTool(), nil
+}
+
+// ListTools returns a page of tools, ordered by name
+func (r *CommandToolRegistry) ListTools(ctx context.Context, opts protocol.ToolListOptions) (protocol.ToolListResult, error) {
+	r.mu.RLock()
+	tools := make([]protocol.Tool, 0, len(r.tools))
+	for _, tool := range r.tools {
+		tools = append(tools, tool.protocolTool())
+	}
+	r.mu.RUnlock()
+
+	page, next := Paginate(tools, func(tool protocol.Tool) string { return tool.Name }, opts.Cursor, opts.PageSize)
+	return protocol.ToolListResult{Tools: page, NextCursor: next}, nil
+}
+
+// CallTool runs the command of a tool with the arguments. Runs that time out or exit with a code other than a
+// success code are error results.
+func (r *CommandToolRegistry) CallTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	r.mu.RLock()
+	tool, ok := r.tools[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrToolNotFound
+	}
+
+	argv, err := tool.argv(params)
+	if err != nil {
+		return nil, err
+	}
+	return r.run(ctx, tool, argv)
+}
+
+// protocolTool returns the tool as listed
+func (t *CommandTool) protocolTool() protocol.Tool {
+	return protocol.Tool{Name: t.Name, Description: t.Description, InputSchema: t.InputSchema}
+}
+
+// argv expands the argv template with the arguments
+func (t *CommandTool) argv(params map[string]interface{}) ([]string, error) {
+	for name := range params {
+		if _, ok := t.InputSchema.Properties[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown argument %s", ErrInvalidParams, name)
+		}
+	}
+	for _, name := range t.InputSchema.Required {
+		if params[name] == nil {
+			return nil, fmt.Errorf("%w: argument %s is required", ErrInvalidParams, name)
+		}
+	}
+
+	argv := []string{t.Args[0]}
+	for _, arg := range t.Args[1:] {
+		// An element that is a single placeholder takes the whole argument, one element per item of an array
+		if match := placeholder.FindStringSubmatch(arg); match != nil && match[0] == arg {
+			value := params[match[1]]
+			items, ok := value.([]interface{})
+			if !ok {
+				items = []interface{}{value}
+			}
+			for _, item := range items {
+				if item == nil {
+					continue
+				}
+				s := formatArgument(item)
+				if strings.HasPrefix(s, "-") && !t.AllowLeadingDash {
+					return nil, fmt.Errorf("%w: argument %s cannot start with a dash", ErrInvalidParams, match[1])
+				}
+				argv = append(argv, s)
+			}
+			continue
+		}
+
+		// Other elements take the arguments within them. An argument starting the element, or the value of an
+		// option as in --file={path}, cannot start with a dash either.
+		var expanded strings.Builder
+		missing := false
+		last := 0
+		for _, match := range placeholder.FindAllStringSubmatchIndex(arg, -1) {
+			expanded.WriteString(arg[last:match[0]])
+			last = match[1]
+			name := arg[match[2]:match[3]]
+			value := params[name]
+			if value == nil {
+				missing = true
+				break
+			}
+			s := formatArgument(value)
+			before := expanded.String()
+			if strings.HasPrefix(s, "-") && (before == "" || strings.HasSuffix(before, "=")) && !t.AllowLeadingDash {
+				return nil, fmt.Errorf("%w: argument %s cannot start with a dash", ErrInvalidParams, name)
+			}
+			expanded.WriteString(s)
+		}
+		if !missing {
+			expanded.WriteString(arg[last:])
+			argv = append(argv, expanded.String())
+		}
+	}
+	return argv, nil
+}
+
+// formatArgument formats an argument as an element of argv. Arrays are joined by commas, and objects are JSON.
+func formatArgument(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatArgument(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// environ returns the environment of the command: the allowed variables of the server's environment and the
+// ones the tool sets
+func (t *CommandTool) environ() []string {
+	env := make([]string, 0, len(t.Env)+len(t.SetEnv))
+	for _, name := range t.Env {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	names := make([]string, 0, len(t.SetEnv))
+	for name := range t.SetEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+t.SetEnv[name])
+	}
+	return env
+}
+
+// run runs a command and maps its outcome to a tool result
+func (r *CommandToolRegistry) run(ctx context.Context, tool *CommandTool, argv []string) (interface{}, error) {
+	runCtx, cancel := context.WithTimeout(ctx, tool.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, argv[0], argv[1:]...)
+	cmd.Dir = tool.Dir
+	cmd.Env = tool.environ()
+	cmd.WaitDelay = commandWaitDelay
+	setProcessGroup(cmd)
+	var confined func() error
+	if tool.Sandbox != nil {
+		var err error
+		if confined, err = sandbox(cmd, tool.Sandbox); err != nil {
+			return nil, fmt.Errorf("failed to sandbox tool %s: %w", tool.Name, err)
+		}
+	}
+
+	stdout := &cappedBuffer{max: tool.MaxOutput}
+	stderr := &cappedBuffer{max: tool.MaxOutput}
+	cmd.Stderr = stderr
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run tool %s: %w", tool.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		if confined != nil {
+			_ = confined()
+		}
+		return nil, fmt.Errorf("failed to run tool %s: %w", tool.Name, err)
+	}
+	if confined != nil {
+		if err := confined(); err != nil {
+			_ = cmd.Wait()
+			return nil, fmt.Errorf("failed to sandbox tool %s: %w", tool.Name, err)
+		}
+	}
+
+	// Read stdout as it comes, reporting the lines read as progress of the call at most every
+	// commandProgressInterval. Reports carry the last line until the output is capped, and only count lines then.
+	reader := bufio.NewReader(pipe)
+	var reported time.Time
+	for n := 1; ; n++ {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			_, _ = stdout.Write(chunk)
+			if time.Since(reported) >= commandProgressInterval {
+				message := ""
+				if !stdout.Truncated() {
+					message = strings.TrimRight(string(chunk), "\r\n")
+				}
+				progress.Report(ctx, float64(n), 0, message)
+				reported = time.Now()
+			}
+		}
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			break
+		}
+	}
+	err = cmd.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if runCtx.Err() != nil {
+		return commandResult(tool, stdout, stderr, fmt.Sprintf("command timed out after %s", tool.Timeout)), nil
+	}
+
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to run tool %s: %w", tool.Name, err)
+	}
+	if !slices.Contains(tool.SuccessExitCodes, code) {
+		return commandResult(tool, stdout, stderr, fmt.Sprintf("command exited with code %d", code)), nil
+	}
+	return commandResult(tool, stdout, stderr, ""), nil
+}
+
+// commandResult makes the result of a run from its output, an error result if it failed for the reason
+func commandResult(tool *CommandTool, stdout, stderr *cappedBuffer, failure string) protocol.ToolCallResult {
+	var items []protocol.ToolCallContent
+	if stdout.Len() > 0 {
+		items = append(items, protocol.NewTextContent(stdout.String()))
+	}
+	if stderr.Len() > 0 && (tool.Stderr == CommandStderrAlways || (tool.Stderr == CommandStderrOnError && failure != "")) {
+		items = append(items, protocol.NewTextContent("stderr:\n"+stderr.String()))
+	}
+	if failure != "" {
+		items = append(items, protocol.NewTextContent(failure))
+	}
+	return protocol.NewToolCallResult(items, failure != "")
+}
+
+// cappedBuffer keeps the first bytes written to it, counting the rest
+type cappedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	max       int64
+	discarded int64
+}
+
+// Write keeps what fits of p
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	if room := b.max - int64(b.buf.Len()); int64(len(p)) > room {
+		b.discarded += int64(len(p)) - room
+		p = p[:room]
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+// Truncated reports whether bytes were discarded
+func (b *cappedBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.discarded > 0
+}
+
+// Len returns how many bytes were written
+func (b *cappedBuffer) Len() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int64(b.buf.Len()) + b.discarded
+}
+
+// String returns the bytes kept, as valid UTF-8, noting how many were discarded
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := strings.ToValidUTF8(b.buf.String(), "�")
+	if b.discarded > 0 {
+		s += fmt.Sprintf("\n[%d more bytes discarded]", b.discarded)
+	}
+	return s
+}
+
+// Ensure CommandToolRegistry implements ToolRegistry
+var _ ToolRegistry = (*CommandToolRegistry)(nil)
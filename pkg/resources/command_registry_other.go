@@ -0,0 +1,9 @@
+//go:build !unix
+
+package resources
+
+import "os/exec"
+
+// setProcessGroup leaves a command as it is, as process groups are only supported on Unix. A cancelled command
+// is killed, but not processes it started.
+func setProcessGroup(cmd *exec.Cmd) {}
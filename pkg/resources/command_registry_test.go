@@ -0,0 +1,260 @@
+//go:build unix
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/progress"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// texts returns the text items of a tool result
+func texts(t *testing.T, result interface{}) ([]string, bool) {
+	callResult, ok := result.(protocol.ToolCallResult)
+	require.True(t, ok)
+	var items []string
+	for _, item := range callResult.Content {
+		items = append(items, item.(protocol.TextContent).Text)
+	}
+	return items, callResult.IsError
+}
+
+func echoTool() CommandTool {
+	return CommandTool{
+		Name: "echo",
+		InputSchema: protocol.InputSchema{
+			Properties: map[string]protocol.SchemaProperty{
+				"message": {Type: "string"},
+				"count":   {Type: "integer"},
+				"files":   {Type: "array"},
+			},
+			Required: []string{"message"},
+		},
+		Args: []string{"echo", "-n", "{message}", "--count={count}", "{files}"},
+	}
+}
+
+func TestCommandToolRegistry_Args(t *testing.T) {
+	ctx := context.Background()
+	registry, err := NewCommandToolRegistry(CommandToolOptions{}, echoTool())
+	require.NoError(t, err)
+
+	// Arguments are passed as they are, never through a shell, and absent ones are left out
+	result, err := registry.CallTool(ctx, "echo", map[string]interface{}{"message": "$HOME; rm -rf /"})
+	require.NoError(t, err)
+	items, isError := texts(t, result)
+	assert.False(t, isError)
+	assert.Equal(t, []string{"$HOME; rm -rf /"}, items)
+
+	// Arrays are an element per item where they fill an element
+	result, err = registry.CallTool(ctx, "echo", map[string]interface{}{
+		"message": "hi",
+		"count":   float64(2),
+		"files":   []interface{}{"a.txt", "b.txt"},
+	})
+	require.NoError(t, err)
+	items, _ = texts(t, result)
+	assert.Equal(t, []string{"hi --count=2 a.txt b.txt"}, items)
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := registry.CallTool(ctx, "echo", map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrInvalidParams)
+		_, err = registry.CallTool(ctx, "echo", map[string]interface{}{"message": "hi", "other": "x"})
+		assert.ErrorIs(t, err, ErrInvalidParams)
+		_, err = registry.CallTool(ctx, "echo", map[string]interface{}{"message": "--help"})
+		assert.ErrorIs(t, err, ErrInvalidParams)
+		_, err = registry.CallTool(ctx, "echo", map[string]interface{}{"message": "hi", "count": "-rf"})
+		assert.ErrorIs(t, err, ErrInvalidParams)
+		_, err = registry.CallTool(ctx, "missing", nil)
+		assert.ErrorIs(t, err, ErrToolNotFound)
+	})
+
+	t.Run("invalid declarations", func(t *testing.T) {
+		for _, tool := range []CommandTool{
+			{Name: "none"},
+			{Name: "program", Args: []string{"{message}"}, InputSchema: echoTool().InputSchema},
+			{Name: "unknown", Args: []string{"echo", "{other}"}},
+			{Name: "stderr", Args: []string{"echo"}, Stderr: "sometimes"},
+			echoTool(),
+		} {
+			assert.Error(t, registry.RegisterTool(tool), tool.Name)
+		}
+	})
+}
+
+func TestCommandToolRegistry_Results(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	t.Setenv("COMMAND_TEST_ALLOWED", "allowed")
+	t.Setenv("COMMAND_TEST_SECRET", "secret")
+
+	registry, err := NewCommandToolRegistry(CommandToolOptions{},
+		CommandTool{
+			Name:   "env",
+			Args:   []string{"sh", "-c", "pwd; echo $COMMAND_TEST_ALLOWED $COMMAND_TEST_SECRET $FIXED"},
+			Env:    []string{"COMMAND_TEST_ALLOWED"},
+			SetEnv: map[string]string{"FIXED": "fixed"},
+			Dir:    dir,
+		},
+		CommandTool{
+			Name: "fail",
+			Args: []string{"sh", "-c", "echo out; echo err >&2; exit 3"},
+		},
+		CommandTool{
+			Name:             "grep",
+			Args:             []string{"sh", "-c", "echo err >&2; exit 1"},
+			SuccessExitCodes: []int{0, 1},
+		},
+		CommandTool{
+			Name:      "loud",
+			Args:      []string{"sh", "-c", "printf 0123456789; printf abc >&2"},
+			MaxOutput: 4,
+			Stderr:    CommandStderrAlways,
+		},
+	)
+	require.NoError(t, err)
+
+	// Only allowed variables are passed on
+	result, err := registry.CallTool(ctx, "env", nil)
+	require.NoError(t, err)
+	items, _ := texts(t, result)
+	resolved, _ := filepath.EvalSymlinks(dir)
+	assert.Equal(t, []string{resolved + "\nallowed fixed\n"}, items)
+
+	// Other exit codes are error results, with stderr
+	result, err = registry.CallTool(ctx, "fail", nil)
+	require.NoError(t, err)
+	items, isError := texts(t, result)
+	assert.True(t, isError)
+	assert.Equal(t, []string{"out\n", "stderr:\nerr\n", "command exited with code 3"}, items)
+
+	result, err = registry.CallTool(ctx, "grep", nil)
+	require.NoError(t, err)
+	items, isError = texts(t, result)
+	assert.False(t, isError)
+	assert.Empty(t, items)
+
+	// Output is capped
+	result, err = registry.CallTool(ctx, "loud", nil)
+	require.NoError(t, err)
+	items, _ = texts(t, result)
+	assert.Equal(t, []string{"0123\n[6 more bytes discarded]", "stderr:\nabc"}, items)
+}
+
+func TestCommandToolRegistry_Progress(t *testing.T) {
+	registry, err := NewCommandToolRegistry(CommandToolOptions{},
+		CommandTool{Name: "count", Args: []string{"sh", "-c", "echo one; echo two; sleep 0.3; printf three"}},
+		CommandTool{Name: "loud", Args: []string{"sh", "-c", "echo 0123456789; sleep 0.3; echo more"}, MaxOutput: 12},
+	)
+	require.NoError(t, err)
+
+	// call calls a tool as a request asking for progress, returning the progress reported
+	call := func(name string) []protocol.ProgressNotificationParams {
+		var (
+			mu      sync.Mutex
+			reports []protocol.ProgressNotificationParams
+		)
+		ctx := progress.WithReporter(context.Background(), json.RawMessage(`"call-1"`), func(params protocol.ProgressNotificationParams) {
+			mu.Lock()
+			defer mu.Unlock()
+			reports = append(reports, params)
+		})
+		_, err := registry.CallTool(ctx, name, nil)
+		require.NoError(t, err)
+		return reports
+	}
+
+	// Lines written together are reported together
+	reports := call("count")
+	require.Len(t, reports, 2)
+	assert.Equal(t, `"call-1"`, string(reports[0].ProgressToken))
+	assert.Equal(t, protocol.ProgressNotificationParams{ProgressToken: reports[0].ProgressToken, Progress: 1, Message: "one"}, reports[0])
+	assert.Equal(t, protocol.ProgressNotificationParams{ProgressToken: reports[0].ProgressToken, Progress: 3, Message: "three"}, reports[1])
+
+	// Output past the cap is only counted
+	reports = call("loud")
+	require.Len(t, reports, 2)
+	assert.Equal(t, "0123456789", reports[0].Message)
+	assert.Equal(t, float64(2), reports[1].Progress)
+	assert.Empty(t, reports[1].Message)
+}
+
+func TestCommandToolRegistry_Cancel(t *testing.T) {
+	// The command starts a process of its own, which must be killed with it
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	registry, err := NewCommandToolRegistry(CommandToolOptions{},
+		CommandTool{
+			Name:    "slow",
+			Args:    []string{"sh", "-c", "sleep 30 & echo $! > " + pidFile + "; wait"},
+			Timeout: 500 * time.Millisecond,
+		},
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	result, err := registry.CallTool(context.Background(), "slow", nil)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	items, isError := texts(t, result)
+	assert.True(t, isError)
+	assert.Equal(t, []string{"command timed out after 500ms"}, items)
+
+	data, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		// Killed processes are gone, or zombies until they are reaped
+		stat, err := os.ReadFile("/proc/" + strings.TrimSpace(string(data)) + "/stat")
+		return os.IsNotExist(err) || strings.Contains(string(stat), ") Z ")
+	}, 2*time.Second, 50*time.Millisecond)
+
+	// Cancelling the call is an error
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = registry.CallTool(ctx, "slow", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLoadCommandTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+tools:
+  - name: list
+    description: Lists a directory
+    input_schema:
+      properties:
+        path: {type: string, description: The directory}
+      required: [path]
+    args: [ls, "{path}"]
+    timeout: 5s
+    env: [PATH]
+`), 0o600))
+
+	tools, err := LoadCommandTools(path)
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, 5*time.Second, tools[0].Timeout)
+	assert.Equal(t, []string{"ls", "{path}"}, tools[0].Args)
+
+	registry, err := NewCommandToolRegistry(CommandToolOptions{}, tools...)
+	require.NoError(t, err)
+	tool, err := registry.GetTool(context.Background(), "list")
+	require.NoError(t, err)
+	assert.Equal(t, "Lists a directory", tool.Description)
+	assert.Equal(t, "object", tool.InputSchema.Type)
+	assert.Equal(t, protocol.SchemaProperty{Type: "string", Description: "The directory"}, tool.InputSchema.Properties["path"])
+	assert.Equal(t, []string{"path"}, tool.InputSchema.Required)
+
+	list, err := registry.ListTools(context.Background(), protocol.ToolListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, list.Tools, 1)
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package resources
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup runs a command in a process group of its own, which is killed when the command is cancelled,
+// along with any process the command started
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,157 @@
+package resources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxSupported is whether commands can be sandboxed on this platform
+const sandboxSupported = true
+
+// sandboxArg0 is the argv[0] the server re-executes itself with to run a sandboxed command
+const sandboxArg0 = "scaled-mcp-sandbox"
+
+// sandboxSpec is what the sandbox wrapper confines a command to
+type sandboxSpec struct {
+	Parent   int            `json:"parent"`
+	ReportFD int            `json:"report_fd"`
+	UID      *uint32        `json:"uid,omitempty"`
+	GID      *uint32        `json:"gid,omitempty"`
+	Limits   []sandboxLimit `json:"limits,omitempty"`
+}
+
+type sandboxLimit struct {
+	Resource int    `json:"resource"`
+	Value    uint64 `json:"value"`
+}
+
+// RunSandboxIfRequested runs the sandbox wrapper if the process was started as one, and never returns then.
+// Otherwise it returns at once. Sandboxed commands are started through the server's own binary, which sets
+// the limits, user and group of the command and executes it, so the command never runs unconfined. Call it
+// first thing in main: tools with a sandbox cannot be registered until it was called.
+func RunSandboxIfRequested() {
+	sandboxEntry.Store(true)
+	if len(os.Args) < 4 || os.Args[0] != sandboxArg0 {
+		return
+	}
+	runSandboxed(os.Args[1], os.Args[2], os.Args[3:])
+}
+
+// sandbox has cmd run through the sandbox wrapper. It returns a function to call once cmd was started, which
+// waits until the wrapper has executed the command and returns why it could not if it failed to.
+func sandbox(cmd *exec.Cmd, sandbox *CommandSandbox) (func() error, error) {
+	if cmd.Err != nil {
+		// Starting the command fails anyway
+		return nil, nil
+	}
+
+	report, reportWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, reportWriter)
+
+	spec := sandboxSpec{
+		Parent:   os.Getpid(),
+		ReportFD: 2 + len(cmd.ExtraFiles),
+		UID:      sandbox.UID,
+		GID:      sandbox.GID,
+	}
+	limits := []sandboxLimit{
+		{unix.RLIMIT_CPU, uint64((sandbox.CPUTime + time.Second - 1) / time.Second)},
+		{unix.RLIMIT_AS, sandbox.MaxMemory},
+		{unix.RLIMIT_FSIZE, sandbox.MaxFileSize},
+		{unix.RLIMIT_NOFILE, sandbox.MaxOpenFiles},
+		{unix.RLIMIT_NPROC, sandbox.MaxProcesses},
+	}
+	for _, l := range limits {
+		if l.Value != 0 {
+			spec.Limits = append(spec.Limits, l)
+		}
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		_ = report.Close()
+		_ = reportWriter.Close()
+		return nil, err
+	}
+
+	cmd.Args = append([]string{sandboxArg0, string(data), cmd.Path}, cmd.Args...)
+	cmd.Path = "/proc/self/exe"
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGKILL
+
+	return func() error {
+		_ = reportWriter.Close()
+		defer report.Close()
+		msg, err := io.ReadAll(report)
+		if err != nil {
+			return err
+		}
+		if len(msg) > 0 {
+			return errors.New(string(msg))
+		}
+		return nil
+	}, nil
+}
+
+// runSandboxed confines the wrapper's process as the spec says and executes the command in it. Failures are
+// written to the report pipe, which closes without a word once the command is executed.
+func runSandboxed(specJSON, path string, argv []string) {
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: invalid spec: %v\n", err)
+		os.Exit(126)
+	}
+	report := os.NewFile(uintptr(spec.ReportFD), "sandbox-report")
+	fail := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(report, format, args...)
+		os.Exit(126)
+	}
+	syscall.CloseOnExec(spec.ReportFD)
+
+	runtime.LockOSThread()
+	for _, l := range spec.Limits {
+		if err := syscall.Setrlimit(l.Resource, &syscall.Rlimit{Cur: l.Value, Max: l.Value}); err != nil {
+			fail("failed to set resource limit %d: %v", l.Resource, err)
+		}
+	}
+	if spec.UID != nil || spec.GID != nil {
+		// The command does not keep the supplementary groups of the server, such as root's
+		if err := syscall.Setgroups([]int{}); err != nil {
+			fail("failed to drop supplementary groups: %v", err)
+		}
+	}
+	if spec.GID != nil {
+		if err := syscall.Setgid(int(*spec.GID)); err != nil {
+			fail("failed to set group: %v", err)
+		}
+	}
+	if spec.UID != nil {
+		if err := syscall.Setuid(int(*spec.UID)); err != nil {
+			fail("failed to set user: %v", err)
+		}
+	}
+
+	// Changing credentials clears the parent death signal, and the server may have died before it was set
+	if err := unix.Prctl(unix.PR_SET_PDEATHSIG, uintptr(syscall.SIGKILL), 0, 0, 0); err != nil {
+		fail("failed to set parent death signal: %v", err)
+	}
+	if os.Getppid() != spec.Parent {
+		os.Exit(126)
+	}
+
+	err := syscall.Exec(path, argv, os.Environ())
+	fail("failed to execute %s: %v", path, err)
+}
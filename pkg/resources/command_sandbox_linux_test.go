@@ -0,0 +1,89 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	RunSandboxIfRequested()
+	os.Exit(m.Run())
+}
+
+func TestCommandToolRegistry_Sandbox(t *testing.T) {
+	ctx := context.Background()
+	registry, err := NewCommandToolRegistry(CommandToolOptions{}, CommandTool{
+		Name:    "limits",
+		Args:    []string{"sh", "-c", "ulimit -n; ulimit -t"},
+		Env:     []string{"PATH"},
+		Sandbox: &CommandSandbox{MaxOpenFiles: 17, CPUTime: 7 * time.Second},
+	})
+	require.NoError(t, err)
+
+	result, err := registry.CallTool(ctx, "limits", nil)
+	require.NoError(t, err)
+	items, isError := texts(t, result)
+	require.False(t, isError, items)
+	assert.Equal(t, []string{"17\n7\n"}, items)
+
+	t.Run("entry point", func(t *testing.T) {
+		// Without RunSandboxIfRequested the binary cannot run the wrapper
+		sandboxEntry.Store(false)
+		defer sandboxEntry.Store(true)
+		err := registry.RegisterTool(CommandTool{Name: "unwrapped", Args: []string{"true"}, Sandbox: &CommandSandbox{}})
+		assert.ErrorContains(t, err, "RunSandboxIfRequested")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		// The wrapper reports why it could not execute the command
+		program := filepath.Join(t.TempDir(), "not-executable")
+		require.NoError(t, os.WriteFile(program, []byte("#!/bin/sh\n"), 0o644))
+		require.NoError(t, registry.RegisterTool(CommandTool{
+			Name:    "broken",
+			Args:    []string{program},
+			Sandbox: &CommandSandbox{MaxOpenFiles: 17},
+		}))
+		_, err := registry.CallTool(ctx, "broken", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to sandbox tool broken: failed to execute "+program)
+	})
+
+	t.Run("user", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("running commands as another user needs root")
+		}
+		// Give the server supplementary groups, which the command must not keep
+		groups, err := os.Getgroups()
+		require.NoError(t, err)
+		require.NoError(t, syscall.Setgroups([]int{0, 4}))
+		t.Cleanup(func() { _ = syscall.Setgroups(groups) })
+		serverGroups, err := os.Getgroups()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int{0, 4}, serverGroups)
+
+		nobody := uint32(65534)
+		require.NoError(t, registry.RegisterTool(CommandTool{
+			Name:    "id",
+			Args:    []string{"sh", "-c", "id -u; id -g; grep ^Groups: /proc/self/status"},
+			Env:     []string{"PATH"},
+			Sandbox: &CommandSandbox{UID: &nobody, GID: &nobody},
+		}))
+		result, err := registry.CallTool(ctx, "id", nil)
+		require.NoError(t, err)
+		items, isError := texts(t, result)
+		require.False(t, isError, items)
+		require.Len(t, items, 1)
+		lines := strings.Split(strings.TrimSpace(items[0]), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, []string{"65534", "65534"}, lines[:2])
+		assert.Empty(t, strings.TrimSpace(strings.TrimPrefix(lines[2], "Groups:")), "supplementary groups")
+	})
+}
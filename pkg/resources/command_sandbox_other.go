@@ -0,0 +1,16 @@
+//go:build !linux
+
+package resources
+
+import "os/exec"
+
+// sandboxSupported is whether commands can be sandboxed on this platform
+const sandboxSupported = false
+
+// RunSandboxIfRequested returns at once, as commands cannot be sandboxed on this platform
+func RunSandboxIfRequested() {}
+
+// sandbox does nothing, as tools with a sandbox cannot be registered on this platform
+func sandbox(cmd *exec.Cmd, sandbox *CommandSandbox) (func() error, error) {
+	return nil, nil
+}
@@ -0,0 +1,59 @@
+package resources
+
+import (
+	"context"
+)
+
+// CompletionProvider suggests values for a prompt argument or resource template variable as the client types
+// it. value is what has been typed so far and arguments holds the values of the other arguments, which may be
+// nil. It returns the candidates, and whether there are more than it returned.
+type CompletionProvider func(ctx context.Context, value string, arguments map[string]string) ([]string, bool, error)
+
+// FindResourceTemplate looks a resource template up by its URI template, going through every page of templates
+func FindResourceTemplate(ctx context.Context, registry ResourceRegistry, uriTemplate string) (ResourceTemplate, bool) {
+	found, ok := ResourceTemplate{}, false
+	eachResourceTemplate(ctx, registry, func(template ResourceTemplate) bool {
+		if template.URITemplate == uriTemplate {
+			found, ok = template, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// CompletionsVersion returns a version of the prompt and resource registries, which changes whenever those of
+// them that are Versioned change, so HasCompletions only needs to run again then
+func CompletionsVersion(registry FeatureRegistry) uint64 {
+	var version uint64
+	if v, ok := registry.PromptRegistry.(Versioned); ok {
+		version += v.Version()
+	}
+	if v, ok := registry.ResourceRegistry.(Versioned); ok {
+		version += v.Version()
+	}
+	return version
+}
+
+// HasCompletions reports whether any prompt argument or resource template variable of the registries has a
+// completion provider, going through every page of prompts and templates
+func HasCompletions(ctx context.Context, registry FeatureRegistry) bool {
+	has := false
+	if registry.PromptRegistry != nil {
+		eachPrompt(ctx, registry.PromptRegistry, func(prompt Prompt) bool {
+			for _, arg := range prompt.Arguments {
+				if arg.Complete != nil {
+					has = true
+				}
+			}
+			return !has
+		})
+	}
+	if !has && registry.ResourceRegistry != nil {
+		eachResourceTemplate(ctx, registry.ResourceRegistry, func(template ResourceTemplate) bool {
+			has = len(template.Completions) > 0
+			return !has
+		})
+	}
+	return has
+}
@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasCompletions(t *testing.T) {
+	ctx := context.Background()
+	none := func(ctx context.Context, value string, arguments map[string]string) ([]string, bool, error) {
+		return nil, false, nil
+	}
+
+	prompts := NewStaticPromptRegistry()
+	templates := NewStaticResourceRegistry()
+	registry := FeatureRegistry{PromptRegistry: prompts, ResourceRegistry: templates}
+
+	// Enough templates for several pages
+	for i := 0; i < 45; i++ {
+		require.NoError(t, templates.RegisterResourceTemplate(NewResourceTemplate(fmt.Sprintf("doc://%02d/{id}", i), "Doc").Build()))
+	}
+	require.NoError(t, prompts.RegisterPrompt(NewPrompt("plain").WithArgument("topic").Add().Build()))
+	assert.False(t, HasCompletions(ctx, registry))
+	assert.False(t, HasCompletions(ctx, FeatureRegistry{}))
+
+	require.NoError(t, templates.RegisterResourceTemplate(NewResourceTemplate("doc://99/{id}", "Doc").WithCompletion("id", none).Build()))
+	assert.True(t, HasCompletions(ctx, registry), "templates on later pages are found")
+
+	template, ok := FindResourceTemplate(ctx, templates, "doc://99/{id}")
+	require.True(t, ok)
+	assert.Contains(t, template.Completions, "id")
+	_, ok = FindResourceTemplate(ctx, templates, "doc://missing/{id}")
+	assert.False(t, ok)
+
+	withPrompt := FeatureRegistry{PromptRegistry: NewStaticPromptRegistry()}
+	require.NoError(t, withPrompt.PromptRegistry.(*StaticPromptRegistry).RegisterPrompt(
+		NewPrompt("review").WithArgument("language").Complete(none).Add().Build()))
+	assert.True(t, HasCompletions(ctx, withPrompt))
+}
+
+func TestCompletionsVersion(t *testing.T) {
+	prompts := NewStaticPromptRegistry()
+	composite := NewCompositeResourceRegistry(CompositeOptions{})
+	registry := FeatureRegistry{PromptRegistry: prompts, ResourceRegistry: composite}
+	assert.Zero(t, CompletionsVersion(registry))
+
+	// Registrations change the version, in composite children too
+	seen := map[uint64]bool{0: true}
+	changed := func() {
+		t.Helper()
+		version := CompletionsVersion(registry)
+		assert.False(t, seen[version], "version %d was seen before", version)
+		seen[version] = true
+	}
+	require.NoError(t, prompts.RegisterPrompt(NewPrompt("plain").Build()))
+	changed()
+	templates := NewStaticResourceRegistry()
+	require.NoError(t, composite.AddRegistry(templates, CompositeChildOptions{Namespace: "docs"}))
+	changed()
+	require.NoError(t, templates.RegisterResourceTemplate(NewResourceTemplate("doc://{id}", "Doc").Build()))
+	changed()
+	assert.Equal(t, CompletionsVersion(registry), CompletionsVersion(registry))
+}
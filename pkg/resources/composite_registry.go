@@ -0,0 +1,577 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// CollisionPolicy decides what a composite registry does when children expose items under the same name
+type CollisionPolicy int
+
+const (
+	// CollisionError rejects a child whose items collide with those of the other children when it is added.
+	// Collisions that appear later fail tool listings and lookups. Prompts and resources cannot fail to list, so
+	// their collisions are logged and the first child's item is served.
+	CollisionError CollisionPolicy = iota
+
+	// CollisionFirstWins serves the item of the child added first, hiding the others
+	CollisionFirstWins
+
+	// CollisionPrefix serves the item of the child added first under its name, and those of the other
+	// children under the name prefixed with their child's namespace
+	CollisionPrefix
+)
+
+// ErrNameCollision is returned when children of a composite registry expose items under the same name
+var ErrNameCollision = errors.New("name collision")
+
+// CompositeOptions configures a composite registry
+type CompositeOptions struct {
+	// Collision decides which item is served when children expose items under the same name
+	Collision CollisionPolicy
+
+	// Separator joins a namespace to a name. It defaults to "_" for tools and prompts, and to "+" for resources,
+	// whose prefixed URIs then read as a URI scheme such as "docs+file:///readme.md".
+	Separator string
+
+	// Logger receives the registry's warnings, such as colliding names or a child it stopped watching. Pass the
+	// server's logger, the one given to server.WithLogger; slog.Default() is used if nil.
+	Logger *slog.Logger
+}
+
+// CompositeChildOptions configures a child of a composite registry
+type CompositeChildOptions struct {
+	// Namespace names the child. Namespaces are unique, and one is required to prefix the child's items.
+	Namespace string
+
+	// Prefix exposes every item of the child under its name prefixed with the namespace
+	Prefix bool
+}
+
+type compositeChild[R any] struct {
+	registry  R
+	namespace string
+	prefix    bool
+}
+
+// composite holds the children of a composite registry, in the order they were added
+type composite[R any] struct {
+	mu        sync.RWMutex
+	opts      CompositeOptions
+	children  []compositeChild[R]
+	separator string
+}
+
+func newComposite[R any](opts CompositeOptions, separator string) composite[R] {
+	if opts.Separator != "" {
+		separator = opts.Separator
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return composite[R]{opts: opts, separator: separator}
+}
+
+// addChild adds a child after checking its options and, under CollisionError, that its names do not collide
+// with those of the current children
+func (c *composite[R]) addChild(registry R, opts CompositeChildOptions, names func(R) ([]string, error)) error {
+	if opts.Prefix && opts.Namespace == "" {
+		return fmt.Errorf("a namespace is required to prefix the child's names")
+	}
+
+	child := compositeChild[R]{registry: registry, namespace: opts.Namespace, prefix: opts.Prefix}
+
+	if c.opts.Collision == CollisionError {
+		taken := make(map[string]bool)
+		for _, existing := range c.snapshot() {
+			childNames, err := names(existing.registry)
+			if err != nil {
+				return err
+			}
+			for _, name := range childNames {
+				taken[c.exposed(existing, name)] = true
+			}
+		}
+		childNames, err := names(registry)
+		if err != nil {
+			return err
+		}
+		for _, name := range childNames {
+			if exposed := c.exposed(child, name); taken[exposed] {
+				return fmt.Errorf("%w: %q", ErrNameCollision, exposed)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, existing := range c.children {
+		if opts.Namespace != "" && existing.namespace == opts.Namespace {
+			return fmt.Errorf("namespace %q is already used", opts.Namespace)
+		}
+	}
+	c.children = append(c.children, child)
+	return nil
+}
+
+// Version changes whenever a child is added or a Versioned child changes
+func (c *composite[R]) Version() uint64 {
+	children := c.snapshot()
+	version := uint64(len(children))
+	for _, child := range children {
+		if v, ok := any(child.registry).(Versioned); ok {
+			version += v.Version()
+		}
+	}
+	return version
+}
+
+func (c *composite[R]) snapshot() []compositeChild[R] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]compositeChild[R](nil), c.children...)
+}
+
+// exposed returns the name an item of the child is exposed under, before collisions are resolved
+func (c *composite[R]) exposed(child compositeChild[R], name string) string {
+	if child.prefix {
+		return c.prefixed(child, name)
+	}
+	return name
+}
+
+func (c *composite[R]) prefixed(child compositeChild[R], name string) string {
+	return child.namespace + c.separator + name
+}
+
+// route is a child that may serve an exposed name, and the name of the item in the child
+type route[R any] struct {
+	child compositeChild[R]
+	name  string
+}
+
+// routes returns the children that may serve an exposed name, in order
+func (c *composite[R]) routes(exposed string) []route[R] {
+	var routes []route[R]
+	for _, child := range c.snapshot() {
+		name, isPrefixed := strings.CutPrefix(exposed, child.namespace+c.separator)
+		isPrefixed = isPrefixed && child.namespace != ""
+		switch {
+		case child.prefix:
+			if isPrefixed {
+				routes = append(routes, route[R]{child: child, name: name})
+			}
+		default:
+			routes = append(routes, route[R]{child: child, name: exposed})
+			if isPrefixed && c.opts.Collision == CollisionPrefix {
+				routes = append(routes, route[R]{child: child, name: name})
+			}
+		}
+	}
+	return routes
+}
+
+// merge lists the items of every child under their exposed names, resolving collisions by the policy. Under
+// CollisionError, the collisions are returned along with the items, the first child's item being kept.
+func merge[R, T any](c *composite[R], items func(R) ([]T, error), name func(T) string, rename func(T, string) T) ([]T, error) {
+	var (
+		merged     []T
+		taken      = make(map[string]bool)
+		collisions []error
+	)
+	for _, child := range c.snapshot() {
+		childItems, err := items(child.registry)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range childItems {
+			exposed := c.exposed(child, name(item))
+			if taken[exposed] {
+				switch c.opts.Collision {
+				case CollisionError:
+					collisions = append(collisions, fmt.Errorf("%w: %q", ErrNameCollision, exposed))
+					continue
+				case CollisionFirstWins:
+					continue
+				case CollisionPrefix:
+					if child.prefix || child.namespace == "" {
+						continue
+					}
+					exposed = c.prefixed(child, name(item))
+					if taken[exposed] {
+						continue
+					}
+				}
+			}
+			taken[exposed] = true
+			merged = append(merged, rename(item, exposed))
+		}
+	}
+	return merged, errors.Join(collisions...)
+}
+
+// watch runs the watchers of the children until ctx is done, passing their notifications on
+func (c *composite[R]) watch(ctx context.Context, notify Notifier, rewrite func(compositeChild[R], string, interface{}) interface{}) error {
+	var wg sync.WaitGroup
+	for _, child := range c.snapshot() {
+		watcher, ok := any(child.registry).(Watcher)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := watcher.Watch(ctx, func(ctx context.Context, method string, params interface{}) {
+				if rewrite != nil {
+					params = rewrite(child, method, params)
+				}
+				notify(ctx, method, params)
+			})
+			if err != nil {
+				c.opts.Logger.Warn("Stopped watching composite registry child", "namespace", child.namespace, "err", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// CompositeToolRegistry serves the tools of several tool registries as one. Tools are listed across the
+// children in name order, and calls go to the child the tool belongs to.
+type CompositeToolRegistry struct {
+	composite[ToolRegistry]
+}
+
+// NewCompositeToolRegistry creates a composite tool registry without children
+func NewCompositeToolRegistry(opts CompositeOptions) *CompositeToolRegistry {
+	return &CompositeToolRegistry{composite: newComposite[ToolRegistry](opts, "_")}
+}
+
+// AddRegistry adds a child after the current ones
+func (r *CompositeToolRegistry) AddRegistry(registry ToolRegistry, opts CompositeChildOptions) error {
+	return r.addChild(registry, opts, func(registry ToolRegistry) ([]string, error) {
+		var names []string
+		err := eachTool(context.Background(), registry, func(tool protocol.Tool) bool {
+			names = append(names, tool.Name)
+			return true
+		})
+		return names, err
+	})
+}
+
+// find returns the child serving the tool exposed as name, and the tool as the child has it
+func (r *CompositeToolRegistry) find(ctx context.Context, name string) (route[ToolRegistry], protocol.Tool, error) {
+	var (
+		found route[ToolRegistry]
+		tool  protocol.Tool
+		ok    bool
+	)
+	for _, candidate := range r.routes(name) {
+		t, err := candidate.child.registry.GetTool(ctx, candidate.name)
+		if errors.Is(err, ErrToolNotFound) {
+			continue
+		}
+		if err != nil {
+			return route[ToolRegistry]{}, protocol.Tool{}, err
+		}
+		if ok {
+			if r.opts.Collision == CollisionError {
+				return route[ToolRegistry]{}, protocol.Tool{}, fmt.Errorf("%w: %q", ErrNameCollision, name)
+			}
+			break
+		}
+		found, tool, ok = candidate, t, true
+	}
+	if !ok {
+		return route[ToolRegistry]{}, protocol.Tool{}, ErrToolNotFound
+	}
+	return found, tool, nil
+}
+
+// GetTool returns a tool by its exposed name
+func (r *CompositeToolRegistry) GetTool(ctx context.Context, name string) (protocol.Tool, error) {
+	_, tool, err := r.find(ctx, name)
+	if err != nil {
+		return protocol.Tool{}, err
+	}
+	tool.Name = name
+	return tool, nil
+}
+
+// ListTools returns a page of the tools of every child, ordered by exposed name
+func (r *CompositeToolRegistry) ListTools(ctx context.Context, opts protocol.ToolListOptions) (protocol.ToolListResult, error) {
+	tools, err := merge(&r.composite,
+		func(registry ToolRegistry) ([]protocol.Tool, error) {
+			var tools []protocol.Tool
+			err := eachTool(ctx, registry, func(tool protocol.Tool) bool {
+				tools = append(tools, tool)
+				return true
+			})
+			return tools, err
+		},
+		func(tool protocol.Tool) string { return tool.Name },
+		func(tool protocol.Tool, name string) protocol.Tool {
+			tool.Name = name
+			return tool
+		})
+	if err != nil {
+		return protocol.ToolListResult{}, err
+	}
+
+	page, next := Paginate(tools, func(tool protocol.Tool) string { return tool.Name }, opts.Cursor, opts.PageSize)
+	return protocol.ToolListResult{Tools: page, NextCursor: next}, nil
+}
+
+// CallTool calls a tool on the child it belongs to
+func (r *CompositeToolRegistry) CallTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	found, _, err := r.find(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return found.child.registry.CallTool(ctx, found.name, params)
+}
+
+// Watch runs the watchers of the children until ctx is done, passing their notifications on
+func (r *CompositeToolRegistry) Watch(ctx context.Context, notify Notifier) error {
+	return r.watch(ctx, notify, nil)
+}
+
+// CompositePromptRegistry serves the prompts of several prompt registries as one
+type CompositePromptRegistry struct {
+	composite[PromptRegistry]
+}
+
+// NewCompositePromptRegistry creates a composite prompt registry without children
+func NewCompositePromptRegistry(opts CompositeOptions) *CompositePromptRegistry {
+	return &CompositePromptRegistry{composite: newComposite[PromptRegistry](opts, "_")}
+}
+
+// AddRegistry adds a child after the current ones
+func (r *CompositePromptRegistry) AddRegistry(registry PromptRegistry, opts CompositeChildOptions) error {
+	return r.addChild(registry, opts, func(registry PromptRegistry) ([]string, error) {
+		var names []string
+		eachPrompt(context.Background(), registry, func(prompt Prompt) bool {
+			names = append(names, prompt.Name)
+			return true
+		})
+		return names, nil
+	})
+}
+
+// find returns the child serving the prompt exposed as name, and the prompt as the child has it
+func (r *CompositePromptRegistry) find(ctx context.Context, name string) (route[PromptRegistry], Prompt, bool) {
+	for _, candidate := range r.routes(name) {
+		if prompt, ok := candidate.child.registry.GetPrompt(ctx, candidate.name); ok {
+			return candidate, prompt, true
+		}
+	}
+	return route[PromptRegistry]{}, Prompt{}, false
+}
+
+// GetPrompt returns a prompt by its exposed name
+func (r *CompositePromptRegistry) GetPrompt(ctx context.Context, name string) (Prompt, bool) {
+	_, prompt, ok := r.find(ctx, name)
+	prompt.Name = name
+	return prompt, ok
+}
+
+// ListPrompts returns a page of the prompts of every child, ordered by exposed name
+func (r *CompositePromptRegistry) ListPrompts(ctx context.Context, opts PromptListOptions) PromptListResult {
+	prompts, err := merge(&r.composite,
+		func(registry PromptRegistry) ([]Prompt, error) {
+			var prompts []Prompt
+			eachPrompt(ctx, registry, func(prompt Prompt) bool {
+				prompts = append(prompts, prompt)
+				return true
+			})
+			return prompts, nil
+		},
+		func(prompt Prompt) string { return prompt.Name },
+		func(prompt Prompt, name string) Prompt {
+			prompt.Name = name
+			return prompt
+		})
+	if err != nil {
+		r.opts.Logger.Warn("Prompts of composite registry collide", "err", err)
+	}
+
+	page, next := Paginate(prompts, func(prompt Prompt) string { return prompt.Name }, opts.Cursor, opts.PageSize)
+	return PromptListResult{Prompts: page, NextCursor: next}
+}
+
+// ProcessPrompt processes a prompt on the child it belongs to
+func (r *CompositePromptRegistry) ProcessPrompt(ctx context.Context, name string, arguments map[string]string) ([]PromptMessage, error) {
+	found, _, ok := r.find(ctx, name)
+	if !ok {
+		return nil, ErrPromptNotFound
+	}
+	return found.child.registry.ProcessPrompt(ctx, found.name, arguments)
+}
+
+// Watch runs the watchers of the children until ctx is done, passing their notifications on
+func (r *CompositePromptRegistry) Watch(ctx context.Context, notify Notifier) error {
+	return r.watch(ctx, notify, nil)
+}
+
+// CompositeResourceRegistry serves the resources and resource templates of several resource registries as one.
+// Resources and templates are named by their URI and URI template.
+type CompositeResourceRegistry struct {
+	composite[ResourceRegistry]
+}
+
+// NewCompositeResourceRegistry creates a composite resource registry without children
+func NewCompositeResourceRegistry(opts CompositeOptions) *CompositeResourceRegistry {
+	return &CompositeResourceRegistry{composite: newComposite[ResourceRegistry](opts, "+")}
+}
+
+// AddRegistry adds a child after the current ones
+func (r *CompositeResourceRegistry) AddRegistry(registry ResourceRegistry, opts CompositeChildOptions) error {
+	return r.addChild(registry, opts, func(registry ResourceRegistry) ([]string, error) {
+		var names []string
+		eachResource(context.Background(), registry, func(resource Resource) bool {
+			names = append(names, resource.URI)
+			return true
+		})
+		return names, nil
+	})
+}
+
+// resources lists the resources of every child under their exposed URIs
+func (r *CompositeResourceRegistry) resources(ctx context.Context) []Resource {
+	resources, err := merge(&r.composite,
+		func(registry ResourceRegistry) ([]Resource, error) {
+			var resources []Resource
+			eachResource(ctx, registry, func(resource Resource) bool {
+				resources = append(resources, resource)
+				return true
+			})
+			return resources, nil
+		},
+		func(resource Resource) string { return resource.URI },
+		func(resource Resource, uri string) Resource {
+			resource.URI = uri
+			return resource
+		})
+	if err != nil {
+		r.opts.Logger.Warn("Resources of composite registry collide", "err", err)
+	}
+	return resources
+}
+
+// ListResources returns a page of the resources of every child, ordered by exposed URI
+func (r *CompositeResourceRegistry) ListResources(ctx context.Context, opts ResourceListOptions) ResourceListResult {
+	page, next := Paginate(r.resources(ctx), func(resource Resource) string { return resource.URI }, opts.Cursor, opts.PageSize)
+	return ResourceListResult{Resources: page, NextCursor: next}
+}
+
+// each calls f with the children that may serve a URI, in order, until it returns an error other than
+// ErrResourceNotFound
+func (r *CompositeResourceRegistry) each(uri string, f func(route[ResourceRegistry]) error) error {
+	for _, candidate := range r.routes(uri) {
+		if err := f(candidate); !errors.Is(err, ErrResourceNotFound) {
+			return err
+		}
+	}
+	return ErrResourceNotFound
+}
+
+// ReadResource reads a resource from the child it belongs to. Contents of a prefixed resource are given the URI
+// the client asked for.
+func (r *CompositeResourceRegistry) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	var contents []ResourceContents
+	err := r.each(uri, func(candidate route[ResourceRegistry]) error {
+		var err error
+		contents, err = candidate.child.registry.ReadResource(ctx, candidate.name)
+		if err != nil || candidate.name == uri {
+			return err
+		}
+		for i, content := range contents {
+			if content.GetURI() != candidate.name {
+				continue
+			}
+			if content.IsBinary() {
+				contents[i] = NewBinaryResourceContents(uri, content.GetMimeType(), content.GetBlob())
+			} else {
+				contents[i] = NewTextResourceContents(uri, content.GetMimeType(), content.GetText())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// SubscribeResource subscribes to a resource on the child it belongs to
+func (r *CompositeResourceRegistry) SubscribeResource(ctx context.Context, uri string) error {
+	return r.each(uri, func(candidate route[ResourceRegistry]) error {
+		return candidate.child.registry.SubscribeResource(ctx, candidate.name)
+	})
+}
+
+// UnsubscribeResource unsubscribes from a resource on the child it belongs to
+func (r *CompositeResourceRegistry) UnsubscribeResource(ctx context.Context, uri string) error {
+	return r.each(uri, func(candidate route[ResourceRegistry]) error {
+		return candidate.child.registry.UnsubscribeResource(ctx, candidate.name)
+	})
+}
+
+// ListResourceTemplates returns a page of the resource templates of every child, ordered by exposed URI
+// template
+func (r *CompositeResourceRegistry) ListResourceTemplates(ctx context.Context, opts ResourceTemplateListOptions) ResourceTemplateListResult {
+	templates, err := merge(&r.composite,
+		func(registry ResourceRegistry) ([]ResourceTemplate, error) {
+			var templates []ResourceTemplate
+			eachResourceTemplate(ctx, registry, func(template ResourceTemplate) bool {
+				templates = append(templates, template)
+				return true
+			})
+			return templates, nil
+		},
+		func(template ResourceTemplate) string { return template.URITemplate },
+		func(template ResourceTemplate, uriTemplate string) ResourceTemplate {
+			template.URITemplate = uriTemplate
+			return template
+		})
+	if err != nil {
+		r.opts.Logger.Warn("Resource templates of composite registry collide", "err", err)
+	}
+
+	page, next := Paginate(templates, func(template ResourceTemplate) string { return template.URITemplate }, opts.Cursor, opts.PageSize)
+	return ResourceTemplateListResult{ResourceTemplates: page, NextCursor: next}
+}
+
+// Watch runs the watchers of the children until ctx is done, passing their notifications on. The URIs of
+// resources/updated notifications are those the resources are exposed under.
+func (r *CompositeResourceRegistry) Watch(ctx context.Context, notify Notifier) error {
+	return r.watch(ctx, notify, func(child compositeChild[ResourceRegistry], method string, params interface{}) interface{} {
+		if method != "notifications/resources/updated" || !child.prefix {
+			return params
+		}
+		switch p := params.(type) {
+		case map[string]string:
+			return map[string]string{"uri": r.prefixed(child, p["uri"])}
+		case map[string]interface{}:
+			if uri, ok := p["uri"].(string); ok {
+				return map[string]interface{}{"uri": r.prefixed(child, uri)}
+			}
+		}
+		return params
+	})
+}
+
+var (
+	_ ToolRegistry     = (*CompositeToolRegistry)(nil)
+	_ PromptRegistry   = (*CompositePromptRegistry)(nil)
+	_ ResourceRegistry = (*CompositeResourceRegistry)(nil)
+	_ Watcher          = (*CompositeToolRegistry)(nil)
+	_ Watcher          = (*CompositePromptRegistry)(nil)
+	_ Watcher          = (*CompositeResourceRegistry)(nil)
+)
@@ -0,0 +1,287 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// toolSource returns a static registry of tools answering with their child's name
+func toolSource(t *testing.T, child string, names ...string) *StaticToolRegistry {
+	registry := NewStaticToolRegistry()
+	for _, name := range names {
+		require.NoError(t, registry.RegisterTool(protocol.Tool{Name: name}, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+			return child + ":" + name, nil
+		}))
+	}
+	return registry
+}
+
+func toolNames(t *testing.T, registry ToolRegistry, pageSize int) []string {
+	var names []string
+	cursor := ""
+	for {
+		page, err := registry.ListTools(context.Background(), protocol.ToolListOptions{Cursor: cursor, PageSize: pageSize})
+		require.NoError(t, err)
+		for _, tool := range page.Tools {
+			names = append(names, tool.Name)
+		}
+		if page.NextCursor == "" {
+			return names
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func TestCompositeToolRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merges and routes", func(t *testing.T) {
+		composite := NewCompositeToolRegistry(CompositeOptions{})
+		require.NoError(t, composite.AddRegistry(toolSource(t, "static", "echo", "time"), CompositeChildOptions{}))
+		require.NoError(t, composite.AddRegistry(toolSource(t, "db", "query", "echo"), CompositeChildOptions{Namespace: "db", Prefix: true}))
+
+		assert.Equal(t, []string{"db_echo", "db_query", "echo", "time"}, toolNames(t, composite, 3))
+
+		result, err := composite.CallTool(ctx, "echo", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "static:echo", result)
+
+		result, err = composite.CallTool(ctx, "db_echo", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "db:echo", result)
+
+		tool, err := composite.GetTool(ctx, "db_query")
+		require.NoError(t, err)
+		assert.Equal(t, "db_query", tool.Name)
+
+		_, err = composite.CallTool(ctx, "query", map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrToolNotFound)
+	})
+
+	t.Run("error policy", func(t *testing.T) {
+		composite := NewCompositeToolRegistry(CompositeOptions{Collision: CollisionError})
+		require.NoError(t, composite.AddRegistry(toolSource(t, "a", "echo"), CompositeChildOptions{Namespace: "a"}))
+		err := composite.AddRegistry(toolSource(t, "b", "echo"), CompositeChildOptions{Namespace: "b"})
+		assert.ErrorIs(t, err, ErrNameCollision)
+
+		// A collision appearing after the child was added fails listing and lookups
+		late := toolSource(t, "c")
+		require.NoError(t, composite.AddRegistry(late, CompositeChildOptions{Namespace: "c"}))
+		require.NoError(t, late.RegisterTool(protocol.Tool{Name: "echo"}, nil))
+		_, err = composite.ListTools(ctx, protocol.ToolListOptions{})
+		assert.ErrorIs(t, err, ErrNameCollision)
+		_, err = composite.GetTool(ctx, "echo")
+		assert.ErrorIs(t, err, ErrNameCollision)
+
+		assert.Error(t, composite.AddRegistry(toolSource(t, "d"), CompositeChildOptions{Namespace: "a"}), "namespaces are unique")
+		assert.Error(t, composite.AddRegistry(toolSource(t, "e"), CompositeChildOptions{Prefix: true}), "prefixing needs a namespace")
+	})
+
+	t.Run("first wins", func(t *testing.T) {
+		composite := NewCompositeToolRegistry(CompositeOptions{Collision: CollisionFirstWins})
+		require.NoError(t, composite.AddRegistry(toolSource(t, "a", "echo"), CompositeChildOptions{Namespace: "a"}))
+		require.NoError(t, composite.AddRegistry(toolSource(t, "b", "echo", "other"), CompositeChildOptions{Namespace: "b"}))
+
+		assert.Equal(t, []string{"echo", "other"}, toolNames(t, composite, 0))
+		result, err := composite.CallTool(ctx, "echo", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "a:echo", result)
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		composite := NewCompositeToolRegistry(CompositeOptions{Collision: CollisionPrefix, Separator: "."})
+		require.NoError(t, composite.AddRegistry(toolSource(t, "a", "echo"), CompositeChildOptions{Namespace: "a"}))
+		require.NoError(t, composite.AddRegistry(toolSource(t, "b", "echo", "other"), CompositeChildOptions{Namespace: "b"}))
+
+		assert.Equal(t, []string{"b.echo", "echo", "other"}, toolNames(t, composite, 0))
+		result, err := composite.CallTool(ctx, "b.echo", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "b:echo", result)
+		result, err = composite.CallTool(ctx, "echo", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, "a:echo", result)
+	})
+
+	t.Run("pagination spans children", func(t *testing.T) {
+		composite := NewCompositeToolRegistry(CompositeOptions{})
+		for c := 0; c < 3; c++ {
+			names := make([]string, 0, 50)
+			for i := 0; i < 50; i++ {
+				names = append(names, fmt.Sprintf("tool%03d", i))
+			}
+			namespace := fmt.Sprintf("c%d", c)
+			require.NoError(t, composite.AddRegistry(toolSource(t, namespace, names...), CompositeChildOptions{Namespace: namespace, Prefix: true}))
+		}
+
+		names := toolNames(t, composite, 7)
+		assert.Len(t, names, 150)
+		assert.Equal(t, "c0_tool000", names[0])
+		assert.Equal(t, "c2_tool049", names[149])
+	})
+}
+
+func TestCompositePromptRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	local := NewStaticPromptRegistry()
+	require.NoError(t, local.RegisterPrompt(NewPrompt("greet").WithUserMessage("Hello from local").Build()))
+	plugin := NewStaticPromptRegistry()
+	require.NoError(t, plugin.RegisterPrompt(NewPrompt("greet").WithUserMessage("Hello from plugin").Build()))
+
+	composite := NewCompositePromptRegistry(CompositeOptions{Collision: CollisionPrefix})
+	require.NoError(t, composite.AddRegistry(local, CompositeChildOptions{}))
+	require.NoError(t, composite.AddRegistry(plugin, CompositeChildOptions{Namespace: "plugin"}))
+
+	var names []string
+	for _, prompt := range composite.ListPrompts(ctx, PromptListOptions{}).Prompts {
+		names = append(names, prompt.Name)
+	}
+	assert.Equal(t, []string{"greet", "plugin_greet"}, names)
+
+	prompt, ok := composite.GetPrompt(ctx, "plugin_greet")
+	require.True(t, ok)
+	assert.Equal(t, "plugin_greet", prompt.Name)
+
+	messages, err := composite.ProcessPrompt(ctx, "plugin_greet", nil)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Hello from plugin", messages[0].Content)
+
+	messages, err = composite.ProcessPrompt(ctx, "greet", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello from local", messages[0].Content)
+
+	_, err = composite.ProcessPrompt(ctx, "missing", nil)
+	assert.ErrorIs(t, err, ErrPromptNotFound)
+}
+
+func TestCompositeRegistry_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	local := NewStaticPromptRegistry()
+	plugin := NewStaticPromptRegistry()
+	composite := NewCompositePromptRegistry(CompositeOptions{Collision: CollisionError, Logger: logger})
+	require.NoError(t, composite.AddRegistry(local, CompositeChildOptions{}))
+	require.NoError(t, composite.AddRegistry(plugin, CompositeChildOptions{Namespace: "plugin"}))
+
+	// Names registered after the children were added can still collide
+	require.NoError(t, local.RegisterPrompt(NewPrompt("greet").WithUserMessage("Hello from local").Build()))
+	require.NoError(t, plugin.RegisterPrompt(NewPrompt("greet").WithUserMessage("Hello from plugin").Build()))
+	assert.Len(t, composite.ListPrompts(context.Background(), PromptListOptions{}).Prompts, 1)
+
+	assert.Contains(t, buf.String(), "Prompts of composite registry collide")
+}
+
+// watchedRegistry is a tool registry whose list changes on its own
+type watchedRegistry struct {
+	*StaticToolRegistry
+	changed chan struct{}
+}
+
+func (r *watchedRegistry) Watch(ctx context.Context, notify Notifier) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-r.changed:
+			notify(ctx, "notifications/tools/list_changed", nil)
+		}
+	}
+}
+
+func TestCompositeRegistry_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	child := &watchedRegistry{StaticToolRegistry: toolSource(t, "plugin"), changed: make(chan struct{})}
+	composite := NewCompositeToolRegistry(CompositeOptions{})
+	require.NoError(t, composite.AddRegistry(toolSource(t, "static", "echo"), CompositeChildOptions{}))
+	require.NoError(t, composite.AddRegistry(child, CompositeChildOptions{Namespace: "plugin", Prefix: true}))
+
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = composite.Watch(ctx, func(ctx context.Context, method string, params interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			methods = append(methods, method)
+		})
+	}()
+
+	child.changed <- struct{}{}
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(methods) == 1 && methods[0] == "notifications/tools/list_changed"
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestCompositeResourceRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	static := NewStaticResourceRegistry()
+	require.NoError(t, static.RegisterResource(Resource{URI: "file:///readme.md", Name: "readme"},
+		func(ctx context.Context, uri string) ([]ResourceContents, error) {
+			return []ResourceContents{NewTextResourceContents(uri, "text/markdown", "static readme")}, nil
+		}))
+	docs := NewStaticResourceRegistry()
+	require.NoError(t, docs.RegisterResource(Resource{URI: "file:///readme.md", Name: "readme"},
+		func(ctx context.Context, uri string) ([]ResourceContents, error) {
+			return []ResourceContents{NewTextResourceContents(uri, "text/markdown", "docs readme")}, nil
+		}))
+	require.NoError(t, docs.RegisterResourceTemplateWithProvider(ResourceTemplate{URITemplate: "file:///pages/{page}", Name: "page"},
+		func(ctx context.Context, uri string) ([]ResourceContents, error) {
+			return []ResourceContents{NewTextResourceContents(uri, "text/plain", "page "+TemplateVariables(ctx)["page"])}, nil
+		}))
+
+	composite := NewCompositeResourceRegistry(CompositeOptions{})
+	require.NoError(t, composite.AddRegistry(static, CompositeChildOptions{}))
+	require.NoError(t, composite.AddRegistry(docs, CompositeChildOptions{Namespace: "docs", Prefix: true}))
+
+	var uris []string
+	for _, resource := range composite.ListResources(ctx, ResourceListOptions{}).Resources {
+		uris = append(uris, resource.URI)
+	}
+	assert.Equal(t, []string{"docs+file:///readme.md", "file:///readme.md"}, uris)
+
+	templates := composite.ListResourceTemplates(ctx, ResourceTemplateListOptions{}).ResourceTemplates
+	require.Len(t, templates, 1)
+	assert.Equal(t, "docs+file:///pages/{page}", templates[0].URITemplate)
+
+	contents, err := composite.ReadResource(ctx, "docs+file:///readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, "docs readme", contents[0].GetText())
+	assert.Equal(t, "docs+file:///readme.md", contents[0].GetURI())
+
+	contents, err = composite.ReadResource(ctx, "file:///readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, "static readme", contents[0].GetText())
+
+	contents, err = composite.ReadResource(ctx, "docs+file:///pages/intro")
+	require.NoError(t, err)
+	assert.Equal(t, "page intro", contents[0].GetText())
+
+	_, err = composite.ReadResource(ctx, "file:///missing")
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+
+	require.NoError(t, composite.SubscribeResource(ctx, "docs+file:///readme.md"))
+	assert.Equal(t, []string{"default"}, docs.GetSubscribers("file:///readme.md"))
+	assert.Empty(t, static.GetSubscribers("file:///readme.md"))
+}
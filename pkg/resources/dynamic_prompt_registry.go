@@ -10,7 +10,8 @@ type PromptProvider interface {
 	// GetPrompt returns a prompt by name
 	GetPrompt(ctx context.Context, name string) (Prompt, bool)
 
-	// ListPrompts returns a list of available prompts
+	// ListPrompts returns a page of the available prompts and the position of the next page, which the server
+	// signs before handing it to the client; Paginate can compute both
 	ListPrompts(ctx context.Context, cursor string) ([]Prompt, string)
 
 	// ProcessPrompt processes a prompt template with the given arguments
@@ -12,7 +12,8 @@ type ToolProvider interface {
 	// GetTool returns a tool by name
 	GetTool(ctx context.Context, name string) (protocol.Tool, error)
 
-	// ListTools returns a list of available resources
+	// ListTools returns a page of the available tools. The cursor is the position the previous page returned
+	// as its next cursor, which the server signs before handing it to the client; Paginate can compute both.
 	ListTools(ctx context.Context, cursor string) (protocol.ToolListResult, error)
 
 	// HandleToolInvocation handles a tool invocation
@@ -7,7 +7,8 @@ import (
 
 // ResourceProvider defines the interface for dynamically providing resources
 type ResourceProviderInterface interface {
-	// ListResources returns a list of available resources
+	// ListResources returns a page of the available resources and the position of the next page, which the
+	// server signs before handing it to the client; Paginate can compute both
 	ListResources(ctx context.Context, cursor string) ([]Resource, string)
 
 	// ReadResource reads a resource by URI
@@ -19,7 +20,8 @@ type ResourceProviderInterface interface {
 	// UnsubscribeResource unsubscribes from updates for a resource
 	UnsubscribeResource(ctx context.Context, uri string) error
 
-	// ListResourceTemplates returns a list of available resource templates
+	// ListResourceTemplates returns a page of the available resource templates and the position of the next
+	// page, like ListResources
 	ListResourceTemplates(ctx context.Context, cursor string) ([]ResourceTemplate, string)
 }
 
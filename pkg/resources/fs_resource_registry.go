@@ -0,0 +1,389 @@
+package resources
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Defaults of FSResourceOptions
+const (
+	DefaultFSURIPrefix    = "file:///"
+	DefaultFSPageSize     = 50
+	DefaultFSPollInterval = 2 * time.Second
+)
+
+// sniffLen is how much of a file is read to detect its MIME type, as http.DetectContentType considers
+const sniffLen = 512
+
+// FSResourceOptions configures an FSResourceRegistry
+type FSResourceOptions struct {
+	// URIPrefix is prepended to a file's path to make its resource URI, DefaultFSURIPrefix if empty
+	URIPrefix string
+
+	// Include lists the globs, as in path.Match, of the files to serve; all files are served if it is empty.
+	// A glob without a slash is matched against file names, one with a slash against paths.
+	Include []string
+
+	// Exclude lists the globs of files not to serve, matched like Include
+	Exclude []string
+
+	// PageSize is the number of resources listed per page, DefaultFSPageSize if zero or less
+	PageSize int
+
+	// PollInterval is how often Watch looks for changes, DefaultFSPollInterval if zero. A negative interval
+	// disables watching.
+	PollInterval time.Duration
+
+	// Logger receives the registry's warnings, such as a tree that cannot be indexed. Pass the server's logger,
+	// the one given to server.WithLogger; slog.Default() is used if nil.
+	Logger *slog.Logger
+}
+
+// fsFile is a file of the tree
+type fsFile struct {
+	size     int64
+	modTime  time.Time
+	mimeType string
+}
+
+// FSResourceRegistry serves the files of a file system, such as os.DirFS or an embed.FS, as resources. Only
+// regular files are served: symbolic links, paths leaving the tree and files the globs leave out are not found.
+type FSResourceRegistry struct {
+	fsys fs.FS
+	opts FSResourceOptions
+
+	mu    sync.RWMutex
+	files map[string]fsFile
+	paths []string // sorted
+
+	subscribers map[string]map[string]bool // uri -> set of subscriber IDs
+}
+
+// NewFSResourceRegistry creates a registry serving the files of fsys, indexing them right away
+func NewFSResourceRegistry(fsys fs.FS, opts FSResourceOptions) *FSResourceRegistry {
+	if opts.URIPrefix == "" {
+		opts.URIPrefix = DefaultFSURIPrefix
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultFSPageSize
+	}
+	if opts.PollInterval == 0 {
+		opts.PollInterval = DefaultFSPollInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	r := &FSResourceRegistry{
+		fsys:        fsys,
+		opts:        opts,
+		files:       make(map[string]fsFile),
+		subscribers: make(map[string]map[string]bool),
+	}
+	r.Refresh()
+	return r
+}
+
+// Refresh indexes the files of the tree again, returning the paths of the files added, removed and modified
+func (r *FSResourceRegistry) Refresh() (added, removed, modified []string) {
+	r.mu.RLock()
+	previous := r.files
+	r.mu.RUnlock()
+
+	files := make(map[string]fsFile, len(previous))
+	err := fs.WalkDir(r.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip what cannot be read rather than give up on the whole tree
+			if d != nil && d.IsDir() && p != "." {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !r.included(p) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		file := fsFile{size: info.Size(), modTime: info.ModTime()}
+		if old, ok := previous[p]; ok && old.size == file.size && old.modTime.Equal(file.modTime) {
+			file.mimeType = old.mimeType
+		} else {
+			file.mimeType = r.detectMimeType(p)
+			if ok {
+				modified = append(modified, p)
+			} else {
+				added = append(added, p)
+			}
+		}
+		files[p] = file
+		return nil
+	})
+	if err != nil {
+		r.opts.Logger.Warn("Failed to index file system resources", "err", err)
+	}
+
+	for p := range previous {
+		if _, ok := files[p]; !ok {
+			removed = append(removed, p)
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	r.mu.Lock()
+	r.files = files
+	r.paths = paths
+	r.mu.Unlock()
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(modified)
+	return added, removed, modified
+}
+
+// included reports whether the globs let the file at p be served
+func (r *FSResourceRegistry) included(p string) bool {
+	if len(r.opts.Include) > 0 && !matchesAny(r.opts.Include, p) {
+		return false
+	}
+	return !matchesAny(r.opts.Exclude, p)
+}
+
+func matchesAny(globs []string, p string) bool {
+	for _, glob := range globs {
+		name := p
+		if !strings.Contains(glob, "/") {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// detectMimeType returns the MIME type of the file at p, from its extension or else its first bytes
+func (r *FSResourceRegistry) detectMimeType(p string) string {
+	if mimeType := mime.TypeByExtension(path.Ext(p)); mimeType != "" {
+		return mimeType
+	}
+
+	f, err := r.fsys.Open(p)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, _ := io.ReadFull(f, head)
+	return http.DetectContentType(head[:n])
+}
+
+// isTextMimeType reports whether contents of the MIME type are served as text
+func isTextMimeType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-yaml",
+		"application/yaml", "application/toml", "application/x-sh":
+		return true
+	}
+	return false
+}
+
+// URI returns the resource URI of the file at p
+func (r *FSResourceRegistry) URI(p string) string {
+	return r.opts.URIPrefix + (&url.URL{Path: p}).EscapedPath()
+}
+
+// filePath returns the path of the file a resource URI names, if it names one in the tree
+func (r *FSResourceRegistry) filePath(uri string) (string, bool) {
+	rest, ok := strings.CutPrefix(uri, r.opts.URIPrefix)
+	if !ok {
+		return "", false
+	}
+	p, err := url.PathUnescape(rest)
+	if err != nil || !fs.ValidPath(p) || p == "." {
+		return "", false
+	}
+	return p, true
+}
+
+// lookup returns the indexed file a resource URI names
+func (r *FSResourceRegistry) lookup(uri string) (string, fsFile, bool) {
+	p, ok := r.filePath(uri)
+	if !ok {
+		return "", fsFile{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	file, ok := r.files[p]
+	return p, file, ok
+}
+
+func (r *FSResourceRegistry) resource(p string, file fsFile) Resource {
+	return Resource{
+		URI:      r.URI(p),
+		Name:     path.Base(p),
+		MimeType: file.mimeType,
+		Size:     file.size,
+	}
+}
+
+// ListResources returns a page of the files, ordered by path. The page size of opts, if set, overrides the
+// registry's.
+func (r *FSResourceRegistry) ListResources(ctx context.Context, opts ResourceListOptions) ResourceListResult {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = r.opts.PageSize
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	paths, next := Paginate(r.paths, func(p string) string { return p }, opts.Cursor, pageSize)
+	result := ResourceListResult{Resources: make([]Resource, 0, len(paths)), NextCursor: next}
+	for _, p := range paths {
+		result.Resources = append(result.Resources, r.resource(p, r.files[p]))
+	}
+	return result
+}
+
+// ReadResource reads a file, as text if its MIME type is textual and it is valid UTF-8, and as binary otherwise
+func (r *FSResourceRegistry) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	p, file, ok := r.lookup(uri)
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	content, err := fs.ReadFile(r.fsys, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if isTextMimeType(file.mimeType) && utf8.Valid(content) {
+		return []ResourceContents{NewTextResourceContents(uri, file.mimeType, string(content))}, nil
+	}
+	return []ResourceContents{NewBinaryResourceContents(uri, file.mimeType, content)}, nil
+}
+
+// SubscribeResource subscribes to updates of a file
+func (r *FSResourceRegistry) SubscribeResource(ctx context.Context, uri string) error {
+	if _, _, ok := r.lookup(uri); !ok {
+		return ErrResourceNotFound
+	}
+	subscriberID, ok := ctx.Value(SubscriberIDKey).(string)
+	if !ok || subscriberID == "" {
+		subscriberID = "default"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.subscribers[uri] == nil {
+		r.subscribers[uri] = make(map[string]bool)
+	}
+	r.subscribers[uri][subscriberID] = true
+	return nil
+}
+
+// UnsubscribeResource unsubscribes from updates of a file
+func (r *FSResourceRegistry) UnsubscribeResource(ctx context.Context, uri string) error {
+	subscriberID, ok := ctx.Value(SubscriberIDKey).(string)
+	if !ok || subscriberID == "" {
+		subscriberID = "default"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subscribers, ok := r.subscribers[uri]
+	if !ok || !subscribers[subscriberID] {
+		return ErrResourceNotFound
+	}
+	delete(subscribers, subscriberID)
+	if len(subscribers) == 0 {
+		delete(r.subscribers, uri)
+	}
+	return nil
+}
+
+// ListResourceTemplates returns no templates
+func (r *FSResourceRegistry) ListResourceTemplates(ctx context.Context, opts ResourceTemplateListOptions) ResourceTemplateListResult {
+	return ResourceTemplateListResult{ResourceTemplates: []ResourceTemplate{}}
+}
+
+// Watch polls the tree for changes until ctx is done. Clients are told the list changed when files are added
+// or removed, and a subscribed file was updated when it is modified or removed. An embed.FS never changes, and
+// is not watched.
+func (r *FSResourceRegistry) Watch(ctx context.Context, notify Notifier) error {
+	if _, ok := r.fsys.(embed.FS); ok || r.opts.PollInterval < 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.notifyChanges(ctx, notify)
+		}
+	}
+}
+
+// notifyChanges refreshes the index and notifies clients of what changed
+func (r *FSResourceRegistry) notifyChanges(ctx context.Context, notify Notifier) {
+	added, removed, modified := r.Refresh()
+
+	r.mu.RLock()
+	var updated []string
+	for _, p := range append(modified, removed...) {
+		if uri := r.URI(p); len(r.subscribers[uri]) > 0 {
+			updated = append(updated, uri)
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, uri := range updated {
+		notify(ctx, "notifications/resources/updated", map[string]string{"uri": uri})
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		notify(ctx, "notifications/resources/list_changed", nil)
+	}
+}
+
+var (
+	_ ResourceRegistry = (*FSResourceRegistry)(nil)
+	_ Watcher          = (*FSResourceRegistry)(nil)
+)
@@ -0,0 +1,182 @@
+package resources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"README.md":          {Data: []byte("# Hello")},
+		"docs/guide.txt":     {Data: []byte("guide")},
+		"docs/data.json":     {Data: []byte(`{"a":1}`)},
+		"images/logo.png":    {Data: []byte("\x89PNG\r\n\x1a\n\x00\x00")},
+		"secret/.env":        {Data: []byte("TOKEN=x")},
+		"bin/tool":           {Data: []byte{0x7f, 'E', 'L', 'F', 0x00, 0x01}},
+		"docs/with space.md": {Data: []byte("spaced")},
+	}
+}
+
+func TestFSResourceRegistry_List(t *testing.T) {
+	ctx := context.Background()
+	registry := NewFSResourceRegistry(testFS(), FSResourceOptions{PageSize: 3})
+
+	var uris []string
+	cursor := ""
+	pages := 0
+	for {
+		page := registry.ListResources(ctx, ResourceListOptions{Cursor: cursor})
+		pages++
+		for _, resource := range page.Resources {
+			uris = append(uris, resource.URI)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{
+		"file:///README.md",
+		"file:///bin/tool",
+		"file:///docs/data.json",
+		"file:///docs/guide.txt",
+		"file:///docs/with%20space.md",
+		"file:///images/logo.png",
+		"file:///secret/.env",
+	}, uris)
+
+	first := registry.ListResources(ctx, ResourceListOptions{}).Resources[0]
+	assert.Equal(t, "README.md", first.Name)
+	assert.Equal(t, int64(7), first.Size)
+	assert.Contains(t, first.MimeType, "text/markdown")
+}
+
+func TestFSResourceRegistry_Globs(t *testing.T) {
+	ctx := context.Background()
+	registry := NewFSResourceRegistry(testFS(), FSResourceOptions{
+		URIPrefix: "docs://",
+		Include:   []string{"docs/*", "*.md"},
+		Exclude:   []string{"*.json"},
+	})
+
+	var uris []string
+	for _, resource := range registry.ListResources(ctx, ResourceListOptions{}).Resources {
+		uris = append(uris, resource.URI)
+	}
+	assert.Equal(t, []string{"docs://README.md", "docs://docs/guide.txt", "docs://docs/with%20space.md"}, uris)
+
+	_, err := registry.ReadResource(ctx, "docs://docs/data.json")
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+}
+
+func TestFSResourceRegistry_Read(t *testing.T) {
+	ctx := context.Background()
+	registry := NewFSResourceRegistry(testFS(), FSResourceOptions{})
+
+	contents, err := registry.ReadResource(ctx, "file:///docs/data.json")
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.True(t, contents[0].IsText())
+	assert.Equal(t, `{"a":1}`, contents[0].GetText())
+	assert.Equal(t, "application/json", contents[0].GetMimeType())
+
+	contents, err = registry.ReadResource(ctx, "file:///docs/with%20space.md")
+	require.NoError(t, err)
+	assert.True(t, contents[0].IsText())
+
+	contents, err = registry.ReadResource(ctx, "file:///images/logo.png")
+	require.NoError(t, err)
+	assert.True(t, contents[0].IsBinary())
+	assert.Equal(t, "image/png", contents[0].GetMimeType())
+
+	contents, err = registry.ReadResource(ctx, "file:///bin/tool")
+	require.NoError(t, err)
+	assert.True(t, contents[0].IsBinary())
+}
+
+func TestFSResourceRegistry_PathTraversal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outside.txt"), []byte("outside"), 0o600))
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.Mkdir(root, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "inside.txt"), []byte("inside"), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "outside.txt"), filepath.Join(root, "link.txt")))
+
+	registry := NewFSResourceRegistry(os.DirFS(root), FSResourceOptions{})
+
+	_, err := registry.ReadResource(ctx, "file:///inside.txt")
+	require.NoError(t, err)
+
+	for _, uri := range []string{
+		"file:///../outside.txt",
+		"file:///%2e%2e/outside.txt",
+		"file:////etc/passwd",
+		"file:///link.txt",
+		"file:///",
+		"other:///inside.txt",
+	} {
+		_, err := registry.ReadResource(ctx, uri)
+		assert.ErrorIs(t, err, ErrResourceNotFound, uri)
+	}
+}
+
+func TestFSResourceRegistry_Watch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "watched.txt")
+	require.NoError(t, os.WriteFile(file, []byte("v1"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := NewFSResourceRegistry(os.DirFS(dir), FSResourceOptions{PollInterval: 10 * time.Millisecond})
+	require.NoError(t, registry.SubscribeResource(ctx, "file:///watched.txt"))
+
+	var (
+		mu      sync.Mutex
+		methods []string
+		params  []interface{}
+	)
+	go func() {
+		_ = registry.Watch(ctx, func(ctx context.Context, method string, p interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			methods = append(methods, method)
+			params = append(params, p)
+		})
+	}()
+
+	received := func(method string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, m := range methods {
+			if m == method {
+				return true
+			}
+		}
+		return false
+	}
+
+	require.NoError(t, os.WriteFile(file, []byte("version 2"), 0o600))
+	assert.Eventually(t, func() bool { return received("notifications/resources/updated") }, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, map[string]string{"uri": "file:///watched.txt"}, params[0])
+	mu.Unlock()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o600))
+	assert.Eventually(t, func() bool { return received("notifications/resources/list_changed") }, time.Second, 10*time.Millisecond)
+
+	contents, err := registry.ReadResource(ctx, "file:///new.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "new", contents[0].GetText())
+}
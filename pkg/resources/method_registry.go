@@ -0,0 +1,116 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MethodHandler handles a request for a custom method, such as a vendor extension. params holds the request's
+// raw params, nil if it had none. The result is encoded as the response's result; a *protocol.JsonRpcError is
+// returned to the client as is.
+type MethodHandler func(ctx context.Context, method string, params json.RawMessage) (interface{}, error)
+
+// MethodRegistry resolves custom methods, which are served alongside the methods of the MCP spec. Methods of the
+// spec, such as tools/call, are handled by the server first.
+type MethodRegistry interface {
+	// GetMethod returns the handler for method, if there is one
+	GetMethod(method string) (MethodHandler, bool)
+
+	// ListMethods returns the method names and prefixes handled, which are advertised under the server's
+	// experimental capabilities
+	ListMethods() []string
+}
+
+// StaticMethodRegistry is a method registry that holds handlers registered for method names and prefixes
+type StaticMethodRegistry struct {
+	mu       sync.RWMutex
+	methods  map[string]MethodHandler
+	prefixes map[string]MethodHandler
+}
+
+// NewStaticMethodRegistry creates a new static method registry
+func NewStaticMethodRegistry() *StaticMethodRegistry {
+	return &StaticMethodRegistry{
+		methods:  make(map[string]MethodHandler),
+		prefixes: make(map[string]MethodHandler),
+	}
+}
+
+// RegisterMethod registers the handler of a method name, e.g. acme/search
+func (r *StaticMethodRegistry) RegisterMethod(method string, handler MethodHandler) error {
+	if method == "" {
+		return fmt.Errorf("method name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.methods[method]; exists {
+		return fmt.Errorf("method %q is already registered", method)
+	}
+	r.methods[method] = handler
+
+	slog.Info("Registered method", "method", method)
+	return nil
+}
+
+// RegisterPrefix registers the handler of every method starting with prefix, e.g. acme/ for a vendor's
+// namespace. Methods registered by name take precedence, then the longest matching prefix.
+func (r *StaticMethodRegistry) RegisterPrefix(prefix string, handler MethodHandler) error {
+	if prefix == "" {
+		return fmt.Errorf("method prefix cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.prefixes[prefix]; exists {
+		return fmt.Errorf("method prefix %q is already registered", prefix)
+	}
+	r.prefixes[prefix] = handler
+
+	slog.Info("Registered method prefix", "prefix", prefix)
+	return nil
+}
+
+// GetMethod returns the handler for method, if there is one
+func (r *StaticMethodRegistry) GetMethod(method string) (MethodHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if handler, ok := r.methods[method]; ok {
+		return handler, true
+	}
+
+	longest := ""
+	var found MethodHandler
+	for prefix, handler := range r.prefixes {
+		if strings.HasPrefix(method, prefix) && len(prefix) > len(longest) {
+			longest, found = prefix, handler
+		}
+	}
+	return found, found != nil
+}
+
+// ListMethods returns the registered method names and prefixes, sorted
+func (r *StaticMethodRegistry) ListMethods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	methods := make([]string, 0, len(r.methods)+len(r.prefixes))
+	for method := range r.methods {
+		methods = append(methods, method)
+	}
+	for prefix := range r.prefixes {
+		methods = append(methods, prefix)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+var _ MethodRegistry = (*StaticMethodRegistry)(nil)
@@ -0,0 +1,46 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticMethodRegistry(t *testing.T) {
+	registry := NewStaticMethodRegistry()
+
+	named := func(name string) MethodHandler {
+		return func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+			return name, nil
+		}
+	}
+
+	require.NoError(t, registry.RegisterMethod("acme/search", named("search")))
+	require.NoError(t, registry.RegisterPrefix("acme/", named("acme")))
+	require.NoError(t, registry.RegisterPrefix("acme/admin/", named("admin")))
+
+	assert.Error(t, registry.RegisterMethod("acme/search", named("again")), "a method is registered once")
+	assert.Error(t, registry.RegisterPrefix("acme/", named("again")), "a prefix is registered once")
+	assert.Error(t, registry.RegisterMethod("", named("empty")))
+	assert.Error(t, registry.RegisterPrefix("", named("empty")), "an empty prefix would match every method")
+
+	resolve := func(method string) string {
+		handler, ok := registry.GetMethod(method)
+		if !ok {
+			return ""
+		}
+		result, err := handler(context.Background(), method, nil)
+		require.NoError(t, err)
+		return result.(string)
+	}
+
+	assert.Equal(t, "search", resolve("acme/search"), "names take precedence over prefixes")
+	assert.Equal(t, "acme", resolve("acme/index"))
+	assert.Equal(t, "admin", resolve("acme/admin/reindex"), "the longest prefix wins")
+	assert.Equal(t, "", resolve("other/search"))
+
+	assert.Equal(t, []string{"acme/", "acme/admin/", "acme/search"}, registry.ListMethods())
+}
@@ -0,0 +1,184 @@
+package resources
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// DefaultPageSize is the number of items a list returns per page when no page size is configured
+const DefaultPageSize = 20
+
+// cursorVersion is the format of the cursors CursorCodec makes. Cursors of other versions are invalid.
+const cursorVersion byte = 1
+
+// ErrInvalidCursor is returned for a cursor that was tampered with, made by another server, or has expired
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Paginate returns the page of items following the position after, ordered by key, and the position of the
+// next page, which is empty on the last page. Positions are keys rather than offsets, so a page starts at the
+// right item even when items were added or removed since the previous one. Keys must be unique. A pageSize of
+// zero or less means DefaultPageSize.
+func Paginate[T any](items []T, key func(T) string, after string, pageSize int) ([]T, string) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool { return key(sorted[i]) < key(sorted[j]) })
+
+	start := 0
+	if after != "" {
+		start = sort.Search(len(sorted), func(i int) bool { return key(sorted[i]) > after })
+	}
+	end := start + pageSize
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+
+	page := sorted[start:end]
+	if end < len(sorted) {
+		return page, key(sorted[end-1])
+	}
+	return page, ""
+}
+
+// CursorCodec turns the positions of registries into the opaque cursors handed to clients, and back. Cursors
+// are signed, so clients cannot forge positions, and may expire.
+type CursorCodec struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// processCursorSecret signs cursors when no secret is configured. It is only known to this process, so
+// servers of a cluster must share a configured secret for cursors to be valid on every node.
+var processCursorSecret = sync.OnceValue(func() []byte {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+})
+
+// NewCursorCodec creates a codec signing cursors with secret, or a random secret of the process if it is empty.
+// Cursors expire ttl after they are made, or never if ttl is zero.
+func NewCursorCodec(secret []byte, ttl time.Duration) *CursorCodec {
+	if len(secret) == 0 {
+		secret = processCursorSecret()
+	}
+	return &CursorCodec{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// Encode returns the cursor of a position
+func (c *CursorCodec) Encode(position string) string {
+	payload := make([]byte, 9, 9+len(position)+sha256.Size)
+	payload[0] = cursorVersion
+	binary.BigEndian.PutUint64(payload[1:9], uint64(c.now().Unix()))
+	payload = append(payload, position...)
+	return base64.RawURLEncoding.EncodeToString(append(payload, c.sign(payload)...))
+}
+
+// Decode returns the position of a cursor, or ErrInvalidCursor
+func (c *CursorCodec) Decode(cursor string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(data) < 9+sha256.Size {
+		return "", ErrInvalidCursor
+	}
+
+	payload, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if payload[0] != cursorVersion || !hmac.Equal(mac, c.sign(payload)) {
+		return "", ErrInvalidCursor
+	}
+
+	issued := time.Unix(int64(binary.BigEndian.Uint64(payload[1:9])), 0)
+	if c.ttl > 0 && c.now().Sub(issued) > c.ttl {
+		return "", ErrInvalidCursor
+	}
+	return string(payload[9:]), nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// eachTool calls f with every tool of the registry until it returns false
+func eachTool(ctx context.Context, registry ToolRegistry, f func(protocol.Tool) bool) error {
+	cursor := ""
+	for {
+		page, err := registry.ListTools(ctx, protocol.ToolListOptions{Cursor: cursor})
+		if err != nil {
+			return err
+		}
+		for _, tool := range page.Tools {
+			if !f(tool) {
+				return nil
+			}
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// eachPrompt calls f with every prompt of the registry until it returns false
+func eachPrompt(ctx context.Context, registry PromptRegistry, f func(Prompt) bool) {
+	cursor := ""
+	for {
+		page := registry.ListPrompts(ctx, PromptListOptions{Cursor: cursor})
+		for _, prompt := range page.Prompts {
+			if !f(prompt) {
+				return
+			}
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// eachResourceTemplate calls f with every resource template of the registry until it returns false
+func eachResourceTemplate(ctx context.Context, registry ResourceRegistry, f func(ResourceTemplate) bool) {
+	cursor := ""
+	for {
+		page := registry.ListResourceTemplates(ctx, ResourceTemplateListOptions{Cursor: cursor})
+		for _, template := range page.ResourceTemplates {
+			if !f(template) {
+				return
+			}
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// eachResource calls f with every resource of the registry until it returns false
+func eachResource(ctx context.Context, registry ResourceRegistry, f func(Resource) bool) {
+	cursor := ""
+	for {
+		page := registry.ListResources(ctx, ResourceListOptions{Cursor: cursor})
+		for _, resource := range page.Resources {
+			if !f(resource) {
+				return
+			}
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return
+		}
+		cursor = page.NextCursor
+	}
+}
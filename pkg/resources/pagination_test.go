@@ -0,0 +1,92 @@
+package resources
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []string{"e", "a", "d", "b", "c"}
+	identity := func(s string) string { return s }
+
+	page, next := Paginate(items, identity, "", 2)
+	assert.Equal(t, []string{"a", "b"}, page)
+	assert.Equal(t, "b", next)
+
+	page, next = Paginate(items, identity, next, 2)
+	assert.Equal(t, []string{"c", "d"}, page)
+	assert.Equal(t, "d", next)
+
+	page, next = Paginate(items, identity, next, 2)
+	assert.Equal(t, []string{"e"}, page)
+	assert.Empty(t, next)
+
+	// Items removed since the previous page do not shift the next one
+	page, _ = Paginate([]string{"a", "d", "e"}, identity, "b", 2)
+	assert.Equal(t, []string{"d", "e"}, page)
+
+	// The input is left unsorted
+	assert.Equal(t, []string{"e", "a", "d", "b", "c"}, items)
+
+	many := make([]string, 0, DefaultPageSize+5)
+	for i := 0; i < DefaultPageSize+5; i++ {
+		many = append(many, fmt.Sprintf("%03d", i))
+	}
+	page, next = Paginate(many, identity, "", 0)
+	assert.Len(t, page, DefaultPageSize)
+	assert.NotEmpty(t, next)
+}
+
+func TestCursorCodec(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"), time.Hour)
+
+	cursor := codec.Encode("tools/42")
+	assert.NotContains(t, cursor, "tools/42")
+	position, err := codec.Decode(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, "tools/42", position)
+
+	t.Run("tampered", func(t *testing.T) {
+		data := []byte(cursor)
+		data[len(data)/2] ^= 1
+		_, err := codec.Decode(string(data))
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		_, err := NewCursorCodec([]byte("other"), time.Hour).Decode(cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		for _, garbage := range []string{"tools/42", "!!!", "AQ"} {
+			_, err := codec.Decode(garbage)
+			assert.ErrorIs(t, err, ErrInvalidCursor, garbage)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expiring := NewCursorCodec([]byte("secret"), time.Minute)
+		issued := time.Now()
+		expiring.now = func() time.Time { return issued }
+		cursor := expiring.Encode("tools/42")
+
+		expiring.now = func() time.Time { return issued.Add(30 * time.Second) }
+		_, err := expiring.Decode(cursor)
+		require.NoError(t, err)
+
+		expiring.now = func() time.Time { return issued.Add(2 * time.Minute) }
+		_, err = expiring.Decode(cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("process secret", func(t *testing.T) {
+		position, err := NewCursorCodec(nil, 0).Decode(NewCursorCodec(nil, 0).Encode("x"))
+		require.NoError(t, err)
+		assert.Equal(t, "x", position)
+	})
+}
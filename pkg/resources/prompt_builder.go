@@ -87,6 +87,12 @@ func (b *PromptArgumentBuilder) Description(description string) *PromptArgumentB
 	return b
 }
 
+// Complete sets the provider suggesting values for the argument
+func (b *PromptArgumentBuilder) Complete(provider CompletionProvider) *PromptArgumentBuilder {
+	b.argument.Complete = provider
+	return b
+}
+
 // Add adds the argument to the prompt and returns the prompt builder
 func (b *PromptArgumentBuilder) Add() *PromptBuilder {
 	b.prompt.prompt.Arguments = append(b.prompt.prompt.Arguments, b.argument)
@@ -29,11 +29,15 @@ type PromptArgument struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	Required    bool   `json:"required"`
+
+	// Complete suggests values for the argument through completion/complete, nil offers none
+	Complete CompletionProvider `json:"-"`
 }
 
 // PromptListOptions provides pagination options for listing prompts
 type PromptListOptions struct {
-	Cursor string // Cursor for pagination
+	Cursor   string // Cursor for pagination
+	PageSize int    // Number of prompts per page, the registry's default if zero
 }
 
 // PromptListResult represents a paginated list of prompts
@@ -15,9 +15,24 @@ type ResourceTemplateBuilder struct {
 	provider ResourceTemplateProvider
 }
 
-// ResourceTemplateProvider is a function that provides resource contents for a given URI that matches a template
+// ResourceTemplateProvider is a function that provides resource contents for a given URI that matches a template.
+// The values of the template's variables in the URI are in TemplateVariables(ctx).
 type ResourceTemplateProvider func(ctx context.Context, uri string) ([]ResourceContents, error)
 
+type templateVariablesKey struct{}
+
+// WithTemplateVariables returns a context carrying the values of the template variables of the URI being read
+func WithTemplateVariables(ctx context.Context, variables map[string]string) context.Context {
+	return context.WithValue(ctx, templateVariablesKey{}, variables)
+}
+
+// TemplateVariables returns the values of the template variables of the URI being read, decoded, or nil when it
+// was not matched against a template. List values are joined with commas.
+func TemplateVariables(ctx context.Context) map[string]string {
+	variables, _ := ctx.Value(templateVariablesKey{}).(map[string]string)
+	return variables
+}
+
 // NewResource creates a new resource builder
 func NewResource(uri string, name string) *ResourceBuilder {
 	return &ResourceBuilder{
@@ -73,6 +88,15 @@ func (b *ResourceTemplateBuilder) WithMimeType(mimeType string) *ResourceTemplat
 	return b
 }
 
+// WithCompletion sets the provider suggesting values for a variable of the resource template
+func (b *ResourceTemplateBuilder) WithCompletion(variable string, provider CompletionProvider) *ResourceTemplateBuilder {
+	if b.template.Completions == nil {
+		b.template.Completions = make(map[string]CompletionProvider)
+	}
+	b.template.Completions[variable] = provider
+	return b
+}
+
 // WithProvider sets the provider function for the resource template
 func (b *ResourceTemplateBuilder) WithProvider(provider ResourceTemplateProvider) *ResourceTemplateBuilder {
 	b.provider = provider
@@ -1,7 +1,31 @@
 package resources
 
+import "context"
+
 type FeatureRegistry struct {
 	ToolRegistry     ToolRegistry
 	PromptRegistry   PromptRegistry
 	ResourceRegistry ResourceRegistry
+
+	// MethodRegistry serves custom methods, nil serves none
+	MethodRegistry MethodRegistry
+}
+
+// Notifier sends a notification to clients
+type Notifier func(ctx context.Context, method string, params interface{})
+
+// Watcher may be implemented by a tool, prompt or resource registry whose items change on their own. The server
+// runs Watch while it is started, and sends the notifications it makes, such as
+// notifications/tools/list_changed, to the clients of the node's sessions.
+type Watcher interface {
+	// Watch notifies clients of changes to the registry's items until ctx is done
+	Watch(ctx context.Context, notify Notifier) error
+}
+
+// Versioned may be implemented by a prompt or resource registry whose items are registered while the server
+// runs. Version changes whenever they do, so that what the server derives from them, such as whether it
+// advertises completions, is computed again then rather than on every session.
+type Versioned interface {
+	// Version returns the version of the registry's items
+	Version() uint64
 }
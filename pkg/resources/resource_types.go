@@ -105,11 +105,15 @@ type ResourceTemplate struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	MimeType    string `json:"mimeType,omitempty"`
+
+	// Completions suggest values for the template's variables through completion/complete, keyed by variable
+	Completions map[string]CompletionProvider `json:"-"`
 }
 
 // ResourceListOptions provides pagination options for listing resources
 type ResourceListOptions struct {
-	Cursor string // Cursor for pagination
+	Cursor   string // Cursor for pagination
+	PageSize int    // Number of resources per page, the registry's default if zero
 }
 
 // ResourceListResult represents a paginated list of resources
@@ -120,7 +124,8 @@ type ResourceListResult struct {
 
 // ResourceTemplateListOptions provides pagination options for listing resource templates
 type ResourceTemplateListOptions struct {
-	Cursor string // Cursor for pagination
+	Cursor   string // Cursor for pagination
+	PageSize int    // Number of templates per page, the registry's default if zero
 }
 
 // ResourceTemplateListResult represents a paginated list of resource templates
@@ -4,9 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
 )
 
@@ -14,6 +14,7 @@ import (
 type StaticPromptRegistry struct {
 	mu      sync.RWMutex
 	prompts map[string]Prompt
+	version atomic.Uint64
 }
 
 // NewStaticPromptRegistry creates a new static prompt registry
@@ -33,10 +34,16 @@ func (r *StaticPromptRegistry) RegisterPrompt(prompt Prompt) error {
 	defer r.mu.Unlock()
 
 	r.prompts[prompt.Name] = prompt
+	r.version.Add(1)
 	slog.Info("Registered prompt", "name", prompt.Name)
 	return nil
 }
 
+// Version returns the number of prompts registered so far
+func (r *StaticPromptRegistry) Version() uint64 {
+	return r.version.Load()
+}
+
 // GetPrompt returns a prompt by name
 func (r *StaticPromptRegistry) GetPrompt(ctx context.Context, name string) (Prompt, bool) {
 	r.mu.RLock()
@@ -46,55 +53,17 @@ func (r *StaticPromptRegistry) GetPrompt(ctx context.Context, name string) (Prom
 	return prompt, ok
 }
 
-// ListPrompts returns a paginated list of prompts
+// ListPrompts returns a page of prompts, ordered by name
 func (r *StaticPromptRegistry) ListPrompts(ctx context.Context, opts PromptListOptions) PromptListResult {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	// Get all prompt names and sort them for consistent pagination
-	names := make([]string, 0, len(r.prompts))
-	for name := range r.prompts {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
-	// Find the starting position based on cursor
-	startPos := 0
-	if opts.Cursor != "" {
-		for i, name := range names {
-			if name == opts.Cursor {
-				startPos = i + 1 // Start after the cursor
-				break
-			}
-		}
-	}
-
-	// Calculate end position
-	endPos := startPos + 20
-	if endPos > len(names) {
-		endPos = len(names)
-	}
-
-	// Extract the prompts for this page
-	var result PromptListResult
-
-	// No prompts or cursor beyond the end
-	if startPos >= len(names) {
-		return result
-	}
-
-	// Get the prompts for this page
-	result.Prompts = make([]Prompt, 0, endPos-startPos)
-	for i := startPos; i < endPos; i++ {
-		result.Prompts = append(result.Prompts, r.prompts[names[i]])
-	}
-
-	// Set next cursor if there are more prompts
-	if endPos < len(names) {
-		result.NextCursor = names[endPos-1]
+	prompts := make([]Prompt, 0, len(r.prompts))
+	for _, prompt := range r.prompts {
+		prompts = append(prompts, prompt)
 	}
+	r.mu.RUnlock()
 
-	return result
+	page, next := Paginate(prompts, func(prompt Prompt) string { return prompt.Name }, opts.Cursor, opts.PageSize)
+	return PromptListResult{Prompts: page, NextCursor: next}
 }
 
 // ProcessPrompt processes a prompt template with the given arguments
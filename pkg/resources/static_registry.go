@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"log/slog"
-	"sort"
 	"sync"
 )
 
@@ -57,57 +56,17 @@ func (r *StaticToolRegistry) GetTool(ctx context.Context, name string) (protocol
 	return tool, nil
 }
 
-// ListTools returns a paginated list of resources
+// ListTools returns a page of tools, ordered by name
 func (r *StaticToolRegistry) ListTools(ctx context.Context, opts protocol.ToolListOptions) (protocol.ToolListResult, error) {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	// Get all tool names and sort them for consistent pagination
-	names := make([]string, 0, len(r.tools))
-	for name := range r.tools {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
-	// Find the starting position based on cursor
-	startPos := 0
-	if opts.Cursor != "" {
-		for i, name := range names {
-			if name == opts.Cursor {
-				startPos = i + 1 // Start after the cursor
-				break
-			}
-		}
-	}
-
-	// Calculate end position
-	endPos := startPos + 20
-	if endPos > len(names) {
-		endPos = len(names)
-	}
-
-	// Extract the resources for this page
-	result := protocol.ToolListResult{
-		Tools: make([]protocol.Tool, 0),
-	}
-
-	// No resources or cursor beyond the end
-	if startPos >= len(names) {
-		return result, nil
-	}
-
-	// Get the resources for this page
-	result.Tools = make([]protocol.Tool, 0, endPos-startPos)
-	for i := startPos; i < endPos; i++ {
-		result.Tools = append(result.Tools, r.tools[names[i]])
-	}
-
-	// Set next cursor if there are more resources
-	if endPos < len(names) {
-		result.NextCursor = names[endPos-1]
+	tools := make([]protocol.Tool, 0, len(r.tools))
+	for _, tool := range r.tools {
+		tools = append(tools, tool)
 	}
+	r.mu.RUnlock()
 
-	return result, nil
+	page, next := Paginate(tools, func(tool protocol.Tool) string { return tool.Name }, opts.Cursor, opts.PageSize)
+	return protocol.ToolListResult{Tools: page, NextCursor: next}, nil
 }
 
 // InvokeTool invokes a tool with the given parameters
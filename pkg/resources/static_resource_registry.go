@@ -6,6 +6,9 @@ import (
 	"log/slog"
 	"sort"
 	"sync"
+	"sync/atomic"
+
+	"github.com/traego/scaled-mcp/pkg/uritemplate"
 )
 
 // Define a custom type for context keys to avoid collisions
@@ -24,6 +27,11 @@ type StaticResourceRegistry struct {
 	resourceTemplates map[string]ResourceTemplate
 	providers         map[string]ResourceProvider
 	subscribers       map[string]map[string]bool // uri -> set of subscriber IDs
+	version           atomic.Uint64
+
+	// Parsed templates that have a provider, most specific first, which URIs without a resource are matched against
+	templates         []*uritemplate.Template
+	templateProviders map[string]ResourceTemplateProvider
 }
 
 // NewStaticResourceRegistry creates a new static resource registry
@@ -33,6 +41,7 @@ func NewStaticResourceRegistry() *StaticResourceRegistry {
 		resourceTemplates: make(map[string]ResourceTemplate),
 		providers:         make(map[string]ResourceProvider),
 		subscribers:       make(map[string]map[string]bool),
+		templateProviders: make(map[string]ResourceTemplateProvider),
 	}
 }
 
@@ -46,6 +55,7 @@ func (r *StaticResourceRegistry) RegisterResource(resource Resource, provider Re
 	defer r.mu.Unlock()
 
 	r.resources[resource.URI] = resource
+	r.version.Add(1)
 	if provider != nil {
 		r.providers[resource.URI] = provider
 	}
@@ -54,79 +64,84 @@ func (r *StaticResourceRegistry) RegisterResource(resource Resource, provider Re
 	return nil
 }
 
-// RegisterResourceTemplate registers a resource template with the registry
+// RegisterResourceTemplate registers a resource template with the registry. The template is only listed; to
+// read the resources it describes, register it with RegisterResourceTemplateWithProvider.
 func (r *StaticResourceRegistry) RegisterResourceTemplate(template ResourceTemplate) error {
+	return r.RegisterResourceTemplateWithProvider(template, nil)
+}
+
+// RegisterResourceTemplateWithProvider registers a resource template, an RFC 6570 URI template, with the provider
+// reading the resources it describes. URIs that no registered resource has are matched against the templates,
+// the most specific first, and read from the provider of the first that matches, which gets the values of the
+// template's variables from TemplateVariables.
+func (r *StaticResourceRegistry) RegisterResourceTemplateWithProvider(template ResourceTemplate, provider ResourceTemplateProvider) error {
 	if template.URITemplate == "" {
 		return fmt.Errorf("resource template URI cannot be empty")
 	}
+	parsed, err := uritemplate.Parse(template.URITemplate)
+	if err != nil {
+		return err
+	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	r.resourceTemplates[template.URITemplate] = template
-	slog.Info("Registered resource template", "uriTemplate", template.URITemplate, "name", template.Name)
-	return nil
-}
-
-// ListResources returns a paginated list of resources
-func (r *StaticResourceRegistry) ListResources(ctx context.Context, opts ResourceListOptions) ResourceListResult {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	r.version.Add(1)
 
-	// Get all resource URIs and sort them for consistent pagination
-	uris := make([]string, 0, len(r.resources))
-	for uri := range r.resources {
-		uris = append(uris, uri)
-	}
-	sort.Strings(uris)
-
-	// Find the starting position based on cursor
-	startPos := 0
-	if opts.Cursor != "" {
-		for i, uri := range uris {
-			if uri == opts.Cursor {
-				startPos = i + 1 // Start after the cursor
-				break
-			}
+	// Replace any previous registration of the template
+	templates := make([]*uritemplate.Template, 0, len(r.templates)+1)
+	for _, t := range r.templates {
+		if t.String() != template.URITemplate {
+			templates = append(templates, t)
 		}
 	}
-
-	// Calculate end position
-	endPos := startPos + 20
-	if endPos > len(uris) {
-		endPos = len(uris)
+	delete(r.templateProviders, template.URITemplate)
+	if provider != nil {
+		templates = append(templates, parsed)
+		r.templateProviders[template.URITemplate] = provider
 	}
+	sort.SliceStable(templates, func(i, j int) bool { return uritemplate.MoreSpecific(templates[i], templates[j]) })
+	r.templates = templates
 
-	// Extract the resources for this page
-	var result ResourceListResult
-
-	// No resources or cursor beyond the end
-	if startPos >= len(uris) {
-		return result
-	}
+	slog.Info("Registered resource template", "uriTemplate", template.URITemplate, "name", template.Name)
+	return nil
+}
 
-	// Get the resources for this page
-	result.Resources = make([]Resource, 0, endPos-startPos)
-	for i := startPos; i < endPos; i++ {
-		result.Resources = append(result.Resources, r.resources[uris[i]])
-	}
+// Version returns the number of resources and resource templates registered so far
+func (r *StaticResourceRegistry) Version() uint64 {
+	return r.version.Load()
+}
 
-	// Set next cursor if there are more resources
-	if endPos < len(uris) {
-		result.NextCursor = uris[endPos-1]
+// ListResources returns a page of resources, ordered by URI
+func (r *StaticResourceRegistry) ListResources(ctx context.Context, opts ResourceListOptions) ResourceListResult {
+	r.mu.RLock()
+	resources := make([]Resource, 0, len(r.resources))
+	for _, resource := range r.resources {
+		resources = append(resources, resource)
 	}
+	r.mu.RUnlock()
 
-	return result
+	page, next := Paginate(resources, func(resource Resource) string { return resource.URI }, opts.Cursor, opts.PageSize)
+	return ResourceListResult{Resources: page, NextCursor: next}
 }
 
-// ReadResource reads a resource by URI
+// ReadResource reads a resource by URI, from the provider of the resource or else of the first template matching it
 func (r *StaticResourceRegistry) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
 	r.mu.RLock()
 	provider, providerExists := r.providers[uri]
 	_, resourceExists := r.resources[uri]
+	var templateProvider ResourceTemplateProvider
+	var variables map[string]string
+	if !resourceExists {
+		templateProvider, variables = r.matchTemplate(uri)
+	}
 	r.mu.RUnlock()
 
 	if !resourceExists {
+		if templateProvider != nil {
+			return templateProvider(WithTemplateVariables(ctx, variables), uri)
+		}
 		return nil, ErrResourceNotFound
 	}
 
@@ -137,6 +152,17 @@ func (r *StaticResourceRegistry) ReadResource(ctx context.Context, uri string) (
 	return provider(ctx, uri)
 }
 
+// matchTemplate returns the provider of the most specific template matching uri, with the values of its variables.
+// The caller must hold the lock.
+func (r *StaticResourceRegistry) matchTemplate(uri string) (ResourceTemplateProvider, map[string]string) {
+	for _, template := range r.templates {
+		if variables, ok := template.Match(uri); ok {
+			return r.templateProviders[template.String()], variables
+		}
+	}
+	return nil, nil
+}
+
 // SubscribeResource subscribes to updates for a resource
 func (r *StaticResourceRegistry) SubscribeResource(ctx context.Context, uri string) error {
 	// Extract subscriber ID from context
@@ -192,55 +218,17 @@ func (r *StaticResourceRegistry) UnsubscribeResource(ctx context.Context, uri st
 	return nil
 }
 
-// ListResourceTemplates returns a paginated list of resource templates
+// ListResourceTemplates returns a page of resource templates, ordered by URI template
 func (r *StaticResourceRegistry) ListResourceTemplates(ctx context.Context, opts ResourceTemplateListOptions) ResourceTemplateListResult {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	// Get all template URIs and sort them for consistent pagination
-	uris := make([]string, 0, len(r.resourceTemplates))
-	for uri := range r.resourceTemplates {
-		uris = append(uris, uri)
-	}
-	sort.Strings(uris)
-
-	// Find the starting position based on cursor
-	startPos := 0
-	if opts.Cursor != "" {
-		for i, uri := range uris {
-			if uri == opts.Cursor {
-				startPos = i + 1 // Start after the cursor
-				break
-			}
-		}
-	}
-
-	// Calculate end position
-	endPos := startPos + 20
-	if endPos > len(uris) {
-		endPos = len(uris)
-	}
-
-	// Extract the templates for this page
-	var result ResourceTemplateListResult
-
-	// No templates or cursor beyond the end
-	if startPos >= len(uris) {
-		return result
-	}
-
-	// Get the templates for this page
-	result.ResourceTemplates = make([]ResourceTemplate, 0, endPos-startPos)
-	for i := startPos; i < endPos; i++ {
-		result.ResourceTemplates = append(result.ResourceTemplates, r.resourceTemplates[uris[i]])
-	}
-
-	// Set next cursor if there are more templates
-	if endPos < len(uris) {
-		result.NextCursor = uris[endPos-1]
+	templates := make([]ResourceTemplate, 0, len(r.resourceTemplates))
+	for _, template := range r.resourceTemplates {
+		templates = append(templates, template)
 	}
+	r.mu.RUnlock()
 
-	return result
+	page, next := Paginate(templates, func(template ResourceTemplate) string { return template.URITemplate }, opts.Cursor, opts.PageSize)
+	return ResourceTemplateListResult{ResourceTemplates: page, NextCursor: next}
 }
 
 // GetSubscribers returns the subscribers for a resource
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -247,10 +248,9 @@ func TestStaticResourceRegistry_Pagination(t *testing.T) {
 		assert.Len(t, result.Resources, 10, "Second page should have 10 resources")
 		assert.Empty(t, result.NextCursor, "Next cursor should be empty for last page")
 
-		// Using a cursor that doesn't exist returns the first page
-		// This is the actual behavior of the implementation
+		// A position no resource has continues from where it sorts, here past the last resource
 		result = registry.ListResources(ctx, ResourceListOptions{Cursor: "test/resource/999"})
-		assert.Len(t, result.Resources, 20, "Non-existent cursor should return first page")
+		assert.Empty(t, result.Resources, "Position after every resource should return an empty page")
 	})
 
 	// Register multiple templates for pagination testing
@@ -280,10 +280,9 @@ func TestStaticResourceRegistry_Pagination(t *testing.T) {
 		assert.Len(t, result.ResourceTemplates, 10, "Second page should have 10 templates")
 		assert.Empty(t, result.NextCursor, "Next cursor should be empty for last page")
 
-		// Using a cursor that doesn't exist returns the first page
-		// This is the actual behavior of the implementation
+		// A position no template has continues from where it sorts, here past the last template
 		result = registry.ListResourceTemplates(ctx, ResourceTemplateListOptions{Cursor: "test/template/{id}/999"})
-		assert.Len(t, result.ResourceTemplates, 20, "Non-existent cursor should return first page")
+		assert.Empty(t, result.ResourceTemplates, "Position after every template should return an empty page")
 	})
 }
 
@@ -468,3 +467,64 @@ func TestStaticResourceRegistry_ResourceProvider(t *testing.T) {
 		assert.Equal(t, expectedError, err)
 	})
 }
+
+func TestStaticResourceRegistry_ReadTemplate(t *testing.T) {
+	ctx := context.Background()
+	registry := NewStaticResourceRegistry()
+
+	// Providers echo the variables they got
+	echo := func(name string) ResourceTemplateProvider {
+		return func(ctx context.Context, uri string) ([]ResourceContents, error) {
+			vars := TemplateVariables(ctx)
+			keys := make([]string, 0, len(vars))
+			for key := range vars {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			text := name
+			for _, key := range keys {
+				text += " " + key + "=" + vars[key]
+			}
+			return []ResourceContents{NewTextResourceContents(uri, "text/plain", text)}, nil
+		}
+	}
+
+	template, provider := NewResourceTemplate("file:///logs/{date}.txt", "Daily log").WithProvider(echo("log")).BuildWithProvider()
+	require.NoError(t, registry.RegisterResourceTemplateWithProvider(template, provider))
+	require.NoError(t, registry.RegisterResourceTemplateWithProvider(ResourceTemplate{URITemplate: "file:///{+path}", Name: "File"}, echo("file")))
+	require.NoError(t, registry.RegisterResourceTemplate(ResourceTemplate{URITemplate: "listed://{id}", Name: "Listed only"}))
+	require.NoError(t, registry.RegisterResource(Resource{URI: "file:///logs/latest.txt", Name: "Latest log"},
+		func(ctx context.Context, uri string) ([]ResourceContents, error) {
+			return []ResourceContents{NewTextResourceContents(uri, "text/plain", "latest")}, nil
+		}))
+
+	read := func(uri string) (string, error) {
+		contents, err := registry.ReadResource(ctx, uri)
+		if err != nil {
+			return "", err
+		}
+		require.Len(t, contents, 1)
+		assert.Equal(t, uri, contents[0].GetURI())
+		return contents[0].GetText(), nil
+	}
+
+	text, err := read("file:///logs/2024-05-01.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "log date=2024-05-01", text, "the most specific template wins")
+
+	text, err = read("file:///etc/hosts")
+	require.NoError(t, err)
+	assert.Equal(t, "file path=etc/hosts", text)
+
+	text, err = read("file:///logs/latest.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "latest", text, "registered resources take precedence over templates")
+
+	_, err = read("listed://7")
+	assert.ErrorIs(t, err, ErrResourceNotFound, "templates without a provider are not read")
+
+	_, err = read("other://thing")
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+
+	assert.Error(t, registry.RegisterResourceTemplate(ResourceTemplate{URITemplate: "bad://{id", Name: "Bad"}))
+}
@@ -0,0 +1,85 @@
+package resources
+
+import (
+	"sort"
+	"sync"
+)
+
+// Subscriptions records the resources each session subscribed to, so that resources/updated notifications are
+// only sent to the sessions that asked for them. It is safe for concurrent use.
+type Subscriptions struct {
+	mu        sync.RWMutex
+	byURI     map[string]map[string]bool // uri -> set of session ids
+	bySession map[string]map[string]bool // session id -> set of uris
+}
+
+// NewSubscriptions creates an empty set of subscriptions
+func NewSubscriptions() *Subscriptions {
+	return &Subscriptions{
+		byURI:     make(map[string]map[string]bool),
+		bySession: make(map[string]map[string]bool),
+	}
+}
+
+// Subscribe records that the session subscribed to uri
+func (s *Subscriptions) Subscribe(sessionId, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byURI[uri] == nil {
+		s.byURI[uri] = make(map[string]bool)
+	}
+	s.byURI[uri][sessionId] = true
+	if s.bySession[sessionId] == nil {
+		s.bySession[sessionId] = make(map[string]bool)
+	}
+	s.bySession[sessionId][uri] = true
+}
+
+// Unsubscribe records that the session unsubscribed from uri
+func (s *Subscriptions) Unsubscribe(sessionId, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sessionId, uri)
+}
+
+// RemoveSession drops the subscriptions of a session that ended, returning the URIs it was subscribed to
+func (s *Subscriptions) RemoveSession(sessionId string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uris := make([]string, 0, len(s.bySession[sessionId]))
+	for uri := range s.bySession[sessionId] {
+		uris = append(uris, uri)
+	}
+	for _, uri := range uris {
+		s.remove(sessionId, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// Subscribers returns the ids of the sessions subscribed to uri, sorted
+func (s *Subscriptions) Subscribers(uri string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.byURI[uri]))
+	for id := range s.byURI[uri] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *Subscriptions) remove(sessionId, uri string) {
+	if sessions := s.byURI[uri]; sessions != nil {
+		delete(sessions, sessionId)
+		if len(sessions) == 0 {
+			delete(s.byURI, uri)
+		}
+	}
+	if uris := s.bySession[sessionId]; uris != nil {
+		delete(uris, uri)
+		if len(uris) == 0 {
+			delete(s.bySession, sessionId)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptions(t *testing.T) {
+	subs := NewSubscriptions()
+	subs.Subscribe("session-a", "file:///a.txt")
+	subs.Subscribe("session-b", "file:///a.txt")
+	subs.Subscribe("session-a", "file:///b.txt")
+
+	assert.Equal(t, []string{"session-a", "session-b"}, subs.Subscribers("file:///a.txt"))
+	assert.Equal(t, []string{"session-a"}, subs.Subscribers("file:///b.txt"))
+	assert.Empty(t, subs.Subscribers("file:///c.txt"))
+
+	subs.Unsubscribe("session-b", "file:///a.txt")
+	assert.Equal(t, []string{"session-a"}, subs.Subscribers("file:///a.txt"))
+
+	assert.Equal(t, []string{"file:///a.txt", "file:///b.txt"}, subs.RemoveSession("session-a"))
+	assert.Empty(t, subs.Subscribers("file:///a.txt"))
+	assert.Empty(t, subs.Subscribers("file:///b.txt"))
+	assert.Empty(t, subs.RemoveSession("session-a"))
+}
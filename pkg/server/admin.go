@@ -0,0 +1,393 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// ErrSessionNotFound is returned by the session admin API for sessions that do not exist on any node
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionInfo describes a session, as reported by its actor
+type SessionInfo struct {
+	SessionId       string                   `json:"session_id"`
+	State           string                   `json:"state"`
+	ProtocolVersion protocol.ProtocolVersion `json:"protocol_version,omitempty"`
+	ClientInfo      protocol.ClientInfo      `json:"client_info"`
+	PrincipalId     string                   `json:"principal_id,omitempty"`
+	LastActivity    time.Time                `json:"last_activity"`
+
+	// Names of the connection actors attached to the session, such as its SSE streams
+	Connections []string `json:"connections"`
+
+	// Address of the node running the session
+	Node string `json:"node,omitempty"`
+}
+
+// AdminAuthorizer decides whether a request may use the admin API, returning an error to reject it
+type AdminAuthorizer func(r *http.Request) error
+
+// AdminBearerToken returns an authorizer accepting requests that carry token as a bearer token. An empty token
+// rejects every request.
+func AdminBearerToken(token string) AdminAuthorizer {
+	return func(r *http.Request) error {
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			return errors.New("invalid admin token")
+		}
+		return nil
+	}
+}
+
+// WithAdmin mounts the admin API at path on the server's router, guarded by authorize
+func WithAdmin(path string, authorize AdminAuthorizer) McpServerOption {
+	return func(s *McpServer) {
+		s.adminPath = path
+		s.adminAuthorizer = authorize
+	}
+}
+
+// AdminRouter returns a router serving the admin API, to mount on a chi router:
+//
+//	GET    /sessions           lists the sessions of every node
+//	GET    /sessions/{id}      shows one session
+//	DELETE /sessions/{id}      terminates a session, with an optional reason query parameter
+//	POST   /notifications      sends {"method": ..., "params": ...} to every session
+//
+// Every request must be accepted by authorize; a nil authorizer rejects all of them.
+func (s *McpServer) AdminRouter(authorize AdminAuthorizer) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := errors.New("admin API is not enabled")
+			if authorize != nil {
+				err = authorize(r)
+			}
+			if err != nil {
+				config.Logger(s).WarnContext(r.Context(), "Rejected admin request", "path", r.URL.Path, "remote_addr", r.RemoteAddr, "err", err)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-admin"`)
+				writeAdminError(w, http.StatusUnauthorized, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	r.Get("/sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions, err := s.ListSessions(r.Context())
+		if err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"sessions": sessions})
+	})
+
+	r.Get("/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		session, err := s.GetSession(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			writeAdminError(w, adminErrorStatus(err), err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, session)
+	})
+
+	r.Delete("/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.TerminateSession(r.Context(), chi.URLParam(r, "id"), r.URL.Query().Get("reason")); err != nil {
+			writeAdminError(w, adminErrorStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	r.Post("/notifications", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+
+		var params interface{}
+		if len(body.Params) > 0 {
+			params = body.Params
+		}
+		sent, err := s.BroadcastNotification(r.Context(), body.Method, params)
+		if err != nil {
+			writeAdminError(w, adminErrorStatus(err), err)
+			return
+		}
+		writeAdminJSON(w, http.StatusAccepted, map[string]int{"sessions": sent})
+	})
+
+	return r
+}
+
+// ListSessions describes every session, on this node and, when clustered, on the other nodes of the
+// cluster. Sessions are sorted by id.
+func (s *McpServer) ListSessions(ctx context.Context) ([]SessionInfo, error) {
+	if s.actorSystem == nil || !s.actorSystem.Running() {
+		return nil, errors.New("actor system is not running")
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		sessions = make([]SessionInfo, 0)
+	)
+	for _, ref := range s.actorSystem.ActorRefs(ctx, s.config.RequestTimeout) {
+		if _, ok := utils.SessionIdFromActorName(ref.Name()); !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := s.sessionInfo(ctx, ref.Name())
+			if err != nil {
+				// Sessions ending while they are listed are left out
+				if !errors.Is(err, ErrSessionNotFound) {
+					config.Logger(s).WarnContext(ctx, "Failed to describe session", "session", ref.Name(), "err", err)
+				}
+				return
+			}
+			info.Node = ref.Address().HostPort()
+
+			mu.Lock()
+			defer mu.Unlock()
+			sessions = append(sessions, *info)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].SessionId < sessions[j].SessionId })
+	return sessions, nil
+}
+
+// GetSession describes a session, wherever it runs in the cluster
+func (s *McpServer) GetSession(ctx context.Context, sessionId string) (*SessionInfo, error) {
+	if s.actorSystem == nil || !s.actorSystem.Running() {
+		return nil, errors.New("actor system is not running")
+	}
+
+	san := utils.GetSessionActorName(sessionId)
+	addr, _, err := s.actorSystem.ActorOf(ctx, san)
+	if err != nil {
+		if utils.IsActorNotFound(err) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	info, err := s.sessionInfo(ctx, san)
+	if err != nil {
+		return nil, err
+	}
+	info.Node = addr.HostPort()
+	return info, nil
+}
+
+// TerminateSession ends a session, wherever it runs in the cluster. Its connections are closed, and the client
+// has to initialize a new session. The reason is recorded in the audit log.
+func (s *McpServer) TerminateSession(ctx context.Context, sessionId string, reason string) error {
+	resp, err := s.sendToSession(ctx, utils.GetSessionActorName(sessionId), &mcppb.TerminateSession{Reason: reason})
+	if err != nil {
+		return err
+	}
+
+	tr, ok := resp.(*mcppb.TerminateSessionResponse)
+	if !ok {
+		return fmt.Errorf("unexpected response type %T", resp)
+	}
+	if !tr.GetSuccess() {
+		return fmt.Errorf("failed to terminate session: %s", tr.GetError())
+	}
+
+	config.Logger(s).InfoContext(ctx, "Terminated session", "session_id", sessionId, "reason", reason)
+	return nil
+}
+
+// BroadcastNotification sends a notification to the client of every session in the cluster, returning the
+// number of sessions it was sent to. Clients without an open stream do not receive it.
+func (s *McpServer) BroadcastNotification(ctx context.Context, method string, params interface{}) (int, error) {
+	sent, err := s.notifySessions(ctx, method, params, false, nil)
+	if err != nil {
+		return 0, err
+	}
+	config.Logger(s).InfoContext(ctx, "Broadcast notification", "method", method, "sessions", sent)
+	return sent, nil
+}
+
+// NotifyLocalSessions sends a notification to the client of every session on this node, returning the number
+// of sessions it was sent to. It suits changes each node sees for itself, such as to files on its disk.
+func (s *McpServer) NotifyLocalSessions(ctx context.Context, method string, params interface{}) (int, error) {
+	return s.notifySessions(ctx, method, params, true, nil)
+}
+
+// notifyResourceSubscribers sends a resources/updated notification to the sessions on this node subscribed to
+// the resource it is about
+func (s *McpServer) notifyResourceSubscribers(ctx context.Context, params interface{}) (int, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", resources.ErrInvalidParams, err)
+	}
+	var updated struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(paramsJSON, &updated); err != nil || updated.URI == "" {
+		return 0, fmt.Errorf("%w: resources/updated needs the uri of the resource", resources.ErrInvalidParams)
+	}
+
+	subscribers := make(map[string]bool)
+	for _, sessionId := range s.resourceSubscriptions.Subscribers(updated.URI) {
+		subscribers[sessionId] = true
+	}
+	if len(subscribers) == 0 {
+		return 0, nil
+	}
+	return s.notifySessions(ctx, "notifications/resources/updated", params, true, func(sessionId string) bool {
+		return subscribers[sessionId]
+	})
+}
+
+// notifySessions sends a notification to the sessions on this node, or in the whole cluster, for which include
+// returns true, or all of them if it is nil
+func (s *McpServer) notifySessions(ctx context.Context, method string, params interface{}, local bool, include func(sessionId string) bool) (int, error) {
+	if !strings.HasPrefix(method, "notifications/") {
+		return 0, fmt.Errorf("%w: method must start with notifications/", resources.ErrInvalidParams)
+	}
+	if s.actorSystem == nil || !s.actorSystem.Running() {
+		return 0, errors.New("actor system is not running")
+	}
+
+	notification := &mcppb.JsonRpcRequest{
+		Jsonrpc: "2.0",
+		Id:      &mcppb.JsonRpcRequest_NullId{NullId: true},
+		Method:  method,
+	}
+	if params != nil {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", resources.ErrInvalidParams, err)
+		}
+		notification.ParamsJson = string(paramsJSON)
+	}
+
+	_, rid, err := s.actorSystem.ActorOf(ctx, "root")
+	if err != nil {
+		return 0, err
+	}
+
+	var names []string
+	if local {
+		for _, pid := range s.actorSystem.Actors() {
+			names = append(names, pid.Name())
+		}
+	} else {
+		for _, ref := range s.actorSystem.ActorRefs(ctx, s.config.RequestTimeout) {
+			names = append(names, ref.Name())
+		}
+	}
+
+	sent := 0
+	for _, name := range names {
+		sessionId, ok := utils.SessionIdFromActorName(name)
+		if !ok || (include != nil && !include(sessionId)) {
+			continue
+		}
+		if err := rid.SendAsync(ctx, name, &mcppb.SessionNotification{Notification: notification}); err != nil {
+			config.Logger(s).WarnContext(ctx, "Failed to send notification to session", "session", name, "err", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// sessionInfo asks the actor of a session to describe it
+func (s *McpServer) sessionInfo(ctx context.Context, sessionActorName string) (*SessionInfo, error) {
+	resp, err := s.sendToSession(ctx, sessionActorName, &mcppb.GetSessionInfo{})
+	if err != nil {
+		return nil, err
+	}
+
+	info, ok := resp.(*mcppb.SessionInfo)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", resp)
+	}
+
+	connections := info.GetConnectionIds()
+	if connections == nil {
+		connections = []string{}
+	}
+	return &SessionInfo{
+		SessionId:       info.GetSessionId(),
+		State:           info.GetState(),
+		ProtocolVersion: protocol.ProtocolVersion(info.GetProtocolVersion()),
+		ClientInfo:      protocol.ClientInfo{Name: info.GetClientName(), Version: info.GetClientVersion()},
+		PrincipalId:     info.GetPrincipalId(),
+		LastActivity:    time.UnixMilli(info.GetLastActivityUnixMs()),
+		Connections:     connections,
+	}, nil
+}
+
+// sendToSession asks a session actor, local or on another node, and waits for its response
+func (s *McpServer) sendToSession(ctx context.Context, sessionActorName string, msg proto.Message) (proto.Message, error) {
+	if s.actorSystem == nil || !s.actorSystem.Running() {
+		return nil, errors.New("actor system is not running")
+	}
+
+	_, rid, err := s.actorSystem.ActorOf(ctx, "root")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := rid.SendSync(ctx, sessionActorName, msg, s.config.RequestTimeout)
+	if err != nil {
+		if utils.IsActorNotFound(err) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// adminErrorStatus returns the HTTP status reporting err
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, resources.ErrInvalidParams):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
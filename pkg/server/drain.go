@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tochemey/goakt/v3/actor"
+
+	"github.com/traego/scaled-mcp/internal/httphandlers"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// WithSessionStore sets the store sessions are handed off through when a node shuts down, so that clients
+// reconnecting to another node carry on without initializing again. By default a Redis store is used when
+// Redis is configured and Session.UseInMemory is false; otherwise sessions are not handed off.
+func WithSessionStore(store sessionstore.Store) McpServerOption {
+	return func(s *McpServer) {
+		s.sessionStore = store
+	}
+}
+
+func (s *McpServer) GetSessionStore() sessionstore.Store {
+	return s.sessionStore
+}
+
+// defaultSessionStore returns the store sessions are handed off through, or nil if there is none
+func (s *McpServer) defaultSessionStore() sessionstore.Store {
+	if s.sessionStore != nil {
+		return s.sessionStore
+	}
+
+	if s.redisClient != nil && !s.config.Session.UseInMemory {
+		return sessionstore.NewRedisStore(s.redisClient, s.config.Session.KeyPrefix)
+	}
+
+	if s.config.Clustering.Type != "" {
+		config.Logger(s).Warn("no session store is configured, sessions will not be handed off to other nodes on shutdown")
+	}
+	return nil
+}
+
+// drain takes the node out of rotation and winds down its sessions: it reports not ready, turns away new
+// sessions, waits for in-flight requests, then hands sessions off and tells SSE clients to reconnect
+func (s *McpServer) drain(ctx context.Context) {
+	logger := config.Logger(s)
+	s.SetDraining(true)
+	if s.Handlers != nil {
+		s.Handlers.SetDraining(true)
+	}
+
+	if delay := s.config.Shutdown.ReadinessDelay; delay > 0 {
+		logger.InfoContext(ctx, "Waiting for load balancers to stop routing to the node", "delay", delay)
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+	}
+
+	if s.Handlers != nil {
+		if err := s.Handlers.WaitInFlight(ctx); err != nil {
+			logger.WarnContext(ctx, "Gave up waiting for in-flight requests", "in_flight", s.Handlers.InFlight(), "err", err)
+		}
+	}
+
+	s.drainSessions(ctx)
+}
+
+// drainSessions asks every session on this node to hand itself off and close its connections. Sessions
+// process their queued requests before the drain message, so requests already accepted still complete.
+func (s *McpServer) drainSessions(ctx context.Context) {
+	if s.actorSystem == nil || !s.actorSystem.Running() {
+		return
+	}
+
+	logger := config.Logger(s)
+	msg := &mcppb.DrainSession{RetryAfterMs: httphandlers.DrainRetryAfter.Milliseconds()}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		drained   int
+		handedOff int
+	)
+	for _, pid := range s.actorSystem.Actors() {
+		if _, ok := utils.SessionIdFromActorName(pid.Name()); !ok || !pid.IsRunning() {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := actor.Ask(ctx, pid, msg, s.drainTimeout())
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to drain session", "session", pid.Name(), "err", err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			drained++
+			if r, ok := resp.(*mcppb.DrainSessionResponse); ok && r.GetHandedOff() {
+				handedOff++
+			}
+		}()
+	}
+	wg.Wait()
+
+	logger.InfoContext(ctx, "Drained sessions", "drained", drained, "handed_off", handedOff)
+}
+
+// drainTimeout returns how long draining may take
+func (s *McpServer) drainTimeout() time.Duration {
+	if s.config.Shutdown.DrainTimeout > 0 {
+		return s.config.Shutdown.DrainTimeout
+	}
+	return 30 * time.Second
+}
+
+// stopTimeout returns how long stopping the HTTP server and the actor system may take
+func (s *McpServer) stopTimeout() time.Duration {
+	if s.config.Shutdown.StopTimeout > 0 {
+		return s.config.Shutdown.StopTimeout
+	}
+	return 5 * time.Second
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/traego/scaled-mcp/pkg/health"
+)
+
+// Names of the built-in readiness checks
+const (
+	CheckActorSystem = "actor_system"
+	CheckRootActor   = "root_actor"
+	CheckCluster     = "cluster"
+	CheckRedis       = "redis"
+)
+
+// WithLivenessCheck adds a check to the liveness endpoint. A failing liveness check tells the orchestrator
+// to restart the process, so it should only fail when the node cannot recover by itself.
+func WithLivenessCheck(check health.Check) McpServerOption {
+	return func(s *McpServer) {
+		s.livenessChecks = append(s.livenessChecks, check)
+	}
+}
+
+// WithReadinessCheck adds a check to the readiness endpoint, e.g. for a database backing a dynamic tool
+// registry. The node is taken out of rotation while any readiness check fails.
+func WithReadinessCheck(check health.Check) McpServerOption {
+	return func(s *McpServer) {
+		s.readinessChecks = append(s.readinessChecks, check)
+	}
+}
+
+// SetDraining sets whether the node reports itself as not ready, so that load balancers stop sending it new
+// sessions. It is set when the server stops.
+func (s *McpServer) SetDraining(draining bool) {
+	if s.readiness != nil {
+		s.readiness.SetDraining(draining)
+	}
+}
+
+// setupHealth creates the liveness and readiness checkers, with the built-in readiness checks first
+func (s *McpServer) setupHealth() {
+	timeout := s.config.Health.CheckTimeout
+	s.liveness = health.NewChecker(timeout, s.livenessChecks...)
+
+	s.readiness = health.NewChecker(timeout,
+		health.NewCheck(CheckActorSystem, s.checkActorSystem),
+		health.NewCheck(CheckRootActor, s.checkRootActor),
+	)
+	if s.config.Clustering.Type != "" {
+		s.readiness.Add(health.NewCheck(CheckCluster, s.checkCluster))
+	}
+	if s.redisClient != nil {
+		s.readiness.Add(health.NewCheck(CheckRedis, s.checkRedis))
+	}
+	s.readiness.Add(s.readinessChecks...)
+}
+
+func (s *McpServer) checkActorSystem(context.Context) error {
+	if !s.actorSystem.Running() {
+		return errors.New("actor system is not running")
+	}
+	return nil
+}
+
+func (s *McpServer) checkRootActor(context.Context) error {
+	pid, err := s.actorSystem.LocalActor("root")
+	if err != nil {
+		return fmt.Errorf("root actor not found: %w", err)
+	}
+	if !pid.IsRunning() {
+		return errors.New("root actor is not running")
+	}
+	return nil
+}
+
+func (s *McpServer) checkCluster(context.Context) error {
+	if !s.actorSystem.InCluster() {
+		return errors.New("node has not joined the cluster")
+	}
+	if peers := int(s.clusterMembers.Load()) - 1; peers < s.config.Health.MinPeers {
+		return fmt.Errorf("%d peers visible, %d required", peers, s.config.Health.MinPeers)
+	}
+	return nil
+}
+
+func (s *McpServer) checkRedis(ctx context.Context) error {
+	return s.redisClient.Ping(ctx).Err()
+}
+
+// healthEndpoints returns the liveness and readiness endpoints keyed by path
+func (s *McpServer) healthEndpoints() map[string]http.Handler {
+	livenessPath := s.config.Health.LivenessPath
+	if livenessPath == "" {
+		livenessPath = "/livez"
+	}
+	readinessPath := s.config.Health.ReadinessPath
+	if readinessPath == "" {
+		readinessPath = "/readyz"
+	}
+	return map[string]http.Handler{
+		livenessPath:  s.liveness,
+		readinessPath: s.readiness,
+	}
+}
@@ -5,30 +5,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	actors2 "github.com/traego/scaled-mcp/internal/actors"
 	"github.com/traego/scaled-mcp/internal/executors"
 	"github.com/traego/scaled-mcp/internal/httphandlers"
+	"github.com/traego/scaled-mcp/pkg/audit"
 	"github.com/traego/scaled-mcp/pkg/auth"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/redis/go-redis/v9"
 	"github.com/tochemey/goakt/v3/actor"
 	"github.com/tochemey/goakt/v3/discovery/static"
+	"github.com/tochemey/goakt/v3/goaktpb"
 	"github.com/tochemey/goakt/v3/remote"
 
 	"github.com/traego/scaled-mcp/internal/logger"
 	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/health"
+	"github.com/traego/scaled-mcp/pkg/metrics"
 	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/ratelimit"
+	"github.com/traego/scaled-mcp/pkg/redact"
 	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"github.com/traego/scaled-mcp/pkg/tracing"
 	"github.com/traego/scaled-mcp/pkg/utils"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // McpServer represents an MCP server
@@ -56,6 +71,73 @@ type McpServer struct {
 	authHandler config.AuthHandler
 
 	traceHandler config.TraceHandler
+
+	// Rate limiting, enabled through the rate_limit config
+	rateLimiter    *ratelimit.Limiter
+	rateLimitStore ratelimit.Store
+
+	// Audit records are written to auditSink; auditFile is the file sink opened from config, if any
+	auditSink audit.Sink
+	auditFile *audit.JSONLinesSink
+
+	// Measurements go to metrics; a Prometheus recorder is used when metrics are enabled in config
+	metrics metrics.Recorder
+
+	// Cancels the subscription counting cluster members, if any
+	stopClusterWatch func()
+
+	// Stops watching the registries for changes, if any is watched
+	stopRegistryWatch context.CancelFunc
+
+	// Unregisters the server that session actors relocated to this node resume with
+	unregisterRelocation func()
+
+	// Spans are created from tracerProvider, or the global provider if it is nil. sdkTracerProvider is set
+	// when the server created the provider itself, for an exporter, and must flush it on stop.
+	tracerProvider    trace.TracerProvider
+	sdkTracerProvider *sdktrace.TracerProvider
+
+	// Stops the TLS certificate reloader, if one is running
+	stopTLSReload context.CancelFunc
+
+	// Logger of the server and everything it runs, set with WithLogger
+	logger *slog.Logger
+
+	// Liveness and readiness endpoints, with the checks added through options
+	liveness        *health.Checker
+	readiness       *health.Checker
+	livenessChecks  []health.Check
+	readinessChecks []health.Check
+
+	// Number of cluster nodes visible to this node, including itself
+	clusterMembers atomic.Int64
+
+	// Whether a prompt or resource template has a completion provider, computed on start, whenever the prompt
+	// or resource registry reports a change, and when their resources.CompletionsVersion, which it was computed
+	// at, changes
+	hasCompletions     atomic.Bool
+	completionsVersion atomic.Uint64
+
+	// Shared client for the configured Redis, if any
+	redisClient redis.UniversalClient
+
+	// Sessions are handed off through sessionStore when the node shuts down, if it is set
+	sessionStore sessionstore.Store
+
+	// Application callbacks run as sessions start and end, set with WithSessionHooks
+	sessionHooks *config.SessionHooks
+
+	// Interceptors wrapping every request and every tool call, added with WithMethodInterceptors and
+	// WithToolInterceptors
+	methodInterceptors []config.MethodInterceptor
+	toolInterceptors   []config.ToolInterceptor
+
+	// Resources the sessions on this node subscribed to, so resources/updated only goes to their subscribers
+	resourceSubscriptions *resources.Subscriptions
+
+	// The admin API is mounted at adminPath when it is set with WithAdmin
+	adminPath       string
+	adminAuthorizer AdminAuthorizer
 }
 
 func (s *McpServer) GetExecutors() config.MethodHandler {
@@ -70,6 +152,38 @@ func (s *McpServer) GetTraceHandler() config.TraceHandler {
 	return s.traceHandler
 }
 
+func (s *McpServer) GetRateLimiter() *ratelimit.Limiter {
+	return s.rateLimiter
+}
+
+func (s *McpServer) GetAuditSink() audit.Sink {
+	return s.auditSink
+}
+
+func (s *McpServer) GetMetrics() metrics.Recorder {
+	return s.metrics
+}
+
+func (s *McpServer) GetTracerProvider() trace.TracerProvider {
+	return s.tracerProvider
+}
+
+func (s *McpServer) GetLogger() *slog.Logger {
+	return s.logger
+}
+
+func (s *McpServer) GetSessionHooks() *config.SessionHooks {
+	return s.sessionHooks
+}
+
+func (s *McpServer) GetToolInterceptors() []config.ToolInterceptor {
+	return s.toolInterceptors
+}
+
+func (s *McpServer) GetResourceSubscriptions() *resources.Subscriptions {
+	return s.resourceSubscriptions
+}
+
 func (s *McpServer) GetServerConfig() *config.ServerConfig {
 	return s.config
 }
@@ -78,8 +192,49 @@ func (s *McpServer) GetFeatureRegistry() resources.FeatureRegistry {
 	return s.featureRegistry
 }
 
+// GetServerCapabilities returns the capabilities the server advertises. Completions are advertised when a
+// prompt argument or resource template variable has a completion provider, and custom methods as experimental
+// capabilities.
 func (s *McpServer) GetServerCapabilities() protocol.ServerCapabilities {
-	return s.serverCapabilities
+	capabilities := s.serverCapabilities
+	if capabilities.Completions == nil && s.advertisesCompletions() {
+		capabilities.Completions = &protocol.CompletionsServerCapability{}
+	}
+	if s.featureRegistry.MethodRegistry == nil {
+		return capabilities
+	}
+	methods := s.featureRegistry.MethodRegistry.ListMethods()
+	if len(methods) == 0 {
+		return capabilities
+	}
+
+	experimental := make(map[string]interface{}, len(capabilities.Experimental)+len(methods))
+	for name, value := range capabilities.Experimental {
+		experimental[name] = value
+	}
+	for _, method := range methods {
+		if _, ok := experimental[method]; !ok {
+			experimental[method] = map[string]interface{}{}
+		}
+	}
+	capabilities.Experimental = experimental
+	return capabilities
+}
+
+// refreshCompletions looks through the prompt and resource registries for completion providers, so that
+// initialize does not list them on every session.
+func (s *McpServer) refreshCompletions(ctx context.Context) {
+	s.completionsVersion.Store(resources.CompletionsVersion(s.featureRegistry))
+	s.hasCompletions.Store(resources.HasCompletions(ctx, s.featureRegistry))
+}
+
+// advertisesCompletions reports whether a completion provider is registered, looking again if the registries
+// changed since the last look
+func (s *McpServer) advertisesCompletions() bool {
+	if resources.CompletionsVersion(s.featureRegistry) != s.completionsVersion.Load() {
+		s.refreshCompletions(context.Background())
+	}
+	return s.hasCompletions.Load()
 }
 
 // GetActorSystem returns the actor system used by the server
@@ -151,6 +306,14 @@ func WithResourceRegistry(registry resources.ResourceRegistry) McpServerOption {
 	}
 }
 
+// WithMethodRegistry sets the registry of custom methods, such as vendor extensions, served alongside the
+// methods of the spec. Its methods are advertised under the experimental server capabilities.
+func WithMethodRegistry(registry resources.MethodRegistry) McpServerOption {
+	return func(s *McpServer) {
+		s.featureRegistry.MethodRegistry = registry
+	}
+}
+
 func WithExecutors(executors *executors.Executors) McpServerOption {
 	return func(s *McpServer) {
 		s.executors = executors
@@ -169,12 +332,103 @@ func WithTraceHandler(th config.TraceHandler) McpServerOption {
 	}
 }
 
+// WithRateLimitStore sets the store used for rate limit counters. By default a Redis store is used when
+// Redis is configured, and an in-memory store (which only limits per node) otherwise.
+func WithRateLimitStore(store ratelimit.Store) McpServerOption {
+	return func(s *McpServer) {
+		s.rateLimitStore = store
+	}
+}
+
+// WithAuditSink adds a custom audit sink. It is used alongside the file sink from the audit config, if any.
+func WithAuditSink(sink audit.Sink) McpServerOption {
+	return func(s *McpServer) {
+		s.auditSink = sink
+	}
+}
+
+// WithMetricsRecorder sets the recorder that receives the server's measurements, replacing the Prometheus
+// recorder created when metrics are enabled. If the recorder implements http.Handler and metrics are
+// enabled, it is served at the metrics path.
+func WithMetricsRecorder(recorder metrics.Recorder) McpServerOption {
+	return func(s *McpServer) {
+		s.metrics = recorder
+	}
+}
+
+// WithLogger sets the logger used by the server, its handlers, executors and actors. Secrets are redacted
+// from everything logged through it. By default JSON is logged to stdout at the info level.
+func WithLogger(l *slog.Logger) McpServerOption {
+	return func(s *McpServer) {
+		s.logger = slog.New(redact.NewHandler(l.Handler()))
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider spans are created with. By default the global
+// provider is used.
+func WithTracerProvider(tp trace.TracerProvider) McpServerOption {
+	return func(s *McpServer) {
+		s.tracerProvider = tp
+	}
+}
+
+// WithSpanExporter exports the server's spans with exporter, through a tracer provider owned by the server
+// that batches spans and is flushed when the server stops
+func WithSpanExporter(exporter sdktrace.SpanExporter) McpServerOption {
+	return func(s *McpServer) {
+		s.sdkTracerProvider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+		s.tracerProvider = s.sdkTracerProvider
+	}
+}
+
+// WithSessionHooks sets the callbacks run as sessions are created, initialized and closed, e.g. to allocate
+// and free per-session resources. OnInitialized can reject a session by returning an error.
+func WithSessionHooks(hooks config.SessionHooks) McpServerOption {
+	return func(s *McpServer) {
+		s.sessionHooks = &hooks
+	}
+}
+
+// WithMethodInterceptors adds interceptors wrapping the handling of every request by the executors, e.g. for
+// logging, caching or authorization. They run in the order they are added, the first one outermost, and apply
+// to executors set with WithExecutors too.
+func WithMethodInterceptors(interceptors ...config.MethodInterceptor) McpServerOption {
+	return func(s *McpServer) {
+		s.methodInterceptors = append(s.methodInterceptors, interceptors...)
+	}
+}
+
+// WithToolInterceptors adds interceptors wrapping every tool call, whichever tool registry is used, which see
+// the tool's name and arguments. They run in the order they are added, the first one outermost.
+func WithToolInterceptors(interceptors ...config.ToolInterceptor) McpServerOption {
+	return func(s *McpServer) {
+		s.toolInterceptors = append(s.toolInterceptors, interceptors...)
+	}
+}
+
 // NewMcpServer creates a new MCP server
 func NewMcpServer(cfg *config.ServerConfig, options ...McpServerOption) (*McpServer, error) {
 	if cfg == nil {
 		cfg = config.DefaultConfig()
 	}
 
+	// Create the server
+	server := &McpServer{
+		config:                cfg,
+		enableSSE:             true, // Default to prefer SSE when available
+		serverCapabilities:    cfg.ServerCapabilities,
+		resourceSubscriptions: resources.NewSubscriptions(),
+	}
+
+	// Apply options
+	for _, opt := range options {
+		opt(server)
+	}
+
+	if server.logger == nil {
+		server.logger = slog.New(redact.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	}
+
 	opts := make([]actor.Option, 0)
 	switch cfg.Clustering.Type {
 	case config.ClusteringTypeK8S:
@@ -196,6 +450,7 @@ func NewMcpServer(cfg *config.ServerConfig, options ...McpServerOption) (*McpSer
 			WithKinds(
 				&actors2.DeathWatcher{},
 				&utils.StateMachineActor{},
+				&actors2.McpSessionActor{},
 				&actors2.ClientConnectionActor{},
 			).
 			WithDiscoveryPort(cfg.Clustering.GossipPort).
@@ -209,8 +464,7 @@ func NewMcpServer(cfg *config.ServerConfig, options ...McpServerOption) (*McpSer
 		opts = append(opts, actor.WithRemote(remote.NewConfig(cfg.Clustering.NodeHost, cfg.Clustering.RemotingPort)))
 	}
 
-	opts = append(opts, actor.WithLogger(logger.NewSlog(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelWarn}).WithGroup("mcp"))))
-	opts = append(opts, actor.WithLogger(logger.NewSlog(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}).WithGroup("mcp"))))
+	opts = append(opts, actor.WithLogger(logger.NewSlog(server.logger.Handler().WithGroup("mcp"))))
 	opts = append(opts, actor.WithPassivationDisabled())
 
 	// Create the actor system
@@ -218,42 +472,72 @@ func NewMcpServer(cfg *config.ServerConfig, options ...McpServerOption) (*McpSer
 	if err != nil {
 		return nil, fmt.Errorf("failed to create actor system: %w", err)
 	}
+	server.actorSystem = actorSystem
 
-	// Create the server
-	server := &McpServer{
-		config:             cfg,
-		actorSystem:        actorSystem,
-		enableSSE:          true, // Default to prefer SSE when available
-		serverCapabilities: cfg.ServerCapabilities,
-	}
-
-	// Apply options
-	for _, opt := range options {
-		opt(server)
+	if server.metrics == nil {
+		if cfg.Metrics.Enable {
+			recorder, err := metrics.NewPrometheusRecorder(cfg.Metrics.Namespace, metrics.NewRegistry())
+			if err != nil {
+				return nil, err
+			}
+			server.metrics = recorder
+		} else {
+			server.metrics = metrics.NopRecorder{}
+		}
 	}
 
 	if server.executors == nil {
 		server.executors = executors.DefaultExecutors(server, nil)
 	}
+	server.executors.Interceptors = append(server.executors.Interceptors, server.methodInterceptors...)
 
 	// Create a default static tool registry if none provided
 	if server.featureRegistry.ToolRegistry == nil {
 		server.featureRegistry.ToolRegistry = resources.NewStaticToolRegistry()
-		slog.Info("Using default static tool registry")
+		server.logger.Info("Using default static tool registry")
 	}
 
 	// Create a default static prompt registry if none provided
 	if server.featureRegistry.PromptRegistry == nil {
 		server.featureRegistry.PromptRegistry = resources.NewStaticPromptRegistry()
-		slog.Info("Using default static prompt registry")
+		server.logger.Info("Using default static prompt registry")
 	}
 
 	// Create a default static resource registry if none provided
 	if server.featureRegistry.ResourceRegistry == nil {
 		server.featureRegistry.ResourceRegistry = resources.NewStaticResourceRegistry()
-		slog.Info("Using default static resource registry")
+		server.logger.Info("Using default static resource registry")
+	}
+
+	if cfg.Audit.FilePath != "" {
+		fileSink, err := audit.NewFileSink(cfg.Audit.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		server.auditFile = fileSink
+		if server.auditSink != nil {
+			server.auditSink = audit.MultiSink{fileSink, server.auditSink}
+		} else {
+			server.auditSink = fileSink
+		}
+	}
+
+	if cfg.Redis != nil && len(cfg.Redis.Addresses) > 0 {
+		server.redisClient = redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs:    cfg.Redis.Addresses,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+	}
+
+	if cfg.RateLimit.Enable {
+		server.rateLimiter = ratelimit.NewLimiter(cfg.RateLimit, server.defaultRateLimitStore())
 	}
 
+	server.sessionStore = server.defaultSessionStore()
+
+	server.setupHealth()
+
 	// Create the MCP handler
 	server.Handlers = httphandlers.NewMCPHandler(cfg, actorSystem, server)
 
@@ -263,6 +547,21 @@ func NewMcpServer(cfg *config.ServerConfig, options ...McpServerOption) (*McpSer
 	return server, nil
 }
 
+func (s *McpServer) defaultRateLimitStore() ratelimit.Store {
+	if s.rateLimitStore != nil {
+		return s.rateLimitStore
+	}
+
+	if s.redisClient != nil {
+		return ratelimit.NewRedisStore(s.redisClient, "")
+	}
+
+	if s.config.Clustering.Type != "" {
+		config.Logger(s).Warn("rate limiting is using an in-memory store, limits will be enforced per node rather than across the cluster")
+	}
+	return ratelimit.NewMemoryStore()
+}
+
 // // RegisterHandlers registers MCP Handlers on the provided ServeMux
 // // This should be called before applying any middleware to the mux
 // func (s *McpServer) RegisterHandlers(mux *http.ServeMux) {
@@ -302,6 +601,8 @@ func (s *McpServer) RegisterHandlers(mux *http.ServeMux) {
 			s.Handlers.HandleMCPPost(w, r)
 		case http.MethodGet:
 			s.Handlers.HandleSSEGet(w, r)
+		case http.MethodDelete:
+			s.Handlers.HandleMCPDelete(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -319,6 +620,39 @@ func (s *McpServer) RegisterHandlers(mux *http.ServeMux) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
+
+	for path, handler := range s.healthEndpoints() {
+		mux.Handle(path, handler)
+	}
+
+	if handler := s.metricsHandler(); handler != nil {
+		mux.Handle(s.metricsPath(), handler)
+	}
+
+	if s.adminPath != "" {
+		prefix := strings.TrimSuffix(s.adminPath, "/")
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, s.AdminRouter(s.adminAuthorizer)))
+	}
+}
+
+// metricsHandler returns the handler serving metrics, or nil if metrics are disabled or the recorder
+// cannot serve them
+func (s *McpServer) metricsHandler() http.Handler {
+	if !s.config.Metrics.Enable {
+		return nil
+	}
+	handler, ok := s.metrics.(http.Handler)
+	if !ok {
+		return nil
+	}
+	return handler
+}
+
+func (s *McpServer) metricsPath() string {
+	if s.config.Metrics.Path == "" {
+		return "/metrics"
+	}
+	return s.config.Metrics.Path
 }
 
 // Start starts the MCP server
@@ -334,11 +668,11 @@ func (s *McpServer) Start(ctx context.Context) error {
 			if mux, ok := s.httpServer.Handler.(*http.ServeMux); ok {
 				// Auto-register Handlers on the mux
 				s.RegisterHandlers(mux)
-				slog.InfoContext(ctx, "Automatically registered MCP Handlers on provided ServeMux")
+				config.Logger(s).InfoContext(ctx, "Automatically registered MCP Handlers on provided ServeMux")
 			}
 		}
 		s.createdServer = false
-		slog.InfoContext(ctx, "Using user-provided HTTP server")
+		config.Logger(s).InfoContext(ctx, "Using user-provided HTTP server")
 	} else if s.userRouter == nil {
 		// Create our own server with our handler
 		s.httpServer = &http.Server{
@@ -346,7 +680,13 @@ func (s *McpServer) Start(ctx context.Context) error {
 			Handler: s.internalHandler,
 		}
 		s.createdServer = true
-		slog.InfoContext(ctx, "Created internal HTTP server", "addr", addr)
+		config.Logger(s).InfoContext(ctx, "Created internal HTTP server", "addr", addr)
+
+		if s.config.HTTP.TLS.Enable {
+			if err := s.configureTLS(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Start the actor system
@@ -365,53 +705,215 @@ func (s *McpServer) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to start root actor: %w", err)
 	}
 
+	s.unregisterRelocation = actors2.RegisterRelocationServer(s.actorSystem, s)
+	s.watchClusterMembers(ctx)
+	s.refreshCompletions(ctx)
+	s.watchRegistries(ctx)
+
 	// Only start the HTTP server if we created it internally
 	if s.createdServer {
-		slog.InfoContext(ctx, "Starting HTTP server", "addr", addr)
+		config.Logger(s).InfoContext(ctx, "Starting HTTP server", "addr", addr)
+		// Listen before returning, so the server accepts connections as soon as Start returns
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+
 		// Start HTTP server
 		go func() {
 			var err error
 			if s.config.HTTP.TLS.Enable {
-				err = s.httpServer.ListenAndServeTLS(s.config.HTTP.TLS.CertFile, s.config.HTTP.TLS.KeyFile)
+				// Certificates are served from the TLS config so they can be reloaded
+				err = s.httpServer.ServeTLS(ln, "", "")
 			} else {
-				err = s.httpServer.ListenAndServe()
+				err = s.httpServer.Serve(ln)
 			}
 			if err != nil && err != http.ErrServerClosed {
-				slog.ErrorContext(ctx, "HTTP server error", "error", err)
+				config.Logger(s).ErrorContext(ctx, "HTTP server error", "error", err)
 			}
 		}()
 	} else {
-		slog.InfoContext(ctx, "HTTP server will be started externally")
+		config.Logger(s).InfoContext(ctx, "HTTP server will be started externally")
 	}
 
-	slog.InfoContext(ctx, "MCP server started", "address", addr)
+	config.Logger(s).InfoContext(ctx, "MCP server started", "address", addr)
 	return nil
 }
 
-// Stop stops the MCP server
+// Stop drains and stops the MCP server. The node reports itself not ready and turns away new sessions, then
+// in-flight requests are given up to the configured drain timeout to finish. Sessions are handed off to the
+// session store, if there is one, and SSE clients are told to reconnect, before the HTTP server and the
+// actor system stop.
 func (s *McpServer) Stop(ctx context.Context) {
-	// Stop HTTP server
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	drainCtx, cancelDrain := context.WithTimeout(ctx, s.drainTimeout())
+	config.Logger(s).InfoContext(ctx, "Draining MCP server")
+	s.drain(drainCtx)
+	cancelDrain()
+
+	ctx, cancel := context.WithTimeout(ctx, s.stopTimeout())
 	defer cancel()
 
-	// Stop actor system - in goakt v3.2.0 we need to use a different approach
-	// since Shutdown is not directly available
-	slog.InfoContext(ctx, "Stopping actor system")
+	// Only stop the HTTP server if we created it internally
+	if s.httpServer != nil && s.createdServer {
+		config.Logger(s).InfoContext(ctx, "Stopping HTTP Server")
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			config.Logger(s).Error("Failed to shutdown HTTP server", "err", err)
+		}
+	}
+
+	if s.stopClusterWatch != nil {
+		s.stopClusterWatch()
+	}
+
+	if s.stopRegistryWatch != nil {
+		s.stopRegistryWatch()
+	}
+
+	if s.unregisterRelocation != nil {
+		s.unregisterRelocation()
+	}
+
+	config.Logger(s).InfoContext(ctx, "Stopping actor system")
 	if s.actorSystem != nil {
 		s.actorMutex.Lock()
 		if err := s.actorSystem.Stop(ctx); err != nil {
-			slog.Error("Failed to shutdown actor system", "err", err)
+			config.Logger(s).Error("Failed to shutdown actor system", "err", err)
 		}
 		s.actorMutex.Unlock()
 	}
 
-	// Only stop the HTTP server if we created it internally
-	if s.httpServer != nil && s.createdServer {
-		slog.InfoContext(ctx, "Stopping HTTP Server")
-		if err := s.httpServer.Shutdown(ctx); err != nil {
-			slog.Error("Failed to shutdown HTTP server", "err", err)
+	if s.stopTLSReload != nil {
+		s.stopTLSReload()
+	}
+
+	if s.sdkTracerProvider != nil {
+		if err := s.sdkTracerProvider.Shutdown(ctx); err != nil {
+			config.Logger(s).Error("Failed to flush spans", "err", err)
+		}
+	}
+
+	if s.auditFile != nil {
+		if err := s.auditFile.Close(); err != nil {
+			config.Logger(s).Error("Failed to close audit log", "err", err)
+		}
+	}
+
+	if s.redisClient != nil {
+		if err := s.redisClient.Close(); err != nil {
+			config.Logger(s).Error("Failed to close Redis client", "err", err)
+		}
+	}
+}
+
+// configureTLS sets up the internal HTTP server's TLS config, including client
+// certificate verification and certificate reloading
+func (s *McpServer) configureTLS() error {
+	reloader, err := newCertReloader(s.config.HTTP.TLS)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := reloader.tlsConfig()
+	if err != nil {
+		return err
+	}
+	s.httpServer.TLSConfig = tlsConfig
+
+	if s.config.HTTP.TLS.ReloadInterval > 0 {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		s.stopTLSReload = cancel
+		go reloader.watch(watchCtx, s.config.HTTP.TLS.ReloadInterval, config.Logger(s))
+	}
+
+	return nil
+}
+
+// watchRegistries runs the watchers of the tool, prompt and resource registries, for those that have one, until
+// the server stops. Each node watches its own registries, so changes are sent to the sessions on this node.
+func (s *McpServer) watchRegistries(ctx context.Context) {
+	var watchers []resources.Watcher
+	for _, registry := range []interface{}{
+		s.featureRegistry.ToolRegistry,
+		s.featureRegistry.PromptRegistry,
+		s.featureRegistry.ResourceRegistry,
+	} {
+		if watcher, ok := registry.(resources.Watcher); ok {
+			watchers = append(watchers, watcher)
 		}
 	}
+	if len(watchers) == 0 {
+		return
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	s.stopRegistryWatch = cancel
+
+	notify := func(ctx context.Context, method string, params interface{}) {
+		if method == "notifications/prompts/list_changed" || method == "notifications/resources/list_changed" {
+			s.refreshCompletions(ctx)
+		}
+		if method == "notifications/resources/updated" {
+			if _, err := s.notifyResourceSubscribers(ctx, params); err != nil {
+				config.Logger(s).WarnContext(ctx, "Failed to send registry notification", "method", method, "err", err)
+			}
+			return
+		}
+		if _, err := s.NotifyLocalSessions(ctx, method, params); err != nil {
+			config.Logger(s).WarnContext(ctx, "Failed to send registry notification", "method", method, "err", err)
+		}
+	}
+	for _, watcher := range watchers {
+		go func() {
+			if err := watcher.Watch(watchCtx, notify); err != nil {
+				config.Logger(s).WarnContext(ctx, "Stopped watching registry", "err", err)
+			}
+		}()
+	}
+}
+
+// watchClusterMembers keeps the cluster members count and metric up to date from the actor system's membership
+// events. A server that is not clustered always reports a single member.
+func (s *McpServer) watchClusterMembers(ctx context.Context) {
+	s.clusterMembers.Store(1)
+	s.metrics.ClusterMembers(1)
+	if !s.actorSystem.InCluster() {
+		return
+	}
+
+	subscriber, err := s.actorSystem.Subscribe()
+	if err != nil {
+		config.Logger(s).WarnContext(ctx, "failed to subscribe to cluster events, cluster members will not be reported", "err", err)
+		return
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	s.stopClusterWatch = func() {
+		cancel()
+		_ = s.actorSystem.Unsubscribe(subscriber)
+	}
+
+	go func() {
+		members := 1
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+				for event := range subscriber.Iterator() {
+					switch event.Payload().(type) {
+					case *goaktpb.NodeJoined:
+						members++
+					case *goaktpb.NodeLeft:
+						members--
+					}
+				}
+				s.clusterMembers.Store(int64(members))
+				s.metrics.ClusterMembers(members)
+			}
+		}
+	}()
 }
 
 // ServeHTTP implements http.Handler, allowing the MCP server to be used directly as a handler
@@ -428,7 +930,7 @@ func (s *McpServer) createHTTPHandler() http.Handler {
 	if s.userRouter != nil {
 		// Use the router provided by the user
 		r = s.userRouter
-		slog.Info("Using user-provided chi router")
+		config.Logger(s).Info("Using user-provided chi router")
 	} else {
 		// Create a new router with our default middleware
 		r = chi.NewRouter()
@@ -443,7 +945,7 @@ func (s *McpServer) createHTTPHandler() http.Handler {
 		if s.config.HTTP.CORS.Enable {
 			corsOptions := cors.Options{
 				AllowedOrigins:   s.config.HTTP.CORS.AllowedOrigins,
-				AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+				AllowedMethods:   []string{"GET", "POST", "DELETE", "OPTIONS"},
 				AllowedHeaders:   s.config.HTTP.CORS.AllowedHeaders,
 				ExposedHeaders:   s.config.HTTP.CORS.ExposedHeaders,
 				AllowCredentials: s.config.HTTP.CORS.AllowCredentials,
@@ -466,6 +968,7 @@ func (s *McpServer) createHTTPHandler() http.Handler {
 		r.Use(s.authHandlerMiddleware)
 		r.Post("/", s.Handlers.HandleMCPPost)
 		r.Get("/", s.Handlers.HandleSSEGet)
+		r.Delete("/", s.Handlers.HandleMCPDelete)
 	})
 
 	if s.config.BackwardCompatible20241105 {
@@ -505,6 +1008,21 @@ func (s *McpServer) createHTTPHandler() http.Handler {
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Liveness and readiness endpoints
+	for path, handler := range s.healthEndpoints() {
+		r.Method(http.MethodGet, path, handler)
+	}
+
+	// Metrics endpoint
+	if handler := s.metricsHandler(); handler != nil {
+		r.Method(http.MethodGet, s.metricsPath(), handler)
+	}
+
+	// Admin API
+	if s.adminPath != "" {
+		r.Mount(s.adminPath, s.AdminRouter(s.adminAuthorizer))
+	}
+
 	return r
 }
 
@@ -520,7 +1038,7 @@ func (s *McpServer) loggingMiddleware(next http.Handler) http.Handler {
 		// Log the request
 		ctx := r.Context()
 		latency := time.Since(start)
-		slog.InfoContext(ctx, "HTTP request",
+		config.Logger(s).InfoContext(ctx, "HTTP request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", ww.Status(),
@@ -548,7 +1066,8 @@ func (s *McpServer) jsonRpcErrorMiddleware(next http.Handler) http.Handler {
 				}
 
 				// Log the panic
-				slog.Error("Panic in handler", "error", err, "stack", string(debug.Stack()))
+				errorId := redact.NewErrorId()
+				config.Logger(s).Error("Panic in handler", "error_id", errorId, "error", redact.String(fmt.Sprint(err)), "stack", string(debug.Stack()))
 
 				// Convert to JSON-RPC error
 				w.Header().Set("Content-Type", "application/json")
@@ -556,7 +1075,10 @@ func (s *McpServer) jsonRpcErrorMiddleware(next http.Handler) http.Handler {
 
 				// Create a generic JSON-RPC internal error
 				// Since we don't have the request ID here, we'll use nil
-				internalError := protocol.NewInternalError(fmt.Sprintf("Internal server error: %v", err), nil)
+				internalError := protocol.NewInternalError(redact.String(fmt.Sprintf("Internal server error: %v", err)), nil)
+				if s.config.Errors.Production {
+					internalError = protocol.NewOpaqueInternalError(errorId, nil)
+				}
 				response := internalError.ToResponse()
 
 				responseJSON, marshalErr := json.Marshal(response)
@@ -580,7 +1102,18 @@ func (s *McpServer) jsonRpcErrorMiddleware(next http.Handler) http.Handler {
 func (s *McpServer) authHandlerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if s.authHandler != nil {
-			ai := s.authHandler.ExtractAuth(r)
+			var ai auth.AuthInfo
+			if verifier, ok := s.authHandler.(config.AuthVerifier); ok {
+				var err error
+				ai, err = verifier.VerifyAuth(r)
+				if err != nil {
+					s.auditAuthFailure(r, err)
+					writeUnauthorized(w, err)
+					return
+				}
+			} else {
+				ai = s.authHandler.ExtractAuth(r)
+			}
 			if ai != nil {
 				ctx := auth.SetAuthInfo(r.Context(), ai)
 				r = r.WithContext(ctx)
@@ -590,20 +1123,76 @@ func (s *McpServer) authHandlerMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// auditAuthFailure records a request whose credentials were rejected
+func (s *McpServer) auditAuthFailure(r *http.Request, err error) {
+	sessionId := r.Header.Get("Mcp-Session-Id")
+	if sessionId == "" {
+		sessionId = r.URL.Query().Get("sessionId")
+	}
+
+	audit.Emit(r.Context(), s.auditSink, &audit.Record{
+		Event:      audit.EventAuthFailure,
+		SessionId:  sessionId,
+		TraceId:    utils.GetTraceId(r.Context()),
+		Outcome:    audit.OutcomeDenied,
+		Error:      err.Error(),
+		RemoteAddr: r.RemoteAddr,
+	})
+}
+
+// writeUnauthorized rejects a request whose credentials failed verification
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	body, _ := json.Marshal(protocol.NewUnauthorizedError(err.Error(), nil).ToResponse())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	_, _ = w.Write(body)
+}
+
+// traceHandlerMiddleware continues the caller's W3C trace, starts the span covering the HTTP request and
+// sets the request's trace id, taken from the trace handler if one is configured
 func (s *McpServer) traceHandlerMiddleware(next http.Handler) http.Handler {
+	tracer := tracing.Tracer(s.tracerProvider)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// TODO I think we can actually move this check out to the outer, it's a waste to run every time.
+		ctx := tracing.ExtractHTTP(r.Context(), r.Header)
+		ctx, span := tracer.Start(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		if sessionId := r.Header.Get("Mcp-Session-Id"); sessionId != "" {
+			span.SetAttributes(tracing.AttrSessionId.String(sessionId))
+		}
+
 		var traceId string
 		if s.traceHandler != nil {
 			traceId = s.traceHandler.ExtractTraceId(r)
 		}
 
-		if traceId != "" {
-			traceId = utils.MustGenerateSecureID(20)
+		if traceId == "" {
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				traceId = sc.TraceID().String()
+			} else {
+				traceId = utils.MustGenerateSecureID(20)
+			}
 		}
 
-		r = r.WithContext(utils.SetTraceId(r.Context(), traceId))
+		r = r.WithContext(utils.SetTraceId(ctx, traceId))
 
-		next.ServeHTTP(w, r)
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
 	})
 }
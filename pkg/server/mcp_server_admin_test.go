@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+// adminRequest sends a request to the admin API, authenticated with token
+func adminRequest(server *McpServer, method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAPI(t *testing.T) {
+	ctx := context.Background()
+	server, err := NewMcpServer(config.DefaultConfig(), WithRouter(chi.NewRouter()), WithAdmin("/admin", AdminBearerToken("secret")))
+	require.NoError(t, err)
+	require.NoError(t, server.Start(ctx))
+	defer server.Stop(ctx)
+
+	w := postMessage(t, server, "", protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: protocol.InitializeParams{
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sessionId := w.Header().Get("Mcp-Session-Id")
+
+	t.Run("rejects requests without the token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, adminRequest(server, http.MethodGet, "/admin/sessions", "", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, adminRequest(server, http.MethodGet, "/admin/sessions", "wrong", "").Code)
+	})
+
+	t.Run("lists sessions", func(t *testing.T) {
+		w := adminRequest(server, http.MethodGet, "/admin/sessions", "secret", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var body struct {
+			Sessions []SessionInfo `json:"sessions"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Sessions, 1)
+		session := body.Sessions[0]
+		assert.Equal(t, sessionId, session.SessionId)
+		assert.Equal(t, "initialized", session.State)
+		assert.Equal(t, protocol.ProtocolVersion20250326, session.ProtocolVersion)
+		assert.Equal(t, protocol.ClientInfo{Name: "test-client", Version: "1.0.0"}, session.ClientInfo)
+		assert.WithinDuration(t, time.Now(), session.LastActivity, time.Minute)
+	})
+
+	t.Run("shows one session", func(t *testing.T) {
+		w := adminRequest(server, http.MethodGet, "/admin/sessions/"+sessionId, "secret", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var session SessionInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+		assert.Equal(t, sessionId, session.SessionId)
+
+		assert.Equal(t, http.StatusNotFound, adminRequest(server, http.MethodGet, "/admin/sessions/unknown", "secret", "").Code)
+	})
+
+	t.Run("broadcasts notifications", func(t *testing.T) {
+		w := adminRequest(server, http.MethodPost, "/admin/notifications", "secret", `{"method": "notifications/tools/list_changed"}`)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		assert.JSONEq(t, `{"sessions": 1}`, w.Body.String())
+
+		w = adminRequest(server, http.MethodPost, "/admin/notifications", "secret", `{"method": "tools/list"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("terminates a session", func(t *testing.T) {
+		w := adminRequest(server, http.MethodDelete, "/admin/sessions/"+sessionId+"?reason=abuse", "secret", "")
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		require.Eventually(t, func() bool {
+			_, err := server.GetSession(ctx, sessionId)
+			return errors.Is(err, ErrSessionNotFound)
+		}, time.Second, 10*time.Millisecond)
+		assert.ErrorIs(t, server.TerminateSession(ctx, sessionId, ""), ErrSessionNotFound)
+	})
+}
+
+func TestBroadcastNotificationValidatesMethod(t *testing.T) {
+	server := &McpServer{config: config.DefaultConfig()}
+	_, err := server.BroadcastNotification(context.Background(), "ping", nil)
+	assert.ErrorIs(t, err, resources.ErrInvalidParams)
+}
+
+func TestResourceUpdatedGoesToSubscribers(t *testing.T) {
+	ctx := context.Background()
+	registry := resources.NewStaticResourceRegistry()
+	require.NoError(t, registry.RegisterResource(resources.Resource{URI: "file:///notes.txt", Name: "notes"}, nil))
+
+	server, err := NewMcpServer(config.DefaultConfig(), WithRouter(chi.NewRouter()), WithResourceRegistry(registry))
+	require.NoError(t, err)
+	require.NoError(t, server.Start(ctx))
+	defer server.Stop(ctx)
+
+	initialize := func() string {
+		w := postMessage(t, server, "", protocol.JSONRPCMessage{
+			JSONRPC: "2.0",
+			ID:      1,
+			Method:  "initialize",
+			Params: protocol.InitializeParams{
+				ProtocolVersion: protocol.ProtocolVersion20250326,
+				ClientInfo:      protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+			},
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Header().Get("Mcp-Session-Id")
+	}
+	subscriber := initialize()
+	initialize()
+
+	w := postMessage(t, server, subscriber, protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "resources/subscribe",
+		Params:  map[string]string{"uri": "file:///notes.txt"},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{subscriber}, registry.GetSubscribers("file:///notes.txt"))
+
+	sent, err := server.notifyResourceSubscribers(ctx, map[string]string{"uri": "file:///notes.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent, "only the subscribed session should be notified")
+
+	sent, err = server.notifyResourceSubscribers(ctx, map[string]string{"uri": "file:///other.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	require.NoError(t, server.TerminateSession(ctx, subscriber, ""))
+	require.Eventually(t, func() bool {
+		return len(registry.GetSubscribers("file:///notes.txt")) == 0
+	}, time.Second, 10*time.Millisecond, "an ended session should be unsubscribed")
+	sent, err = server.notifyResourceSubscribers(ctx, map[string]string{"uri": "file:///notes.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
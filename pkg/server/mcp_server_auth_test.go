@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/traego/scaled-mcp/pkg/audit"
 	"github.com/traego/scaled-mcp/pkg/auth"
 	"github.com/traego/scaled-mcp/pkg/protocol"
 	"github.com/traego/scaled-mcp/pkg/resources"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
@@ -51,6 +53,8 @@ func (t AuthTestHandler) Deserialize(bytes []byte) (auth.AuthInfo, error) {
 }
 
 var _ config.AuthHandler = (*AuthTestHandler)(nil)
+var _ config.AuthHandler = (*auth.APIKeyAuthHandler)(nil)
+var _ config.AuthVerifier = (*auth.APIKeyAuthHandler)(nil)
 
 // TestMcpServerWithAuth tests the auth flow through pattern
 func TestMcpServerWithAuth(t *testing.T) {
@@ -121,3 +125,79 @@ func TestMcpServerWithAuth(t *testing.T) {
 		mcpServer.Stop(ctx)
 	})
 }
+
+// TestAuthVerifierRejectsInvalidCredentials checks that handlers implementing AuthVerifier can reject requests
+func TestAuthVerifierRejectsInvalidCredentials(t *testing.T) {
+	manager := auth.NewAPIKeyManager(auth.NewMemoryAPIKeyStore())
+	key, _, err := manager.Mint(context.Background(), auth.MintRequest{PrincipalId: "svc"})
+	require.NoError(t, err)
+
+	s := &McpServer{authHandler: auth.NewAPIKeyAuthHandler(manager)}
+	handler := s.authHandlerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := "anonymous"
+		if ai := auth.GetAuthInfo(r.Context()); ai != nil {
+			principal = ai.GetPrincipalId()
+		}
+		_, _ = w.Write([]byte(principal))
+	}))
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantBody   string
+	}{
+		{"valid key", key, http.StatusOK, "svc"},
+		{"no key", "", http.StatusOK, "anonymous"},
+		{"invalid key", "smk_bogus_key", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.key != "" {
+				r.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, w.Body.String(), fmt.Sprintf("%d", protocol.ErrUnauthorized))
+			} else {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthFailureIsAudited(t *testing.T) {
+	var records []*audit.Record
+	sink := audit.SinkFunc(func(ctx context.Context, r *audit.Record) error {
+		records = append(records, r)
+		return nil
+	})
+
+	manager := auth.NewAPIKeyManager(auth.NewMemoryAPIKeyStore())
+	s := &McpServer{authHandler: auth.NewAPIKeyAuthHandler(manager)}
+	WithAuditSink(sink)(s)
+
+	handler := s.authHandlerMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("X-API-Key", "smk_bogus_key")
+	r.Header.Set("Mcp-Session-Id", "session-1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Len(t, records, 1)
+	assert.Equal(t, audit.EventAuthFailure, records[0].Event)
+	assert.Equal(t, audit.OutcomeDenied, records[0].Outcome)
+	assert.Equal(t, "session-1", records[0].SessionId)
+	assert.Equal(t, r.RemoteAddr, records[0].RemoteAddr)
+	assert.NotEmpty(t, records[0].Error)
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+)
+
+// postMessage posts a JSON-RPC message to the server's MCP endpoint
+func postMessage(t *testing.T, server *McpServer, sessionId string, message protocol.JSONRPCMessage) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(message)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionId != "" {
+		req.Header.Set("Mcp-Session-Id", sessionId)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func newDrainTestServer(t *testing.T, store sessionstore.Store, tool func(ctx context.Context, params map[string]interface{}) (interface{}, error)) *McpServer {
+	t.Helper()
+	registry := resources.NewStaticToolRegistry()
+	require.NoError(t, registry.RegisterTool(protocol.Tool{Name: "work", InputSchema: protocol.InputSchema{}}, tool))
+
+	server, err := NewMcpServer(config.DefaultConfig(), WithRouter(chi.NewRouter()), WithToolRegistry(registry), WithSessionStore(store))
+	require.NoError(t, err)
+	require.NoError(t, server.Start(context.Background()))
+	return server
+}
+
+func TestStopHandsSessionsOff(t *testing.T) {
+	ctx := context.Background()
+	store := sessionstore.NewMemoryStore()
+
+	release := make(chan struct{})
+	slow := func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		<-release
+		return "done", nil
+	}
+	first := newDrainTestServer(t, store, slow)
+
+	w := postMessage(t, first, "", protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: protocol.InitializeParams{
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sessionId := w.Header().Get("Mcp-Session-Id")
+	require.NotEmpty(t, sessionId)
+
+	w = postMessage(t, first, sessionId, protocol.JSONRPCMessage{JSONRPC: "2.0", Method: "notifications/initialized"})
+	require.Less(t, w.Code, http.StatusBadRequest)
+
+	// A tool call is in flight when the server is asked to stop
+	inFlight := make(chan *httptest.ResponseRecorder)
+	go func() {
+		inFlight <- postMessage(t, first, sessionId, protocol.JSONRPCMessage{
+			JSONRPC: "2.0",
+			ID:      2,
+			Method:  "tools/call",
+			Params:  map[string]interface{}{"name": "work", "arguments": map[string]interface{}{}},
+		})
+	}()
+	require.Eventually(t, func() bool { return first.Handlers.InFlight() == 1 }, time.Second, 5*time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		first.Stop(ctx)
+		close(stopped)
+	}()
+
+	// New sessions are turned away while the node drains
+	require.Eventually(t, first.Handlers.Draining, time.Second, 5*time.Millisecond)
+	w = postMessage(t, first, "", protocol.JSONRPCMessage{JSONRPC: "2.0", ID: 3, Method: "initialize"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	code, _ := getReport(t, first, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	// The in-flight call completes before the session is handed off
+	close(release)
+	w = <-inFlight
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "done")
+	<-stopped
+
+	// The client carries on with another node without initializing again
+	second := newDrainTestServer(t, store, slow)
+	defer second.Stop(ctx)
+
+	w = postMessage(t, second, sessionId, protocol.JSONRPCMessage{JSONRPC: "2.0", ID: 4, Method: "tools/list"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response protocol.JSONRPCMessage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Nil(t, response.Error)
+	assert.Contains(t, w.Body.String(), `"work"`)
+}
+
+func TestStopWithoutSessionStore(t *testing.T) {
+	server, err := NewMcpServer(config.DefaultConfig(), WithRouter(chi.NewRouter()))
+	require.NoError(t, err)
+	assert.Nil(t, server.GetSessionStore())
+	require.NoError(t, server.Start(context.Background()))
+
+	w := postMessage(t, server, "", protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: protocol.InitializeParams{
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	start := time.Now()
+	server.Stop(context.Background())
+	assert.Less(t, time.Since(start), 5*time.Second, "an idle server should stop without waiting for the drain timeout")
+	assert.True(t, server.Handlers.Draining())
+}
+
+func TestDefaultSessionStore(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Redis = &config.RedisConfig{Addresses: []string{"localhost:6379"}}
+
+	server, err := NewMcpServer(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, server.GetSessionStore(), "Redis only backs sessions when the in-memory store is disabled")
+
+	cfg.Session.UseInMemory = false
+	server, err = NewMcpServer(cfg)
+	require.NoError(t, err)
+	assert.IsType(t, &sessionstore.RedisStore{}, server.GetSessionStore())
+}
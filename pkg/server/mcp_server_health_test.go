@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/health"
+)
+
+func getReport(t *testing.T, server *McpServer, target string) (int, health.Report) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	var report health.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report), w.Body.String())
+	return w.Code, report
+}
+
+func checkStatuses(report health.Report) map[string]health.Status {
+	statuses := make(map[string]health.Status)
+	for _, c := range report.Checks {
+		statuses[c.Name] = c.Status
+	}
+	return statuses
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	ctx := context.Background()
+	dbUp := true
+	server, err := NewMcpServer(config.DefaultConfig(),
+		WithRouter(chi.NewRouter()),
+		WithLivenessCheck(health.NewCheck("heartbeat", func(context.Context) error { return nil })),
+		WithReadinessCheck(health.NewCheck("database", func(context.Context) error {
+			if !dbUp {
+				return errors.New("connection refused")
+			}
+			return nil
+		})),
+	)
+	require.NoError(t, err)
+
+	// Not ready until the actor system has started, but alive
+	code, report := getReport(t, server, "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFail, checkStatuses(report)[CheckActorSystem])
+
+	code, report = getReport(t, server, "/livez?verbose")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]health.Status{"heartbeat": health.StatusOK}, checkStatuses(report))
+
+	require.NoError(t, server.Start(ctx))
+
+	code, report = getReport(t, server, "/readyz?verbose")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]health.Status{
+		CheckActorSystem: health.StatusOK,
+		CheckRootActor:   health.StatusOK,
+		"database":       health.StatusOK,
+	}, checkStatuses(report))
+
+	code, report = getReport(t, server, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, report.Checks, "checks are only listed when verbose")
+
+	// A failing user check takes the node out of rotation
+	dbUp = false
+	code, report = getReport(t, server, "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFail, checkStatuses(report)["database"])
+	dbUp = true
+
+	server.SetDraining(true)
+	code, report = getReport(t, server, "/readyz?verbose")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFail, checkStatuses(report)[health.DrainingCheck])
+	server.SetDraining(false)
+
+	server.Stop(ctx)
+	code, _ = getReport(t, server, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = getReport(t, server, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestClusterReadinessCheck(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Clustering.Type = config.ClusteringTypeK8S
+	cfg.Health.MinPeers = 1
+	server, err := NewMcpServer(cfg)
+	require.NoError(t, err)
+
+	_, report := getReport(t, server, "/readyz?verbose")
+	require.Contains(t, checkStatuses(report), CheckCluster)
+	assert.Equal(t, health.StatusFail, checkStatuses(report)[CheckCluster])
+	assert.EqualError(t, server.checkCluster(context.Background()), "node has not joined the cluster")
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/auth"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/metrics"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// hookRecorder records the session hooks run, with the session id and principal they were run for
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *hookRecorder) record(ctx context.Context, event string) {
+	principal := ""
+	if ai := auth.GetAuthInfo(ctx); ai != nil {
+		principal = ai.GetPrincipalId()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, fmt.Sprintf("%s %s %s", event, utils.GetSessionId(ctx), principal))
+}
+
+func (h *hookRecorder) Events() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.events...)
+}
+
+func (h *hookRecorder) hooks() config.SessionHooks {
+	return config.SessionHooks{
+		OnSessionCreated: func(ctx context.Context) {
+			h.record(ctx, "created")
+		},
+		OnInitialized: func(ctx context.Context, clientInfo protocol.ClientInfo, capabilities protocol.ClientCapabilities) error {
+			if clientInfo.Name == "blocked-client" {
+				return protocol.NewError(-32001, "client is not allowed", nil, nil)
+			}
+			if capabilities.Roots == nil {
+				return protocol.NewInvalidParamsError("roots capability is required", nil)
+			}
+			h.record(ctx, "initialized "+clientInfo.Name)
+			return nil
+		},
+		OnSessionClosed: func(ctx context.Context, reason string) {
+			h.record(ctx, "closed "+reason)
+		},
+	}
+}
+
+// initializeAs sends an initialize request authenticated as principal
+func initializeAs(t *testing.T, server *McpServer, principal string, clientName string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: protocol.InitializeParams{
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: clientName, Version: "1.0.0"},
+			Capabilities:    protocol.ClientCapabilities{Roots: &protocol.RootsClientCapability{}},
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", principal)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+	return w
+}
+
+func TestSessionHooks(t *testing.T) {
+	ctx := context.Background()
+	recorder := &hookRecorder{}
+	server, err := NewMcpServer(config.DefaultConfig(),
+		WithRouter(chi.NewRouter()),
+		WithAuthHandler(AuthTestHandler{}),
+		WithSessionHooks(recorder.hooks()),
+	)
+	require.NoError(t, err)
+	require.NoError(t, server.Start(ctx))
+	defer server.Stop(ctx)
+
+	t.Run("run as a session starts and is deleted", func(t *testing.T) {
+		w := initializeAs(t, server, "alice", "test-client")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		sessionId := w.Header().Get("Mcp-Session-Id")
+		require.NotEmpty(t, sessionId)
+
+		req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+		req.Header.Set("Mcp-Session-Id", sessionId)
+		req.Header.Set("Authorization", "alice")
+		w = httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		require.Eventually(t, func() bool { return len(recorder.Events()) == 3 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{
+			"created " + sessionId + " alice",
+			"initialized test-client " + sessionId + " alice",
+			"closed " + metrics.SessionEndDeleted + " " + sessionId + " alice",
+		}, recorder.Events())
+
+		w = httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("can reject initialization", func(t *testing.T) {
+		recorder.mu.Lock()
+		recorder.events = nil
+		recorder.mu.Unlock()
+
+		w := initializeAs(t, server, "bob", "blocked-client")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		sessionId := w.Header().Get("Mcp-Session-Id")
+
+		var response protocol.JSONRPCMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotNil(t, response.Error)
+		assert.JSONEq(t, `{"code": -32001, "message": "client is not allowed"}`, mustJSON(t, response.Error))
+
+		require.Eventually(t, func() bool { return len(recorder.Events()) == 2 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{
+			"created " + sessionId + " bob",
+			"closed " + metrics.SessionEndRejected + " " + sessionId + " bob",
+		}, recorder.Events())
+		_, err := server.GetSession(ctx, sessionId)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
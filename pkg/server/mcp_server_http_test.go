@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -84,3 +86,35 @@ func TestMcpServerWithHttpServerOnly(t *testing.T) {
 		_ = server.Shutdown(ctx)
 	})
 }
+
+func TestJsonRpcErrorMiddlewareRedactsPanics(t *testing.T) {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("dial postgres://app:pa55word@db:5432/app failed")
+	})
+
+	serve := func(production bool) map[string]interface{} {
+		cfg := config.DefaultConfig()
+		cfg.Errors.Production = production
+		s := &McpServer{config: cfg}
+
+		w := httptest.NewRecorder()
+		s.jsonRpcErrorMiddleware(panicking).ServeHTTP(w, httptest.NewRequest(http.MethodPost, cfg.HTTP.MCPPath, nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "pa55word")
+
+		var response struct {
+			Error map[string]interface{} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Error
+	}
+
+	rpcErr := serve(false)
+	assert.Contains(t, rpcErr["message"], "postgres://app:[REDACTED]@db:5432/app")
+
+	rpcErr = serve(true)
+	assert.Equal(t, "Internal error", rpcErr["message"])
+	data, ok := rpcErr["data"].(map[string]interface{})
+	require.True(t, ok)
+	assert.NotEmpty(t, data["errorId"])
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+func TestInterceptors(t *testing.T) {
+	ctx := context.Background()
+	registry := resources.NewStaticToolRegistry()
+	require.NoError(t, registry.RegisterTool(resources.NewTool("greet").WithString("name").Add().Build(),
+		func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+			return "hello " + params["name"].(string), nil
+		}))
+
+	var methods []string
+	server, err := NewMcpServer(config.DefaultConfig(),
+		WithRouter(chi.NewRouter()),
+		WithToolRegistry(registry),
+		WithMethodInterceptors(func(ctx context.Context, method string, req *mcppb.JsonRpcRequest, next config.MethodInvoker) (*mcppb.JsonRpcResponse, error) {
+			methods = append(methods, method)
+			return next(ctx, method, req)
+		}),
+		WithToolInterceptors(func(ctx context.Context, name string, args map[string]interface{}, next config.ToolInvoker) (interface{}, error) {
+			args["name"] = "intercepted " + args["name"].(string)
+			return next(ctx, name, args)
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, server.Start(ctx))
+	defer server.Stop(ctx)
+
+	w := postMessage(t, server, "", protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: protocol.InitializeParams{
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sessionId := w.Header().Get("Mcp-Session-Id")
+
+	w = postMessage(t, server, sessionId, protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/call",
+		Params:  map[string]interface{}{"name": "greet", "arguments": map[string]interface{}{"name": "alice"}},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "hello intercepted alice")
+	assert.Equal(t, []string{"tools/call"}, methods, "initialize is handled by the session, not the executors")
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	server, err := NewMcpServer(config.DefaultConfig(), WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "Using default static tool registry", "server should log through the configured logger")
+
+	server.GetLogger().Info("connecting", "dsn", "postgres://user:pass@db/app")
+	assert.NotContains(t, buf.String(), "user:pass", "the configured logger should redact secrets")
+	assert.Same(t, server.GetLogger(), config.Logger(server))
+}
+
+func TestDefaultLogger(t *testing.T) {
+	server, err := NewMcpServer(config.DefaultConfig())
+	require.NoError(t, err)
+	assert.NotNil(t, server.GetLogger())
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/resources"
+)
+
+func TestCustomMethods(t *testing.T) {
+	ctx := context.Background()
+	methods := resources.NewStaticMethodRegistry()
+	require.NoError(t, methods.RegisterPrefix("acme/", func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return map[string]string{"handled": method}, nil
+	}))
+
+	server, err := NewMcpServer(config.DefaultConfig(), WithRouter(chi.NewRouter()), WithMethodRegistry(methods))
+	require.NoError(t, err)
+	require.NoError(t, server.Start(ctx))
+	defer server.Stop(ctx)
+
+	w := postMessage(t, server, "", protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: protocol.InitializeParams{
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sessionId := w.Header().Get("Mcp-Session-Id")
+
+	var initialized struct {
+		Result protocol.InitializeResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &initialized))
+	assert.Contains(t, initialized.Result.Capabilities.Experimental, "acme/", "custom methods are advertised")
+
+	call := func(id int, method string) protocol.JSONRPCMessage {
+		w := postMessage(t, server, sessionId, protocol.JSONRPCMessage{JSONRPC: "2.0", ID: id, Method: method})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response protocol.JSONRPCMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	response := call(2, "acme/reindex")
+	require.Nil(t, response.Error)
+	assert.JSONEq(t, `{"handled":"acme/reindex"}`, mustJSON(t, response.Result))
+
+	response = call(3, "vendor/unknown")
+	require.NotNil(t, response.Error)
+	assert.Contains(t, mustJSON(t, response.Error), `"code":-32601`)
+}
+
+func TestCompletionsCapability(t *testing.T) {
+	prompts := resources.NewStaticPromptRegistry()
+	server, err := NewMcpServer(config.DefaultConfig(), WithRouter(chi.NewRouter()), WithPromptRegistry(prompts))
+	require.NoError(t, err)
+	assert.Nil(t, server.GetServerCapabilities().Completions, "completions are not advertised without providers")
+
+	require.NoError(t, prompts.RegisterPrompt(resources.NewPrompt("review").
+		WithArgument("language").Complete(func(ctx context.Context, value string, arguments map[string]string) ([]string, bool, error) {
+		return []string{"go"}, false, nil
+	}).Add().
+		Build()))
+	assert.NotNil(t, server.GetServerCapabilities().Completions)
+}
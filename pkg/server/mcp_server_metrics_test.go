@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/metrics"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Metrics.Enable = true
+
+	server, err := NewMcpServer(cfg)
+	require.NoError(t, err)
+
+	// A malformed message is still counted
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, cfg.HTTP.MCPPath, strings.NewReader("{")))
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "mcp_sessions_active 0")
+	assert.Contains(t, w.Body.String(), `mcp_http_requests_total{method="invalid"`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
+
+func TestMetricsEndpointDisabled(t *testing.T) {
+	server, err := NewMcpServer(config.DefaultConfig())
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, metrics.NopRecorder{}, server.GetMetrics())
+}
+
+// httpRecorder keeps the methods of posted messages
+type httpRecorder struct {
+	metrics.NopRecorder
+	mu      sync.Mutex
+	methods []string
+}
+
+func (r *httpRecorder) HTTPRequest(method string, _ int, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.methods = append(r.methods, method)
+}
+
+func TestWithMetricsRecorder(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Metrics.Enable = true
+	recorder := &httpRecorder{}
+
+	server, err := NewMcpServer(cfg, WithMetricsRecorder(recorder))
+	require.NoError(t, err)
+	assert.Same(t, recorder, server.GetMetrics())
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodPost, cfg.HTTP.MCPPath, strings.NewReader("{")))
+	assert.Equal(t, []string{"invalid"}, recorder.methods)
+
+	// The recorder cannot serve metrics, so there is no endpoint
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
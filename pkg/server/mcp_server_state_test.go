@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/sessionstate"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+)
+
+func TestSessionStateSurvivesLosingTheNode(t *testing.T) {
+	ctx := context.Background()
+	store := sessionstore.NewMemoryStore()
+
+	// The tool pages through results, keeping its cursor in the session
+	nextPage := func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		page, _, err := sessionstate.Get[int](ctx, "page")
+		if err != nil {
+			return nil, err
+		}
+		page++
+		if err := sessionstate.Set(ctx, "page", page); err != nil {
+			return nil, err
+		}
+		return map[string]int{"page": page}, nil
+	}
+
+	first := newDrainTestServer(t, store, nextPage)
+	defer first.Stop(ctx)
+
+	w := postMessage(t, first, "", protocol.JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params: protocol.InitializeParams{
+			ProtocolVersion: protocol.ProtocolVersion20250326,
+			ClientInfo:      protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+		},
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	sessionId := w.Header().Get("Mcp-Session-Id")
+
+	callTool := func(server *McpServer, id int) string {
+		w := postMessage(t, server, sessionId, protocol.JSONRPCMessage{
+			JSONRPC: "2.0",
+			ID:      id,
+			Method:  "tools/call",
+			Params:  map[string]interface{}{"name": "work", "arguments": map[string]interface{}{}},
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return w.Body.String()
+	}
+
+	assert.Contains(t, callTool(first, 2), `\"page\":1`)
+	assert.Contains(t, callTool(first, 3), `\"page\":2`)
+
+	// Another node that the client reaches resumes the session with its state, as it would if the first node
+	// had been lost
+	second := newDrainTestServer(t, store, nextPage)
+	defer second.Stop(ctx)
+	assert.Contains(t, callTool(second, 4), `\"page\":3`)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/tracing"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// headerTraceHandler takes trace ids from the X-Request-Id header
+type headerTraceHandler struct{}
+
+func (headerTraceHandler) ExtractTraceId(r *http.Request) string {
+	return r.Header.Get("X-Request-Id")
+}
+
+func (headerTraceHandler) SetTraceId(ctx context.Context, traceId string) context.Context {
+	return utils.SetTraceId(ctx, traceId)
+}
+
+func TestTraceHandlerMiddleware(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	serve := func(t *testing.T, s *McpServer, header http.Header, status int) (context.Context, *tracetest.InMemoryExporter) {
+		exporter := tracetest.NewInMemoryExporter()
+		s.config = config.DefaultConfig()
+		s.tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		var handled context.Context
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handled = r.Context()
+			w.WriteHeader(status)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		s.traceHandlerMiddleware(next).ServeHTTP(httptest.NewRecorder(), req)
+		require.NotNil(t, handled)
+		return handled, exporter
+	}
+
+	t.Run("continues the caller's trace", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("traceparent", traceparent)
+		header.Set("Mcp-Session-Id", "session-1")
+
+		ctx, exporter := serve(t, &McpServer{}, header, http.StatusOK)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", utils.GetTraceId(ctx))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "POST /mcp", span.Name)
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+		assert.Contains(t, span.Attributes, tracing.AttrSessionId.String("session-1"))
+		assert.Equal(t, codes.Unset, span.Status.Code)
+
+		// The carrier sent to actors continues the HTTP span
+		carrier := tracing.Inject(ctx)
+		assert.Contains(t, carrier["traceparent"], span.SpanContext.SpanID().String())
+	})
+
+	t.Run("keeps the trace handler's id", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("X-Request-Id", "req-42")
+
+		ctx, _ := serve(t, &McpServer{traceHandler: headerTraceHandler{}}, header, http.StatusOK)
+		assert.Equal(t, "req-42", utils.GetTraceId(ctx))
+		assert.Equal(t, "req-42", tracing.Inject(ctx)[tracing.TraceIdKey])
+	})
+
+	t.Run("marks server errors", func(t *testing.T) {
+		_, exporter := serve(t, &McpServer{}, http.Header{}, http.StatusInternalServerError)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+	})
+}
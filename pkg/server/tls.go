@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/config"
+)
+
+// certReloader holds the server certificate and client CA pool, and reloads them
+// when the backing files change on disk.
+type certReloader struct {
+	cfg config.TLSConfig
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+func newCertReloader(cfg config.TLSConfig) (*certReloader, error) {
+	r := &certReloader{
+		cfg:      cfg,
+		modTimes: make(map[string]time.Time),
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// files returns the set of files the reloader watches
+func (r *certReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// load reads the certificate, key and client CA bundle from disk
+func (r *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, f := range r.files() {
+		if fi, err := os.Stat(f); err == nil {
+			modTimes[f] = fi.ModTime()
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// changed reports whether any of the watched files has a different modification time
+func (r *certReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.files() {
+		fi, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if !fi.ModTime().Equal(r.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch polls the watched files until the context is cancelled, logging reloads to logger
+func (r *certReloader) watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				// Keep serving with the previous certificates until the files are valid again
+				logger.ErrorContext(ctx, "Failed to reload TLS certificates", "error", err)
+				continue
+			}
+			logger.InfoContext(ctx, "Reloaded TLS certificates")
+		}
+	}
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// tlsConfig builds a server TLS config that always uses the latest loaded material
+func (r *certReloader) tlsConfig() (*tls.Config, error) {
+	clientAuth, err := clientAuthType(r.cfg.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	if clientAuth != tls.NoClientCert && r.cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("client_ca_file is required when client_auth is %q", r.cfg.ClientAuth)
+	}
+
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+
+	if clientAuth == tls.NoClientCert {
+		return base, nil
+	}
+
+	// Resolve the client CA pool per handshake so a reloaded bundle takes effect immediately
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.mu.RLock()
+		pool := r.clientCA
+		r.mu.RUnlock()
+
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		c.ClientAuth = clientAuth
+		c.ClientCAs = pool
+		return c, nil
+	}
+
+	return base, nil
+}
+
+func clientAuthType(mode config.TLSClientAuthMode) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", config.TLSClientAuthNone:
+		return tls.NoClientCert, nil
+	case config.TLSClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case config.TLSClientAuthRequired:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown client_auth mode %q", mode)
+	}
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traego/scaled-mcp/pkg/auth"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/test/testutils"
+)
+
+var _ config.AuthHandler = (*auth.MTLSAuthHandler)(nil)
+
+type tlsFixture struct {
+	ca         *testutils.TestCA
+	certFile   string
+	keyFile    string
+	caFile     string
+	clientCert tls.Certificate
+}
+
+func newTLSFixture(t *testing.T) *tlsFixture {
+	dir := t.TempDir()
+
+	ca, err := testutils.NewTestCA("test-ca")
+	require.NoError(t, err)
+
+	serverCert, err := ca.Issue(testutils.CertOptions{
+		CommonName: "localhost",
+		DNSNames:   []string{"localhost"},
+		IPs:        []net.IP{net.ParseIP("127.0.0.1")},
+		IsServer:   true,
+	})
+	require.NoError(t, err)
+
+	certFile, keyFile, err := serverCert.WriteFiles(dir, "server")
+	require.NoError(t, err)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.CertPEM, 0o600))
+
+	client, err := ca.Issue(testutils.CertOptions{CommonName: "test-client"})
+	require.NoError(t, err)
+	clientCert, err := client.TLSCertificate()
+	require.NoError(t, err)
+
+	return &tlsFixture{
+		ca:         ca,
+		certFile:   certFile,
+		keyFile:    keyFile,
+		caFile:     caFile,
+		clientCert: clientCert,
+	}
+}
+
+// startTLSServer serves a handler that reports the mTLS principal using the given TLS settings
+func startTLSServer(t *testing.T, tlsCfg config.TLSConfig) (*httptest.Server, *certReloader) {
+	reloader, err := newCertReloader(tlsCfg)
+	require.NoError(t, err)
+
+	serverTLS, err := reloader.tlsConfig()
+	require.NoError(t, err)
+
+	handler := auth.NewMTLSAuthHandler()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ai := handler.ExtractAuth(r)
+		if ai == nil {
+			_, _ = fmt.Fprint(w, "anonymous")
+			return
+		}
+		_, _ = fmt.Fprint(w, ai.GetPrincipalId())
+	}))
+	srv.TLS = serverTLS
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv, reloader
+}
+
+func tlsClient(pool *x509.CertPool, certs ...tls.Certificate) *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certs},
+		},
+	}
+}
+
+func getBody(t *testing.T, c *http.Client, url string) (string, error) {
+	resp, err := c.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	buf := make([]byte, 256)
+	n, _ := resp.Body.Read(buf)
+	return string(buf[:n]), nil
+}
+
+func TestTLSClientAuthRequired(t *testing.T) {
+	f := newTLSFixture(t)
+	srv, _ := startTLSServer(t, config.TLSConfig{
+		Enable:       true,
+		CertFile:     f.certFile,
+		KeyFile:      f.keyFile,
+		ClientCAFile: f.caFile,
+		ClientAuth:   config.TLSClientAuthRequired,
+	})
+
+	body, err := getBody(t, tlsClient(f.ca.Pool(), f.clientCert), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "test-client", body)
+
+	_, err = getBody(t, tlsClient(f.ca.Pool()), srv.URL)
+	assert.Error(t, err, "connections without a client certificate should be rejected")
+
+	otherCA, err := testutils.NewTestCA("other-ca")
+	require.NoError(t, err)
+	untrusted, err := otherCA.Issue(testutils.CertOptions{CommonName: "intruder"})
+	require.NoError(t, err)
+	untrustedCert, err := untrusted.TLSCertificate()
+	require.NoError(t, err)
+
+	_, err = getBody(t, tlsClient(f.ca.Pool(), untrustedCert), srv.URL)
+	assert.Error(t, err, "certificates from an unknown CA should be rejected")
+}
+
+func TestTLSClientAuthOptional(t *testing.T) {
+	f := newTLSFixture(t)
+	srv, _ := startTLSServer(t, config.TLSConfig{
+		Enable:       true,
+		CertFile:     f.certFile,
+		KeyFile:      f.keyFile,
+		ClientCAFile: f.caFile,
+		ClientAuth:   config.TLSClientAuthOptional,
+	})
+
+	body, err := getBody(t, tlsClient(f.ca.Pool()), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "anonymous", body)
+
+	body, err = getBody(t, tlsClient(f.ca.Pool(), f.clientCert), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "test-client", body)
+}
+
+func TestTLSConfigValidation(t *testing.T) {
+	f := newTLSFixture(t)
+
+	reloader, err := newCertReloader(config.TLSConfig{CertFile: f.certFile, KeyFile: f.keyFile, ClientAuth: config.TLSClientAuthRequired})
+	require.NoError(t, err)
+	_, err = reloader.tlsConfig()
+	assert.Error(t, err, "client auth without a CA bundle should be rejected")
+
+	reloader, err = newCertReloader(config.TLSConfig{CertFile: f.certFile, KeyFile: f.keyFile, ClientAuth: "sometimes"})
+	require.NoError(t, err)
+	_, err = reloader.tlsConfig()
+	assert.Error(t, err)
+
+	_, err = newCertReloader(config.TLSConfig{CertFile: filepath.Join(t.TempDir(), "missing.crt"), KeyFile: f.keyFile})
+	assert.Error(t, err)
+}
+
+func TestTLSCertificateReload(t *testing.T) {
+	f := newTLSFixture(t)
+	srv, reloader := startTLSServer(t, config.TLSConfig{
+		Enable:       true,
+		CertFile:     f.certFile,
+		KeyFile:      f.keyFile,
+		ClientCAFile: f.caFile,
+		ClientAuth:   config.TLSClientAuthRequired,
+	})
+
+	// Rotate to a new CA: new server certificate and new client CA bundle
+	newCA, err := testutils.NewTestCA("rotated-ca")
+	require.NoError(t, err)
+	serverCert, err := newCA.Issue(testutils.CertOptions{
+		CommonName: "localhost",
+		IPs:        []net.IP{net.ParseIP("127.0.0.1")},
+		IsServer:   true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(f.certFile, serverCert.CertPEM, 0o600))
+	require.NoError(t, os.WriteFile(f.keyFile, serverCert.KeyPEM, 0o600))
+	require.NoError(t, os.WriteFile(f.caFile, newCA.CertPEM, 0o600))
+
+	// Make sure the modification time moves even on filesystems with coarse timestamps
+	future := time.Now().Add(time.Minute)
+	for _, file := range []string{f.certFile, f.keyFile, f.caFile} {
+		require.NoError(t, os.Chtimes(file, future, future))
+	}
+
+	require.True(t, reloader.changed())
+	require.NoError(t, reloader.load())
+	assert.False(t, reloader.changed())
+
+	newClient, err := newCA.Issue(testutils.CertOptions{CommonName: "rotated-client"})
+	require.NoError(t, err)
+	newClientCert, err := newClient.TLSCertificate()
+	require.NoError(t, err)
+
+	body, err := getBody(t, tlsClient(newCA.Pool(), newClientCert), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-client", body)
+
+	_, err = getBody(t, tlsClient(f.ca.Pool(), f.clientCert), srv.URL)
+	assert.Error(t, err, "the old server certificate should no longer be served")
+}
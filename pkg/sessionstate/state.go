@@ -0,0 +1,186 @@
+// Package sessionstate lets tools, prompts and resources keep values across the requests of a session, such as
+// a selected project or a cursor into a result set. Values are stored as JSON, so that they can be handed to
+// another node with the rest of the session.
+package sessionstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultMaxBytes is the size limit of a session's state when none is configured
+const DefaultMaxBytes = 64 * 1024
+
+var (
+	// ErrNoSession is returned when a context does not belong to a session
+	ErrNoSession = errors.New("no session state in context")
+
+	// ErrTooLarge is returned when setting a value would take the session's state over its size limit
+	ErrTooLarge = errors.New("session state is too large")
+)
+
+// State holds the values of a session. It is safe for concurrent use.
+type State struct {
+	mu       sync.Mutex
+	values   map[string]json.RawMessage
+	size     int
+	maxBytes int
+	dirty    bool
+}
+
+// New creates the state of a session, holding at most maxBytes of keys and JSON-encoded values, starting from
+// values, which may be nil. A maxBytes of zero or less uses DefaultMaxBytes.
+func New(maxBytes int, values map[string]json.RawMessage) *State {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	s := &State{values: make(map[string]json.RawMessage, len(values)), maxBytes: maxBytes}
+	for key, value := range values {
+		s.values[key] = value
+		s.size += len(key) + len(value)
+	}
+	return s
+}
+
+// Get decodes the value of key into v, reporting whether the key is set
+func (s *State) Get(key string, v interface{}) (bool, error) {
+	s.mu.Lock()
+	value, ok := s.values[key]
+	s.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(value, v); err != nil {
+		return true, fmt.Errorf("failed to decode session state %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Set stores value, encoded as JSON, under key. It returns ErrTooLarge if the state would exceed its limit.
+func (s *State) Set(key string, value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode session state %q: %w", key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := s.size + len(key) + len(encoded)
+	if previous, ok := s.values[key]; ok {
+		size -= len(key) + len(previous)
+	}
+	if size > s.maxBytes {
+		return fmt.Errorf("%w: setting %q needs %d bytes, the limit is %d", ErrTooLarge, key, size, s.maxBytes)
+	}
+
+	s.values[key] = encoded
+	s.size = size
+	s.dirty = true
+	return nil
+}
+
+// Delete removes key
+func (s *State) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.size -= len(key) + len(previous)
+		s.dirty = true
+	}
+}
+
+// Keys returns the keys that are set, sorted
+func (s *State) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Size returns the number of bytes the keys and encoded values take
+func (s *State) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Values returns a copy of the encoded values, or nil if there are none
+func (s *State) Values() map[string]json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.values) == 0 {
+		return nil
+	}
+	values := make(map[string]json.RawMessage, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
+
+// TakeChanged reports whether the state changed since it was last called
+func (s *State) TakeChanged() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := s.dirty
+	s.dirty = false
+	return changed
+}
+
+type stateContextKey struct{}
+
+// WithState returns a context carrying the state of the session handling a request
+func WithState(ctx context.Context, s *State) context.Context {
+	return context.WithValue(ctx, stateContextKey{}, s)
+}
+
+// FromContext returns the state of the session handling the request in ctx, or nil
+func FromContext(ctx context.Context) *State {
+	s, _ := ctx.Value(stateContextKey{}).(*State)
+	return s
+}
+
+// Get returns the value of key in the state of the session handling the request in ctx, and whether it is set
+func Get[T any](ctx context.Context, key string) (T, bool, error) {
+	var value T
+	s := FromContext(ctx)
+	if s == nil {
+		return value, false, ErrNoSession
+	}
+	ok, err := s.Get(key, &value)
+	return value, ok, err
+}
+
+// Set stores value under key in the state of the session handling the request in ctx
+func Set(ctx context.Context, key string, value interface{}) error {
+	s := FromContext(ctx)
+	if s == nil {
+		return ErrNoSession
+	}
+	return s.Set(key, value)
+}
+
+// Delete removes key from the state of the session handling the request in ctx
+func Delete(ctx context.Context, key string) error {
+	s := FromContext(ctx)
+	if s == nil {
+		return ErrNoSession
+	}
+	s.Delete(key)
+	return nil
+}
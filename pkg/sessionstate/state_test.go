@@ -0,0 +1,81 @@
+package sessionstate
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cursor struct {
+	Query  string `json:"query"`
+	Offset int    `json:"offset"`
+}
+
+func TestState(t *testing.T) {
+	ctx := WithState(context.Background(), New(0, nil))
+
+	_, ok, err := Get[string](ctx, "project")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, Set(ctx, "project", "scaled-mcp"))
+	require.NoError(t, Set(ctx, "cursor", cursor{Query: "open issues", Offset: 20}))
+
+	project, ok, err := Get[string](ctx, "project")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "scaled-mcp", project)
+
+	c, ok, err := Get[cursor](ctx, "cursor")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, cursor{Query: "open issues", Offset: 20}, c)
+
+	_, _, err = Get[int](ctx, "project")
+	assert.Error(t, err, "a value decodes only into a compatible type")
+
+	state := FromContext(ctx)
+	assert.Equal(t, []string{"cursor", "project"}, state.Keys())
+	assert.True(t, state.TakeChanged())
+	assert.False(t, state.TakeChanged())
+
+	require.NoError(t, Delete(ctx, "cursor"))
+	assert.Equal(t, []string{"project"}, state.Keys())
+	assert.Equal(t, len("project")+len(`"scaled-mcp"`), state.Size())
+	assert.True(t, state.TakeChanged())
+}
+
+func TestStateSizeLimit(t *testing.T) {
+	state := New(20, nil)
+	require.NoError(t, state.Set("key", "0123456789"))
+
+	// Replacing a value only counts the new one
+	require.NoError(t, state.Set("key", "9876543210"))
+
+	err := state.Set("other", "value")
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.Equal(t, []string{"key"}, state.Keys())
+}
+
+func TestStateRestoredFromValues(t *testing.T) {
+	state := New(0, map[string]json.RawMessage{"project": json.RawMessage(`"scaled-mcp"`)})
+
+	var project string
+	ok, err := state.Get("project", &project)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "scaled-mcp", project)
+	assert.False(t, state.TakeChanged(), "restoring values is not a change")
+	assert.Equal(t, map[string]json.RawMessage{"project": json.RawMessage(`"scaled-mcp"`)}, state.Values())
+}
+
+func TestOutsideSession(t *testing.T) {
+	ctx := context.Background()
+	_, _, err := Get[string](ctx, "project")
+	assert.ErrorIs(t, err, ErrNoSession)
+	assert.ErrorIs(t, Set(ctx, "project", "x"), ErrNoSession)
+	assert.ErrorIs(t, Delete(ctx, "project"), ErrNoSession)
+}
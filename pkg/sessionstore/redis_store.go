@@ -0,0 +1,62 @@
+package sessionstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisPrefix is the key prefix used by RedisStore when none is given
+const DefaultRedisPrefix = "mcp:session:"
+
+// RedisStore keeps session snapshots in Redis, as JSON, so that any node in a cluster can resume them
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore creates a store using the given client. An empty prefix uses DefaultRedisPrefix.
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = DefaultRedisPrefix
+	}
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Save implements Store
+func (s *RedisStore) Save(ctx context.Context, snapshot *Snapshot, ttl time.Duration) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session snapshot: %w", err)
+	}
+	if err := s.client.Set(ctx, s.prefix+snapshot.SessionId, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save session snapshot: %w", err)
+	}
+	return nil
+}
+
+// Take implements Store
+func (s *RedisStore) Take(ctx context.Context, sessionId string) (*Snapshot, error) {
+	data, err := s.client.GetDel(ctx, s.prefix+sessionId).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to take session snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+var _ Store = (*RedisStore)(nil)
@@ -0,0 +1,106 @@
+// Package sessionstore keeps snapshots of sessions outside of the actor that owns them, so that a session
+// can be handed from a node that is shutting down to whichever node its client reconnects to.
+package sessionstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+// ErrNotFound is returned when a store holds no snapshot for a session
+var ErrNotFound = errors.New("session snapshot not found")
+
+// Snapshot is the state needed to resume an initialized session on another node
+type Snapshot struct {
+	SessionId       string                   `json:"session_id"`
+	ProtocolVersion protocol.ProtocolVersion `json:"protocol_version"`
+	ClientInfo      protocol.ClientInfo      `json:"client_info"`
+	PrincipalId     string                   `json:"principal_id,omitempty"`
+
+	ClientCapabilities protocol.ClientCapabilities `json:"client_capabilities"`
+
+	// Auth info of the client, serialized by the server's auth handler
+	AuthInfo []byte `json:"auth_info,omitempty"`
+
+	// Whether the client sent notifications/initialized
+	ClientNotificationsInitialized bool `json:"client_notifications_initialized"`
+
+	// Logging level set by the client, empty if it never set one
+	LogLevel protocol.LoggingLevel `json:"log_level,omitempty"`
+
+	// Key/value state kept by the session's tools, JSON-encoded
+	State map[string]json.RawMessage `json:"state,omitempty"`
+
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// Store holds session snapshots. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save stores a snapshot, replacing any previous one for the session. It is dropped after ttl.
+	Save(ctx context.Context, snapshot *Snapshot, ttl time.Duration) error
+
+	// Take removes and returns the snapshot of a session, so that only one node resumes it. It returns
+	// ErrNotFound if there is none.
+	Take(ctx context.Context, sessionId string) (*Snapshot, error)
+}
+
+type memoryEntry struct {
+	snapshot  Snapshot
+	expiresAt time.Time
+}
+
+// MemoryStore is an in-process Store. It only hands sessions between servers in the same process; use
+// RedisStore in a cluster.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(_ context.Context, snapshot *Snapshot, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, id)
+		}
+	}
+
+	s.entries[snapshot.SessionId] = memoryEntry{snapshot: *snapshot, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Take implements Store
+func (s *MemoryStore) Take(_ context.Context, sessionId string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[sessionId]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(s.entries, sessionId)
+
+	if s.now().After(entry.expiresAt) {
+		return nil, ErrNotFound
+	}
+	return &entry.snapshot, nil
+}
+
+var _ Store = (*MemoryStore)(nil)
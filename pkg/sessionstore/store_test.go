@@ -0,0 +1,96 @@
+package sessionstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/traego/scaled-mcp/pkg/protocol"
+)
+
+func testSnapshot(id string) *Snapshot {
+	return &Snapshot{
+		SessionId:                      id,
+		ProtocolVersion:                protocol.ProtocolVersion20250326,
+		ClientInfo:                     protocol.ClientInfo{Name: "test-client", Version: "1.0.0"},
+		PrincipalId:                    "user-1",
+		ClientCapabilities:             protocol.ClientCapabilities{Roots: &protocol.RootsClientCapability{ListChanged: true}},
+		AuthInfo:                       []byte("user-1"),
+		ClientNotificationsInitialized: true,
+		LogLevel:                       protocol.LoggingLevelWarning,
+		LastActivity:                   time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+// testStore exercises the Store contract. expire moves time past ttl.
+func testStore(t *testing.T, store Store, expire func(ttl time.Duration)) {
+	ctx := context.Background()
+
+	t.Run("save and take", func(t *testing.T) {
+		snapshot := testSnapshot("s1")
+		require.NoError(t, store.Save(ctx, snapshot, time.Minute))
+
+		taken, err := store.Take(ctx, "s1")
+		require.NoError(t, err)
+		assert.Equal(t, snapshot, taken)
+
+		_, err = store.Take(ctx, "s1")
+		assert.ErrorIs(t, err, ErrNotFound, "a snapshot should only be taken once")
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		_, err := store.Take(ctx, "missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("save replaces", func(t *testing.T) {
+		first := testSnapshot("s2")
+		require.NoError(t, store.Save(ctx, first, time.Minute))
+		second := testSnapshot("s2")
+		second.PrincipalId = "user-2"
+		require.NoError(t, store.Save(ctx, second, time.Minute))
+
+		taken, err := store.Take(ctx, "s2")
+		require.NoError(t, err)
+		assert.Equal(t, "user-2", taken.PrincipalId)
+	})
+
+	t.Run("snapshots expire", func(t *testing.T) {
+		require.NoError(t, store.Save(ctx, testSnapshot("s3"), time.Minute))
+		expire(time.Minute)
+
+		_, err := store.Take(ctx, "s3")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	testStore(t, store, func(ttl time.Duration) {
+		now = now.Add(ttl + time.Second)
+	})
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	store := NewRedisStore(client, "")
+	testStore(t, store, func(ttl time.Duration) {
+		mr.FastForward(ttl + time.Second)
+	})
+
+	require.NoError(t, store.Save(context.Background(), testSnapshot("s4"), time.Minute))
+	assert.Equal(t, []string{DefaultRedisPrefix + "s4"}, mr.Keys())
+}
@@ -0,0 +1,110 @@
+// Package tracing creates OpenTelemetry spans for requests and propagates W3C trace context between
+// HTTP handlers and actors, including across cluster nodes.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/redact"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+// TracerName is the instrumentation name of the server's tracer
+const TracerName = "github.com/traego/scaled-mcp"
+
+// TraceIdKey is the carrier key holding the trace id set by a config.TraceHandler, which need not be a
+// W3C trace id
+const TraceIdKey = "mcp-trace-id"
+
+// Span attributes
+const (
+	AttrMethod      = attribute.Key("mcp.method")
+	AttrSessionId   = attribute.Key("mcp.session.id")
+	AttrToolName    = attribute.Key("mcp.tool.name")
+	AttrPromptName  = attribute.Key("mcp.prompt.name")
+	AttrResourceURI = attribute.Key("mcp.resource.uri")
+)
+
+// propagator carries W3C traceparent and tracestate
+var propagator = propagation.TraceContext{}
+
+// Tracer returns the server's tracer from provider, or from the global provider if provider is nil
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(TracerName)
+}
+
+// Inject returns a carrier holding the trace context of ctx, to be sent along with a WrappedRequest
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if traceId := utils.GetTraceId(ctx); traceId != "" {
+		carrier[TraceIdKey] = traceId
+	}
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns ctx with the trace context held in carrier
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	if traceId := carrier[TraceIdKey]; traceId != "" {
+		ctx = utils.SetTraceId(ctx, traceId)
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// ExtractHTTP returns ctx with the trace context sent in the request headers
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// TraceId returns the trace id set by a config.TraceHandler, falling back to the id of the current span's
+// trace
+func TraceId(ctx context.Context) string {
+	if traceId := utils.GetTraceId(ctx); traceId != "" {
+		return traceId
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// SetError marks span as failed. The error is redacted before it is recorded.
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	msg := redact.String(err.Error())
+	span.RecordError(redactedError(msg))
+	span.SetStatus(codes.Error, msg)
+}
+
+// SetResponseStatus marks span as failed if response carries a JSON-RPC error
+func SetResponseStatus(span trace.Span, response *mcppb.JsonRpcResponse) {
+	if rpcErr := response.GetError(); rpcErr != nil {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", int(rpcErr.Code)))
+		span.SetStatus(codes.Error, redact.String(rpcErr.Message))
+	}
+}
+
+type redactedError string
+
+func (e redactedError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/traego/scaled-mcp/pkg/proto/mcppb"
+	"github.com/traego/scaled-mcp/pkg/utils"
+)
+
+func newTestProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func TestInjectExtract(t *testing.T) {
+	provider, _ := newTestProvider()
+	ctx, span := Tracer(provider).Start(context.Background(), "parent")
+	defer span.End()
+	ctx = utils.SetTraceId(ctx, "custom-id")
+
+	carrier := Inject(ctx)
+	assert.Contains(t, carrier, "traceparent")
+	assert.Equal(t, "custom-id", carrier[TraceIdKey])
+
+	extracted := Extract(context.Background(), carrier)
+	sc := trace.SpanContextFromContext(extracted)
+	assert.True(t, sc.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), sc.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), sc.SpanID())
+	assert.Equal(t, "custom-id", utils.GetTraceId(extracted))
+}
+
+func TestInjectWithoutTrace(t *testing.T) {
+	assert.Nil(t, Inject(context.Background()))
+
+	ctx := context.Background()
+	assert.Equal(t, ctx, Extract(ctx, nil))
+}
+
+func TestExtractHTTP(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	sc := trace.SpanContextFromContext(ExtractHTTP(context.Background(), header))
+	require.True(t, sc.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+}
+
+func TestTraceId(t *testing.T) {
+	assert.Empty(t, TraceId(context.Background()))
+
+	provider, _ := newTestProvider()
+	ctx, span := Tracer(provider).Start(context.Background(), "span")
+	defer span.End()
+	assert.Equal(t, span.SpanContext().TraceID().String(), TraceId(ctx))
+
+	assert.Equal(t, "custom-id", TraceId(utils.SetTraceId(ctx, "custom-id")))
+}
+
+func TestSetError(t *testing.T) {
+	provider, exporter := newTestProvider()
+	_, span := Tracer(provider).Start(context.Background(), "span")
+	SetError(span, nil)
+	SetError(span, errors.New("dial postgres://app:pa55word@db/app failed"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.NotContains(t, spans[0].Status.Description, "pa55word")
+	require.Len(t, spans[0].Events, 1)
+	for _, attr := range spans[0].Events[0].Attributes {
+		assert.NotContains(t, attr.Value.Emit(), "pa55word")
+	}
+}
+
+func TestSetResponseStatus(t *testing.T) {
+	provider, exporter := newTestProvider()
+	_, ok := Tracer(provider).Start(context.Background(), "ok")
+	SetResponseStatus(ok, &mcppb.JsonRpcResponse{Response: &mcppb.JsonRpcResponse_ResultJson{ResultJson: "{}"}})
+	ok.End()
+
+	_, failed := Tracer(provider).Start(context.Background(), "failed")
+	SetResponseStatus(failed, &mcppb.JsonRpcResponse{Response: &mcppb.JsonRpcResponse_Error{
+		Error: &mcppb.JsonRpcError{Code: -32601, Message: "Method not found"},
+	}})
+	failed.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "Method not found", spans[1].Status.Description)
+}
@@ -0,0 +1,564 @@
+// Package uritemplate parses RFC 6570 URI templates, up to level 4, expands them and matches URIs against them.
+//
+// Matching is the inverse of expansion and, like it, is ambiguous in general. A URI matches a template when it is
+// one of the template's expansions. Each variable gets the text its expression produced, decoded. List values,
+// from a list or an exploded variable, come back joined with commas. Associative array values are not recovered.
+// Query expressions ({?x} and {&x}) match their variables in any order.
+package uritemplate
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Variable is a variable of a template expression
+type Variable struct {
+	// Name of the variable
+	Name string
+
+	// Operator of the expression the variable is in, empty for simple string expansion
+	Operator string
+
+	// Explode is set for variables with the * modifier
+	Explode bool
+
+	// MaxLength is the length of the :N prefix modifier, zero when there is none
+	MaxLength int
+}
+
+// Template is a parsed URI template
+type Template struct {
+	raw         string
+	parts       []part
+	re          *regexp.Regexp
+	captures    []capture
+	literalSize int
+}
+
+// part is a literal, when expr is nil, or an expression
+type part struct {
+	literal string
+	expr    *expression
+}
+
+type expression struct {
+	op   operator
+	vars []Variable
+}
+
+// operator holds how an expression's operator expands, as in appendix A of RFC 6570
+type operator struct {
+	name     string
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var operators = map[byte]operator{
+	'+': {name: "+", sep: ",", reserved: true},
+	'.': {name: ".", first: ".", sep: "."},
+	'/': {name: "/", first: "/", sep: "/"},
+	';': {name: ";", first: ";", sep: ";", named: true},
+	'?': {name: "?", first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {name: "&", first: "&", sep: "&", named: true, ifEmpty: "="},
+	'#': {name: "#", first: "#", sep: ",", reserved: true},
+}
+
+var simple = operator{sep: ","}
+
+// capture is what a regexp group of the matcher holds
+type capture struct {
+	kind captureKind
+	v    Variable
+	sep  string
+
+	// vars are the variables of a query expression
+	vars []Variable
+}
+
+type captureKind int
+
+const (
+	// captureValue holds a variable's value, with list items separated by sep
+	captureValue captureKind = iota
+	// captureNamed holds name=value pairs separated by sep, for exploded ; variables
+	captureNamed
+	// captureQuery holds a query string, for ? and & expressions
+	captureQuery
+)
+
+// Parse parses a URI template
+func Parse(s string) (*Template, error) {
+	t := &Template{raw: s}
+	rest := s
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			open = len(rest)
+		}
+		if closing := strings.IndexByte(rest[:open], '}'); closing >= 0 {
+			return nil, fmt.Errorf("uri template %q: unexpected '}'", s)
+		}
+		if open > 0 {
+			t.parts = append(t.parts, part{literal: rest[:open]})
+			t.literalSize += open
+		}
+		if open == len(rest) {
+			break
+		}
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("uri template %q: unclosed expression", s)
+		}
+		expr, err := parseExpression(rest[open+1 : open+end])
+		if err != nil {
+			return nil, fmt.Errorf("uri template %q: %w", s, err)
+		}
+		t.parts = append(t.parts, part{expr: expr})
+		rest = rest[open+end+1:]
+	}
+
+	if err := t.compile(); err != nil {
+		return nil, fmt.Errorf("uri template %q: %w", s, err)
+	}
+	return t, nil
+}
+
+// MustParse parses a URI template, panicking if it is invalid
+func MustParse(s string) *Template {
+	t, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func parseExpression(s string) (*expression, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	expr := &expression{op: simple}
+	if op, ok := operators[s[0]]; ok {
+		expr.op = op
+		s = s[1:]
+	} else if strings.IndexByte("=,!@|", s[0]) >= 0 {
+		return nil, fmt.Errorf("reserved operator %q", s[0])
+	}
+
+	for _, spec := range strings.Split(s, ",") {
+		v := Variable{Operator: expr.op.name}
+		if strings.HasSuffix(spec, "*") {
+			v.Explode = true
+			spec = spec[:len(spec)-1]
+		} else if colon := strings.IndexByte(spec, ':'); colon >= 0 {
+			n, err := strconv.Atoi(spec[colon+1:])
+			if err != nil || n < 1 || n > 9999 {
+				return nil, fmt.Errorf("invalid prefix modifier in %q", spec)
+			}
+			v.MaxLength = n
+			spec = spec[:colon]
+		}
+		if !validName(spec) {
+			return nil, fmt.Errorf("invalid variable name %q", spec)
+		}
+		v.Name = spec
+		expr.vars = append(expr.vars, v)
+	}
+	return expr, nil
+}
+
+// validName reports whether s is a variable name: ALPHA, DIGIT, _ and percent-encoded characters, in
+// dot-separated parts
+func validName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, name := range strings.Split(s, ".") {
+		if name == "" {
+			return false
+		}
+		for i := 0; i < len(name); i++ {
+			c := name[i]
+			switch {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+			case c == '%' && i+2 < len(name) && isHex(name[i+1]) && isHex(name[i+2]):
+				i += 2
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns the template as it was parsed
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Variables returns the template's variables, in the order they appear
+func (t *Template) Variables() []Variable {
+	var vars []Variable
+	for _, p := range t.parts {
+		if p.expr != nil {
+			vars = append(vars, p.expr.vars...)
+		}
+	}
+	return vars
+}
+
+// Names returns the names of the template's variables, in the order they first appear
+func (t *Template) Names() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, v := range t.Variables() {
+		if !seen[v.Name] {
+			seen[v.Name] = true
+			names = append(names, v.Name)
+		}
+	}
+	return names
+}
+
+// Expand expands the template. Values are strings, []string for lists or map[string]string for associative
+// arrays; a missing variable, an empty list or an empty map is undefined and expands to nothing.
+func (t *Template) Expand(values map[string]interface{}) (string, error) {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.expr == nil {
+			b.WriteString(p.literal)
+			continue
+		}
+		if err := p.expr.expand(&b, values); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func (e *expression) expand(b *strings.Builder, values map[string]interface{}) error {
+	op := e.op
+	first := true
+	for _, v := range e.vars {
+		value, ok := values[v.Name]
+		if !ok || value == nil {
+			continue
+		}
+
+		var expanded string
+		switch value := value.(type) {
+		case string:
+			expanded = op.expandString(v, value)
+		case []string:
+			if len(value) == 0 {
+				continue
+			}
+			expanded = op.expandList(v, value)
+		case map[string]string:
+			if len(value) == 0 {
+				continue
+			}
+			expanded = op.expandMap(v, value)
+		default:
+			return fmt.Errorf("variable %q: unsupported value type %T", v.Name, value)
+		}
+
+		if first {
+			b.WriteString(op.first)
+			first = false
+		} else {
+			b.WriteString(op.sep)
+		}
+		b.WriteString(expanded)
+	}
+	return nil
+}
+
+func (op operator) expandString(v Variable, value string) string {
+	if v.MaxLength > 0 {
+		if runes := []rune(value); len(runes) > v.MaxLength {
+			value = string(runes[:v.MaxLength])
+		}
+	}
+	if !op.named {
+		return op.encode(value)
+	}
+	if value == "" {
+		return v.Name + op.ifEmpty
+	}
+	return v.Name + "=" + op.encode(value)
+}
+
+func (op operator) expandList(v Variable, items []string) string {
+	encoded := make([]string, len(items))
+	for i, item := range items {
+		encoded[i] = op.encode(item)
+		if v.Explode && op.named {
+			if item == "" {
+				encoded[i] = v.Name + op.ifEmpty
+			} else {
+				encoded[i] = v.Name + "=" + encoded[i]
+			}
+		}
+	}
+	if v.Explode {
+		return strings.Join(encoded, op.sep)
+	}
+	return op.prefixName(v, strings.Join(encoded, ","))
+}
+
+func (op operator) expandMap(v Variable, m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		switch {
+		case !v.Explode:
+			pairs = append(pairs, op.encode(key)+","+op.encode(m[key]))
+		case m[key] == "":
+			pairs = append(pairs, op.encode(key)+op.ifEmpty)
+		default:
+			pairs = append(pairs, op.encode(key)+"="+op.encode(m[key]))
+		}
+	}
+	if v.Explode {
+		return strings.Join(pairs, op.sep)
+	}
+	return op.prefixName(v, strings.Join(pairs, ","))
+}
+
+// prefixName names an unexploded composite value for named operators
+func (op operator) prefixName(v Variable, value string) string {
+	if !op.named {
+		return value
+	}
+	if value == "" {
+		return v.Name + op.ifEmpty
+	}
+	return v.Name + "=" + value
+}
+
+// encode percent-encodes value, leaving unreserved characters, and reserved characters and existing
+// percent-encodings for the + and # operators
+func (op operator) encode(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case isUnreserved(c):
+			b.WriteByte(c)
+		case op.reserved && isReserved(c):
+			b.WriteByte(c)
+		case op.reserved && c == '%' && i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]):
+			b.WriteString(value[i : i+3])
+			i += 2
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-._~", c) >= 0
+}
+
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+const (
+	// unreservedChar matches a character of a value expanded without the + and # operators, except '.'
+	unreservedChar = `[A-Za-z0-9\-_~]|%[0-9A-Fa-f]{2}`
+	// reservedChar matches a character of a value expanded with the + and # operators, except ',', '?' and '#'
+	reservedChar = `[A-Za-z0-9\-._~:/\[\]@!$&'()*+;=]|%[0-9A-Fa-f]{2}`
+)
+
+// compile builds the regexp the template matches URIs with
+func (t *Template) compile() error {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, p := range t.parts {
+		if p.expr == nil {
+			b.WriteString(regexp.QuoteMeta(p.literal))
+			continue
+		}
+		t.compileExpression(&b, p.expr)
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return err
+	}
+	t.re = re
+	return nil
+}
+
+func (t *Template) compileExpression(b *strings.Builder, e *expression) {
+	op := e.op
+
+	// Query expressions match a whole query string, whose parameters are picked out after matching
+	if op.name == "?" || op.name == "&" {
+		fmt.Fprintf(b, `(?:%s([^#]*))?`, regexp.QuoteMeta(op.first))
+		t.captures = append(t.captures, capture{kind: captureQuery, vars: e.vars})
+		return
+	}
+
+	// A lone variable may hold a list; with several, commas separate the variables
+	value := valuePattern(op, len(e.vars) == 1)
+
+	switch op.name {
+	case "", "+", "#":
+		if op.first != "" {
+			fmt.Fprintf(b, `(?:%s`, regexp.QuoteMeta(op.first))
+		}
+		for i, v := range e.vars {
+			if i == 0 {
+				fmt.Fprintf(b, `(%s)`, value)
+			} else {
+				fmt.Fprintf(b, `(?:,(%s))?`, value)
+			}
+			t.captures = append(t.captures, capture{kind: captureValue, v: v, sep: ","})
+		}
+		if op.first != "" {
+			b.WriteString(`)?`)
+		}
+
+	case ".", "/":
+		sep := regexp.QuoteMeta(op.sep)
+		for _, v := range e.vars {
+			if v.Explode {
+				fmt.Fprintf(b, `(?:%s((?:%s)+?(?:%s(?:%s)+?)*?))?`, sep, segmentChar(op), sep, segmentChar(op))
+				t.captures = append(t.captures, capture{kind: captureValue, v: v, sep: op.sep})
+			} else {
+				fmt.Fprintf(b, `(?:%s(%s))?`, sep, value)
+				t.captures = append(t.captures, capture{kind: captureValue, v: v, sep: ","})
+			}
+		}
+
+	case ";":
+		for _, v := range e.vars {
+			name := regexp.QuoteMeta(v.Name)
+			if v.Explode {
+				fmt.Fprintf(b, `((?:;%s(?:=(?:%s)*)?)+)?`, name, segmentChar(op))
+				t.captures = append(t.captures, capture{kind: captureNamed, v: v, sep: ";"})
+			} else {
+				fmt.Fprintf(b, `(?:;%s(?:=(%s))?)?`, name, valuePattern(op, true))
+				t.captures = append(t.captures, capture{kind: captureValue, v: v, sep: ","})
+			}
+		}
+	}
+}
+
+// segmentChar matches a character of a single value expanded with op
+func segmentChar(op operator) string {
+	if op.reserved {
+		return reservedChar
+	}
+	if op.name == "" || op.name == ";" {
+		return unreservedChar + `|\.`
+	}
+	return unreservedChar
+}
+
+// valuePattern matches a value expanded with op, which may be a comma-separated list if list is set. It is lazy,
+// so that a value leaves the text the rest of the template can match, e.g. the extension in {name}{.ext}.
+func valuePattern(op operator, list bool) string {
+	char := segmentChar(op)
+	if list {
+		return fmt.Sprintf(`(?:%s)+?(?:,(?:%s)+?)*?`, char, char)
+	}
+	return fmt.Sprintf(`(?:%s)+?`, char)
+}
+
+// Match reports whether uri is an expansion of the template, returning the values of the variables it
+// defines, decoded
+func (t *Template) Match(uri string) (map[string]string, bool) {
+	groups := t.re.FindStringSubmatchIndex(uri)
+	if groups == nil {
+		return nil, false
+	}
+
+	values := make(map[string]string)
+	query := url.Values{}
+	var queryVars []Variable
+	for i, c := range t.captures {
+		start, end := groups[2*(i+1)], groups[2*(i+1)+1]
+		if c.kind == captureQuery {
+			queryVars = append(queryVars, c.vars...)
+		}
+		if start < 0 {
+			continue
+		}
+		text := uri[start:end]
+
+		switch c.kind {
+		case captureValue:
+			items := strings.Split(text, c.sep)
+			for j, item := range items {
+				items[j] = unescape(item)
+			}
+			values[c.v.Name] = strings.Join(items, ",")
+
+		case captureNamed:
+			var items []string
+			for _, pair := range strings.Split(strings.TrimPrefix(text, c.sep), c.sep) {
+				_, value, _ := strings.Cut(pair, "=")
+				items = append(items, unescape(value))
+			}
+			values[c.v.Name] = strings.Join(items, ",")
+
+		case captureQuery:
+			parsed, err := url.ParseQuery(strings.TrimPrefix(text, "&"))
+			if err != nil {
+				return nil, false
+			}
+			for key, v := range parsed {
+				query[key] = append(query[key], v...)
+			}
+		}
+	}
+
+	for _, v := range queryVars {
+		if items, ok := query[v.Name]; ok {
+			values[v.Name] = strings.Join(items, ",")
+		}
+	}
+	return values, true
+}
+
+func unescape(s string) string {
+	if unescaped, err := url.PathUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// MoreSpecific reports whether a should be tried before b when matching a URI against several templates:
+// templates with more literal text first, then those with fewer variables
+func MoreSpecific(a, b *Template) bool {
+	if a.literalSize != b.literalSize {
+		return a.literalSize > b.literalSize
+	}
+	if na, nb := len(a.Variables()), len(b.Variables()); na != nb {
+		return na < nb
+	}
+	return a.raw < b.raw
+}
@@ -0,0 +1,175 @@
+package uritemplate
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The variables of the examples in section 3.2 of RFC 6570
+var rfcValues = map[string]interface{}{
+	"var":   "value",
+	"hello": "Hello World!",
+	"path":  "/foo/bar",
+	"empty": "",
+	"list":  []string{"red", "green", "blue"},
+	"keys":  map[string]string{"semi": ";", "dot": ".", "comma": ","},
+	"x":     "1024",
+	"y":     "768",
+	"who":   "fred",
+	"base":  "http://example.com/home/",
+}
+
+func TestExpand(t *testing.T) {
+	tests := map[string]string{
+		// Level 1
+		"{var}":   "value",
+		"{hello}": "Hello%20World%21",
+		// Level 2
+		"{+var}":           "value",
+		"{+hello}":         "Hello%20World!",
+		"{+path}/here":     "/foo/bar/here",
+		"here?ref={+path}": "here?ref=/foo/bar",
+		"X{#var}":          "X#value",
+		"X{#hello}":        "X#Hello%20World!",
+		// Level 3
+		"map?{x,y}":        "map?1024,768",
+		"{x,hello,y}":      "1024,Hello%20World%21,768",
+		"{+path,x}/here":   "/foo/bar,1024/here",
+		"{#x,hello,y}":     "#1024,Hello%20World!,768",
+		"X{.var}":          "X.value",
+		"X{.x,y}":          "X.1024.768",
+		"{/var}":           "/value",
+		"{/var,x}/here":    "/value/1024/here",
+		"{;x,y}":           ";x=1024;y=768",
+		"{;x,y,empty}":     ";x=1024;y=768;empty",
+		"{?x,y}":           "?x=1024&y=768",
+		"{?x,y,empty}":     "?x=1024&y=768&empty=",
+		"?fixed=yes{&x}":   "?fixed=yes&x=1024",
+		"{&x,y,empty}":     "&x=1024&y=768&empty=",
+		"{?x,undefined,y}": "?x=1024&y=768",
+		// Level 4
+		"{var:3}":         "val",
+		"{list}":          "red,green,blue",
+		"{list*}":         "red,green,blue",
+		"{keys}":          "comma,%2C,dot,.,semi,%3B",
+		"{keys*}":         "comma=%2C,dot=.,semi=%3B",
+		"{+path:6}/here":  "/foo/b/here",
+		"{#keys*}":        "#comma=,,dot=.,semi=;",
+		"X{.list*}":       "X.red.green.blue",
+		"{/list*,path:4}": "/red/green/blue/%2Ffoo",
+		"{;list*}":        ";list=red;list=green;list=blue",
+		"{;keys*}":        ";comma=%2C;dot=.;semi=%3B",
+		"{?var:3}":        "?var=val",
+		"{?list*}":        "?list=red&list=green&list=blue",
+		"{&keys*}":        "&comma=%2C&dot=.&semi=%3B",
+	}
+
+	for template, expected := range tests {
+		t.Run(template, func(t *testing.T) {
+			tmpl, err := Parse(template)
+			require.NoError(t, err)
+			expanded, err := tmpl.Expand(rfcValues)
+			require.NoError(t, err)
+			assert.Equal(t, expected, expanded)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, template := range []string{"{", "}", "{}", "a{b", "{=x}", "{x:0}", "{x:10000}", "{x y}", "{.}"} {
+		_, err := Parse(template)
+		assert.Error(t, err, template)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		template string
+		uri      string
+		values   map[string]string
+	}{
+		{"file:///logs/{date}.txt", "file:///logs/2024-05-01.txt", map[string]string{"date": "2024-05-01"}},
+		{"repo://{owner}/{repo}", "repo://traego/scaled-mcp", map[string]string{"owner": "traego", "repo": "scaled-mcp"}},
+		{"greeting://{name}", "greeting://Hello%20World", map[string]string{"name": "Hello World"}},
+		{"file:///{+path}", "file:///var/log/syslog", map[string]string{"path": "var/log/syslog"}},
+		{"doc://{id}{#section}", "doc://42#intro", map[string]string{"id": "42", "section": "intro"}},
+		{"doc://{id}{#section}", "doc://42", map[string]string{"id": "42"}},
+		{"img://{name}{.ext}", "img://logo.png", map[string]string{"name": "logo", "ext": "png"}},
+		{"api://users{/id,tab}", "api://users/7/posts", map[string]string{"id": "7", "tab": "posts"}},
+		{"api://users{/id,tab}", "api://users/7", map[string]string{"id": "7"}},
+		{"tree://{/path*}", "tree:///a/b/c", map[string]string{"path": "a,b,c"}},
+		{"map://{;x,y}", "map://;x=1024;y=768", map[string]string{"x": "1024", "y": "768"}},
+		{"colors://{;list*}", "colors://;list=red;list=green", map[string]string{"list": "red,green"}},
+		{"search://items{?q,limit}", "search://items?limit=10&q=go%20lang", map[string]string{"q": "go lang", "limit": "10"}},
+		{"search://items{?q}{&page}", "search://items?q=x&page=2", map[string]string{"q": "x", "page": "2"}},
+		{"search://items{?q}", "search://items", map[string]string{}},
+		{"tags://{list}", "tags://red,green", map[string]string{"list": "red,green"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template+" "+tt.uri, func(t *testing.T) {
+			values, ok := MustParse(tt.template).Match(tt.uri)
+			require.True(t, ok)
+			assert.Equal(t, tt.values, values)
+		})
+	}
+
+	for template, uri := range map[string]string{
+		"file:///logs/{date}.txt": "file:///logs/2024/05/01.txt",
+		"repo://{owner}/{repo}":   "repo://traego",
+		"greeting://{name}":       "greeting://",
+		"api://users{/id}":        "api://accounts/7",
+	} {
+		_, ok := MustParse(template).Match(uri)
+		assert.False(t, ok, "%s should not match %s", template, uri)
+	}
+}
+
+func TestMatchRoundTrip(t *testing.T) {
+	values := map[string]interface{}{"owner": "a b", "repo": "x/y", "ref": "v1.0"}
+	tmpl := MustParse("repo://{owner}/{repo}{?ref}")
+
+	uri, err := tmpl.Expand(values)
+	require.NoError(t, err)
+	assert.Equal(t, "repo://a%20b/x%2Fy?ref=v1.0", uri)
+
+	matched, ok := tmpl.Match(uri)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"owner": "a b", "repo": "x/y", "ref": "v1.0"}, matched)
+}
+
+func TestVariables(t *testing.T) {
+	tmpl := MustParse("api://{org}/items{/id*}{?q,limit:3}{&q}")
+	assert.Equal(t, []Variable{
+		{Name: "org"},
+		{Name: "id", Operator: "/", Explode: true},
+		{Name: "q", Operator: "?"},
+		{Name: "limit", Operator: "?", MaxLength: 3},
+		{Name: "q", Operator: "&"},
+	}, tmpl.Variables())
+	assert.Equal(t, []string{"org", "id", "q", "limit"}, tmpl.Names())
+}
+
+func TestMoreSpecific(t *testing.T) {
+	templates := []*Template{
+		MustParse("file:///{+path}"),
+		MustParse("file:///logs/{date}.txt"),
+		MustParse("file:///logs/{name}"),
+		MustParse("file:///logs/{year}/{name}"),
+	}
+	sort.SliceStable(templates, func(i, j int) bool { return MoreSpecific(templates[i], templates[j]) })
+
+	var order []string
+	for _, tmpl := range templates {
+		order = append(order, tmpl.String())
+	}
+	assert.Equal(t, []string{
+		"file:///logs/{date}.txt",
+		"file:///logs/{year}/{name}",
+		"file:///logs/{name}",
+		"file:///{+path}",
+	}, order)
+}
@@ -0,0 +1,9 @@
+package utils
+
+import "strings"
+
+// IsActorNotFound reports whether err is the actor system's error for an unknown actor. The actor system
+// formats the actor's name into the error, so it cannot be matched with errors.Is.
+func IsActorNotFound(err error) bool {
+	return err != nil && strings.HasSuffix(err.Error(), " not found")
+}
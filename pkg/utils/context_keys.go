@@ -1,5 +1,7 @@
 package utils
 
+import "context"
+
 type sessionIdCtxKey string
 type authInfoCtxKey string
 type traceIdCtxKey string
@@ -7,3 +9,11 @@ type traceIdCtxKey string
 var SessionIdCtx sessionIdCtxKey = "session_id"
 var AuthInfoCtx authInfoCtxKey = "auth"
 var TraceIdCtx traceIdCtxKey = "trace_id"
+
+// GetSessionId returns the id of the session a context belongs to, or an empty string
+func GetSessionId(ctx context.Context) string {
+	if sessionId, ok := ctx.Value(SessionIdCtx).(string); ok {
+		return sessionId
+	}
+	return ""
+}
@@ -1,12 +1,30 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func GetSessionActorName(sessionId string) string {
 	sessionActorName := fmt.Sprintf("%s-session", sessionId)
 	return sessionActorName
 }
 
+// SessionIdFromActorName returns the id of the session whose actor has the given name, and false if the name
+// is not a session actor's
+func SessionIdFromActorName(actorName string) (string, bool) {
+	sessionId, ok := strings.CutSuffix(actorName, "-session")
+	if !ok || sessionId == "" {
+		return "", false
+	}
+	return sessionId, true
+}
+
 func GetDefaultSSEConnectionName(sessionId string) string {
 	return fmt.Sprintf("%s-channels-default", sessionId)
 }
+
+// GetSSEClientActorName returns the name of the actor streaming a 2024-11-05 SSE client's messages
+func GetSSEClientActorName(sessionId string) string {
+	return fmt.Sprintf("%s-client", sessionId)
+}
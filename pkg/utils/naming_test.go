@@ -42,6 +42,17 @@ func TestGetSessionActorName(t *testing.T) {
 	}
 }
 
+func TestSessionIdFromActorName(t *testing.T) {
+	sessionId, ok := SessionIdFromActorName(GetSessionActorName("abc123"))
+	assert.True(t, ok)
+	assert.Equal(t, "abc123", sessionId)
+
+	for _, name := range []string{"root", "-session", "abc123-channels-default", "abc123-client"} {
+		_, ok := SessionIdFromActorName(name)
+		assert.False(t, ok, name)
+	}
+}
+
 func TestGetDefaultSSEConnectionName(t *testing.T) {
 	testCases := []struct {
 		name           string
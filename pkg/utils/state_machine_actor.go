@@ -50,6 +50,9 @@ type StateMachineActor struct {
 
 	// Unhandled message handler (optional)
 	unhandledHandler func(ctx *actor.ReceiveContext, data Data, message interface{}) Data
+
+	// Stop handler (optional)
+	stopHandler func(ctx context.Context, data Data)
 }
 
 // NewStateMachineActor creates a new state machine actor
@@ -76,6 +79,12 @@ func (a *StateMachineActor) WhenUnhandled(handler func(ctx *actor.ReceiveContext
 	return a
 }
 
+// WhenStopped registers a handler called with the final data when the actor stops
+func (a *StateMachineActor) WhenStopped(handler func(ctx context.Context, data Data)) *StateMachineActor {
+	a.stopHandler = handler
+	return a
+}
+
 // PreStart is called when the actor is started
 func (a *StateMachineActor) PreStart(ctx context.Context) error {
 	a.mu.RLock()
@@ -155,9 +164,14 @@ func (a *StateMachineActor) Receive(ctx *actor.ReceiveContext) {
 func (a *StateMachineActor) PostStop(ctx context.Context) error {
 	a.mu.RLock()
 	finalState := a.currentState
+	finalData := a.data
+	stopHandler := a.stopHandler
 	a.mu.RUnlock()
 
 	slog.DebugContext(ctx, "stopping state machine actor: "+a.id, "final_state", finalState, "state_id", a.id)
+	if stopHandler != nil {
+		stopHandler(ctx, finalData)
+	}
 	return nil
 }
 
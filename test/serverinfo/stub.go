@@ -0,0 +1,80 @@
+package serverinfo
+
+import (
+	"log/slog"
+
+	"github.com/traego/scaled-mcp/pkg/audit"
+	"github.com/traego/scaled-mcp/pkg/config"
+	"github.com/traego/scaled-mcp/pkg/metrics"
+	"github.com/traego/scaled-mcp/pkg/protocol"
+	"github.com/traego/scaled-mcp/pkg/ratelimit"
+	"github.com/traego/scaled-mcp/pkg/resources"
+	"github.com/traego/scaled-mcp/pkg/sessionstore"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Stub implements config.McpServerInfo with zero values. Test fixtures embed it and implement only
+// the methods their tests rely on.
+type Stub struct{}
+
+func (Stub) GetFeatureRegistry() resources.FeatureRegistry {
+	return resources.FeatureRegistry{}
+}
+
+func (Stub) GetServerCapabilities() protocol.ServerCapabilities {
+	return protocol.ServerCapabilities{}
+}
+
+func (Stub) GetServerConfig() *config.ServerConfig {
+	return nil
+}
+
+func (Stub) GetExecutors() config.MethodHandler {
+	return nil
+}
+
+func (Stub) GetAuthHandler() config.AuthHandler {
+	return nil
+}
+
+func (Stub) GetTraceHandler() config.TraceHandler {
+	return nil
+}
+
+func (Stub) GetRateLimiter() *ratelimit.Limiter {
+	return nil
+}
+
+func (Stub) GetAuditSink() audit.Sink {
+	return nil
+}
+
+func (Stub) GetMetrics() metrics.Recorder {
+	return nil
+}
+
+func (Stub) GetTracerProvider() trace.TracerProvider {
+	return nil
+}
+
+func (Stub) GetLogger() *slog.Logger {
+	return nil
+}
+
+func (Stub) GetSessionStore() sessionstore.Store {
+	return nil
+}
+
+func (Stub) GetSessionHooks() *config.SessionHooks {
+	return nil
+}
+
+func (Stub) GetToolInterceptors() []config.ToolInterceptor {
+	return nil
+}
+
+func (Stub) GetResourceSubscriptions() *resources.Subscriptions {
+	return nil
+}
+
+var _ config.McpServerInfo = Stub{}